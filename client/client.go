@@ -0,0 +1,161 @@
+// Package client calls the pipeline serve API (see GET /openapi.json for the full description).
+//
+//	c := client.New("http://pipeline:8090", nil)
+//	weeks, err := c.Weeks(ctx)
+//	kid, err := c.AnalyzeKid(ctx, profileID, weeks[len(weeks)-1].WeekNumber)
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"ai-production-pipeline/internal/gold"
+	"ai-production-pipeline/internal/silver"
+	"ai-production-pipeline/internal/weekmanager"
+)
+
+// Response types, shared with the server so they cannot drift from what it encodes
+type (
+	Week        = weekmanager.WeekRange
+	KidAnalysis = silver.EnhancedKidData
+	Report      = gold.AIReport
+)
+
+// maxErrorBody caps how much of an error response is kept in the error message
+const maxErrorBody = 4096
+
+// Client calls one serve API instance
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+}
+
+// New returns a client for baseURL; httpClient may be nil for http.DefaultClient
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), httpClient: httpClient}
+}
+
+// SetToken sends token as a bearer token with every request (the server's serve.token_env)
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// StatusError is a non-200 response; Message is the server's plain-text error
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("pipeline API: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Weeks lists the weeks with data, numbered as in pipeline runs (GET /weeks)
+func (c *Client) Weeks(ctx context.Context) ([]Week, error) {
+	var weeks []Week
+	return weeks, c.getJSON(ctx, "/weeks", nil, &weeks)
+}
+
+// AnalyzeKid returns one kid's analytics for a week (GET /analysis/kid)
+func (c *Client) AnalyzeKid(ctx context.Context, profileID string, week int) (*KidAnalysis, error) {
+	var kid KidAnalysis
+	if err := c.getJSON(ctx, "/analysis/kid", kidQuery(profileID, week), &kid); err != nil {
+		return nil, err
+	}
+	return &kid, nil
+}
+
+// KidReport returns a kid's stored Gold report (GET /reports/kid, needs data.database_output on the server)
+func (c *Client) KidReport(ctx context.Context, profileID string, week int) (*Report, error) {
+	var report Report
+	if err := c.getJSON(ctx, "/reports/kid", kidQuery(profileID, week), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// AnalyzeWeek streams every kid's analytics for a week to fn as they arrive (GET /analysis/week).
+// An error from fn stops the stream and is returned.
+func (c *Client) AnalyzeWeek(ctx context.Context, week int, fn func(KidAnalysis) error) error {
+	resp, err := c.get(ctx, "/analysis/week", url.Values{"week": {strconv.Itoa(week)}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var kid KidAnalysis
+		if err := json.Unmarshal(scanner.Bytes(), &kid); err != nil {
+			return fmt.Errorf("failed to parse week stream: %w", err)
+		}
+		if err := fn(kid); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// OpenAPI returns the server's OpenAPI document (GET /openapi.json)
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var document json.RawMessage
+	return document, c.getJSON(ctx, "/openapi.json", nil, &document)
+}
+
+// getJSON decodes a 200 JSON response into v
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	resp, err := c.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", path, err)
+	}
+	return nil
+}
+
+// get sends a GET and returns the response when it is 200, or a StatusError
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+	return resp, nil
+}
+
+// kidQuery is the query of the per-kid endpoints
+func kidQuery(profileID string, week int) url.Values {
+	return url.Values{"profile_id": {profileID}, "week": {strconv.Itoa(week)}}
+}
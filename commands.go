@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"ai-production-pipeline/internal/bronze"
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/costledger"
+	"ai-production-pipeline/internal/gold"
+	"ai-production-pipeline/internal/processor"
+	"ai-production-pipeline/internal/runlock"
+	"ai-production-pipeline/internal/silver"
+	"ai-production-pipeline/internal/uuid"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/sirupsen/logrus"
+)
+
+// command is one pipeline subcommand; name may be two words ("silver diff")
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(args []string) int
+}
+
+// commands lists the subcommands in help order
+func commands() []command {
+	return []command{
+		{"run", "run [flags]", "Run Silver and Gold for every available week (the default)", runRun},
+		{"backfill", "backfill --from YYYY-MM-DD --to YYYY-MM-DD [flags]", "Run the weeks overlapping a date range", runBackfill},
+		{"report", "report --week YYYY-MM-DD | --last [--profile-id ID [--stream]]", "Run a single week, or one kid's report", runReport},
+		{"weeks", "weeks", "List the weeks found in the database", runWeeks},
+		{"validate-config", "validate-config [--config path]", "Check config.yaml, prompts and templates without DB or API access", runValidateConfig},
+		{"regenerate", "regenerate [--older-than HASH] [--yes]", "Refresh stored reports made with older templates", runRegenerate},
+		{"flush-deferred", "flush-deferred", "Send prompts queued while the AI provider was down (gold.outage_queue)", runFlushDeferred},
+		{"archive", "archive", "Move old stored reports to cold storage (database_output.archive)", runArchive},
+		{"rename-week-files", "rename-week-files [--yes]", "Rename outputs named by week number to their start date", runRenameWeekFiles},
+		{"render", "render --week YYYY-MM-DD", "Write HTML/PDF copies of a week's reports (gold.render)", runRender},
+		{"prompt show", "prompt show --profile ID --week YYYY-MM-DD", "Print the prompt for one kid and week (no API call)", runPromptShow},
+		{"compare", "compare [--week YYYY-MM-DD] A B", "Compare a week's reports across two environments", runCompare},
+		{"silver diff", "silver diff old.json new.json", "Compare two Silver outputs field by field", runSilverDiff},
+		{"serve", "serve [--addr :8090] [--schedule \"0 6 * * MON\"]", "Serve Silver analytics; run weeks on a schedule", runServe},
+		{"openapi", "openapi [--out file]", "Print the serve API's OpenAPI document", runOpenAPI},
+		{"cost report", "cost report [--by week|month|model|tenant] [--csv f]", "Break down recorded AI cost with changes vs the prior period (cost_ledger)", runCostReport},
+		{"smoke", "smoke [--real-api] [--skip-db] [--timeout 30s]", "Post-deploy check: one synthetic kid through the full path, nothing persisted", runSmoke},
+	}
+}
+
+// runCommand dispatches args to a subcommand. No subcommand (or only flags) means run, as before.
+func runCommand(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runRun(args)
+	}
+	if args[0] == "help" {
+		printUsage()
+		return 0
+	}
+	for _, cmd := range commands() {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd.run(args[len(words):])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "❌ Error: unknown command %q\n\n", strings.Join(args, " "))
+	printUsage()
+	return 2
+}
+
+// printUsage lists the subcommands
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: pipeline <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-52s %s\n", cmd.usage, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'pipeline <command> --help' for a command's flags. Every command except serve and")
+	fmt.Fprintln(os.Stderr, "openapi accepts --output json: one JSON result on stdout, logs on stderr.")
+}
+
+// addRunFlags registers the flags shared by run, backfill and report
+func addRunFlags(fs *flag.FlagSet, opts *runOptions) {
+	fs.IntVar(&opts.Limit, "limit", 0, "Process only the first N kids per week (0 = all)")
+	fs.Float64Var(&opts.Sample, "sample", 0, "Process a random X% sample of kids per week (0 = all)")
+	fs.Int64Var(&opts.Seed, "seed", 42, "Seed for --sample so the same kids are picked every run")
+	fs.StringVar(&opts.CampaignFile, "campaign-file", "", "Campaign notes injected into prompts via {{CAMPAIGN}} for this run")
+	fs.StringVar(&opts.Order, "order", orderOldestFirst, "Week processing order: oldest-first or newest-first (latest week's reports land first)")
+	fs.BoolVar(&opts.Redeliver, "redeliver", false, "Deliver reports again even if they were delivered before (delivery.enabled)")
+	fs.BoolVar(&opts.Fresh, "fresh", false, "Regenerate every report instead of keeping those already in the week's output (gold.reuse_existing)")
+	fs.BoolVar(&opts.NoCache, "no-cache", false, "Call the AI API even when openai.response_cache has the response")
+	fs.StringVar(&opts.Granularity, "granularity", granularityWeek, "Report period: week, or month to roll weeks up into monthly reports (monthly.*)")
+	fs.BoolVar(&opts.FromBronze, "from-bronze", false, "Run Silver on each week's latest bronze snapshot instead of the live tables (bronze.*)")
+	fs.BoolVar(&opts.Resume, "resume", false, "Continue an interrupted run: skip completed weeks and reuse checkpointed reports (run.checkpoint_dir)")
+	fs.StringVar(&opts.Fixtures, "fixtures", "", "Read profiles, wallets, transactions and missions from JSON files in this directory and use a mock AI (no database)")
+}
+
+// runRun runs every available week: pipeline [run] [flags]
+func runRun(args []string) int {
+	opts := runOptions{}
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	addRunFlags(fs, &opts)
+	out := addOutputFlag(fs, "run")
+	fs.Parse(args)
+	return executeRun(opts, out)
+}
+
+// runBackfill runs the weeks overlapping a date range: pipeline backfill --from YYYY-MM-DD --to YYYY-MM-DD
+func runBackfill(args []string) int {
+	opts := runOptions{}
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	from := fs.String("from", "", "First day of the range (YYYY-MM-DD)")
+	to := fs.String("to", "", "Last day of the range (YYYY-MM-DD, inclusive)")
+	addRunFlags(fs, &opts)
+	out := addOutputFlag(fs, "backfill")
+	fs.Parse(args)
+
+	if *from == "" || *to == "" {
+		fs.Usage()
+		return out.fail(2, fmt.Errorf("--from and --to are required"))
+	}
+	var err error
+	if opts.From, err = time.Parse("2006-01-02", *from); err != nil {
+		return out.fail(2, fmt.Errorf("invalid --from %q (want YYYY-MM-DD)", *from))
+	}
+	if opts.To, err = time.Parse("2006-01-02", *to); err != nil {
+		return out.fail(2, fmt.Errorf("invalid --to %q (want YYYY-MM-DD)", *to))
+	}
+	if opts.To.Before(opts.From) {
+		return out.fail(2, fmt.Errorf("--to is before --from"))
+	}
+	return executeRun(opts, out)
+}
+
+// runReport runs one week: pipeline report --week YYYY-MM-DD | --last [--profile-id ID [--stream]]
+func runReport(args []string) int {
+	opts := runOptions{}
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	week := fs.String("week", "", "Week start date, YYYY-MM-DD (as listed by pipeline weeks)")
+	fs.BoolVar(&opts.LastWeek, "last", false, "The latest week")
+	fs.StringVar(&opts.ProfileID, "profile-id", "", "Only this kid: its report is regenerated and merged into the week's output")
+	fs.BoolVar(&opts.Stream, "stream", false, "With --profile-id: stream the completion and print it to stderr as it is generated")
+	addRunFlags(fs, &opts)
+	out := addOutputFlag(fs, "report")
+	fs.Parse(args)
+
+	if (*week != "") == opts.LastWeek {
+		fs.Usage()
+		return out.fail(2, fmt.Errorf("pass either --week YYYY-MM-DD or --last"))
+	}
+	if *week != "" {
+		if _, err := weekmanager.ParseKey(*week); err != nil {
+			return out.fail(2, err)
+		}
+		opts.WeekStart = *week
+	}
+	if opts.ProfileID != "" {
+		if _, err := uuid.Parse(opts.ProfileID); err != nil {
+			return out.fail(2, fmt.Errorf("invalid --profile-id: %w", err))
+		}
+	}
+	return executeRun(opts, out)
+}
+
+// executeRun validates run options and runs the pipeline until done or interrupted
+func executeRun(opts runOptions, out *cliOutput) int {
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+	if opts.Limit < 0 || opts.Sample < 0 || opts.Sample > 100 {
+		return out.fail(2, fmt.Errorf("--limit must be >= 0 and --sample must be between 0 and 100"))
+	}
+	if opts.Order != orderOldestFirst && opts.Order != orderNewestFirst {
+		return out.fail(2, fmt.Errorf("--order must be %s or %s", orderOldestFirst, orderNewestFirst))
+	}
+
+	// Setup signal handling for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		fmt.Fprintln(os.Stderr, "\n🛑 Received interrupt signal, shutting down gracefully...")
+		cancel()
+	}()
+
+	// Run the application
+	var result runResult
+	if err := runAutomatedPipeline(ctx, opts, &result); err != nil {
+		return out.exit(1, result, err)
+	}
+	return out.done(result)
+}
+
+// runValidateConfig checks a config file and everything it points to, without DB or API access:
+// pipeline validate-config [--config path]
+func runValidateConfig(args []string) int {
+	fs := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Config file to check")
+	out := addOutputFlag(fs, "validate-config")
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	_, problems, err := checkConfig(*configPath)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	result := validationResult{Config: *configPath, Valid: len(problems) == 0, Problems: problems}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", problem)
+		}
+		return out.exit(1, result, fmt.Errorf("%d problems in %s", len(problems), *configPath))
+	}
+	out.printf("✅ %s is valid\n", *configPath)
+	return out.done(result)
+}
+
+// validationResult is validate-config's result with --output json
+type validationResult struct {
+	Config   string   `json:"config"`
+	Valid    bool     `json:"valid"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// checkConfig loads a config file and lists its problems; err is set when it cannot be loaded at all
+func checkConfig(path string) (*config.Config, []string, error) {
+	var problems []string
+	if err := config.CheckUnknownKeys(path); err != nil {
+		problems = append(problems, err.Error())
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Run.MaxDuration != "" {
+		if _, err := time.ParseDuration(cfg.Run.MaxDuration); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid run.max_duration %q: %v", cfg.Run.MaxDuration, err))
+		}
+	}
+	if cfg.Delivery.PendingTimeout != "" {
+		if _, err := time.ParseDuration(cfg.Delivery.PendingTimeout); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid delivery.pending_timeout %q: %v", cfg.Delivery.PendingTimeout, err))
+		}
+	}
+	if _, err := processor.ParseProvider(cfg.OpenAI.Provider); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := processor.ParseAuthStyle(cfg.OpenAI.AuthStyle); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := runlock.NewLocker(nil, logrus.New(), cfg.Run.Lock); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := silver.NewAmountFormat(cfg.Silver.Amounts); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := silver.NewFeatureRegistry(cfg.Silver.Features); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := silver.NewInterestRule(cfg.Silver.Interest); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := gold.AutoTuneConfig(cfg.Batch); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.Gold.Render.Enabled {
+		if _, err := gold.NewReportRenderer(cfg.Gold.Render); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if _, err := silver.NewDeletedProfilePolicy(cfg.Silver.DeletedProfiles); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := silver.ValidateMetricsQuery(cfg.Silver); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := silver.NewBalancePolicy(cfg.Silver.Balances); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.Silver.MaxConcurrent < 0 {
+		problems = append(problems, "silver.max_concurrent cannot be negative")
+	} else if limit := cfg.Database.MaxOpenConns; limit > 0 && cfg.Silver.MaxConcurrent > limit {
+		problems = append(problems, fmt.Sprintf("silver.max_concurrent (%d) exceeds database.max_open_conns (%d)", cfg.Silver.MaxConcurrent, limit))
+	}
+	if err := writeBatching(cfg).Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if archive := cfg.Data.DatabaseOutput.Archive; archive.Enabled && archive.Dir == "" {
+		problems = append(problems, "data.database_output.archive.dir is required when archiving is enabled")
+	}
+	if _, err := costledger.NewLedger(nil, logrus.New(), cfg.CostLedger.Table, ""); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := bronze.NewLayer(nil, logrus.New(), cfg.Bronze, ""); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := weekmanager.CheckDetection(cfg.Calendar); err != nil {
+		problems = append(problems, err.Error())
+	}
+	switch cfg.Silver.PartialWeekMode {
+	case "", "include", "skip":
+	default:
+		problems = append(problems, fmt.Sprintf("silver.partial_week_mode must be include or skip, got %q", cfg.Silver.PartialWeekMode))
+	}
+	if _, _, err := cfg.Status.SLO.Settings(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	for lang, file := range cfg.Monthly.TemplateFiles {
+		if _, err := os.Stat(file); err != nil {
+			problems = append(problems, fmt.Sprintf("monthly.template_files.%s: %v", lang, err))
+		}
+	}
+	if cfg.Delivery.Enabled && cfg.Delivery.OutboxDir == "" {
+		problems = append(problems, "delivery.outbox_dir is required when delivery is enabled")
+	}
+	if err := gold.ValidateConfig(cfg); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return cfg, problems, nil
+}
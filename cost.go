@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/costledger"
+	"ai-production-pipeline/internal/gold"
+	"ai-production-pipeline/internal/processor"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+// costTenant is the tenant the cost ledger records: cost_ledger.tenant, else the run lock namespace
+func costTenant(cfg *config.Config) string {
+	if cfg.CostLedger.Tenant != "" {
+		return cfg.CostLedger.Tenant
+	}
+	return cfg.Run.Lock.Namespace
+}
+
+// newRunID returns an ID for the run: its start time plus a random suffix, so runs starting in the
+// same second differ. Only lowercase letters, digits and underscores, as it names the replay schema.
+func newRunID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return time.Now().Format("20060102_150405") + "_" + hex.EncodeToString(suffix)
+}
+
+// openCostLedger returns the cost ledger (nil when cost_ledger is disabled)
+func openCostLedger(cfg *config.Config, logger *logrus.Logger, db *sql.DB) (*costledger.Ledger, error) {
+	if !cfg.CostLedger.Enabled {
+		return nil, nil
+	}
+	ledger, err := costledger.NewLedger(db, logger, cfg.CostLedger.Table, costTenant(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cost ledger: %w", err)
+	}
+	return ledger, nil
+}
+
+// recordCosts writes the run's AI usage to the cost ledger, per label and model. Week labels get
+// their start date so the report can order them. It runs after the run, even a cancelled one.
+func recordCosts(ledger *costledger.Ledger, logger *logrus.Logger, runID string, goldLayer *gold.GoldLayer, weeks []weekmanager.WeekRange) {
+	if ledger == nil {
+		return
+	}
+	weekStarts := make(map[string]time.Time, len(weeks))
+	for _, week := range weeks {
+		weekStarts[week.Label] = week.StartDate
+	}
+
+	var entries []costledger.Entry
+	for _, proc := range []*processor.AIProcessor{goldLayer.GetAIProcessor(), goldLayer.GetConsensusProcessor()} {
+		if proc == nil {
+			continue
+		}
+		for _, usage := range proc.GetTokenTracker().GetUsageByLabel() {
+			entry := costledger.Entry{
+				Label:            usage.Label,
+				Model:            usage.Model,
+				Requests:         usage.Requests,
+				PromptTokens:     usage.PromptTokens,
+				CompletionTokens: usage.CompletionTokens,
+				CostUSD:          usage.EstimatedCost,
+			}
+			if start, ok := weekStarts[usage.Label]; ok {
+				entry.WeekStart = &start
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := ledger.Record(ctx, runID, entries); err != nil {
+		logger.Warnf("⚠️  Could not record run costs: %v", err)
+		return
+	}
+	logger.Infof("💵 Recorded %d cost entries for run %s (tenant %s)", len(entries), runID, ledger.Tenant())
+}
+
+// runCostReport prints cost breakdowns from the cost ledger:
+// pipeline cost report [--by week|month|model|tenant] [--since YYYY-MM-DD] [--tenant T] [--csv file]
+func runCostReport(args []string) int {
+	fs := flag.NewFlagSet("cost report", flag.ExitOnError)
+	by := fs.String("by", costledger.ByMonth, "Breakdown: week, month, model or tenant (model and tenant cover the latest month)")
+	since := fs.String("since", "", "First day reported, YYYY-MM-DD (default: the first day of the month three months ago)")
+	tenant := fs.String("tenant", "", "Only this tenant (default: all)")
+	csvPath := fs.String("csv", "", "Also export the rows to this CSV file")
+	out := addOutputFlag(fs, "cost report")
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+	if err := costledger.ValidateBy(*by); err != nil {
+		return out.fail(2, err)
+	}
+	now := time.Now()
+	query := costledger.Query{By: *by, Tenant: *tenant, Since: time.Date(now.Year(), now.Month()-3, 1, 0, 0, 0, 0, time.Local)}
+	if *since != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *since, time.Local)
+		if err != nil {
+			return out.fail(2, fmt.Errorf("--since must be YYYY-MM-DD: %w", err))
+		}
+		query.Since = parsed
+	}
+
+	godotenv.Load()
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to load config: %w", err))
+	}
+	logger := setupLogger(cfg)
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to connect to database: %w", err))
+	}
+	defer db.Close()
+	ledger, err := costledger.NewLedger(db, logger, cfg.CostLedger.Table, costTenant(cfg))
+	if err != nil {
+		return out.fail(1, err)
+	}
+
+	report, err := ledger.Report(context.Background(), query)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	printCostReport(out, report)
+	if *csvPath != "" {
+		if err := writeCostCSV(*csvPath, report); err != nil {
+			return out.exit(1, report, err)
+		}
+		out.printf("📄 Exported %d rows to %s\n", len(report.Rows), *csvPath)
+	}
+	return out.done(report)
+}
+
+// printCostReport prints the report as a table
+func printCostReport(out *cliOutput, report *costledger.Report) {
+	title := fmt.Sprintf("💵 AI cost by %s since %s", report.By, report.Since)
+	if report.Period != "" {
+		title = fmt.Sprintf("💵 AI cost by %s in %s (vs %s)", report.By, report.Period, report.PriorPeriod)
+	}
+	if report.Tenant != "" {
+		title += ", tenant " + report.Tenant
+	}
+	out.printf("%s\n\n", title)
+	if len(report.Rows) == 0 {
+		out.printf("No recorded usage (is cost_ledger.enabled on?)\n")
+		return
+	}
+
+	w := tabwriter.NewWriter(out.text(), 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "\tRequests\tPrompt tokens\tCompletion tokens\tCost USD\tPrior USD\tDelta USD\tDelta %\t")
+	for _, row := range report.Rows {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.2f\t%s\t%s\t%s\t\n", row.Key, row.Requests, row.PromptTokens, row.CompletionTokens,
+			row.CostUSD, optionalAmount(row.PriorCostUSD, "%.2f"), optionalAmount(row.DeltaUSD, "%+.2f"), optionalAmount(row.DeltaPercent, "%+.1f%%"))
+	}
+	fmt.Fprintf(w, "Total\t\t\t\t%.2f\t\t\t\t\n", report.TotalUSD)
+	w.Flush()
+}
+
+// optionalAmount formats a value that may be missing
+func optionalAmount(value *float64, format string) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf(format, *value)
+}
+
+// writeCostCSV exports the report rows for spreadsheets
+func writeCostCSV(path string, report *costledger.Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{report.By, "requests", "prompt_tokens", "completion_tokens", "cost_usd", "prior_cost_usd", "delta_usd", "delta_percent"})
+	csvAmount := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', 6, 64)
+	}
+	for _, row := range report.Rows {
+		w.Write([]string{
+			row.Key,
+			strconv.Itoa(row.Requests),
+			strconv.FormatInt(row.PromptTokens, 10),
+			strconv.FormatInt(row.CompletionTokens, 10),
+			strconv.FormatFloat(row.CostUSD, 'f', 6, 64),
+			csvAmount(row.PriorCostUSD),
+			csvAmount(row.DeltaUSD),
+			csvAmount(row.DeltaPercent),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
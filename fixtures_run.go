@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fixtures"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fixtureReportFile is the mock AI's report in a fixtures directory (optional; default: the smoke test's)
+const fixtureReportFile = "ai_report.json"
+
+// setupFixtures prepares a run on a fixtures directory (run --fixtures): source rows come from its
+// JSON files, AI calls go to the local mock, and the features that need the database are switched
+// off. Outputs, run state, checkpoints and the delivery outbox go to <dir>/output, so a QA run never
+// touches the deployment's files.
+func setupFixtures(ctx context.Context, cfg *config.Config, dir string, logger *logrus.Logger) (*fixtures.Set, error) {
+	set, err := fixtures.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := smokeReport
+	data, err := os.ReadFile(filepath.Join(dir, fixtureReportFile))
+	switch {
+	case err == nil:
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("%s is not valid JSON", filepath.Join(dir, fixtureReportFile))
+		}
+		report = string(data)
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read mock report: %w", err)
+	}
+	url, err := startMockAI(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	useMockAI(cfg, url)
+
+	var off []string
+	disable := func(enabled *bool, name string) {
+		if *enabled {
+			*enabled = false
+			off = append(off, name)
+		}
+	}
+	disable(&cfg.Database.CheckSchema, "database.check_schema")
+	disable(&cfg.Silver.MetricStore.Enabled, "silver.metric_store")
+	disable(&cfg.Data.DatabaseOutput.Enabled, "data.database_output")
+	disable(&cfg.CostLedger.Enabled, "cost_ledger")
+	disable(&cfg.Categorization.Enabled, "categorization")
+	disable(&cfg.Bronze.Enabled, "bronze")
+	disable(&cfg.Run.Lock.Enabled, "run.lock")
+	disable(&cfg.Gold.OptionalSections.Enabled, "gold.optional_sections")
+	disable(&cfg.Gold.OperatorNotes.Enabled, "gold.operator_notes")
+	disable(&cfg.OpenAI.ResponseCache.Enabled, "openai.response_cache")
+	if cfg.Prompts.DBTable != "" {
+		cfg.Prompts.DBTable = ""
+		off = append(off, "prompts.db_table")
+	}
+
+	output := filepath.Join(dir, "output")
+	cfg.Data.OutputDir = output
+	cfg.Status.StateFile = filepath.Join(output, "run_state.json")
+	cfg.Run.CheckpointDir = filepath.Join(output, "checkpoints")
+	cfg.Delivery.OutboxDir = filepath.Join(output, "outbox")
+	cfg.Gold.OutageQueue.Dir = filepath.Join(output, "deferred")
+	for _, path := range []*string{&cfg.Status.SummaryFile, &cfg.Status.MetricsFile, &cfg.Status.SLO.HistoryFile} {
+		if *path != "" {
+			*path = filepath.Join(output, filepath.Base(*path))
+		}
+	}
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", output, err)
+	}
+
+	counts := set.Counts()
+	logger.Warnf("🧪 --fixtures: reading %s (%d profiles, %d wallets, %d transactions, %d missions) with a mock AI, no database",
+		dir, counts["profiles"], counts["wallets"], counts["wallet_transactions"], counts["missions"])
+	logger.Warnf("   Outputs go to %s; delivery sends every report (no ledger)", output)
+	if len(off) > 0 {
+		logger.Warnf("   Switched off (need the database or the real AI): %s", strings.Join(off, ", "))
+	}
+	return set, nil
+}
@@ -0,0 +1,131 @@
+// Package apispec describes the serve-mode HTTP API as an OpenAPI 3 document. Response schemas are
+// generated from the Go types the handlers encode, so the document cannot drift from the handlers.
+package apispec
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/gold"
+	"ai-production-pipeline/internal/silver"
+	"ai-production-pipeline/internal/weekmanager"
+)
+
+// Path is where serve publishes the document
+const Path = "/openapi.json"
+
+// Document returns the OpenAPI document for the serve API
+func Document() map[string]interface{} {
+	schemas := &schemaSet{components: map[string]interface{}{}}
+	week := schemas.ref(reflect.TypeOf(weekmanager.WeekRange{}))
+	kid := schemas.ref(reflect.TypeOf(silver.EnhancedKidData{}))
+	report := schemas.ref(reflect.TypeOf(gold.AIReport{}))
+
+	profileParam := parameter("profile_id", "Kid profile ID (UUID)", map[string]interface{}{"type": "string", "format": "uuid"})
+	weekParam := parameter("week", "Week number, as listed by /weeks and pipeline weeks", map[string]interface{}{"type": "integer", "minimum": 1})
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "AI production pipeline: Silver analytics API",
+			"description": "Weekly kid analytics (pipeline serve) and stored Gold reports. Errors are plain text.",
+			"version":     buildinfo.Version,
+		},
+		"paths": map[string]interface{}{
+			"/weeks": map[string]interface{}{
+				"get": operation("listWeeks", "Weeks with data, numbered as in pipeline runs", nil,
+					jsonResponse("Available weeks", map[string]interface{}{"type": "array", "items": week}),
+					http.StatusUnauthorized, http.StatusInternalServerError),
+			},
+			"/analysis/kid": map[string]interface{}{
+				"get": operation("analyzeKid", "One kid's analytics for a week, with trends against the preceding weeks",
+					[]interface{}{profileParam, weekParam},
+					jsonResponse("The kid's analytics", kid),
+					http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError),
+			},
+			"/analysis/week": map[string]interface{}{
+				"get": operation("analyzeWeek", "Every kid's analytics for a week, streamed as newline-delimited JSON (one object per line)",
+					[]interface{}{weekParam},
+					map[string]interface{}{
+						"description": "One kid per line; a stream that ends early was cut off by a server-side error",
+						"content":     map[string]interface{}{"application/x-ndjson": map[string]interface{}{"schema": kid}},
+					},
+					http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound),
+			},
+			"/reports/kid": map[string]interface{}{
+				"get": operation("getKidReport", "A kid's stored Gold report, rehydrated from cold storage when archived (needs data.database_output)",
+					[]interface{}{profileParam, weekParam},
+					jsonResponse("The stored report", report),
+					http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError),
+			},
+			Path: map[string]interface{}{
+				"get": public(operation("getOpenAPI", "This document", nil,
+					jsonResponse("OpenAPI 3 document", map[string]interface{}{"type": "object"}))),
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+		// The token (serve.token_env) is required when serve listens on a non-loopback address
+		"security": []interface{}{map[string]interface{}{"bearerAuth": []interface{}{}}},
+	}
+}
+
+// JSON returns the document encoded for pipeline openapi and the /openapi.json endpoint
+func JSON() ([]byte, error) {
+	return json.MarshalIndent(Document(), "", "  ")
+}
+
+// Handler serves the document
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := JSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// operation describes a GET endpoint with its success response and plain-text error statuses
+func operation(id, summary string, parameters []interface{}, ok map[string]interface{}, errorStatuses ...int) map[string]interface{} {
+	responses := map[string]interface{}{"200": ok}
+	for _, status := range errorStatuses {
+		responses[strconv.Itoa(status)] = map[string]interface{}{
+			"description": http.StatusText(status),
+			"content":     map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+		}
+	}
+	op := map[string]interface{}{"operationId": id, "summary": summary, "responses": responses}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+	return op
+}
+
+// public marks an operation that needs no token
+func public(op map[string]interface{}) map[string]interface{} {
+	op["security"] = []interface{}{}
+	return op
+}
+
+// parameter describes a required query parameter
+func parameter(name, description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"name": name, "in": "query", "required": true, "description": description, "schema": schema}
+}
+
+// jsonResponse describes a JSON success response
+func jsonResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}},
+	}
+}
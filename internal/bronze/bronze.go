@@ -0,0 +1,411 @@
+package bronze
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// historyDays is how far before the week activity rows are kept: Silver compares a week with the
+// two weeks before it
+const historyDays = 14
+
+// restoreBatchSize is how many rows go into one INSERT when a snapshot is restored
+const restoreBatchSize = 1000
+
+// table is a source table Silver reads. Activity tables are limited to the week and its history;
+// the others are taken whole (wallet balances are current state, not time-ranged).
+type table struct {
+	Name     string
+	Ranged   bool // Only rows with created_at in [week start - historyDays, week end)
+	KeepLate bool // Ranged, but rows after the week end are kept too
+	Optional bool // From the config: skipped when there is no such table
+}
+
+// tables are the source tables every snapshot holds, in restore order. Later transactions are
+// kept because Silver undoes them from the current balances (silver.balances.mode: transactions).
+var tables = []table{
+	{Name: "profiles"},
+	{Name: "wallets"},
+	{Name: "wallet_transactions", Ranged: true, KeepLate: true},
+	{Name: "missions", Ranged: true},
+}
+
+// schemaNamePattern restricts the replay schema to a plain identifier
+var schemaNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// sqlTablePattern finds the names after FROM and JOIN in features SQL; a name followed by . or ( is
+// a schema or a function (group 2). CTE names match too; they are not tables, so they are skipped
+// when extracting.
+var sqlTablePattern = regexp.MustCompile(`(?i)\b(?:from|join)\s+([a-z_][a-z0-9_]*)\b(\s*[.(])?`)
+
+// SourceTables lists the tables Silver reads besides profiles, wallets, transactions and missions:
+// spending categories, operator notes, section preferences, balance snapshots and the tables named
+// in features SQL, as far as the config enables them
+func SourceTables(cfg *config.Config) []string {
+	var names []string
+	if cfg.Categorization.Enabled {
+		name := cfg.Categorization.Table
+		if name == "" {
+			name = "transaction_categories"
+		}
+		names = append(names, name)
+	}
+	if cfg.Gold.OperatorNotes.Enabled && cfg.Gold.OperatorNotes.Table != "" {
+		names = append(names, cfg.Gold.OperatorNotes.Table)
+	}
+	if cfg.Gold.OptionalSections.Enabled && cfg.Gold.OptionalSections.Table != "" {
+		names = append(names, cfg.Gold.OptionalSections.Table)
+	}
+	if cfg.Silver.Balances.Mode == "snapshots" {
+		name := cfg.Silver.Balances.SnapshotTable
+		if name == "" {
+			name = "balance_snapshots"
+		}
+		names = append(names, name)
+	}
+	for _, feature := range cfg.Silver.Features {
+		for _, match := range sqlTablePattern.FindAllStringSubmatch(feature.SQL, -1) {
+			if match[2] == "" {
+				names = append(names, strings.ToLower(match[1]))
+			}
+		}
+	}
+	return names
+}
+
+// Snapshot is one week's raw source rows (bronze_YYYY-MM-DD_vN.json), each row as Postgres'
+// row_to_json of the source row
+type Snapshot struct {
+	Week      string                       `json:"week"`
+	StartDate string                       `json:"start_date"`
+	EndDate   string                       `json:"end_date"`
+	Version   int                          `json:"version"`
+	TakenAt   string                       `json:"taken_at"`
+	Checksum  string                       `json:"checksum"` // SHA256 of the rows; equal checksums mean equal rows
+	RowCounts map[string]int               `json:"row_counts"`
+	Tables    map[string][]json.RawMessage `json:"tables"`
+	Metadata  *buildinfo.Metadata          `json:"metadata,omitempty"`
+}
+
+// Layer snapshots Silver's source rows per week and restores snapshots for replays
+type Layer struct {
+	db           *sql.DB
+	logger       *logrus.Logger
+	dir          string
+	replaySchema string
+	compression  string
+	metadata     *buildinfo.Metadata
+	tables       []table // tables plus AddTables', in restore order
+}
+
+// NewLayer checks the bronze settings (db may be nil to only validate them)
+func NewLayer(db *sql.DB, logger *logrus.Logger, cfg config.BronzeConfig, compression string) (*Layer, error) {
+	l := &Layer{
+		db:           db,
+		logger:       logger,
+		dir:          cfg.Dir,
+		replaySchema: cfg.ReplaySchema,
+		compression:  compression,
+		tables:       append([]table(nil), tables...),
+	}
+	if l.dir == "" {
+		l.dir = filepath.Join("data", "bronze")
+	}
+	if l.replaySchema == "" {
+		l.replaySchema = "bronze_replay"
+	}
+	if !schemaNamePattern.MatchString(l.replaySchema) {
+		return nil, fmt.Errorf("bronze.replay_schema %q must be a lowercase identifier", l.replaySchema)
+	}
+	if l.replaySchema == "public" {
+		return nil, fmt.Errorf("bronze.replay_schema cannot be public: restoring replaces its tables")
+	}
+	return l, nil
+}
+
+// SetMetadata stamps build and config metadata into the snapshots
+func (l *Layer) SetMetadata(metadata buildinfo.Metadata) {
+	l.metadata = &metadata
+}
+
+// AddTables snapshots the named tables too, whole (see SourceTables). Names that are not tables in
+// the live schema are skipped.
+func (l *Layer) AddTables(names ...string) {
+	for _, name := range names {
+		known := !schemaNamePattern.MatchString(name)
+		for _, t := range l.tables {
+			known = known || t.Name == name
+		}
+		if !known {
+			l.tables = append(l.tables, table{Name: name, Optional: true})
+		}
+	}
+}
+
+// SetRunID gives the run its own replay schema (<replay_schema>_<runID>), so replays running at the
+// same time never restore over each other's snapshot. Drop it with DropReplaySchema when the run ends.
+func (l *Layer) SetRunID(runID string) error {
+	schema := l.replaySchema + "_" + strings.ToLower(runID)
+	if !schemaNamePattern.MatchString(schema) || len(schema) > 63 {
+		return fmt.Errorf("replay schema %q for run %s is not a valid identifier", schema, runID)
+	}
+	l.replaySchema = schema
+	return nil
+}
+
+// ReplaySchema is the schema snapshots are restored into
+func (l *Layer) ReplaySchema() string {
+	return l.replaySchema
+}
+
+// DropReplaySchema removes the replay schema and the snapshot restored into it
+func (l *Layer) DropReplaySchema(ctx context.Context) error {
+	if _, err := l.db.ExecContext(ctx, "DROP SCHEMA IF EXISTS "+pq.QuoteIdentifier(l.replaySchema)+" CASCADE"); err != nil {
+		return fmt.Errorf("failed to drop schema %s: %w", l.replaySchema, err)
+	}
+	return nil
+}
+
+// weekPrefix is the start of a week's snapshot file names (labels are not file-safe, start dates are)
+func weekPrefix(week weekmanager.WeekRange) string {
+	return "bronze_" + week.StartDate.Format("2006-01-02") + "_v"
+}
+
+// Latest returns the path and version of a week's newest snapshot (version 0 when there is none)
+func (l *Layer) Latest(week weekmanager.WeekRange) (string, int, error) {
+	prefix := weekPrefix(week)
+	matches, err := filepath.Glob(filepath.Join(l.dir, prefix+"*.json*"))
+	if err != nil {
+		return "", 0, err
+	}
+	latestPath, latest := "", 0
+	for _, match := range matches {
+		name := strings.TrimPrefix(filepath.Base(match), prefix)
+		version, err := strconv.Atoi(name[:strings.Index(name, ".")])
+		if err != nil || version <= latest {
+			continue
+		}
+		latestPath, latest = strings.TrimSuffix(strings.TrimSuffix(match, ".gz"), ".zst"), version
+	}
+	return latestPath, latest, nil
+}
+
+// Load reads a snapshot file
+func Load(path string) (*Snapshot, error) {
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bronze snapshot: %w", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse bronze snapshot %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// Extract snapshots the week's source rows. A new version is written only when the rows differ from
+// the week's latest snapshot; otherwise that snapshot is returned.
+func (l *Layer) Extract(ctx context.Context, week weekmanager.WeekRange) (*Snapshot, string, error) {
+	startDate, endDate := week.FormatDateRange()
+	snapshot := &Snapshot{
+		Week:      week.Label,
+		StartDate: startDate,
+		EndDate:   endDate,
+		TakenAt:   time.Now().Format(time.RFC3339),
+		RowCounts: make(map[string]int),
+		Tables:    make(map[string][]json.RawMessage),
+		Metadata:  l.metadata,
+	}
+	from := week.StartDate.AddDate(0, 0, -historyDays).Format("2006-01-02")
+	hash := sha256.New()
+	for _, t := range l.tables {
+		if t.Optional {
+			exists, err := l.tableExists(ctx, t.Name)
+			if err != nil {
+				return nil, "", err
+			}
+			if !exists {
+				continue
+			}
+		}
+		rows, err := l.extractTable(ctx, t, from, endDate)
+		if err != nil {
+			return nil, "", err
+		}
+		snapshot.Tables[t.Name] = rows
+		snapshot.RowCounts[t.Name] = len(rows)
+		fmt.Fprintf(hash, "%s\n", t.Name)
+		for _, row := range rows {
+			hash.Write(row)
+			hash.Write([]byte("\n"))
+		}
+	}
+	snapshot.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	latestPath, latest, err := l.Latest(week)
+	if err != nil {
+		return nil, "", err
+	}
+	if latest > 0 {
+		previous, err := Load(latestPath)
+		if err == nil && previous.Checksum == snapshot.Checksum {
+			l.logger.Infof("🥉 Bronze snapshot unchanged for %s (v%d)", week.Label, latest)
+			return previous, latestPath, nil
+		}
+	}
+
+	snapshot.Version = latest + 1
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create bronze directory %s: %w", l.dir, err)
+	}
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal bronze snapshot: %w", err)
+	}
+	path := filepath.Join(l.dir, fmt.Sprintf("%s%d.json", weekPrefix(week), snapshot.Version))
+	writtenPath, err := fileio.WriteFile(path, encoded, l.compression)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	l.logger.Infof("🥉 Bronze snapshot v%d saved to: %s (%s)", snapshot.Version, writtenPath, snapshot.describeCounts())
+	return snapshot, path, nil
+}
+
+// tableExists reports whether the live schema has a table called name
+func (l *Layer) tableExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	if err := l.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, "public."+name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", name, err)
+	}
+	return exists, nil
+}
+
+// extractTable reads a table's rows as JSON, sorted so the same rows always give the same checksum
+func (l *Layer) extractTable(ctx context.Context, t table, from, to string) ([]json.RawMessage, error) {
+	query := fmt.Sprintf(`SELECT row_to_json(t)::text FROM %s t ORDER BY 1`, pq.QuoteIdentifier(t.Name))
+	args := []interface{}{}
+	if t.Ranged && t.KeepLate {
+		query = fmt.Sprintf(`
+			SELECT row_to_json(t)::text FROM %s t
+			WHERE created_at >= $1::date
+			ORDER BY 1
+		`, pq.QuoteIdentifier(t.Name))
+		args = append(args, from)
+	} else if t.Ranged {
+		query = fmt.Sprintf(`
+			SELECT row_to_json(t)::text FROM %s t
+			WHERE created_at >= $1::date AND created_at < $2::date
+			ORDER BY 1
+		`, pq.QuoteIdentifier(t.Name))
+		args = append(args, from, to)
+	}
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s: %w", t.Name, err)
+	}
+	defer rows.Close()
+
+	result := []json.RawMessage{}
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %w", t.Name, err)
+		}
+		result = append(result, json.RawMessage(row))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s: %w", t.Name, err)
+	}
+	return result, nil
+}
+
+// describeCounts lists the row counts for logs, e.g. "missions=12, profiles=40"
+func (s *Snapshot) describeCounts() string {
+	names := make([]string, 0, len(s.RowCounts))
+	for name := range s.RowCounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, s.RowCounts[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Restore replaces the replay schema's tables with the snapshot's rows, in one transaction. The
+// tables are created like the live ones, so Silver's queries run unchanged against a connection
+// whose search_path starts with the replay schema. A configured table the snapshot lacks (it was
+// taken before the table was configured) is still read live, with a warning.
+func (l *Layer) Restore(ctx context.Context, snapshot *Snapshot) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin bronze restore: %w", err)
+	}
+	defer tx.Rollback()
+
+	schema := pq.QuoteIdentifier(l.replaySchema)
+	if _, err := tx.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+schema); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", l.replaySchema, err)
+	}
+	for _, t := range l.tables {
+		rows, ok := snapshot.Tables[t.Name]
+		if !ok {
+			if !t.Optional {
+				return fmt.Errorf("bronze snapshot v%d of %s has no %s rows", snapshot.Version, snapshot.Week, t.Name)
+			}
+			if exists, err := l.tableExists(ctx, t.Name); err == nil && exists {
+				l.logger.Warnf("⚠️  Bronze snapshot v%d of %s has no %s: Silver reads the live table", snapshot.Version, snapshot.Week, t.Name)
+			}
+			continue
+		}
+		target := schema + "." + pq.QuoteIdentifier(t.Name)
+		statements := []string{
+			"DROP TABLE IF EXISTS " + target,
+			fmt.Sprintf("CREATE TABLE %s (LIKE public.%s INCLUDING DEFAULTS)", target, pq.QuoteIdentifier(t.Name)),
+		}
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("failed to prepare %s: %w", target, err)
+			}
+		}
+		insert := fmt.Sprintf("INSERT INTO %[1]s SELECT * FROM json_populate_recordset(NULL::%[1]s, $1::json)", target)
+		for start := 0; start < len(rows); start += restoreBatchSize {
+			end := start + restoreBatchSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			batch, err := json.Marshal(rows[start:end])
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s rows: %w", t.Name, err)
+			}
+			if _, err := tx.ExecContext(ctx, insert, string(batch)); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", t.Name, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit bronze restore: %w", err)
+	}
+	l.logger.Infof("🥉 Restored bronze snapshot v%d of %s into schema %s (%s)", snapshot.Version, snapshot.Week, l.replaySchema, snapshot.describeCounts())
+	return nil
+}
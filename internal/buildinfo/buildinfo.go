@@ -0,0 +1,158 @@
+package buildinfo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/prompts"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Set at build time:
+//
+//	go build -ldflags "-X ai-production-pipeline/internal/buildinfo.Version=v1.2.0 \
+//	  -X ai-production-pipeline/internal/buildinfo.GitSHA=$(git rev-parse --short HEAD) \
+//	  -X ai-production-pipeline/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	GitSHA    = ""
+	BuildTime = ""
+)
+
+// Metadata identifies exactly what produced an artifact: binary, config, model and prompts
+type Metadata struct {
+	Version           string `json:"version"`
+	GitSHA            string `json:"git_sha"`
+	BuildTime         string `json:"build_time,omitempty"`
+	GoVersion         string `json:"go_version"`
+	Environment       string `json:"environment"`
+	ConfigHash        string `json:"config_hash"`
+	Model             string `json:"model"`
+	Provider          string `json:"provider"`
+	TemplateHash      string `json:"template_hash"`
+	SystemMessageHash string `json:"system_message_hash"`
+	CampaignFile      string `json:"campaign_file,omitempty"`
+	CampaignHash      string `json:"campaign_hash,omitempty"`
+}
+
+// Collect builds the metadata for a run from the loaded config and the file it was read from
+func Collect(cfg *config.Config, configPath string) Metadata {
+	metadata := Metadata{
+		Version:           Version,
+		GitSHA:            gitSHA(),
+		BuildTime:         BuildTime,
+		GoVersion:         runtime.Version(),
+		Environment:       environment(),
+		ConfigHash:        fileHash(configPath),
+		Model:             cfg.OpenAI.Model,
+		Provider:          provider(cfg.OpenAI.Provider, cfg.OpenAI.BaseURL),
+		TemplateHash:      promptHash(cfg.Prompts.TemplateFile, prompts.DefaultTemplate),
+		SystemMessageHash: promptHash(cfg.Prompts.SystemMessageFile, prompts.DefaultSystemMessage),
+	}
+	if cfg.Prompts.ExtraContextFile != "" {
+		metadata.CampaignFile = cfg.Prompts.ExtraContextFile
+		metadata.CampaignHash = fileHash(cfg.Prompts.ExtraContextFile)
+	}
+	return metadata
+}
+
+// LogBanner logs the environment banner at the start of a run
+func (m Metadata) LogBanner(logger *logrus.Logger) {
+	logger.WithFields(logrus.Fields{
+		"version":     m.Version,
+		"git_sha":     m.GitSHA,
+		"environment": m.Environment,
+		"config_hash": m.ConfigHash,
+		"model":       m.Model,
+		"provider":    m.Provider,
+		"template":    m.TemplateHash,
+	}).Info("🏷️  Build and config fingerprint")
+}
+
+// PromptsHash hashes a set of prompt texts keyed by what each one is ("template/vi", ...), so that
+// editing, adding or removing any of them changes it
+func PromptsHash(texts map[string]string) string {
+	keys := make([]string, 0, len(texts))
+	for key := range texts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s\x00%d\x00%s", key, len(texts[key]), texts[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// gitSHA returns the ldflags SHA, falling back to the VCS revision embedded by the Go toolchain
+func gitSHA() string {
+	if GitSHA != "" {
+		return GitSHA
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+				return s.Value[:7]
+			}
+		}
+	}
+	return "unknown"
+}
+
+// environment returns PIPELINE_ENV (default "development")
+func environment() string {
+	if env := os.Getenv("PIPELINE_ENV"); env != "" {
+		return env
+	}
+	return "development"
+}
+
+// provider returns the configured AI provider, or for OpenAI-compatible endpoints the API base URL host
+func provider(name, baseURL string) string {
+	if name != "" && name != "openai" {
+		return name
+	}
+	if baseURL == "" {
+		return "openai"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return baseURL
+	}
+	host := strings.TrimPrefix(u.Hostname(), "api.")
+	return strings.TrimSuffix(strings.TrimSuffix(host, ".com"), ".azure")
+}
+
+// fileHash returns the ContentHash of the file ("missing" if unreadable)
+func fileHash(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "missing"
+	}
+	return ContentHash(data)
+}
+
+// promptHash hashes a prompt file, or the embedded default Gold uses when the file does not exist
+func promptHash(path, embedded string) string {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return ContentHash([]byte(embedded))
+	}
+	return fileHash(path)
+}
+
+// ContentHash returns the first 12 hex chars of the SHA-256 of data
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
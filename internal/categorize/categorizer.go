@@ -0,0 +1,369 @@
+package categorize
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/processor"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Other is the category for descriptions that fit none of the configured categories
+const Other = "other"
+
+// usageLabel is the token tracking bucket for categorization calls
+const usageLabel = "categorization"
+
+// identifierPattern restricts configured table/column names to plain identifiers (they are put into SQL)
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Stats summarizes one categorization run
+type Stats struct {
+	Transactions int // Uncategorized spending transactions found
+	Descriptions int // Distinct descriptions among them
+	FromCache    int // Descriptions already categorized earlier
+	Classified   int // Descriptions classified by the model in this run
+	Written      int // Category rows written back
+	FailedCalls  int // Batches that failed (left for the next run)
+}
+
+// Categorizer classifies wallet_transactions descriptions into spending categories and
+// writes them to a categories table that Silver joins for its spending breakdown
+type Categorizer struct {
+	db         *sql.DB
+	proc       *processor.AIProcessor
+	logger     *logrus.Logger
+	cfg        config.CategorizationConfig
+	categories []string
+	cache      map[string]string // Normalized description → category
+}
+
+// NewCategorizer creates a categorizer; proc is reused with cfg.Model as a per-call override
+func NewCategorizer(db *sql.DB, proc *processor.AIProcessor, logger *logrus.Logger, cfg config.CategorizationConfig) (*Categorizer, error) {
+	if cfg.Table == "" {
+		cfg.Table = "transaction_categories"
+	}
+	if cfg.DescriptionColumn == "" {
+		cfg.DescriptionColumn = "description"
+	}
+	if !identifierPattern.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("invalid categorization.table %q", cfg.Table)
+	}
+	if !identifierPattern.MatchString(cfg.DescriptionColumn) {
+		return nil, fmt.Errorf("invalid categorization.description_column %q", cfg.DescriptionColumn)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+
+	var categories []string
+	seen := make(map[string]bool)
+	for _, category := range append(cfg.Categories, Other) {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category != "" && !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+
+	return &Categorizer{
+		db:         db,
+		proc:       proc,
+		logger:     logger,
+		cfg:        cfg,
+		categories: categories,
+		cache:      make(map[string]string),
+	}, nil
+}
+
+// Table returns the categories table name
+func (c *Categorizer) Table() string {
+	return c.cfg.Table
+}
+
+// Run categorizes uncategorized spending transactions created in [from, to).
+// Each distinct description is sent to the model once; failed batches stay uncategorized for the next run.
+func (c *Categorizer) Run(ctx context.Context, from, to time.Time) (Stats, error) {
+	var stats Stats
+
+	if err := c.ensureTable(ctx); err != nil {
+		return stats, err
+	}
+	if err := c.loadCache(ctx); err != nil {
+		return stats, err
+	}
+
+	pending, err := c.pendingTransactions(ctx, from, to)
+	if err != nil {
+		return stats, err
+	}
+	stats.Transactions = len(pending)
+
+	// Distinct descriptions not categorized before
+	var unknown []string
+	seen := make(map[string]bool)
+	for _, tx := range pending {
+		key := normalize(tx.description)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, ok := c.cache[key]; ok {
+			stats.FromCache++
+			continue
+		}
+		unknown = append(unknown, tx.description)
+	}
+	stats.Descriptions = len(seen)
+
+	if c.cfg.MaxPerRun > 0 && len(unknown) > c.cfg.MaxPerRun {
+		c.logger.Warnf("⚠️  %d new descriptions, classifying the first %d (categorization.max_per_run)", len(unknown), c.cfg.MaxPerRun)
+		unknown = unknown[:c.cfg.MaxPerRun]
+	}
+
+	for start := 0; start < len(unknown); start += c.cfg.BatchSize {
+		if ctx.Err() != nil {
+			break
+		}
+		end := start + c.cfg.BatchSize
+		if end > len(unknown) {
+			end = len(unknown)
+		}
+
+		categories, err := c.classify(ctx, unknown[start:end])
+		if err != nil {
+			stats.FailedCalls++
+			c.logger.Warnf("⚠️  Categorization batch %d-%d failed: %v", start+1, end, err)
+			continue
+		}
+		for description, category := range categories {
+			c.cache[normalize(description)] = category
+		}
+		stats.Classified += len(categories)
+	}
+
+	written, err := c.writeBack(ctx, pending)
+	stats.Written = written
+	if err != nil {
+		return stats, err
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"transactions": stats.Transactions,
+		"descriptions": stats.Descriptions,
+		"from_cache":   stats.FromCache,
+		"classified":   stats.Classified,
+		"written":      stats.Written,
+		"failed_calls": stats.FailedCalls,
+	}).Info("🏷️  Transaction categorization complete")
+
+	return stats, nil
+}
+
+// ensureTable creates the categories table if it does not exist
+func (c *Categorizer) ensureTable(ctx context.Context) error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			transaction_id TEXT PRIMARY KEY,
+			description    TEXT NOT NULL,
+			category       TEXT NOT NULL,
+			model          TEXT NOT NULL DEFAULT '',
+			categorized_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS %[1]s_description_idx ON %[1]s (lower(description));
+	`, c.cfg.Table)
+	if _, err := c.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create %s: %w", c.cfg.Table, err)
+	}
+	return nil
+}
+
+// loadCache reads earlier categorizations so repeated descriptions are never re-sent
+func (c *Categorizer) loadCache(ctx context.Context) error {
+	query := fmt.Sprintf(`
+		SELECT DISTINCT ON (lower(description)) description, category
+		FROM %s
+		ORDER BY lower(description), categorized_at DESC
+	`, c.cfg.Table)
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to load categories: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var description, category string
+		if err := rows.Scan(&description, &category); err != nil {
+			return fmt.Errorf("failed to scan category: %w", err)
+		}
+		c.cache[normalize(description)] = category
+	}
+	return rows.Err()
+}
+
+type pendingTransaction struct {
+	id          string
+	description string
+}
+
+// pendingTransactions returns spending transactions in [from, to) without a category
+func (c *Categorizer) pendingTransactions(ctx context.Context, from, to time.Time) ([]pendingTransaction, error) {
+	query := fmt.Sprintf(`
+		SELECT wt.id::text, TRIM(wt.%[2]s)
+		FROM wallet_transactions wt
+		LEFT JOIN %[1]s tc ON tc.transaction_id = wt.id::text
+		WHERE tc.transaction_id IS NULL
+		  AND wt.type = 'withdraw'
+		  AND wt.created_at >= $1
+		  AND wt.created_at < $2
+		  AND COALESCE(TRIM(wt.%[2]s), '') <> ''
+	`, c.cfg.Table, c.cfg.DescriptionColumn)
+	rows, err := c.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query uncategorized transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []pendingTransaction
+	for rows.Next() {
+		var tx pendingTransaction
+		if err := rows.Scan(&tx.id, &tx.description); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		pending = append(pending, tx)
+	}
+	return pending, rows.Err()
+}
+
+// classification is the structured model response
+type classification struct {
+	Results []struct {
+		Index    int    `json:"i"`
+		Category string `json:"category"`
+	} `json:"results"`
+}
+
+// classify asks the model for one category per description
+func (c *Categorizer) classify(ctx context.Context, descriptions []string) (map[string]string, error) {
+	type item struct {
+		Index int    `json:"i"`
+		Text  string `json:"text"`
+	}
+	items := make([]item, len(descriptions))
+	for i, description := range descriptions {
+		items[i] = item{Index: i, Text: description}
+	}
+	input, err := json.Marshal(map[string]interface{}{"descriptions": items})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal descriptions: %w", err)
+	}
+
+	system := fmt.Sprintf("You classify children's spending descriptions from a kids' finance app (mostly Vietnamese). "+
+		"Return exactly one category per description, chosen from: %s. Use %q when none fits.",
+		strings.Join(c.categories, ", "), Other)
+
+	result, _, err := processor.DoJSON[classification](ctx, c.proc, processor.Request{
+		Messages: []processor.Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: string(input)},
+		},
+		Model:          c.cfg.Model,
+		ResponseSchema: c.responseSchema(),
+		SchemaName:     "transaction_categories",
+		UsageLabel:     usageLabel,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(c.categories))
+	for _, category := range c.categories {
+		allowed[category] = true
+	}
+
+	categories := make(map[string]string, len(descriptions))
+	for _, r := range result.Results {
+		if r.Index < 0 || r.Index >= len(descriptions) {
+			continue
+		}
+		category := strings.ToLower(strings.TrimSpace(r.Category))
+		if !allowed[category] {
+			category = Other
+		}
+		categories[descriptions[r.Index]] = category
+	}
+	return categories, nil
+}
+
+// responseSchema restricts the model output to the configured categories
+func (c *Categorizer) responseSchema() json.RawMessage {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"results": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"i":        map[string]interface{}{"type": "integer"},
+						"category": map[string]interface{}{"type": "string", "enum": c.categories},
+					},
+					"required":             []string{"i", "category"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"results"},
+		"additionalProperties": false,
+	}
+	data, _ := json.Marshal(schema)
+	return data
+}
+
+// writeBack stores a category for every pending transaction whose description is now known
+func (c *Categorizer) writeBack(ctx context.Context, pending []pendingTransaction) (int, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin category write: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s (transaction_id, description, category, model)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (transaction_id) DO NOTHING
+	`, c.cfg.Table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare category write: %w", err)
+	}
+	defer stmt.Close()
+
+	written := 0
+	for _, p := range pending {
+		category, ok := c.cache[normalize(p.description)]
+		if !ok {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, p.id, p.description, category, c.cfg.Model); err != nil {
+			return 0, fmt.Errorf("failed to write category for %s: %w", p.id, err)
+		}
+		written++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit categories: %w", err)
+	}
+	return written, nil
+}
+
+// normalize makes descriptions that differ only in case or spacing share a cache entry
+func normalize(description string) string {
+	return strings.ToLower(strings.Join(strings.Fields(description), " "))
+}
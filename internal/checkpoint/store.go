@@ -0,0 +1,172 @@
+package checkpoint
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Store records completed weeks and every report generated so far, so a run interrupted at week 5
+// of 12 can be resumed without paying for the same AI calls again. Each week has a JSONL file of
+// kid reports, appended as they are generated, and a done marker written once the week commits.
+type Store struct {
+	dir    string
+	logger *logrus.Logger
+	mu     sync.Mutex
+}
+
+// kidEntry is one line of a week's kids file
+type kidEntry struct {
+	ProfileID string          `json:"profile_id"`
+	Report    json.RawMessage `json:"report"`
+}
+
+// weekDone is the content of a week's done marker
+type weekDone struct {
+	Week        string `json:"week"`
+	CompletedAt string `json:"completed_at"`
+}
+
+// NewStore opens the checkpoint directory, creating it if missing
+func NewStore(dir string, logger *logrus.Logger) (*Store, error) {
+	if dir == "" {
+		dir = filepath.Join("data", "checkpoints")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory %s: %w", dir, err)
+	}
+	return &Store{dir: dir, logger: logger}, nil
+}
+
+// weekKey turns a week label into a file name
+func weekKey(week string) string {
+	sum := sha256.Sum256([]byte(week))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (s *Store) kidsPath(week string) string {
+	return filepath.Join(s.dir, "week_"+weekKey(week)+".kids.jsonl")
+}
+
+func (s *Store) donePath(week string) string {
+	return filepath.Join(s.dir, "week_"+weekKey(week)+".done.json")
+}
+
+// WeekDone reports whether week was completed by an earlier run (false for a nil store)
+func (s *Store) WeekDone(week string) bool {
+	if s == nil {
+		return false
+	}
+	_, err := os.Stat(s.donePath(week))
+	return err == nil
+}
+
+// MarkWeekDone records week as completed and drops its kid checkpoints
+func (s *Store) MarkWeekDone(week string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(weekDone{Week: week, CompletedAt: time.Now().Format(time.RFC3339)})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.donePath(week), data, 0644); err != nil {
+		return fmt.Errorf("failed to checkpoint week %s: %w", week, err)
+	}
+	if err := os.Remove(s.kidsPath(week)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.logger.Warnf("⚠️  Could not remove kid checkpoints for %s: %v", week, err)
+	}
+	return nil
+}
+
+// AppendKid records a kid's generated report for week
+func (s *Store) AppendKid(week, profileID string, report interface{}) error {
+	if s == nil || profileID == "" {
+		return nil
+	}
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint for %s: %w", profileID, err)
+	}
+	line, err := json.Marshal(kidEntry{ProfileID: profileID, Report: payload})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.kidsPath(week), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open kid checkpoints for %s: %w", week, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to checkpoint %s: %w", profileID, err)
+	}
+	return nil
+}
+
+// Kids returns the reports checkpointed for week, keyed by profile ID (the last one wins). A line cut
+// short by a crash is skipped.
+func (s *Store) Kids(week string) (map[string]json.RawMessage, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.kidsPath(week))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open kid checkpoints for %s: %w", week, err)
+	}
+	defer file.Close()
+
+	kids := make(map[string]json.RawMessage)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry kidEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.ProfileID == "" {
+			s.logger.Warnf("⚠️  Skipping unreadable kid checkpoint for %s", week)
+			continue
+		}
+		kids[entry.ProfileID] = entry.Report
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read kid checkpoints for %s: %w", week, err)
+	}
+	return kids, nil
+}
+
+// ClearWeek removes a week's checkpoints, so a run that is not resuming starts the week from scratch.
+// Call it with the week's run lock held: another run may be resuming the week.
+func (s *Store) ClearWeek(week string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, path := range []string{s.kidsPath(week), s.donePath(week)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to clear checkpoint %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package checkpoint
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store, err := NewStore(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	return store
+}
+
+func TestKidsLastReportWins(t *testing.T) {
+	store := newTestStore(t)
+	store.AppendKid("Tuần 1", "kid-a", map[string]string{"summary": "first"})
+	store.AppendKid("Tuần 1", "kid-a", map[string]string{"summary": "second"})
+	store.AppendKid("Tuần 1", "kid-b", map[string]string{"summary": "b"})
+	store.AppendKid("Tuần 2", "kid-c", map[string]string{"summary": "c"})
+
+	kids, err := store.Kids("Tuần 1")
+	if err != nil {
+		t.Fatalf("Kids: %v", err)
+	}
+	if len(kids) != 2 || string(kids["kid-a"]) != `{"summary":"second"}` {
+		t.Errorf("Kids = %s", kids)
+	}
+}
+
+func TestKidsSkipsTruncatedLine(t *testing.T) {
+	store := newTestStore(t)
+	store.AppendKid("Tuần 1", "kid-a", map[string]string{"summary": "a"})
+	file, err := os.OpenFile(store.kidsPath("Tuần 1"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"profile_id":"kid-b","rep`) // Cut short by a crash
+	file.Close()
+
+	kids, err := store.Kids("Tuần 1")
+	if err != nil {
+		t.Fatalf("Kids: %v", err)
+	}
+	if _, ok := kids["kid-a"]; !ok || len(kids) != 1 {
+		t.Errorf("Kids = %s, want only kid-a", kids)
+	}
+}
+
+func TestMarkWeekDoneDropsKids(t *testing.T) {
+	store := newTestStore(t)
+	store.AppendKid("Tuần 1", "kid-a", map[string]string{"summary": "a"})
+	if err := store.MarkWeekDone("Tuần 1"); err != nil {
+		t.Fatalf("MarkWeekDone: %v", err)
+	}
+	if !store.WeekDone("Tuần 1") {
+		t.Error("week not done after MarkWeekDone")
+	}
+	if kids, _ := store.Kids("Tuần 1"); len(kids) != 0 {
+		t.Errorf("kid checkpoints kept after MarkWeekDone: %s", kids)
+	}
+}
+
+func TestClearWeekOnlyClearsThatWeek(t *testing.T) {
+	store := newTestStore(t)
+	store.AppendKid("Tuần 1", "kid-a", map[string]string{"summary": "a"})
+	store.MarkWeekDone("Tuần 2")
+	store.AppendKid("Tuần 3", "kid-b", map[string]string{"summary": "b"})
+	store.MarkWeekDone("Tuần 3")
+	store.AppendKid("Tuần 3", "kid-c", map[string]string{"summary": "c"})
+
+	if err := store.ClearWeek("Tuần 3"); err != nil {
+		t.Fatalf("ClearWeek: %v", err)
+	}
+	if store.WeekDone("Tuần 3") {
+		t.Error("Tuần 3 still done after ClearWeek")
+	}
+	if kids, _ := store.Kids("Tuần 3"); len(kids) != 0 {
+		t.Errorf("Tuần 3 kids kept after ClearWeek: %s", kids)
+	}
+	if !store.WeekDone("Tuần 2") {
+		t.Error("ClearWeek cleared another week's done marker")
+	}
+	if kids, _ := store.Kids("Tuần 1"); len(kids) != 1 {
+		t.Errorf("ClearWeek cleared another week's kids: %s", kids)
+	}
+
+	// Clearing a week without checkpoints is not an error
+	if err := store.ClearWeek("Tuần 9"); err != nil {
+		t.Errorf("ClearWeek on an empty week: %v", err)
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var store *Store
+	if store.WeekDone("Tuần 1") {
+		t.Error("nil store reports a done week")
+	}
+	if err := store.AppendKid("Tuần 1", "kid-a", "report"); err != nil {
+		t.Errorf("AppendKid: %v", err)
+	}
+	if kids, err := store.Kids("Tuần 1"); kids != nil || err != nil {
+		t.Errorf("Kids = %v, %v", kids, err)
+	}
+	if err := store.ClearWeek("Tuần 1"); err != nil {
+		t.Errorf("ClearWeek: %v", err)
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"time"
+
+	"ai-production-pipeline/internal/redact"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,6 +24,52 @@ type Config struct {
 	Retry      RetryConfig      `yaml:"retry"`
 	Formatting FormattingConfig `yaml:"formatting"`
 	Monitoring MonitoringConfig `yaml:"monitoring"`
+	Calendar   CalendarConfig   `yaml:"calendar"`
+	HTTP       HTTPConfig       `yaml:"http"`
+	Bronze     BronzeConfig     `yaml:"bronze"`
+	Silver     SilverConfig     `yaml:"silver"`
+	Status     StatusConfig     `yaml:"status"`
+	Serve      ServeConfig      `yaml:"serve"`
+	Run        RunConfig        `yaml:"run"`
+	Gold       GoldConfig       `yaml:"gold"`
+
+	Categorization CategorizationConfig `yaml:"categorization"`
+	Delivery       DeliveryConfig       `yaml:"delivery"`
+	Currency       CurrencyConfig       `yaml:"currency"`
+	Monthly        MonthlyConfig        `yaml:"monthly"`
+	CostLedger     CostLedgerConfig     `yaml:"cost_ledger"`
+}
+
+// ServeConfig protects the serve API, which returns kids' names, birth dates, balances and reports
+type ServeConfig struct {
+	TokenEnv string `yaml:"token_env"` // Env var holding the bearer token, required when serve listens beyond loopback ("" = SERVE_API_TOKEN)
+}
+
+// CostLedgerConfig records each run's AI usage and cost in a table for pipeline cost report
+type CostLedgerConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Table   string `yaml:"table"`  // Created if missing ("" = ai_costs)
+	Tenant  string `yaml:"tenant"` // Recorded with every entry ("" = run.lock.namespace, or "default")
+}
+
+// CurrencyConfig describes the tenant's currency, used for amounts in prompts and formatted strings
+type CurrencyConfig struct {
+	Code               string            `yaml:"code"`                // ISO 4217, e.g. VND, THB, IDR
+	Symbol             string            `yaml:"symbol"`              // e.g. ₫, ฿, Rp ("" = ₫ for VND, the code otherwise)
+	SymbolPosition     string            `yaml:"symbol_position"`     // "before" (Rp 10.000) or "after" (10.000 ₫)
+	Decimals           *int              `yaml:"decimals"`            // Digits after the decimal separator, amounts are rounded to this (unset = the code's ISO 4217 minor unit)
+	ThousandsSeparator string            `yaml:"thousands_separator"` // "." or ","
+	DecimalSeparator   string            `yaml:"decimal_separator"`   // "," or "."
+	UnitNames          map[string]string `yaml:"unit_names"`          // Unit name per report language, e.g. vi: đồng
+}
+
+// DeliveryConfig controls sending finished reports to families, recorded in a ledger table
+type DeliveryConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	OutboxDir   string `yaml:"outbox_dir"`   // One JSON file per report for the email/push service
+	LedgerTable string `yaml:"ledger_table"` // Created if missing; one row per (report version, channel)
+	// Sends still unconfirmed after this long are reconciled at startup, e.g. "1h" (empty = 1h)
+	PendingTimeout string `yaml:"pending_timeout"`
 }
 
 // DatabaseConfig holds database connection settings
@@ -33,6 +83,7 @@ type DatabaseConfig struct {
 	MaxIdleConns   int    `yaml:"max_idle_conns"`
 	MaxOpenConns   int    `yaml:"max_open_conns"`
 	MaxLifetimeMin int    `yaml:"max_lifetime_minutes"`
+	CheckSchema    bool   `yaml:"check_schema"` // Verify source columns and types at startup
 }
 
 // QueriesConfig holds SQL queries
@@ -46,25 +97,124 @@ type QueriesConfig struct {
 
 // DataConfig holds data output settings
 type DataConfig struct {
-	OutputDir   string   `yaml:"output_dir"`
-	Formats     []string `yaml:"formats"`
-	Compression bool     `yaml:"compression"`
+	OutputDir         string   `yaml:"output_dir"`
+	Formats           []string `yaml:"formats"`
+	Compression       bool     `yaml:"compression"`
+	CompressionFormat string   `yaml:"compression_format"` // gzip (default) or zstd
+
+	DatabaseOutput DatabaseOutputConfig `yaml:"database_output"`
+}
+
+// DatabaseOutputConfig controls writing Silver and Gold outputs to Postgres alongside the files
+type DatabaseOutputConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	SilverTable string `yaml:"silver_table"` // Created if missing; one row per (week, profile_id)
+	GoldTable   string `yaml:"gold_table"`   // Created if missing; one row per (week, profile_id)
+
+	WriteBatchSize     int `yaml:"write_batch_size"`      // Rows per multi-row upsert (default 500)
+	MaxInFlightBatches int `yaml:"max_in_flight_batches"` // Batches marshaled ahead of the database (default 4)
+
+	Archive ArchiveConfig `yaml:"archive"`
+}
+
+// ArchiveConfig moves old Gold report rows to cold storage, leaving a stub row (pipeline archive)
+type ArchiveConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	AfterMonths int    `yaml:"after_months"` // Archive reports written (or rehydrated) this many months ago (default 6)
+	Dir         string `yaml:"dir"`          // Cold storage root, e.g. an S3 bucket mounted with mountpoint-s3
+	BatchSize   int    `yaml:"batch_size"`   // Rows per query while archiving (default 500)
+}
+
+// Cutoff returns the time before which reports are archived
+func (a ArchiveConfig) Cutoff(now time.Time) time.Time {
+	months := a.AfterMonths
+	if months <= 0 {
+		months = 6
+	}
+	return now.AddDate(0, -months, 0)
+}
+
+// CompressionCodec returns the output compression format, or "" when compression is off
+func (d *DataConfig) CompressionCodec() string {
+	if !d.Compression {
+		return ""
+	}
+	if d.CompressionFormat == "" {
+		return "gzip"
+	}
+	return d.CompressionFormat
 }
 
 // LoggingConfig holds logging settings
 type LoggingConfig struct {
-	Level     string `yaml:"level"`
-	Output    string `yaml:"output"`
-	LogToFile bool   `yaml:"log_to_file"`
-	LogDir    string `yaml:"log_dir"`
+	Level       string `yaml:"level"`
+	Output      string `yaml:"output"`
+	LogToFile   bool   `yaml:"log_to_file"`
+	LogDir      string `yaml:"log_dir"`
+	MaxSizeMB   int    `yaml:"max_size_mb"`   // Rotate when a log file exceeds this size
+	MaxAgeHours int    `yaml:"max_age_hours"` // Rotate when a log file is older than this
+	MaxBackups  int    `yaml:"max_backups"`   // Rotated files to keep
+	Compress    bool   `yaml:"compress"`      // Gzip rotated files
 }
 
 // OpenAIConfig holds OpenAI API settings
 type OpenAIConfig struct {
-	Model          string  `yaml:"model"`
-	MaxTokens      int     `yaml:"max_tokens"`
-	Temperature    float64 `yaml:"temperature"`
-	TimeoutSeconds int     `yaml:"timeout_seconds"`
+	Provider       string            `yaml:"provider"` // openai (default) | anthropic; the key is read from OPENAI_API_KEY or ANTHROPIC_API_KEY
+	Model          string            `yaml:"model"`
+	MaxTokens      int               `yaml:"max_tokens"`
+	Temperature    float64           `yaml:"temperature"`
+	TimeoutSeconds int               `yaml:"timeout_seconds"`             // Single attempt timeout
+	ItemBudgetSecs int               `yaml:"item_budget_seconds"`         // Total time per kid across retries and backoff, 0 = unlimited
+	BaseURL        string            `yaml:"base_url"`                    // OpenAI-compatible endpoint (gateway), default api.openai.com
+	AuthStyle      string            `yaml:"auth_style"`                  // bearer (default) | api-key (Azure OpenAI)
+	APIVersion     string            `yaml:"api_version"`                 // Azure OpenAI api-version query parameter ("" = none)
+	ExtraHeaders   map[string]string `yaml:"extra_headers"`               // Additional headers sent with every request
+	StoreResponses bool              `yaml:"store_responses"`             // Store completions to recover them after client timeouts
+	Preflight      bool              `yaml:"preflight"`                   // Verify key, model and JSON mode with a tiny call before the run
+	Stream         bool              `yaml:"stream"`                      // Stream completions (SSE, openai provider only)
+	StreamIdleSecs int               `yaml:"stream_idle_timeout_seconds"` // Fail a stream silent for this long (default 20)
+
+	ResponseCache  ResponseCacheConfig  `yaml:"response_cache"`
+	FaultInjection FaultInjectionConfig `yaml:"fault_injection"`
+}
+
+// FaultInjectionConfig makes AI calls fail on purpose at the given rates (0-1), to test retries,
+// checkpoints and partial flushes. Never enable it in production.
+type FaultInjectionConfig struct {
+	Enabled           bool    `yaml:"enabled"`
+	TimeoutRate       float64 `yaml:"timeout_rate"`        // Call fails as a client timeout
+	RateLimitRate     float64 `yaml:"rate_limit_rate"`     // Call fails with 429
+	ServerErrorRate   float64 `yaml:"server_error_rate"`   // Call fails with 503
+	MalformedJSONRate float64 `yaml:"malformed_json_rate"` // Completion is cut in half
+	SlowRate          float64 `yaml:"slow_rate"`           // Call is delayed by slow_delay
+	SlowDelay         string  `yaml:"slow_delay"`          // e.g. "20s"
+	Seed              int64   `yaml:"seed"`                // Replays the same faults (0 = random)
+}
+
+// ResponseCacheConfig caches AI responses on disk by request hash (development reruns; --no-cache skips it)
+type ResponseCacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"` // One JSON file per request hash
+	TTL     string `yaml:"ttl"` // e.g. "24h"; older entries are refetched (empty = never expire)
+}
+
+// Settings returns the cache directory ("" when disabled) and the TTL (0 = never expire)
+func (c ResponseCacheConfig) Settings() (string, time.Duration, error) {
+	if !c.Enabled {
+		return "", 0, nil
+	}
+	dir := c.Dir
+	if dir == "" {
+		dir = "data/ai_cache"
+	}
+	if c.TTL == "" {
+		return dir, 0, nil
+	}
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil || ttl < 0 {
+		return "", 0, fmt.Errorf("invalid openai.response_cache.ttl %q", c.TTL)
+	}
+	return dir, ttl, nil
 }
 
 // PromptsConfig holds prompt template settings
@@ -72,12 +222,43 @@ type PromptsConfig struct {
 	TemplateFile      string `yaml:"template_file"`
 	SystemMessageFile string `yaml:"system_message_file"`
 	Week              string `yaml:"week"`
+	ExtraContextFile  string `yaml:"extra_context_file"` // Campaign notes injected via {{CAMPAIGN}} ("" = none)
+	DBTable           string `yaml:"db_table"`           // Table of active template/system message overrides ("" = files only)
+
+	DefaultLanguage string                          `yaml:"default_language"` // Language of template_file (default "vi")
+	Languages       map[string]LanguagePromptConfig `yaml:"languages"`        // Per-language templates, keyed by ISO code
+
+	WeekTypes map[string]WeekTypePromptConfig `yaml:"week_types"` // Prompts per calendar week type (exam, holiday, ...); normal weeks use the files above
+}
+
+// WeekTypePromptConfig replaces the template and/or system message during one type of week ("" keeps the normal file)
+type WeekTypePromptConfig struct {
+	TemplateFile      string                          `yaml:"template_file"`       // In default_language
+	SystemMessageFile string                          `yaml:"system_message_file"` // In default_language
+	Languages         map[string]LanguagePromptConfig `yaml:"languages"`           // Per-language files for this week type
+}
+
+// LanguagePromptConfig holds the prompt files for one report language
+type LanguagePromptConfig struct {
+	TemplateFile      string `yaml:"template_file"`
+	SystemMessageFile string `yaml:"system_message_file"`
 }
 
 // BatchConfig holds batch processing settings
 type BatchConfig struct {
-	Size          int `yaml:"size"`
-	MaxConcurrent int `yaml:"max_concurrent"`
+	Size          int            `yaml:"size"`
+	MaxConcurrent int            `yaml:"max_concurrent"` // Starting concurrency when auto_tune is on
+	AutoTune      AutoTuneConfig `yaml:"auto_tune"`
+}
+
+// AutoTuneConfig adjusts Gold concurrency within bounds from observed latency and error rate
+type AutoTuneConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	MinConcurrent int     `yaml:"min_concurrent"` // Default 2 (1 when max_concurrent is 1)
+	MaxConcurrent int     `yaml:"max_concurrent"` // Default 2x batch.max_concurrent
+	TargetP90     string  `yaml:"target_p90"`     // p90 API time above this halves concurrency, e.g. "20s"
+	MaxErrorRate  float64 `yaml:"max_error_rate"` // Share of attempts 429/5xx/timed out above this halves concurrency (default 0.05)
+	Window        int     `yaml:"window"`         // Attempts per adjustment (default 20)
 }
 
 // RateLimitConfig holds rate limiting settings
@@ -107,6 +288,381 @@ type MonitoringConfig struct {
 	ShowProgress    bool `yaml:"show_progress"`
 }
 
+// CalendarConfig holds week detection and school calendar settings used for week numbering
+type CalendarConfig struct {
+	SemesterStart   string   `yaml:"semester_start"`   // YYYY-MM-DD; empty means plain calendar weeks
+	HolidayWeeks    []string `yaml:"holiday_weeks"`    // Any date (YYYY-MM-DD) inside a holiday week
+	ExcludeHolidays bool     `yaml:"exclude_holidays"` // Drop holiday weeks from processing entirely
+	AnchorDate      string   `yaml:"anchor_date"`      // YYYY-MM-DD; activity before it is ignored (default 2025-10-01)
+	SourceTables    []string `yaml:"source_tables"`    // Tables whose activity defines weeks: wallet_transactions, missions (default wallet_transactions)
+	WeekStart       string   `yaml:"week_start"`       // First day of the week: monday (default) or sunday
+
+	WeekTypes map[string][]string `yaml:"week_types"` // Week type (e.g. exam) → any date inside each such week; holiday_weeks are type "holiday"
+}
+
+// HTTPConfig holds outbound HTTP client settings
+type HTTPConfig struct {
+	Proxy            string `yaml:"proxy"`              // Egress proxy URL; empty uses HTTPS_PROXY env
+	CABundleFile     string `yaml:"ca_bundle_file"`     // Extra PEM CA certificates to trust
+	MaxRequestBytes  int64  `yaml:"max_request_bytes"`  // Reject prompts larger than this
+	MaxResponseBytes int64  `yaml:"max_response_bytes"` // Stop reading responses larger than this
+}
+
+// BronzeConfig controls the raw source snapshots taken before Silver, so a week can be rerun on
+// the same rows (run --from-bronze) after the production database changed
+type BronzeConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	Dir          string `yaml:"dir"`           // Snapshot files ("" = data/bronze)
+	ReplaySchema string `yaml:"replay_schema"` // Schema --from-bronze restores snapshots into ("" = bronze_replay)
+}
+
+// SilverConfig holds Silver layer settings
+type SilverConfig struct {
+	PartialWeekMode string                `yaml:"partial_week_mode"` // "include" (week-to-date, stored as .partial) or "skip"
+	MissionStatuses MissionStatusConfig   `yaml:"mission_statuses"`
+	LanguageColumn  string                `yaml:"language_column"` // profiles column with the app language ("" = off)
+	ParentColumn    string                `yaml:"parent_column"`   // profiles column with the kid's parent profile ID ("" = off)
+	MetricStore     MetricStoreConfig     `yaml:"metric_store"`
+	Amounts         AmountConfig          `yaml:"amounts"`
+	Features        []FeatureConfig       `yaml:"features"` // Derived metrics added without code changes beyond a calculation function
+	Interest        InterestConfig        `yaml:"interest"`
+	DeletedProfiles DeletedProfilesConfig `yaml:"deleted_profiles"`
+	MetricsQuery    string                `yaml:"metrics_query"` // "aggregate" (one statement per week, default) or "per_kid" (fallback for older planners)
+	Balances        BalancesConfig        `yaml:"balances"`
+	MaxConcurrent   int                   `yaml:"max_concurrent"` // Kids analyzed at once (default 1); keep below database.max_open_conns
+	ProgressLog     ProgressLogConfig     `yaml:"progress_log"`
+}
+
+// ProgressLogConfig sets how often Silver logs its progress through a week; per-kid lines are debug level
+type ProgressLogConfig struct {
+	EveryKids    int `yaml:"every_kids"`    // Log after this many more kids (default 500)
+	EverySeconds int `yaml:"every_seconds"` // Or after this many seconds (default 30)
+}
+
+// DeletedProfilesConfig controls how soft-deleted (churned) kid profiles are analyzed
+type DeletedProfilesConfig struct {
+	Column string `yaml:"column"` // profiles timestamp column set when a profile is deleted, e.g. "deleted_at" ("" = off)
+	Mode   string `yaml:"mode"`   // "include" (default), "exclude" (no output for the kid) or "flag" (deleted_profile data quality flag)
+}
+
+// BalancesConfig controls how wallet balances at the end of a past week are read
+type BalancesConfig struct {
+	Mode          string `yaml:"mode"`           // "transactions" (current balance minus later flows, default), "snapshots" or "current"
+	SnapshotTable string `yaml:"snapshot_table"` // snapshots mode: table with wallet_id, balance and snapshot_at (default "balance_snapshots")
+}
+
+// InterestConfig identifies the weekly interest paid on the study wallet, reported apart from deposits
+type InterestConfig struct {
+	Types        []string `yaml:"types"`         // wallet_transactions.type values that are interest (default: interest)
+	SourceColumn string   `yaml:"source_column"` // wallet_transactions column flagging interest credits ("" = types only)
+	SourceValues []string `yaml:"source_values"` // source_column values that mark interest (default: interest)
+}
+
+// FeatureConfig defines one derived metric computed per kid and week
+type FeatureConfig struct {
+	Name            string `yaml:"name"`
+	Description     string `yaml:"description"`
+	SQL             string `yaml:"sql"`               // SELECT returning one number; $1 profile ID, $2 week start, $3 week end
+	Amount          bool   `yaml:"amount"`            // The SQL value is a money amount stored as silver.amounts
+	Derive          string `yaml:"derive"`            // Registered calculation function (silver/features.go)
+	OutputField     string `yaml:"output_field"`      // Key under features in the week metrics (default: name)
+	IncludeInPrompt bool   `yaml:"include_in_prompt"` // Send the value to the AI with the kid's data
+}
+
+// AmountConfig describes how wallet balances and transaction amounts are stored
+type AmountConfig struct {
+	Storage  string `yaml:"storage"`  // "numeric" (decimal đồng) or "integer" (whole minor units)
+	Decimals int    `yaml:"decimals"` // Minor-unit digits per đồng (0 = amounts are whole đồng)
+}
+
+// MetricStoreConfig controls the per-kid, per-week metrics history table
+type MetricStoreConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Table         string `yaml:"table"`          // Created if missing
+	LookbackWeeks int    `yaml:"lookback_weeks"` // Stored weeks added to each kid's history (0 = none)
+}
+
+// MissionStatusConfig maps missions.status values to completed/pending/failed
+type MissionStatusConfig struct {
+	Completed []string `yaml:"completed"`
+	Pending   []string `yaml:"pending"` // Unlisted statuses are also treated as pending
+	Failed    []string `yaml:"failed"`
+}
+
+// StatusConfig holds run-state persistence and status endpoint settings
+type StatusConfig struct {
+	StateFile              string    `yaml:"state_file"`               // Persisted run state (for restarts)
+	PersistIntervalSeconds int       `yaml:"persist_interval_seconds"` // How often state is written
+	ListenAddr             string    `yaml:"listen_addr"`              // Serve GET /status here (empty = disabled)
+	SummaryFile            string    `yaml:"summary_file"`             // Compact status for dashboards (empty = disabled)
+	MetricsFile            string    `yaml:"metrics_file"`             // Prometheus textfile for node_exporter (empty = disabled)
+	SLO                    SLOConfig `yaml:"slo"`
+
+	Prometheus PrometheusConfig `yaml:"prometheus"`
+}
+
+// PrometheusConfig adds the pipeline's counters and histograms (AI requests, retries, tokens, week
+// duration, DB latency, per-layer results) to /metrics
+type PrometheusConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	ListenAddr string `yaml:"listen_addr"` // Serve /metrics here ("" = only alongside /status on status.listen_addr)
+}
+
+// SLOConfig holds the report availability SLO: the share of kids whose report is available
+// within a target time after the week ends (Monday 00:00)
+type SLOConfig struct {
+	Enabled          bool    `yaml:"enabled"`
+	TargetHours      float64 `yaml:"target_hours"`      // Reports should be available this long after week end
+	ObjectivePercent float64 `yaml:"objective_percent"` // Share of kids that should meet the target
+	HistoryFile      string  `yaml:"history_file"`      // Each week appended as JSONL on its first delivery (empty = disabled)
+}
+
+// Settings returns the target (default 6h) and objective percent (default 99)
+func (c SLOConfig) Settings() (time.Duration, float64, error) {
+	target, objective := c.TargetHours, c.ObjectivePercent
+	if target == 0 {
+		target = 6
+	}
+	if objective == 0 {
+		objective = 99
+	}
+	if target < 0 {
+		return 0, 0, fmt.Errorf("status.slo.target_hours must be positive, got %g", c.TargetHours)
+	}
+	if objective < 0 || objective > 100 {
+		return 0, 0, fmt.Errorf("status.slo.objective_percent must be between 0 and 100, got %g", c.ObjectivePercent)
+	}
+	return time.Duration(target * float64(time.Hour)), objective, nil
+}
+
+// CategorizationConfig holds the optional pre-Silver transaction categorization stage
+type CategorizationConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	Model             string   `yaml:"model"`              // Cheap model used for classification
+	Table             string   `yaml:"table"`              // Categories table written back to the database
+	DescriptionColumn string   `yaml:"description_column"` // wallet_transactions column with the free-text description
+	Categories        []string `yaml:"categories"`         // Allowed spending categories ("other" is always allowed)
+	BatchSize         int      `yaml:"batch_size"`         // Distinct descriptions per API call
+	MaxPerRun         int      `yaml:"max_per_run"`        // Cap on distinct new descriptions sent per run (0 = no cap)
+}
+
+// MonthlyConfig holds the monthly rollup and report (pipeline run --granularity=month)
+type MonthlyConfig struct {
+	Model             string            `yaml:"model"`              // "" = openai.model; usage is reported under "monthly"
+	MaxTokens         int               `yaml:"max_tokens"`         // 0 = openai.max_tokens
+	TemplateFiles     map[string]string `yaml:"template_files"`     // Language -> prompt template ({{KID}}, {{MONTH}}, {{CURRENCY}})
+	IncludeIncomplete bool              `yaml:"include_incomplete"` // Also roll up and report the month still running
+}
+
+// RunConfig holds whole-run settings
+type RunConfig struct {
+	MaxDuration     string        `yaml:"max_duration"`      // e.g. "3h30m"; soft-stop when reached (empty = unlimited)
+	StreamWeeks     bool          `yaml:"stream_weeks"`      // Feed kids from Silver to Gold as they are analyzed
+	StreamQueueSize int           `yaml:"stream_queue_size"` // Analyzed kids buffered ahead of Gold
+	CheckpointDir   string        `yaml:"checkpoint_dir"`    // Completed weeks and generated reports, for --resume
+	Lock            RunLockConfig `yaml:"lock"`
+
+	ScheduleHistoryFile string `yaml:"schedule_history_file"` // serve --schedule: every scheduled run appended as JSONL (empty = disabled)
+}
+
+// RunLockConfig prevents two pipeline instances from processing the same week against one database
+type RunLockConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Namespace   string `yaml:"namespace"`    // Lock key prefix; one per tenant/environment sharing the database
+	OnConflict  string `yaml:"on_conflict"`  // wait | skip | fail
+	WaitTimeout string `yaml:"wait_timeout"` // wait: give up after this long, e.g. "30m" (empty = no limit)
+}
+
+// GoldConfig holds Gold layer report quality settings
+type GoldConfig struct {
+	NumericGuard     NumericGuardConfig     `yaml:"numeric_guard"`
+	ScoreCalibration ScoreCalibrationConfig `yaml:"score_calibration"`
+	Consensus        ConsensusConfig        `yaml:"consensus"`
+	SuggestionDedup  SuggestionDedupConfig  `yaml:"suggestion_dedup"`
+	Identity         IdentityConfig         `yaml:"identity"`
+	SectionTaxonomy  SectionTaxonomyConfig  `yaml:"section_taxonomy"`
+	OptionalSections OptionalSectionsConfig `yaml:"optional_sections"`
+	Regeneration     RegenerationConfig     `yaml:"regeneration"`
+	ParentDigest     ParentDigestConfig     `yaml:"parent_digest"`
+	FamilyReport     FamilyReportConfig     `yaml:"family_report"`
+	KidVersion       KidVersionConfig       `yaml:"kid_version"`
+	ReportStyle      ReportStyleConfig      `yaml:"report_style"`
+	ReuseExisting    bool                   `yaml:"reuse_existing"` // Rerun only generates kids missing from the week's output
+	OperatorNotes    OperatorNotesConfig    `yaml:"operator_notes"`
+	OutageQueue      OutageQueueConfig      `yaml:"outage_queue"`
+	CostBudget       CostBudgetConfig       `yaml:"cost_budget"`
+	PromptHistory    PromptHistoryConfig    `yaml:"prompt_history"`
+	Render           RenderConfig           `yaml:"render"`
+	Badges           BadgesConfig           `yaml:"badges"`
+}
+
+// BadgesConfig is the gamification badge catalog; each report recommends one badge from it
+type BadgesConfig struct {
+	Enabled bool    `yaml:"enabled"`
+	Catalog []Badge `yaml:"catalog"`
+}
+
+// Badge is one badge the AI may recommend
+type Badge struct {
+	Key          string            `yaml:"key"`
+	Names        map[string]string `yaml:"names"`        // Language -> name shown in the app
+	Descriptions map[string]string `yaml:"descriptions"` // Language -> when the badge fits (prompt only)
+}
+
+// RenderConfig controls the parent-readable HTML/PDF copies written next to each week's JSON reports
+type RenderConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Formats      []string `yaml:"formats"`       // "html" and/or "pdf" (default: html)
+	TemplateFile string   `yaml:"template_file"` // html/template layout ("" = built-in Vietnamese layout)
+	PDFCommand   string   `yaml:"pdf_command"`   // HTML-to-PDF converter called as <command> <in.html> <out.pdf> (default: wkhtmltopdf)
+}
+
+// PromptHistoryConfig controls which earlier weeks reach the prompt
+type PromptHistoryConfig struct {
+	Mode string `yaml:"mode"` // none (current week only) | full (previous two weeks' metrics) | delta (changes and trends vs the previous week)
+}
+
+// OutageQueueConfig queues rendered prompts instead of failing kids while the AI provider is down
+type OutageQueueConfig struct {
+	Enabled             bool   `yaml:"enabled"`
+	ConsecutiveFailures int    `yaml:"consecutive_failures"` // Kids failing in a row on timeouts, 429/5xx or network errors before the provider counts as down
+	Dir                 string `yaml:"dir"`                  // One JSONL file per week; pipeline flush-deferred sends them
+}
+
+// CostBudgetConfig downgrades the run's remaining non-urgent reports to a cheaper tier once the run
+// is projected to exceed its cost or token budget
+type CostBudgetConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	MaxCostUSD     float64  `yaml:"max_cost_usd"`    // Projected run cost that triggers the downgrade (0 = no cost limit)
+	MaxTokens      int      `yaml:"max_tokens"`      // Projected run tokens that trigger it, e.g. the provider quota left (0 = no token limit)
+	MinReports     int      `yaml:"min_reports"`     // Reports generated before the run is projected (default 5)
+	DowngradeModel string   `yaml:"downgrade_model"` // Cheaper model for downgraded reports ("" = keep openai.model)
+	ServiceTier    string   `yaml:"service_tier"`    // "" = standard; "flex" = OpenAI's discounted tier, for the models that offer it (o3, o4-mini, gpt-5)
+	Urgent         []string `yaml:"urgent"`          // Kids never downgraded: operator_note, requested_sections, data_quality
+}
+
+// OperatorNotesConfig controls human-written per-kid, per-week notes appended to reports without the AI
+type OperatorNotesConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	Table           string `yaml:"table"`            // profile_id, week_start, note, approved_by, created_at
+	MaxChars        int    `yaml:"max_chars"`        // Longer notes are rejected, never cut
+	RequireApproval bool   `yaml:"require_approval"` // Only notes with approved_by set (reviewed by a second person)
+}
+
+// ReportStyleConfig tunes report length, reading level and tone without editing the templates
+type ReportStyleConfig struct {
+	Verbosity    string         `yaml:"verbosity"`     // short | standard | detailed
+	ReadingLevel string         `yaml:"reading_level"` // easy | standard | advanced
+	Tone         string         `yaml:"tone"`          // encouraging | neutral
+	MaxTokens    map[string]int `yaml:"max_tokens"`    // Verbosity -> completion limit (0 or missing = openai.max_tokens)
+}
+
+// ParentDigestConfig controls the short per-parent digest across all their kids' reports
+type ParentDigestConfig struct {
+	Enabled       bool              `yaml:"enabled"`
+	Model         string            `yaml:"model"`          // Cheapest model; usage is reported under "parent_digest"
+	MaxChars      int               `yaml:"max_chars"`      // Push notification body limit
+	TemplateFiles map[string]string `yaml:"template_files"` // Language -> prompt template ({{KIDS}}, {{WEEK}}, {{MAX_CHARS}})
+}
+
+// KidVersionConfig controls the short kid-facing version written next to each week's parent reports
+type KidVersionConfig struct {
+	Enabled       bool              `yaml:"enabled"`
+	Mode          string            `yaml:"mode"`           // template (built from the parent report, no AI call) | ai (rewritten by the cheap model)
+	Model         string            `yaml:"model"`          // ai mode; usage is reported under "kid_version"
+	TemplateFiles map[string]string `yaml:"template_files"` // ai mode: language -> prompt template ({{REPORT}}, {{CHILD_NAME}}, {{MAX_BULLETS}})
+}
+
+// FamilyReportConfig controls the household report across all of a parent's kids
+type FamilyReportConfig struct {
+	Enabled       bool              `yaml:"enabled"`
+	Model         string            `yaml:"model"`          // "" = openai.model; usage is reported under "family_report"
+	MaxTokens     int               `yaml:"max_tokens"`     // 0 = 800
+	MinKids       int               `yaml:"min_kids"`       // Families with fewer kids are skipped (0 = 2, siblings only)
+	TemplateFiles map[string]string `yaml:"template_files"` // Language -> prompt template ({{FAMILY}}, {{WEEK}}, {{CURRENCY}})
+}
+
+// RegenerationConfig controls refreshing stored reports generated with older prompt templates
+type RegenerationConfig struct {
+	BatchSize  int     `yaml:"batch_size"`   // Reports per batch, written back after each batch
+	MaxCostUSD float64 `yaml:"max_cost_usd"` // Refuse plans estimated above this (0 = no limit)
+}
+
+// OptionalSectionsConfig controls report sections parents can switch on per kid in the app
+type OptionalSectionsConfig struct {
+	Enabled  bool                         `yaml:"enabled"`
+	Table    string                       `yaml:"table"`    // Preferences table: profile_id, section_key, enabled, detail
+	Sections map[string]map[string]string `yaml:"sections"` // Section key -> language -> template block file
+}
+
+// SectionTaxonomyConfig enumerates the allowed performance section titles and levels per language,
+// so API consumers can rely on stable keys (e.g. to pick icons)
+type SectionTaxonomyConfig struct {
+	Enabled             bool              `yaml:"enabled"`
+	Sections            []TaxonomySection `yaml:"sections"`
+	Levels              []TaxonomyLevel   `yaml:"levels"`
+	DropUnknownSections bool              `yaml:"drop_unknown_sections"` // Remove sections whose title matches no entry
+}
+
+// TaxonomySection is one allowed performance section
+type TaxonomySection struct {
+	Key     string            `yaml:"key"`
+	Titles  map[string]string `yaml:"titles"`  // Language -> title
+	Aliases []string          `yaml:"aliases"` // Other titles the AI uses for this section
+}
+
+// TaxonomyLevel is one allowed level, chosen by the section's final score
+type TaxonomyLevel struct {
+	Key      string            `yaml:"key"`
+	Labels   map[string]string `yaml:"labels"` // Language -> label
+	MinScore int               `yaml:"min_score"`
+	MaxScore int               `yaml:"max_score"`
+}
+
+// NumericGuardConfig controls the check that reports only use figures from the kid's metrics
+type NumericGuardConfig struct {
+	Enabled          bool    `yaml:"enabled"`
+	TolerancePercent float64 `yaml:"tolerance_percent"` // Allowed relative difference when matching numbers
+	MaxReprompts     int     `yaml:"max_reprompts"`     // Re-prompts with "only use provided figures" on mismatch
+}
+
+// ScoreCalibrationConfig maps AI section scores onto deterministic metrics
+type ScoreCalibrationConfig struct {
+	Enabled        bool              `yaml:"enabled"`
+	ScaleMin       int               `yaml:"scale_min"`
+	ScaleMax       int               `yaml:"scale_max"`
+	AIWeight       float64           `yaml:"ai_weight"`       // 1 = AI score only, 0 = metric only
+	DefaultMetric  string            `yaml:"default_metric"`  // Metric for unmapped sections ("" keeps the AI score)
+	SectionMetrics map[string]string `yaml:"section_metrics"` // Section title -> activity_score | completion_rate
+}
+
+// ConsensusConfig controls two-model report generation for flagged kids
+type ConsensusConfig struct {
+	Enabled            bool     `yaml:"enabled"`
+	SecondaryModel     string   `yaml:"secondary_model"`
+	SecondaryBaseURL   string   `yaml:"secondary_base_url"`    // Empty = same endpoint as openai.base_url
+	SecondaryAPIKeyEnv string   `yaml:"secondary_api_key_env"` // Empty = OPENAI_API_KEY
+	Strategy           string   `yaml:"strategy"`              // select | merge
+	FlaggedProfileIDs  []string `yaml:"flagged_profile_ids"`   // Kids with complaints or manual review
+	AnomalySpendRatio  float64  `yaml:"anomaly_spend_ratio"`   // Flag when spent > ratio * received (0 = off)
+	MaxSharePercent    float64  `yaml:"max_share_percent"`     // Cap on the share of kids using consensus
+}
+
+// SuggestionDedupConfig keeps parent suggestions from repeating week after week
+type SuggestionDedupConfig struct {
+	Enabled             bool    `yaml:"enabled"`
+	HistoryWeeks        int     `yaml:"history_weeks"`        // Previous weeks passed to the prompt and checked
+	MaxOverlapPercent   float64 `yaml:"max_overlap_percent"`  // Re-prompt when more suggestions than this repeat
+	SimilarityThreshold float64 `yaml:"similarity_threshold"` // Word overlap (Jaccard) at which two suggestions count as the same
+	MaxReprompts        int     `yaml:"max_reprompts"`
+}
+
+// IdentityConfig tracks kids by profile ID across earlier weeks' reports, so a renamed kid is still one child
+type IdentityConfig struct {
+	Enabled      bool `yaml:"enabled"`
+	HistoryWeeks int  `yaml:"history_weeks"` // Earlier weeks' report files checked for other names (default 4)
+}
+
 // LoadConfig loads configuration from YAML file
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -116,12 +672,30 @@ func LoadConfig(path string) (*Config, error) {
 
 	var config Config
 	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+		return nil, fmt.Errorf("failed to parse config: %w", redact.Error(err))
 	}
 
+	// Never let the database password reach logs or error messages
+	redact.Register(config.Database.Password)
+
 	return &config, nil
 }
 
+// CheckUnknownKeys reports config keys that match no setting (usually typos, silently ignored by LoadConfig)
+func CheckUnknownKeys(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return redact.Error(err)
+	}
+	return nil
+}
+
 // ConnectionString returns PostgreSQL connection string
 func (d *DatabaseConfig) ConnectionString() string {
 	return fmt.Sprintf(
@@ -129,3 +703,8 @@ func (d *DatabaseConfig) ConnectionString() string {
 		d.Host, d.Port, d.User, d.Password, d.DBName, d.SSLMode,
 	)
 }
+
+// String describes the connection for logs, with the password masked
+func (d DatabaseConfig) String() string {
+	return redact.String(d.ConnectionString())
+}
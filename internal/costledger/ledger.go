@@ -0,0 +1,111 @@
+package costledger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// identifierPattern restricts the configured table name to a plain identifier (it is put into SQL)
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Entry is one run's AI usage for one label (a week, a month or a task such as kid_version) and model
+type Entry struct {
+	Label            string
+	WeekStart        *time.Time // Start of the week when the label is a week (nil for months and tasks)
+	Model            string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	CostUSD          float64
+}
+
+// Ledger keeps every run's AI usage and estimated cost in a table, so costs can be reported per
+// week, month, model and tenant without the run logs
+type Ledger struct {
+	db     *sql.DB
+	logger *logrus.Logger
+	table  string
+	tenant string
+}
+
+// NewLedger creates the ledger and its table if missing; tenant is recorded with every entry
+func NewLedger(db *sql.DB, logger *logrus.Logger, table, tenant string) (*Ledger, error) {
+	if table == "" {
+		table = "ai_costs"
+	}
+	if !identifierPattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid cost ledger table %q", table)
+	}
+	if tenant == "" {
+		tenant = "default"
+	}
+	if db == nil {
+		return &Ledger{logger: logger, table: table, tenant: tenant}, nil
+	}
+
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			run_id            TEXT NOT NULL,
+			tenant            TEXT NOT NULL,
+			label             TEXT NOT NULL,
+			week_start        DATE,
+			model             TEXT NOT NULL,
+			requests          INT NOT NULL,
+			prompt_tokens     BIGINT NOT NULL,
+			completion_tokens BIGINT NOT NULL,
+			cost_usd          NUMERIC(14, 6) NOT NULL,
+			recorded_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (run_id, tenant, label, model)
+		);
+		CREATE INDEX IF NOT EXISTS %[1]s_recorded_idx ON %[1]s (recorded_at)
+	`, table)
+	if _, err := db.Exec(query); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", table, err)
+	}
+
+	return &Ledger{db: db, logger: logger, table: table, tenant: tenant}, nil
+}
+
+// Tenant is the tenant entries are recorded for
+func (l *Ledger) Tenant() string {
+	return l.tenant
+}
+
+// Record writes a run's entries in one transaction; recording the same run again replaces its entries
+func (l *Ledger) Record(ctx context.Context, runID string, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin cost ledger write: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (run_id, tenant, label, week_start, model, requests, prompt_tokens, completion_tokens, cost_usd)
+		VALUES ($1, $2, $3, $4::date, $5, $6, $7, $8, $9)
+		ON CONFLICT (run_id, tenant, label, model) DO UPDATE
+		SET week_start = EXCLUDED.week_start, requests = EXCLUDED.requests, prompt_tokens = EXCLUDED.prompt_tokens,
+		    completion_tokens = EXCLUDED.completion_tokens, cost_usd = EXCLUDED.cost_usd, recorded_at = now()
+	`, l.table)
+	for _, entry := range entries {
+		var weekStart interface{}
+		if entry.WeekStart != nil {
+			weekStart = entry.WeekStart.Format("2006-01-02")
+		}
+		if _, err := tx.ExecContext(ctx, query, runID, l.tenant, entry.Label, weekStart, entry.Model,
+			entry.Requests, entry.PromptTokens, entry.CompletionTokens, entry.CostUSD); err != nil {
+			return fmt.Errorf("failed to record cost of %s (%s): %w", entry.Label, entry.Model, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cost ledger write: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package costledger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Report breakdowns (pipeline cost report --by)
+const (
+	ByWeek   = "week"   // Per report week, compared with the week before
+	ByMonth  = "month"  // Per calendar month the cost was incurred, compared with the month before
+	ByModel  = "model"  // Per model in the latest month, compared with the month before
+	ByTenant = "tenant" // Per tenant in the latest month, compared with the month before
+)
+
+// Query selects what a report covers
+type Query struct {
+	By     string
+	Since  time.Time // Entries recorded on or after this time (the month before is read too, for the deltas)
+	Tenant string    // "" = all tenants
+}
+
+// Row is one line of a report: the period's usage and its change from the prior period
+type Row struct {
+	Key              string   `json:"key"` // Week label, YYYY-MM, model or tenant
+	Requests         int      `json:"requests"`
+	PromptTokens     int64    `json:"prompt_tokens"`
+	CompletionTokens int64    `json:"completion_tokens"`
+	CostUSD          float64  `json:"cost_usd"`
+	PriorCostUSD     *float64 `json:"prior_cost_usd,omitempty"` // nil when there is no prior period
+	DeltaUSD         *float64 `json:"delta_usd,omitempty"`
+	DeltaPercent     *float64 `json:"delta_percent,omitempty"` // nil when the prior cost is zero
+}
+
+// Report is a cost breakdown
+type Report struct {
+	By          string  `json:"by"`
+	Since       string  `json:"since"`
+	Tenant      string  `json:"tenant,omitempty"`
+	Period      string  `json:"period,omitempty"`       // model and tenant: the month reported (YYYY-MM)
+	PriorPeriod string  `json:"prior_period,omitempty"` // model and tenant: the month compared with
+	Rows        []Row   `json:"rows"`
+	TotalUSD    float64 `json:"total_usd"`
+}
+
+// ValidateBy checks a --by value
+func ValidateBy(by string) error {
+	switch by {
+	case ByWeek, ByMonth, ByModel, ByTenant:
+		return nil
+	}
+	return fmt.Errorf("--by must be %s, %s, %s or %s, got %q", ByWeek, ByMonth, ByModel, ByTenant, by)
+}
+
+// usageRow is the ledger summed per tenant, label, week, model and month
+type usageRow struct {
+	Tenant           string
+	Label            string
+	WeekStart        *time.Time
+	Model            string
+	Month            string // YYYY-MM the cost was recorded in
+	Requests         int
+	PromptTokens     int64
+	CompletionTokens int64
+	CostUSD          float64
+}
+
+// Report reads the ledger from the month before q.Since and builds the breakdown
+func (l *Ledger) Report(ctx context.Context, q Query) (*Report, error) {
+	if err := ValidateBy(q.By); err != nil {
+		return nil, err
+	}
+	from := time.Date(q.Since.Year(), q.Since.Month()-1, 1, 0, 0, 0, 0, q.Since.Location())
+	query := fmt.Sprintf(`
+		SELECT tenant, label, week_start, model, to_char(recorded_at, 'YYYY-MM'),
+		       SUM(requests), SUM(prompt_tokens), SUM(completion_tokens), SUM(cost_usd)::float8
+		FROM %s
+		WHERE recorded_at >= $1 AND ($2 = '' OR tenant = $2)
+		GROUP BY 1, 2, 3, 4, 5
+	`, l.table)
+	rows, err := l.db.QueryContext(ctx, query, from, q.Tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cost ledger: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []usageRow
+	for rows.Next() {
+		var row usageRow
+		if err := rows.Scan(&row.Tenant, &row.Label, &row.WeekStart, &row.Model, &row.Month,
+			&row.Requests, &row.PromptTokens, &row.CompletionTokens, &row.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan cost ledger: %w", err)
+		}
+		usage = append(usage, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cost ledger: %w", err)
+	}
+	return buildReport(q, usage), nil
+}
+
+// buildReport groups the usage rows for q.By and computes the deltas
+func buildReport(q Query, usage []usageRow) *Report {
+	report := &Report{By: q.By, Since: q.Since.Format("2006-01-02"), Tenant: q.Tenant, Rows: []Row{}}
+	sinceMonth := q.Since.Format("2006-01")
+
+	switch q.By {
+	case ByWeek, ByMonth:
+		// Periods in order; the first one in range is compared with the one before it, if read
+		type period struct {
+			row   Row
+			order string
+		}
+		periods := make(map[string]*period)
+		for _, u := range usage {
+			key, order := u.Month, u.Month
+			if q.By == ByWeek {
+				if u.WeekStart == nil {
+					continue // Monthly reports and tasks are not tied to a week
+				}
+				key, order = u.Label, u.WeekStart.Format("2006-01-02")
+			}
+			p, ok := periods[key]
+			if !ok {
+				p = &period{row: Row{Key: key}, order: order}
+				periods[key] = p
+			}
+			add(&p.row, u)
+		}
+		ordered := make([]*period, 0, len(periods))
+		for _, p := range periods {
+			ordered = append(ordered, p)
+		}
+		sort.Slice(ordered, func(i, j int) bool { return ordered[i].order < ordered[j].order })
+		for i, p := range ordered {
+			inRange := p.order >= sinceMonth
+			if q.By == ByWeek {
+				inRange = p.order >= report.Since
+			}
+			if !inRange {
+				continue
+			}
+			if i > 0 {
+				setPrior(&p.row, ordered[i-1].row.CostUSD)
+			}
+			report.Rows = append(report.Rows, p.row)
+			report.TotalUSD += p.row.CostUSD
+		}
+
+	case ByModel, ByTenant:
+		// The latest month read against the month before it
+		latest := ""
+		for _, u := range usage {
+			if u.Month > latest {
+				latest = u.Month
+			}
+		}
+		if latest == "" {
+			return report
+		}
+		latestTime, _ := time.Parse("2006-01", latest)
+		prior := latestTime.AddDate(0, -1, 0).Format("2006-01")
+		report.Period, report.PriorPeriod = latest, prior
+
+		current := make(map[string]*Row)
+		previous := make(map[string]float64)
+		for _, u := range usage {
+			key := u.Model
+			if q.By == ByTenant {
+				key = u.Tenant
+			}
+			switch u.Month {
+			case latest:
+				row, ok := current[key]
+				if !ok {
+					row = &Row{Key: key}
+					current[key] = row
+				}
+				add(row, u)
+			case prior:
+				previous[key] += u.CostUSD
+			}
+		}
+		for key := range previous {
+			if _, ok := current[key]; !ok {
+				current[key] = &Row{Key: key} // Used last month only: shows the drop to zero
+			}
+		}
+		for key, row := range current {
+			if cost, ok := previous[key]; ok {
+				setPrior(row, cost)
+			}
+			report.Rows = append(report.Rows, *row)
+			report.TotalUSD += row.CostUSD
+		}
+		sort.Slice(report.Rows, func(i, j int) bool {
+			if report.Rows[i].CostUSD != report.Rows[j].CostUSD {
+				return report.Rows[i].CostUSD > report.Rows[j].CostUSD
+			}
+			return report.Rows[i].Key < report.Rows[j].Key
+		})
+	}
+	return report
+}
+
+// add sums a usage row into a report row
+func add(row *Row, u usageRow) {
+	row.Requests += u.Requests
+	row.PromptTokens += u.PromptTokens
+	row.CompletionTokens += u.CompletionTokens
+	row.CostUSD += u.CostUSD
+}
+
+// setPrior records the prior period's cost and the change from it
+func setPrior(row *Row, prior float64) {
+	delta := row.CostUSD - prior
+	row.PriorCostUSD, row.DeltaUSD = &prior, &delta
+	if prior > 0 {
+		percent := delta / prior * 100
+		row.DeltaPercent = &percent
+	}
+}
@@ -0,0 +1,231 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/gold"
+	"ai-production-pipeline/internal/redact"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sender delivers one report to a family (email, push, ...)
+type Sender interface {
+	Channel() string
+	Send(ctx context.Context, report gold.AIReport) error
+}
+
+// Verifier is implemented by senders that can tell whether an unconfirmed send reached the channel
+type Verifier interface {
+	Delivered(ctx context.Context, profileID, week, version string) (bool, error)
+}
+
+// Stats summarizes the deliveries for one report file
+type Stats struct {
+	Delivered        int
+	AlreadyDelivered int // Skipped: this report version was delivered before
+	Unconfirmed      int // Skipped: an earlier send never confirmed (reconciled by a later run once stale)
+	Failed           int
+	NoProfile        int // Skipped: reports without a profile ID cannot be keyed
+}
+
+// Deliverer sends reports through a Sender, recording each one in the Ledger first
+type Deliverer struct {
+	ledger *Ledger
+	sender Sender
+	logger *logrus.Logger
+}
+
+// NewDeliverer creates a deliverer for one channel. With a nil ledger every report is sent and
+// nothing is recorded (run --fixtures).
+func NewDeliverer(ledger *Ledger, sender Sender, logger *logrus.Logger) *Deliverer {
+	return &Deliverer{ledger: ledger, sender: sender, logger: logger}
+}
+
+// DeliverFile delivers every report in a week's Gold output. Each (profile, week, template version)
+// is sent at most once per channel; redeliver sends again regardless of the ledger.
+func (d *Deliverer) DeliverFile(ctx context.Context, reportPath string, redeliver bool) (Stats, error) {
+	var stats Stats
+
+	data, err := fileio.ReadFile(reportPath)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read reports: %w", err)
+	}
+	var output struct {
+		Week     string          `json:"week"`
+		Reports  []gold.AIReport `json:"reports"`
+		Metadata *struct {
+			TemplateHash string `json:"template_hash"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return stats, fmt.Errorf("failed to parse reports: %w", err)
+	}
+
+	channel := d.sender.Channel()
+	for _, report := range output.Reports {
+		if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+		if report.ProfileID == "" {
+			stats.NoProfile++
+			continue
+		}
+
+		week := report.Week
+		if week == "" {
+			week = output.Week
+		}
+		version := report.TemplateHash
+		if version == "" && output.Metadata != nil {
+			version = output.Metadata.TemplateHash
+		}
+		key := ReportKey(report.ProfileID, week, version)
+
+		claimed, err := d.ledger.Claim(ctx, key, channel, report.ProfileID, week, version, redeliver)
+		if err != nil {
+			return stats, err
+		}
+		if !claimed {
+			status, err := d.ledger.Status(ctx, key, channel)
+			if err != nil {
+				return stats, err
+			}
+			if status == StatusPending {
+				stats.Unconfirmed++
+				d.logger.Warnf("   ⚠️  %s: earlier %s delivery was never confirmed, not resending", report.ChildName, channel)
+			} else {
+				stats.AlreadyDelivered++
+			}
+			continue
+		}
+
+		if err := d.sender.Send(ctx, report); err != nil {
+			err = redact.Error(err)
+			stats.Failed++
+			d.logger.Errorf("   ❌ %s delivery failed for %s: %v", channel, report.ChildName, err)
+			if err := d.ledger.Fail(ctx, key, channel, err); err != nil {
+				return stats, err
+			}
+			continue
+		}
+		if err := d.ledger.Confirm(ctx, key, channel); err != nil {
+			return stats, err
+		}
+		stats.Delivered++
+	}
+
+	d.logger.WithFields(logrus.Fields{
+		"channel":           channel,
+		"delivered":         stats.Delivered,
+		"already_delivered": stats.AlreadyDelivered,
+		"unconfirmed":       stats.Unconfirmed,
+		"failed":            stats.Failed,
+		"no_profile":        stats.NoProfile,
+	}).Info("📬 Report delivery complete")
+	return stats, nil
+}
+
+// Reconcile settles the deliveries left pending for longer than olderThan. A sender that implements
+// Verifier decides: sends that arrived are confirmed, the others released for the next delivery to
+// retry. Without a Verifier every stale send is released, so it is retried rather than never sent.
+func (d *Deliverer) Reconcile(ctx context.Context, olderThan time.Duration) error {
+	channel := d.sender.Channel()
+	stale, err := d.ledger.Stale(ctx, channel, olderThan)
+	if err != nil || len(stale) == 0 {
+		return err
+	}
+	verifier, _ := d.sender.(Verifier)
+	confirmed, retried := 0, 0
+	for _, entry := range stale {
+		delivered := false
+		if verifier != nil {
+			if delivered, err = verifier.Delivered(ctx, entry.ProfileID, entry.Week, entry.Version); err != nil {
+				return fmt.Errorf("failed to check the %s delivery for %s: %w", channel, entry.ProfileID, err)
+			}
+		}
+		if delivered {
+			if err := d.ledger.Confirm(ctx, entry.Key, channel); err != nil {
+				return err
+			}
+			confirmed++
+			continue
+		}
+		if err := d.ledger.Fail(ctx, entry.Key, channel, fmt.Errorf("send was never confirmed")); err != nil {
+			return err
+		}
+		retried++
+	}
+	d.logger.Infof("📬 Reconciled %d unconfirmed %s deliveries: %d had arrived, %d will be retried", len(stale), channel, confirmed, retried)
+	return nil
+}
+
+// unsafePathChars are replaced in outbox directory and file names
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// OutboxSender writes each report as a JSON file for an external email/push service to pick up
+type OutboxSender struct {
+	dir string
+}
+
+// NewOutboxSender creates the outbox directory if missing
+func NewOutboxSender(dir string) (*OutboxSender, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox %s: %w", dir, err)
+	}
+	return &OutboxSender{dir: dir}, nil
+}
+
+// Channel implements Sender
+func (s *OutboxSender) Channel() string {
+	return "outbox"
+}
+
+// path returns where a kid's report for week is written
+func (s *OutboxSender) path(profileID, week string) string {
+	return filepath.Join(s.dir, unsafePathChars.ReplaceAllString(week, "_"), unsafePathChars.ReplaceAllString(profileID, "_")+".json")
+}
+
+// Send implements Sender: <dir>/<week>/<profile_id>.json, written atomically
+func (s *OutboxSender) Send(ctx context.Context, report gold.AIReport) error {
+	path := s.path(report.ProfileID, report.Week)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", path, err)
+	}
+	return nil
+}
+
+// Delivered implements Verifier: the report is in the outbox, written from this template version
+func (s *OutboxSender) Delivered(ctx context.Context, profileID, week, version string) (bool, error) {
+	data, err := os.ReadFile(s.path(profileID, week))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var report gold.AIReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return false, nil // A torn write never arrived
+	}
+	return report.TemplateHash == "" || report.TemplateHash == version, nil
+}
@@ -0,0 +1,172 @@
+package delivery
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Ledger entry statuses
+const (
+	StatusPending   = "pending"   // Claimed; the send started but was not confirmed (reconciled once stale)
+	StatusDelivered = "delivered" // Confirmed sent
+	StatusFailed    = "failed"    // Send returned an error; the next run may claim it again
+)
+
+// identifierPattern restricts the configured table name to a plain identifier (it is put into SQL)
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Ledger records every report delivery in the database so the same report version is sent at most
+// once per channel, across restarts and concurrent runs
+type Ledger struct {
+	db     *sql.DB
+	logger *logrus.Logger
+	table  string
+}
+
+// Entry is one recorded delivery
+type Entry struct {
+	Key       string
+	ProfileID string
+	Week      string
+	Version   string
+}
+
+// ReportKey returns the ledger key for one version of a kid's weekly report
+func ReportKey(profileID, week, version string) string {
+	sum := sha256.Sum256([]byte(profileID + "\x00" + week + "\x00" + version))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewLedger creates the ledger and its table if missing
+func NewLedger(db *sql.DB, logger *logrus.Logger, table string) (*Ledger, error) {
+	if table == "" {
+		table = "report_deliveries"
+	}
+	if !identifierPattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid delivery ledger table %q", table)
+	}
+
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			report_key   TEXT NOT NULL,
+			channel      TEXT NOT NULL,
+			profile_id   TEXT NOT NULL,
+			week         TEXT NOT NULL,
+			version      TEXT NOT NULL,
+			status       TEXT NOT NULL,
+			attempts     INT NOT NULL DEFAULT 1,
+			last_error   TEXT NOT NULL DEFAULT '',
+			claimed_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+			delivered_at TIMESTAMPTZ,
+			PRIMARY KEY (report_key, channel)
+		)
+	`, table)
+	if _, err := db.Exec(query); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", table, err)
+	}
+
+	return &Ledger{db: db, logger: logger, table: table}, nil
+}
+
+// Claim atomically reserves a delivery before sending. It returns false when the report was already
+// delivered or a previous send is unconfirmed, unless redeliver is set. Failed sends can be claimed again.
+// A nil ledger claims every delivery (nothing is recorded).
+func (l *Ledger) Claim(ctx context.Context, key, channel, profileID, week, version string, redeliver bool) (bool, error) {
+	if l == nil {
+		return true, nil
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (report_key, channel, profile_id, week, version, status)
+		VALUES ($1, $2, $3, $4, $5, '%[2]s')
+		ON CONFLICT (report_key, channel) DO UPDATE
+		SET status = '%[2]s', attempts = %[1]s.attempts + 1, last_error = '', claimed_at = now(), delivered_at = NULL
+		WHERE %[1]s.status = '%[3]s' OR $6
+	`, l.table, StatusPending, StatusFailed)
+	result, err := l.db.ExecContext(ctx, query, key, channel, profileID, week, version, redeliver)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim delivery: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim delivery: %w", err)
+	}
+	return claimed == 1, nil
+}
+
+// Status returns the recorded status of a delivery ("" when there is none)
+func (l *Ledger) Status(ctx context.Context, key, channel string) (string, error) {
+	query := fmt.Sprintf(`SELECT status FROM %s WHERE report_key = $1 AND channel = $2`, l.table)
+	var status string
+	err := l.db.QueryRowContext(ctx, query, key, channel).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read delivery status: %w", err)
+	}
+	return status, nil
+}
+
+// Confirm marks a claimed delivery as sent
+func (l *Ledger) Confirm(ctx context.Context, key, channel string) error {
+	if l == nil {
+		return nil
+	}
+	query := fmt.Sprintf(`
+		UPDATE %s SET status = $3, delivered_at = now()
+		WHERE report_key = $1 AND channel = $2
+	`, l.table)
+	if _, err := l.db.ExecContext(ctx, query, key, channel, StatusDelivered); err != nil {
+		return fmt.Errorf("failed to confirm delivery: %w", err)
+	}
+	return nil
+}
+
+// Fail releases a claimed delivery after a send error so a later run can retry it
+func (l *Ledger) Fail(ctx context.Context, key, channel string, sendErr error) error {
+	if l == nil {
+		return nil
+	}
+	query := fmt.Sprintf(`
+		UPDATE %s SET status = $3, last_error = $4
+		WHERE report_key = $1 AND channel = $2 AND status = $5
+	`, l.table)
+	if _, err := l.db.ExecContext(ctx, query, key, channel, StatusFailed, sendErr.Error(), StatusPending); err != nil {
+		return fmt.Errorf("failed to record delivery failure: %w", err)
+	}
+	return nil
+}
+
+// Stale returns the channel's deliveries still pending after olderThan: sends that started but never
+// confirmed, e.g. because the run crashed mid-send. A nil ledger has none.
+func (l *Ledger) Stale(ctx context.Context, channel string, olderThan time.Duration) ([]Entry, error) {
+	if l == nil {
+		return nil, nil
+	}
+	query := fmt.Sprintf(`
+		SELECT report_key, profile_id, week, version FROM %s
+		WHERE channel = $1 AND status = $2 AND claimed_at < now() - make_interval(secs => $3)
+		ORDER BY claimed_at
+	`, l.table)
+	rows, err := l.db.QueryContext(ctx, query, channel, StatusPending, olderThan.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending deliveries: %w", err)
+	}
+	defer rows.Close()
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		if err := rows.Scan(&entry.Key, &entry.ProfileID, &entry.Week, &entry.Version); err != nil {
+			return nil, fmt.Errorf("failed to read pending deliveries: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,153 @@
+package fileio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression formats for pipeline artifacts
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Extension returns the file suffix appended for a compression format
+func Extension(format string) string {
+	switch format {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// ResolvePath returns the existing file for path, trying compressed variants (.gz, .zst)
+func ResolvePath(path string) (string, error) {
+	for _, candidate := range []string{path, path + ".gz", path + ".zst"} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("file not found: %s (also tried .gz and .zst)", path)
+}
+
+// ReadFile reads path (or its compressed variant) and transparently decompresses gzip/zstd content
+func ReadFile(path string) ([]byte, error) {
+	resolved, err := ResolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decompress(data)
+}
+
+// Decompress detects gzip/zstd by magic bytes; uncompressed data is returned unchanged
+func Decompress(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip data: %w", err)
+		}
+		defer r.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip data: %w", err)
+		}
+		return out, nil
+
+	case bytes.HasPrefix(data, zstdMagic):
+		r, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zstd data: %w", err)
+		}
+		defer r.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress zstd data: %w", err)
+		}
+		return out, nil
+
+	default:
+		return data, nil
+	}
+}
+
+// Encode compresses data with format (returned unchanged when format is empty)
+func Encode(data []byte, format string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case CompressionNone:
+		return data, nil
+
+	case CompressionGzip:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to gzip data: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to gzip data: %w", err)
+		}
+
+	case CompressionZstd:
+		w, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("failed to zstd data: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to zstd data: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported compression format %q", format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes data to path, compressed with format when set.
+// Compressed files get a .gz/.zst suffix; the actual path written is returned.
+func WriteFile(path string, data []byte, format string) (string, error) {
+	encoded, err := Encode(data, format)
+	if err != nil {
+		return "", err
+	}
+
+	if format == CompressionNone {
+		return path, os.WriteFile(path, encoded, 0644)
+	}
+
+	compressedPath := path + Extension(format)
+	if err := os.WriteFile(compressedPath, encoded, 0644); err != nil {
+		return "", err
+	}
+
+	// Remove a stale uncompressed copy so readers don't pick up old data
+	if _, err := os.Stat(path); err == nil {
+		os.Remove(path)
+	}
+	return compressedPath, nil
+}
@@ -0,0 +1,134 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Tables are the source tables a fixture set provides, one <table>.json file each
+var Tables = []string{"profiles", "wallets", "wallet_transactions", "missions"}
+
+// requiredTables must have a file; the others are empty when missing
+var requiredTables = map[string]bool{"profiles": true}
+
+// timeLayouts are the timestamp formats accepted in fixtures (row_to_json output and plain dates)
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Row is one source row, keyed by column name as Postgres' row_to_json writes it
+type Row map[string]interface{}
+
+// Set is a test tenant's source rows, read from JSON files instead of the database. Each file is an
+// array of rows in the shape of a bronze snapshot's tables, so a snapshot can be turned into fixtures.
+type Set struct {
+	Dir    string
+	tables map[string][]Row
+}
+
+// Load reads the fixture files in dir
+func Load(dir string) (*Set, error) {
+	set := &Set{Dir: dir, tables: make(map[string][]Row)}
+	for _, table := range Tables {
+		path := filepath.Join(dir, table+".json")
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && !requiredTables[table] {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber() // Amounts stay exact until Silver parses them
+		var rows []Row
+		if err := decoder.Decode(&rows); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for i, row := range rows {
+			if _, ok := row.Time("created_at"); !ok && table != "profiles" && table != "wallets" {
+				return nil, fmt.Errorf("%s row %d: missing or invalid created_at", path, i+1)
+			}
+		}
+		set.tables[table] = rows
+	}
+	return set, nil
+}
+
+// Rows returns a table's rows
+func (s *Set) Rows(table string) []Row {
+	return s.tables[table]
+}
+
+// Counts returns the number of rows per table
+func (s *Set) Counts() map[string]int {
+	counts := make(map[string]int, len(Tables))
+	for _, table := range Tables {
+		counts[table] = len(s.tables[table])
+	}
+	return counts
+}
+
+// CreatedAt returns the created_at times of a table's rows, for week detection
+func (s *Set) CreatedAt(table string) []time.Time {
+	var times []time.Time
+	for _, row := range s.tables[table] {
+		if t, ok := row.Time("created_at"); ok {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// String returns a column as text ("" when missing or null)
+func (r Row) String(column string) string {
+	switch v := r[column].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Has reports whether a column is present and not null
+func (r Row) Has(column string) bool {
+	return r[column] != nil
+}
+
+// Amount returns a numeric column as text, the way a numeric column scans (nil when missing or null)
+func (r Row) Amount(column string) interface{} {
+	switch v := r[column].(type) {
+	case nil:
+		return nil
+	case json.Number:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+// Time returns a timestamp column as its wall-clock time (the offset, if any, is dropped), so it
+// compares with week boundaries the way a timestamp column does
+func (r Row) Time(column string) (time.Time, bool) {
+	text := strings.TrimSpace(r.String(column))
+	if text == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,49 @@
+package gold
+
+import (
+	"fmt"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/processor"
+)
+
+// AutoTuneConfig converts batch.auto_tune for the AI processor (fixed concurrency when disabled)
+func AutoTuneConfig(cfg config.BatchConfig) (processor.AutoTuneConfig, error) {
+	tune := cfg.AutoTune
+	if !tune.Enabled {
+		return processor.AutoTuneConfig{}, nil
+	}
+	result := processor.AutoTuneConfig{
+		Enabled:       true,
+		MinConcurrent: tune.MinConcurrent,
+		MaxConcurrent: tune.MaxConcurrent,
+		MaxErrorRate:  tune.MaxErrorRate,
+		Window:        tune.Window,
+	}
+	if result.MaxConcurrent == 0 {
+		result.MaxConcurrent = 2 * cfg.MaxConcurrent
+	}
+	if result.MinConcurrent == 0 {
+		result.MinConcurrent = 2
+		if result.MaxConcurrent == 1 {
+			result.MinConcurrent = 1
+		}
+	}
+	if result.MaxErrorRate == 0 {
+		result.MaxErrorRate = 0.05
+	}
+	target := tune.TargetP90
+	if target == "" {
+		target = "20s"
+	}
+	p90, err := time.ParseDuration(target)
+	if err != nil {
+		return processor.AutoTuneConfig{}, fmt.Errorf("invalid batch.auto_tune.target_p90 %q", tune.TargetP90)
+	}
+	result.TargetP90 = p90
+	if err := result.Validate(); err != nil {
+		return processor.AutoTuneConfig{}, fmt.Errorf("batch.%w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,34 @@
+package gold
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ai-production-pipeline/internal/fileio"
+)
+
+// ReportAvailability returns when each report in a week's output became available to parents.
+// Reports generated during this run (at or after runStart) became available when the week was
+// published; reports kept from an earlier run were available from their generated_at.
+func ReportAvailability(path string, runStart, publishedAt time.Time) ([]time.Time, error) {
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var output reportOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	available := make([]time.Time, 0, len(output.Reports))
+	for _, report := range output.Reports {
+		generatedAt, err := time.Parse(time.RFC3339, report.GeneratedAt)
+		if err == nil && generatedAt.Before(runStart) {
+			available = append(available, generatedAt)
+			continue
+		}
+		available = append(available, publishedAt)
+	}
+	return available, nil
+}
@@ -0,0 +1,76 @@
+package gold
+
+import (
+	"math"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// Deterministic metrics AI scores can be calibrated against
+const (
+	MetricActivityScore  = "activity_score"  // Silver activity score (0-100)
+	MetricCompletionRate = "completion_rate" // Missions completed / total
+)
+
+// scoreCalibrator anchors AI section scores to deterministic metrics so scores don't drift week to week
+type scoreCalibrator struct {
+	cfg config.ScoreCalibrationConfig
+}
+
+// newScoreCalibrator returns nil when calibration is disabled
+func newScoreCalibrator(cfg config.ScoreCalibrationConfig) *scoreCalibrator {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.ScaleMax <= cfg.ScaleMin {
+		cfg.ScaleMin, cfg.ScaleMax = 1, 5
+	}
+	if cfg.AIWeight < 0 || cfg.AIWeight > 1 {
+		cfg.AIWeight = 0.5
+	}
+	return &scoreCalibrator{cfg: cfg}
+}
+
+// Apply keeps the AI's score as RawScore and replaces Score with the calibrated value
+func (c *scoreCalibrator) Apply(report *AIReport, kid KidDataV2) {
+	if c == nil {
+		return
+	}
+	for i := range report.PerformanceSections {
+		section := &report.PerformanceSections[i]
+		section.RawScore = section.Score
+
+		metric := c.cfg.SectionMetrics[section.Title]
+		if metric == "" && section.Key != "" {
+			metric = c.cfg.SectionMetrics[section.Key]
+		}
+		if metric == "" {
+			metric = c.cfg.DefaultMetric
+		}
+		value, ok := metricValue(metric, kid)
+		if !ok {
+			continue
+		}
+
+		scaleMin, scaleMax := float64(c.cfg.ScaleMin), float64(c.cfg.ScaleMax)
+		raw := math.Max(scaleMin, math.Min(scaleMax, float64(section.Score)))
+		anchor := scaleMin + value*(scaleMax-scaleMin)
+		calibrated := c.cfg.AIWeight*raw + (1-c.cfg.AIWeight)*anchor
+		section.Score = int(math.Round(calibrated))
+	}
+}
+
+// metricValue returns a deterministic metric normalized to 0-1
+func metricValue(metric string, kid KidDataV2) (float64, bool) {
+	switch metric {
+	case MetricActivityScore:
+		return math.Max(0, math.Min(kid.ActivityScore/100, 1)), true
+	case MetricCompletionRate:
+		if kid.MissionsTotal == 0 {
+			return 0, false
+		}
+		return math.Min(float64(kid.MissionsCompleted)/float64(kid.MissionsTotal), 1), true
+	default:
+		return 0, false
+	}
+}
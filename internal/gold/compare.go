@@ -0,0 +1,206 @@
+package gold
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/fileio"
+)
+
+// reportOutput is the on-disk form of a week's Gold output
+type reportOutput struct {
+	Week       string              `json:"week"`
+	Status     string              `json:"status"`
+	Reports    []AIReport          `json:"reports"`
+	TokenUsage *WeekTokenUsage     `json:"token_usage"`
+	Metadata   *buildinfo.Metadata `json:"metadata"`
+}
+
+// OutputSummary is the evaluation of one environment's week output
+type OutputSummary struct {
+	Path              string             `json:"path"`
+	Label             string             `json:"label"` // Model and template hash, when recorded
+	Reports           int                `json:"reports"`
+	Status            string             `json:"status"`
+	TokenUsage        *WeekTokenUsage    `json:"token_usage"`
+	CostPerReport     float64            `json:"cost_per_report"`
+	ValidationFailed  int                `json:"validation_failed"` // Reports with unsupported numbers left after re-prompts
+	Reprompts         int                `json:"reprompts"`
+	EvaluatorMean     float64            `json:"evaluator_mean"`
+	LengthMin         int                `json:"length_min"`
+	LengthP50         int                `json:"length_p50"`
+	LengthP90         int                `json:"length_p90"`
+	LengthMax         int                `json:"length_max"`
+	SectionScoreMeans map[string]float64 `json:"section_score_means"` // Lowercased section title -> mean score
+}
+
+// WeekComparison is a side-by-side evaluation of the same week from two environments
+type WeekComparison struct {
+	A *OutputSummary `json:"a"`
+	B *OutputSummary `json:"b"`
+}
+
+// CompareWeekOutputs loads two Gold outputs for the same week (e.g. staging vs production)
+func CompareWeekOutputs(pathA, pathB string) (*WeekComparison, error) {
+	a, err := summarizeOutput(pathA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := summarizeOutput(pathB)
+	if err != nil {
+		return nil, err
+	}
+	return &WeekComparison{A: a, B: b}, nil
+}
+
+// EvaluateReport scores a report's completeness and numeric consistency (higher is better)
+func EvaluateReport(report *AIReport) float64 {
+	unsupported := 0
+	if report.Quality != nil {
+		unsupported = report.Quality.UnsupportedNumbers
+	}
+	return qualityScore(report, unsupported)
+}
+
+// summarizeOutput computes cost, validation, length and evaluator statistics for one output file
+func summarizeOutput(path string) (*OutputSummary, error) {
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var output reportOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	summary := &OutputSummary{
+		Path:              path,
+		Reports:           len(output.Reports),
+		Status:            output.Status,
+		TokenUsage:        output.TokenUsage,
+		SectionScoreMeans: make(map[string]float64),
+	}
+	if summary.Status == "" {
+		summary.Status = "complete"
+	}
+	if output.Metadata != nil {
+		summary.Label = fmt.Sprintf("%s, template %s", output.Metadata.Model, output.Metadata.TemplateHash)
+	}
+	if output.TokenUsage != nil && len(output.Reports) > 0 {
+		summary.CostPerReport = output.TokenUsage.EstimatedCostUSD / float64(len(output.Reports))
+	}
+
+	var lengths []int
+	var evaluatorTotal float64
+	sectionCounts := make(map[string]int)
+	for i := range output.Reports {
+		report := &output.Reports[i]
+		if report.Quality != nil {
+			summary.Reprompts += report.Quality.Reprompts
+			if report.Quality.UnsupportedNumbers > 0 {
+				summary.ValidationFailed++
+			}
+		}
+		evaluatorTotal += EvaluateReport(report)
+		lengths = append(lengths, reportLength(report))
+		for _, section := range report.PerformanceSections {
+			title := strings.ToLower(strings.TrimSpace(section.Title)) // AI capitalization varies
+			summary.SectionScoreMeans[title] += float64(section.Score)
+			sectionCounts[title]++
+		}
+	}
+	for title, count := range sectionCounts {
+		summary.SectionScoreMeans[title] /= float64(count)
+	}
+
+	if len(lengths) > 0 {
+		sort.Ints(lengths)
+		summary.EvaluatorMean = evaluatorTotal / float64(len(lengths))
+		summary.LengthMin = lengths[0]
+		summary.LengthP50 = lengths[len(lengths)/2]
+		summary.LengthP90 = lengths[len(lengths)*9/10]
+		summary.LengthMax = lengths[len(lengths)-1]
+	}
+
+	return summary, nil
+}
+
+// reportLength counts the characters of all free-text fields in a report
+func reportLength(report *AIReport) int {
+	length := 0
+	for _, text := range reportTexts(report) {
+		length += len([]rune(text))
+	}
+	return length
+}
+
+// Format renders the comparison as a side-by-side table
+func (c *WeekComparison) Format() string {
+	var b strings.Builder
+	row := func(name, a, bVal string) {
+		fmt.Fprintf(&b, "%-28s %-28s %-28s\n", name, a, bVal)
+	}
+
+	row("", "A", "B")
+	row("file", shorten(c.A.Path, 28), shorten(c.B.Path, 28))
+	if c.A.Label != "" || c.B.Label != "" {
+		row("model/template", shorten(c.A.Label, 28), shorten(c.B.Label, 28))
+	}
+	row("status", c.A.Status, c.B.Status)
+	row("reports", fmt.Sprint(c.A.Reports), fmt.Sprint(c.B.Reports))
+	row("cost (USD)", formatCost(c.A.TokenUsage), formatCost(c.B.TokenUsage))
+	row("cost per report (USD)", fmt.Sprintf("%.5f", c.A.CostPerReport), fmt.Sprintf("%.5f", c.B.CostPerReport))
+	row("validation failures", fmt.Sprint(c.A.ValidationFailed), fmt.Sprint(c.B.ValidationFailed))
+	row("re-prompts", fmt.Sprint(c.A.Reprompts), fmt.Sprint(c.B.Reprompts))
+	row("evaluator score (mean)", fmt.Sprintf("%.2f", c.A.EvaluatorMean), fmt.Sprintf("%.2f", c.B.EvaluatorMean))
+	row("length min/p50/p90/max",
+		fmt.Sprintf("%d/%d/%d/%d", c.A.LengthMin, c.A.LengthP50, c.A.LengthP90, c.A.LengthMax),
+		fmt.Sprintf("%d/%d/%d/%d", c.B.LengthMin, c.B.LengthP50, c.B.LengthP90, c.B.LengthMax))
+
+	titles := make(map[string]bool)
+	for t := range c.A.SectionScoreMeans {
+		titles[t] = true
+	}
+	for t := range c.B.SectionScoreMeans {
+		titles[t] = true
+	}
+	sorted := make([]string, 0, len(titles))
+	for t := range titles {
+		sorted = append(sorted, t)
+	}
+	sort.Strings(sorted)
+	if len(sorted) > 0 {
+		b.WriteString("\nMean section scores:\n")
+	}
+	for _, t := range sorted {
+		row("  "+shorten(t, 26), formatMean(c.A.SectionScoreMeans, t), formatMean(c.B.SectionScoreMeans, t))
+	}
+
+	return b.String()
+}
+
+func formatCost(usage *WeekTokenUsage) string {
+	if usage == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.4f (%d tok)", usage.EstimatedCostUSD, usage.PromptTokens+usage.CompletionTokens)
+}
+
+func formatMean(means map[string]float64, title string) string {
+	if v, ok := means[title]; ok {
+		return fmt.Sprintf("%.2f", v)
+	}
+	return "-"
+}
+
+// shorten keeps the tail of long values so file names stay visible
+func shorten(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return "…" + string(r[len(r)-max+1:])
+}
@@ -0,0 +1,200 @@
+package gold
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/processor"
+)
+
+// Consensus strategies
+const (
+	ConsensusSelect = "select" // Keep the better of the two reports
+	ConsensusMerge  = "merge"  // Keep the better report, averaging scores and combining goals/suggestions
+)
+
+// ConsensusInfo records how a report was produced in consensus mode
+type ConsensusInfo struct {
+	Strategy      string               `json:"strategy"`
+	Reason        string               `json:"reason"`
+	SelectedModel string               `json:"selected_model"`
+	Candidates    []ConsensusCandidate `json:"candidates"`
+}
+
+// ConsensusCandidate is one model's report quality and cost
+type ConsensusCandidate struct {
+	Model              string  `json:"model"`
+	UnsupportedNumbers int     `json:"unsupported_numbers"`
+	Sections           int     `json:"sections"`
+	QualityScore       float64 `json:"quality_score"`
+	CostUSD            float64 `json:"cost_usd"`
+	Error              string  `json:"error,omitempty"`
+}
+
+// consensusPlanner decides which kids get a second-model report, capped to a share of all kids
+type consensusPlanner struct {
+	cfg       config.ConsensusConfig
+	flagged   map[string]bool
+	secondary *processor.AIProcessor
+
+	mu        sync.Mutex
+	kidsSeen  int
+	consensus int
+}
+
+// newConsensusPlanner returns nil when consensus mode is disabled
+func newConsensusPlanner(cfg config.ConsensusConfig, secondary *processor.AIProcessor) *consensusPlanner {
+	if !cfg.Enabled || secondary == nil {
+		return nil
+	}
+	if cfg.Strategy == "" {
+		cfg.Strategy = ConsensusSelect
+	}
+	flagged := make(map[string]bool)
+	for _, id := range cfg.FlaggedProfileIDs {
+		flagged[id] = true
+	}
+	return &consensusPlanner{cfg: cfg, flagged: flagged, secondary: secondary}
+}
+
+// reason returns why a kid needs consensus ("" when it doesn't, or when the share cap is reached)
+func (cp *consensusPlanner) reason(kid KidDataV2) string {
+	if cp == nil {
+		return ""
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.kidsSeen++
+
+	var reason string
+	totalSpent := kid.JoySpent + kid.SpendingSpent + kid.CharitySpent + kid.StudySpent
+	switch {
+	case cp.flagged[kid.ProfileID]:
+		reason = "flagged profile"
+	case cp.cfg.AnomalySpendRatio > 0 && kid.MoneyReceived > 0 && totalSpent > kid.MoneyReceived*cp.cfg.AnomalySpendRatio:
+		reason = fmt.Sprintf("spending %.1fx money received", totalSpent/kid.MoneyReceived)
+	default:
+		return ""
+	}
+
+	// Keep consensus to a small share of kids (always allow at least one)
+	limit := int(math.Ceil(float64(cp.kidsSeen) * cp.cfg.MaxSharePercent / 100))
+	if cp.cfg.MaxSharePercent > 0 && cp.consensus >= limit && cp.consensus > 0 {
+		return ""
+	}
+	cp.consensus++
+	return reason
+}
+
+// qualityScore ranks a report: complete sections and goals count for, unsupported numbers against
+func qualityScore(report *AIReport, unsupported int) float64 {
+	score := float64(len(report.PerformanceSections)) + 0.5*float64(len(report.FinancialTendencies))
+	if len(report.NextWeekGoals) > 0 {
+		score++
+	}
+	if len(report.ParentSuggestions) > 0 {
+		score++
+	}
+	return score - 2*float64(unsupported)
+}
+
+// generateConsensusReport generates the report with both models and keeps (or merges) the better one
+func (gl *GoldLayer) generateConsensusReport(ctx context.Context, kid KidDataV2, weekLabel, reason string, queued *renderedPrompt) (*AIReport, error) {
+	gl.logger.Infof("   🤝 %s: consensus mode (%s)", kid.Nickname, reason)
+
+	processors := []struct {
+		model string
+		proc  *processor.AIProcessor
+	}{
+		{gl.config.OpenAI.Model, gl.aiProcessor},
+		{gl.config.Gold.Consensus.SecondaryModel, gl.consensus.secondary},
+	}
+
+	var reports []*AIReport
+	var candidates []ConsensusCandidate
+	best := -1
+	for _, p := range processors {
+		// The cost is the candidate's own calls: other kids generate on the same trackers at the same time
+		report, result, err := gl.generateWithProcessor(ctx, p.proc, kid, weekLabel, queued)
+		candidate := ConsensusCandidate{Model: p.model, CostUSD: result.usage.EstimatedCost}
+		if err != nil {
+			candidate.Error = err.Error()
+			candidates = append(candidates, candidate)
+			reports = append(reports, nil)
+			continue
+		}
+		candidate.UnsupportedNumbers = result.unsupported
+		candidate.Sections = len(report.PerformanceSections)
+		candidate.QualityScore = qualityScore(report, result.unsupported)
+		candidates = append(candidates, candidate)
+		reports = append(reports, report)
+		if best < 0 || candidate.QualityScore > candidates[best].QualityScore {
+			best = len(candidates) - 1
+		}
+	}
+
+	if best < 0 {
+		return nil, fmt.Errorf("consensus failed for both models: %s; %s", candidates[0].Error, candidates[1].Error)
+	}
+
+	selected := reports[best]
+	if gl.consensus.cfg.Strategy == ConsensusMerge {
+		if other := reports[1-best]; other != nil {
+			mergeReports(selected, other)
+		}
+	}
+	selected.Consensus = &ConsensusInfo{
+		Strategy:      gl.consensus.cfg.Strategy,
+		Reason:        reason,
+		SelectedModel: candidates[best].Model,
+		Candidates:    candidates,
+	}
+
+	gl.logger.Infof("   🤝 %s: selected %s (quality %.1f)", kid.Nickname, candidates[best].Model, candidates[best].QualityScore)
+	return selected, nil
+}
+
+// mergeReports averages section scores with matching titles and adds goals, suggestions, strengths,
+// risk and badge the primary lacks
+func mergeReports(primary, other *AIReport) {
+	otherScores := make(map[string]int)
+	for _, s := range other.PerformanceSections {
+		otherScores[s.Title] = s.Score
+	}
+	for i := range primary.PerformanceSections {
+		section := &primary.PerformanceSections[i]
+		if score, ok := otherScores[section.Title]; ok {
+			section.Score = int(math.Round(float64(section.Score+score) / 2))
+		}
+	}
+	primary.NextWeekGoals = appendUnique(primary.NextWeekGoals, other.NextWeekGoals)
+	primary.ParentSuggestions = appendUnique(primary.ParentSuggestions, other.ParentSuggestions)
+	primary.Strengths = appendUnique(primary.Strengths, other.Strengths)
+	if primary.TopRisk == "" {
+		primary.TopRisk = other.TopRisk
+	}
+	if primary.Badge == nil {
+		primary.Badge = other.Badge
+	}
+}
+
+// appendUnique appends items not already present (case-insensitive)
+func appendUnique(base, extra []string) []string {
+	seen := make(map[string]bool)
+	for _, s := range base {
+		seen[strings.ToLower(strings.TrimSpace(s))] = true
+	}
+	for _, s := range extra {
+		key := strings.ToLower(strings.TrimSpace(s))
+		if !seen[key] {
+			seen[key] = true
+			base = append(base, s)
+		}
+	}
+	return base
+}
@@ -0,0 +1,193 @@
+package gold
+
+import (
+	"fmt"
+	"sync"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/processor"
+	"ai-production-pipeline/internal/progress"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of kids gold.cost_budget never downgrades
+const (
+	UrgentOperatorNote      = "operator_note"      // A customer-success note is attached
+	UrgentRequestedSections = "requested_sections" // The parent asked for optional sections
+	UrgentDataQuality       = "data_quality"       // Silver flagged the profile data
+)
+
+// ReportDowngrade records that a report was generated on the cheaper tier to stay within the run budget
+type ReportDowngrade struct {
+	Model       string `json:"model"`                  // Model the report was generated with
+	ServiceTier string `json:"service_tier,omitempty"` // e.g. "flex"
+	Reason      string `json:"reason"`                 // The projection that triggered the downgrade
+}
+
+// costBudget projects the run's cost and tokens after each report. Once a projection exceeds its
+// budget, the remaining non-urgent reports of the run use the cheaper tier.
+type costBudget struct {
+	maxCost    float64
+	maxTokens  int
+	minReports int
+	tier       processor.Tier
+	model      string // Model downgraded reports are generated with
+	urgent     map[string]bool
+	logger     *logrus.Logger
+
+	mu           sync.Mutex
+	reports      int              // Reports generated at the standard tier
+	reportCost   float64          // Their own calls' cost, for the average per report
+	reportTokens int              // Their own calls' tokens
+	downgrade    *ReportDowngrade // Set once the budget is projected to be exceeded
+}
+
+// newCostBudget returns nil when the cost budget is disabled
+func newCostBudget(cfg config.CostBudgetConfig, ai config.OpenAIConfig, logger *logrus.Logger) (*costBudget, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.MaxCostUSD < 0 || cfg.MaxTokens < 0 {
+		return nil, fmt.Errorf("gold.cost_budget limits must not be negative")
+	}
+	if cfg.MaxCostUSD == 0 && cfg.MaxTokens == 0 {
+		return nil, fmt.Errorf("gold.cost_budget needs max_cost_usd or max_tokens")
+	}
+	switch cfg.ServiceTier {
+	case "":
+	case processor.ServiceTierFlex:
+		if ai.Provider == processor.ProviderAnthropic {
+			return nil, fmt.Errorf("gold.cost_budget.service_tier %q is only offered by OpenAI", cfg.ServiceTier)
+		}
+		if model := valueOr(cfg.DowngradeModel, ai.Model); !processor.FlexOffered(model) {
+			return nil, fmt.Errorf("gold.cost_budget.service_tier %q is not offered for %s; leave it empty for the standard tier", cfg.ServiceTier, model)
+		}
+	default:
+		return nil, fmt.Errorf("gold.cost_budget.service_tier must be empty or %q, got %q", processor.ServiceTierFlex, cfg.ServiceTier)
+	}
+	if cfg.ServiceTier == "" && (cfg.DowngradeModel == "" || cfg.DowngradeModel == ai.Model) {
+		return nil, fmt.Errorf("gold.cost_budget needs a downgrade_model other than openai.model or a service_tier")
+	}
+	urgent := make(map[string]bool)
+	for _, kind := range cfg.Urgent {
+		switch kind {
+		case UrgentOperatorNote, UrgentRequestedSections, UrgentDataQuality:
+			urgent[kind] = true
+		default:
+			return nil, fmt.Errorf("unknown gold.cost_budget.urgent entry %q (use %s, %s or %s)",
+				kind, UrgentOperatorNote, UrgentRequestedSections, UrgentDataQuality)
+		}
+	}
+	if cfg.MinReports <= 0 {
+		cfg.MinReports = 5
+	}
+
+	model := cfg.DowngradeModel
+	if model == "" {
+		model = ai.Model
+	}
+	return &costBudget{
+		maxCost:    cfg.MaxCostUSD,
+		maxTokens:  cfg.MaxTokens,
+		minReports: cfg.MinReports,
+		tier:       processor.Tier{Model: cfg.DowngradeModel, ServiceTier: cfg.ServiceTier},
+		model:      model,
+		urgent:     urgent,
+		logger:     logger,
+	}, nil
+}
+
+// isUrgent reports whether kid keeps the standard tier whatever the budget
+func (b *costBudget) isUrgent(kid KidDataV2) bool {
+	return (b.urgent[UrgentOperatorNote] && kid.OperatorNote != "") ||
+		(b.urgent[UrgentRequestedSections] && len(kid.RequestedSections) > 0) ||
+		(b.urgent[UrgentDataQuality] && len(kid.DataQuality) > 0)
+}
+
+// downgradeFor returns the downgrade to apply to kid's report (nil = standard tier)
+func (b *costBudget) downgradeFor(kid KidDataV2) *ReportDowngrade {
+	if b == nil || b.isUrgent(kid) {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.downgrade == nil {
+		return nil
+	}
+	downgrade := *b.downgrade
+	return &downgrade
+}
+
+// record projects the run after a report generated at the standard tier: what was spent so far
+// plus the average per report for the kids left in this week and the weeks not started. The average
+// comes from each report's own usage, since the run's spend includes reports still being generated.
+func (b *costBudget) record(report, spent processor.TokenUsage, run progress.RunState) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.downgrade != nil {
+		return
+	}
+	b.reports++
+	b.reportCost += report.EstimatedCost
+	b.reportTokens += report.TotalTokens
+	if b.reports < b.minReports {
+		return
+	}
+
+	remaining := run.WeekKidsTotal - run.WeekKidsDone
+	if weeksLeft := run.TotalWeeks - run.CurrentWeekNum; weeksLeft > 0 {
+		remaining += weeksLeft * run.WeekKidsTotal
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	projectedCost := spent.EstimatedCost + b.reportCost/float64(b.reports)*float64(remaining)
+	projectedTokens := spent.TotalTokens + b.reportTokens/b.reports*remaining
+
+	var reason string
+	switch {
+	case b.maxCost > 0 && projectedCost > b.maxCost:
+		reason = fmt.Sprintf("projected run cost $%.2f exceeds budget $%.2f", projectedCost, b.maxCost)
+	case b.maxTokens > 0 && projectedTokens > b.maxTokens:
+		reason = fmt.Sprintf("projected run tokens %d exceed budget %d", projectedTokens, b.maxTokens)
+	default:
+		return
+	}
+	b.downgrade = &ReportDowngrade{Model: b.model, ServiceTier: b.tier.ServiceTier, Reason: reason}
+	tier := b.model
+	if b.tier.ServiceTier != "" {
+		tier += " (" + b.tier.ServiceTier + ")"
+	}
+	b.logger.Warnf("💸 %s after %d reports: the remaining %d non-urgent reports use %s", reason, b.reports, remaining, tier)
+}
+
+// countDowngraded counts the reports generated on the cheaper tier
+func countDowngraded(reports []AIReport) int {
+	count := 0
+	for _, report := range reports {
+		if report.Downgrade != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// runUsage sums the run's usage across primary and consensus models
+func (gl *GoldLayer) runUsage() processor.TokenUsage {
+	var usage processor.TokenUsage
+	for _, proc := range []*processor.AIProcessor{gl.aiProcessor, gl.GetConsensusProcessor()} {
+		if proc == nil {
+			continue
+		}
+		summary := proc.GetTokenTracker().GetTotalSummary()
+		usage.PromptTokens += summary.PromptTokens
+		usage.CompletionTokens += summary.CompletionTokens
+		usage.TotalTokens += summary.TotalTokens
+		usage.EstimatedCost += summary.EstimatedCost
+	}
+	return usage
+}
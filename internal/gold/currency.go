@@ -0,0 +1,188 @@
+package gold
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// Symbol placement values (currency.symbol_position)
+const (
+	SymbolBefore = "before" // Rp 10.000
+	SymbolAfter  = "after"  // 10.000 ₫
+)
+
+// defaultCurrency is used for every field left empty in the currency config
+var defaultCurrency = config.CurrencyConfig{
+	Code:               "VND",
+	Symbol:             "₫",
+	SymbolPosition:     SymbolAfter,
+	ThousandsSeparator: ".",
+	DecimalSeparator:   ",",
+	UnitNames:          map[string]string{"vi": "đồng", "en": "VND"},
+}
+
+// isoMinorUnits lists the ISO 4217 currencies whose minor unit is not 2 digits
+var isoMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// minorUnits returns the digits after the decimal separator ISO 4217 gives a currency code
+func minorUnits(code string) int {
+	if digits, ok := isoMinorUnits[code]; ok {
+		return digits
+	}
+	return 2
+}
+
+// currencyFormat rounds and formats amounts in the tenant's currency
+type currencyFormat struct {
+	code      string
+	symbol    string
+	before    bool
+	decimals  int
+	thousands string
+	decimal   string
+	unitNames map[string]string
+}
+
+// newCurrencyFormat validates the currency config, defaulting to Vietnamese đồng
+func newCurrencyFormat(cfg config.CurrencyConfig) (*currencyFormat, error) {
+	code := strings.ToUpper(strings.TrimSpace(cfg.Code))
+	if code == "" {
+		code = defaultCurrency.Code
+	}
+	if len(code) != 3 {
+		return nil, fmt.Errorf("currency.code must be a 3-letter ISO 4217 code, got %q", cfg.Code)
+	}
+	decimals := minorUnits(code)
+	if cfg.Decimals != nil {
+		decimals = *cfg.Decimals
+	}
+	if decimals < 0 || decimals > 4 {
+		return nil, fmt.Errorf("currency.decimals must be between 0 and 4, got %d", decimals)
+	}
+
+	f := &currencyFormat{
+		code:      code,
+		symbol:    cfg.Symbol,
+		decimals:  decimals,
+		thousands: cfg.ThousandsSeparator,
+		decimal:   cfg.DecimalSeparator,
+		unitNames: cfg.UnitNames,
+	}
+	if code == defaultCurrency.Code {
+		if f.symbol == "" {
+			f.symbol = defaultCurrency.Symbol
+		}
+		if len(f.unitNames) == 0 {
+			f.unitNames = defaultCurrency.UnitNames
+		}
+	}
+	if f.symbol == "" {
+		f.symbol = code
+	}
+	if f.thousands == "" {
+		f.thousands = defaultCurrency.ThousandsSeparator
+	}
+	if f.decimal == "" {
+		f.decimal = defaultCurrency.DecimalSeparator
+	}
+	if f.thousands == f.decimal {
+		return nil, fmt.Errorf("currency.thousands_separator and currency.decimal_separator must differ, both are %q", f.thousands)
+	}
+
+	switch valueOr(cfg.SymbolPosition, SymbolAfter) {
+	case SymbolBefore:
+		f.before = true
+	case SymbolAfter:
+	default:
+		return nil, fmt.Errorf("currency.symbol_position must be before or after, got %q", cfg.SymbolPosition)
+	}
+	return f, nil
+}
+
+// round rounds an amount to the currency's decimals
+func (f *currencyFormat) round(amount float64) float64 {
+	scale := math.Pow10(f.decimals)
+	return math.Round(amount*scale) / scale
+}
+
+// roundAmounts rounds the kid's money figures as sent to the AI, so it never sees
+// more digits than the currency displays (nil leaves them unchanged)
+func (f *currencyFormat) roundAmounts(kid *KidDataV2) {
+	if f == nil {
+		return
+	}
+	kid.Currency = f.code
+	for _, amount := range []*float64{
+		&kid.JoyWallet, &kid.SpendingWallet, &kid.CharityWallet, &kid.StudyWallet, &kid.MoneyReceived,
+		&kid.JoySpent, &kid.SpendingSpent, &kid.CharitySpent, &kid.StudySpent, &kid.InterestEarned,
+	} {
+		*amount = f.round(*amount)
+	}
+	for category, amount := range kid.SpendingByCategory {
+		kid.SpendingByCategory[category] = f.round(amount)
+	}
+}
+
+// format renders an amount with the currency's separators and symbol, e.g. "15.615 ₫" or "฿1,250.50"
+func (f *currencyFormat) format(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatFloat(f.round(amount), 'f', f.decimals, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(f.thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+	number := grouped.String()
+	if fraction != "" {
+		number += f.decimal + fraction
+	}
+
+	if f.before {
+		if last, _ := utf8.DecodeLastRuneInString(f.symbol); unicode.IsLetter(last) {
+			return sign + f.symbol + " " + number // "Rp 10.000", but "฿1,250"
+		}
+		return sign + f.symbol + number
+	}
+	return sign + number + " " + f.symbol
+}
+
+// unitName returns the spoken unit for a report language (the ISO code when none is configured)
+func (f *currencyFormat) unitName(language string) string {
+	if name := f.unitNames[language]; name != "" {
+		return name
+	}
+	return f.code
+}
+
+// promptBlock tells the AI which currency the amounts are in and how to write them ("" when nil)
+func (f *currencyFormat) promptBlock(language string) string {
+	if f == nil {
+		return ""
+	}
+	example := f.format(15615.07)
+	if language == "en" {
+		return fmt.Sprintf("Currency: all amounts are in %s. Call the unit \"%s\" and write amounts like %s (never use another currency).\n",
+			f.code, f.unitName(language), example)
+	}
+	return fmt.Sprintf("Tiền tệ: mọi số tiền đều tính bằng %s. Gọi đơn vị là \"%s\" và viết số tiền dạng %s (không dùng đơn vị tiền tệ khác).\n",
+		f.code, f.unitName(language), example)
+}
@@ -0,0 +1,54 @@
+package gold
+
+import "strings"
+
+// childName returns the name used in the report, falling back to a neutral form of address
+func childName(kid KidDataV2, language string) string {
+	if strings.TrimSpace(kid.Nickname) != "" {
+		return kid.Nickname
+	}
+	if language == "en" {
+		return "your child"
+	}
+	return "bé"
+}
+
+// dataQualityNotes tells the AI what not to write about when profile data is missing
+func dataQualityNotes(kid KidDataV2, language string) string {
+	var notes []string
+	if kid.Age == nil {
+		if language == "en" {
+			notes = append(notes, "The child's age is unknown: do NOT mention or guess the age.")
+		} else {
+			notes = append(notes, "Không rõ tuổi của bé: KHÔNG nhắc đến hoặc đoán tuổi của bé.")
+		}
+	}
+	if strings.TrimSpace(kid.Nickname) == "" {
+		if language == "en" {
+			notes = append(notes, "The child's name is unknown: refer to them as \"your child\", do not invent a name.")
+		} else {
+			notes = append(notes, "Không có tên của bé: gọi là \"bé\", không tự đặt tên.")
+		}
+	}
+	if hasFlag(kid.DataQuality, "missing_wallets") {
+		if language == "en" {
+			notes = append(notes, "Some of the child's wallets were deleted: balances may be incomplete, do NOT comment on the balance of individual wallets.")
+		} else {
+			notes = append(notes, "Một số ví của bé đã bị xoá: số dư có thể không đầy đủ, KHÔNG nhận xét về số dư từng ví.")
+		}
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return "\n\n" + strings.Join(notes, "\n")
+}
+
+// hasFlag reports whether a Silver data quality flag is set
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package gold
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/processor"
+)
+
+// familyUsageLabel is the token tracking bucket for family reports
+const familyUsageLabel = "family_report"
+
+// FamilyReport is the weekly household summary for one parent across all their kids. The money
+// figures are computed from Silver; only the text fields are written by the AI.
+type FamilyReport struct {
+	ParentID           string      `json:"parent_id"`
+	Week               string      `json:"week"`
+	Language           string      `json:"language"`
+	Kids               []FamilyKid `json:"kids"`
+	MoneyReceived      float64     `json:"money_received"` // Household income of the week, study wallet interest included
+	TotalSpent         float64     `json:"total_spent"`
+	SavingsRate        *float64    `json:"savings_rate"` // Share of the income not spent, in % (null without income)
+	Summary            string      `json:"summary"`
+	SiblingComparisons []string    `json:"sibling_comparisons"`
+	JointSuggestions   []string    `json:"joint_suggestions"` // Things the family can do together
+	GeneratedAt        string      `json:"generated_at"`
+}
+
+// FamilyKid is one kid's share of a family report
+type FamilyKid struct {
+	ProfileID         string   `json:"profile_id"`
+	Name              string   `json:"name"`
+	MoneyReceived     float64  `json:"money_received"`
+	TotalSpent        float64  `json:"total_spent"`
+	SavingsRate       *float64 `json:"savings_rate"`
+	MissionsCompleted int      `json:"missions_completed"`
+	MissionsTotal     int      `json:"missions_total"`
+	Strengths         []string `json:"strengths,omitempty"` // From the kid's weekly report, when it has one
+	TopRisk           string   `json:"top_risk,omitempty"`
+}
+
+// familyInput is what the model sees of a family: the computed figures, no text of its own
+type familyInput struct {
+	Kids          []FamilyKid `json:"kids"`
+	MoneyReceived float64     `json:"money_received"`
+	TotalSpent    float64     `json:"total_spent"`
+	SavingsRate   *float64    `json:"savings_rate"`
+}
+
+// familyResponse is what the model returns for one family
+type familyResponse struct {
+	Summary            string   `json:"summary"`
+	SiblingComparisons []string `json:"sibling_comparisons"`
+	JointSuggestions   []string `json:"joint_suggestions"`
+}
+
+// familyReporter holds the family report templates per language
+type familyReporter struct {
+	cfg             config.FamilyReportConfig
+	templates       map[string]string
+	defaultLanguage string
+}
+
+// loadFamilyReporter loads the family report templates (nil when disabled)
+func loadFamilyReporter(cfg config.FamilyReportConfig, defaultLanguage string) (*familyReporter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = 800
+	}
+	if cfg.MinKids <= 0 {
+		cfg.MinKids = 2
+	}
+	r := &familyReporter{cfg: cfg, templates: make(map[string]string), defaultLanguage: defaultLanguage}
+	for lang, file := range cfg.TemplateFiles {
+		template, err := loadPromptTemplate(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load family report template (%s): %w", lang, err)
+		}
+		r.templates[NormalizeLanguage(lang)] = template
+	}
+	if _, ok := r.templates[defaultLanguage]; !ok {
+		return nil, fmt.Errorf("gold.family_report.template_files has no %q template", defaultLanguage)
+	}
+	return r, nil
+}
+
+// savingsRate is the share of income not spent, in % with one decimal (nil without income)
+func savingsRate(received, spent float64) *float64 {
+	if received <= 0 {
+		return nil
+	}
+	rate := roundPercent((received - spent) / received * 100)
+	return &rate
+}
+
+// newFamilyReport adds up a family's kids; reports holds the kids' weekly reports by profile ID
+func (gl *GoldLayer) newFamilyReport(parentID string, kids []KidDataV2, reports map[string]AIReport, weekLabel string) *FamilyReport {
+	family := &FamilyReport{ParentID: parentID, Week: weekLabel, Language: gl.reportLanguage(kids[0])}
+	for _, kid := range kids {
+		received := gl.currency.round(kid.MoneyReceived + kid.InterestEarned)
+		spent := gl.currency.round(kid.totalSpent())
+		member := FamilyKid{
+			ProfileID:         kid.ProfileID,
+			Name:              kid.Nickname,
+			MoneyReceived:     received,
+			TotalSpent:        spent,
+			SavingsRate:       savingsRate(received, spent),
+			MissionsCompleted: kid.MissionsCompleted,
+			MissionsTotal:     kid.MissionsTotal,
+		}
+		if report, ok := reports[kid.ProfileID]; ok {
+			member.Strengths = report.Strengths
+			member.TopRisk = report.TopRisk
+		}
+		family.Kids = append(family.Kids, member)
+		family.MoneyReceived += received
+		family.TotalSpent += spent
+	}
+	family.MoneyReceived = gl.currency.round(family.MoneyReceived)
+	family.TotalSpent = gl.currency.round(family.TotalSpent)
+	family.SavingsRate = savingsRate(family.MoneyReceived, family.TotalSpent)
+	return family
+}
+
+// prompt renders the family report prompt
+func (r *familyReporter) prompt(family *FamilyReport, currency string) (string, error) {
+	data, err := json.Marshal(familyInput{
+		Kids:          family.Kids,
+		MoneyReceived: family.MoneyReceived,
+		TotalSpent:    family.TotalSpent,
+		SavingsRate:   family.SavingsRate,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal family input: %w", err)
+	}
+
+	template, ok := r.templates[family.Language]
+	if !ok {
+		template = r.templates[r.defaultLanguage]
+	}
+	prompt := strings.ReplaceAll(template, "{{FAMILY}}", string(data))
+	prompt = strings.ReplaceAll(prompt, "{{WEEK}}", family.Week)
+	prompt = strings.ReplaceAll(prompt, "{{CURRENCY}}", currency)
+	return prompt, nil
+}
+
+// GenerateFamilyReports writes one household report per parent with at least min_kids kids to
+// familyPath. Kids are grouped by parent_id from the week's Silver output, and each kid's strengths
+// and top risk are taken from the week's Gold reports. Failed families are logged and skipped.
+func (gl *GoldLayer) GenerateFamilyReports(ctx context.Context, silverPath, reportPath, familyPath, weekLabel string) (int, error) {
+	if gl.families == nil {
+		return 0, fmt.Errorf("family reports are disabled (gold.family_report.enabled)")
+	}
+
+	data, err := fileio.ReadFile(silverPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read silver output: %w", err)
+	}
+	var silverData struct {
+		Kids []map[string]interface{} `json:"kids"`
+	}
+	if err := json.Unmarshal(data, &silverData); err != nil {
+		return 0, fmt.Errorf("failed to parse silver output: %w", err)
+	}
+
+	data, err = fileio.ReadFile(reportPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read reports: %w", err)
+	}
+	var output reportOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return 0, fmt.Errorf("failed to parse reports: %w", err)
+	}
+	reports := make(map[string]AIReport, len(output.Reports))
+	for _, report := range output.Reports {
+		reports[report.ProfileID] = report
+	}
+
+	// Group by parent, keeping first-seen order
+	var parents []string
+	byParent := make(map[string][]KidDataV2)
+	for _, kidMap := range silverData.Kids {
+		kid := gl.convertEnhancedToV2(kidMap, weekLabel)
+		if kid.ParentID == "" {
+			continue
+		}
+		if _, ok := byParent[kid.ParentID]; !ok {
+			parents = append(parents, kid.ParentID)
+		}
+		byParent[kid.ParentID] = append(byParent[kid.ParentID], kid)
+	}
+	if len(parents) == 0 {
+		gl.logger.Warn("⚠️  No kids with a parent_id, skipping family reports (set silver.parent_column)")
+		return 0, nil
+	}
+
+	minKids := gl.families.cfg.MinKids
+	var families []FamilyReport
+	skipped := 0
+	gl.logger.Infof("🏠 Generating family reports for %d parents", len(parents))
+	for _, parentID := range parents {
+		if ctx.Err() != nil {
+			return len(families), ctx.Err()
+		}
+		kids := byParent[parentID]
+		if len(kids) < minKids {
+			skipped++
+			continue
+		}
+
+		family := gl.newFamilyReport(parentID, kids, reports, weekLabel)
+		if err := gl.generateFamilyText(ctx, family); err != nil {
+			gl.logger.Errorf("   ❌ Family report failed for %s: %v", parentID, err)
+			continue
+		}
+		families = append(families, *family)
+	}
+	if skipped > 0 {
+		gl.logger.Infof("   ⏭️  %d families with fewer than %d kids skipped (gold.family_report.min_kids)", skipped, minKids)
+	}
+
+	result := map[string]interface{}{
+		"generated_at":  time.Now().Format(time.RFC3339),
+		"week":          weekLabel,
+		"model":         valueOr(gl.families.cfg.Model, gl.config.OpenAI.Model),
+		"currency":      gl.currency.code,
+		"total_reports": len(families),
+		"reports":       families,
+	}
+	if gl.metadata != nil {
+		result["metadata"] = gl.metadata
+	}
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return len(families), fmt.Errorf("failed to marshal family reports: %w", err)
+	}
+	writtenPath, err := fileio.WriteFile(familyPath, encoded, gl.config.Data.CompressionCodec())
+	if err != nil {
+		return len(families), fmt.Errorf("failed to write file %s: %w", familyPath, err)
+	}
+
+	gl.logger.Infof("✅ Family reports saved to: %s (%d/%d)", writtenPath, len(families), len(parents)-skipped)
+	return len(families), nil
+}
+
+// generateFamilyText asks the model for a family's summary, sibling comparisons and joint suggestions
+func (gl *GoldLayer) generateFamilyText(ctx context.Context, family *FamilyReport) error {
+	prompt, err := gl.families.prompt(family, gl.currency.promptBlock(family.Language))
+	if err != nil {
+		return err
+	}
+	result, _, err := processor.DoJSON[familyResponse](ctx, gl.aiProcessor, processor.Request{
+		Messages:   []processor.Message{{Role: "user", Content: prompt}},
+		Model:      gl.families.cfg.Model,
+		MaxTokens:  gl.families.cfg.MaxTokens,
+		UsageLabel: familyUsageLabel,
+	})
+	if err != nil {
+		return err
+	}
+	if family.Summary = strings.TrimSpace(result.Summary); family.Summary == "" {
+		return fmt.Errorf("family report has no summary")
+	}
+	family.SiblingComparisons = nonBlank(result.SiblingComparisons)
+	family.JointSuggestions = nonBlank(result.JointSuggestions)
+	family.GeneratedAt = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+// nonBlank trims the entries and drops empty ones (never nil, so the JSON is always a list)
+func nonBlank(values []string) []string {
+	kept := []string{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
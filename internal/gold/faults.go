@@ -0,0 +1,35 @@
+package gold
+
+import (
+	"fmt"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/processor"
+)
+
+// FaultConfig converts openai.fault_injection for the AI processor (no faults when disabled)
+func FaultConfig(cfg config.FaultInjectionConfig) (processor.FaultConfig, error) {
+	if !cfg.Enabled {
+		return processor.FaultConfig{}, nil
+	}
+	faults := processor.FaultConfig{
+		TimeoutRate:     cfg.TimeoutRate,
+		RateLimitRate:   cfg.RateLimitRate,
+		ServerErrorRate: cfg.ServerErrorRate,
+		MalformedRate:   cfg.MalformedJSONRate,
+		SlowRate:        cfg.SlowRate,
+		Seed:            cfg.Seed,
+	}
+	if cfg.SlowDelay != "" {
+		delay, err := time.ParseDuration(cfg.SlowDelay)
+		if err != nil || delay < 0 {
+			return processor.FaultConfig{}, fmt.Errorf("invalid openai.fault_injection.slow_delay %q", cfg.SlowDelay)
+		}
+		faults.SlowDelay = delay
+	}
+	if err := faults.Validate(); err != nil {
+		return processor.FaultConfig{}, fmt.Errorf("openai.%w", err)
+	}
+	return faults, nil
+}
@@ -3,25 +3,108 @@ package gold
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/checkpoint"
 	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/outputstore"
 	"ai-production-pipeline/internal/processor"
+	"ai-production-pipeline/internal/progress"
+	"ai-production-pipeline/internal/telemetry"
+	"ai-production-pipeline/internal/unitofwork"
 
 	"github.com/sirupsen/logrus"
 )
 
 // GoldLayer handles AI inference with enhanced prompts
 type GoldLayer struct {
-	config         *config.Config
-	logger         *logrus.Logger
-	aiProcessor    *processor.AIProcessor
-	promptTemplate string // Cached prompt template from file
-	systemMessage  string // Cached system message from file
+	config           *config.Config
+	logger           *logrus.Logger
+	aiProcessor      *processor.AIProcessor
+	promptTemplate   string               // Cached prompt template from file
+	systemMessage    string               // Cached system message from file
+	prompts          map[string]promptSet // Templates per report language
+	parsedPrompts    sync.Map             // Template text → *template.Template
+	weekTypes        *weekTypes           // Exam/holiday week prompts from the school calendar
+	defaultLanguage  string
+	campaign         string // Per-run campaign notes for {{CAMPAIGN}} ("" = none)
+	progress         *progress.Tracker
+	softStop         context.Context // When done, stop starting new kids and flush (run.max_duration)
+	quality          qualityTracker
+	calibrator       *scoreCalibrator
+	taxonomy         *sectionTaxonomy
+	badges           *badgeCatalog     // Badges the AI may recommend (nil = none)
+	optional         *optionalSections // Parent-requested section blocks (nil = disabled)
+	digester         *parentDigester   // Per-parent digest templates (nil = disabled)
+	families         *familyReporter   // Household report templates (nil = disabled)
+	kidVersions      *kidVersioner     // Kid-facing versions of the reports (nil = disabled)
+	style            reportStyle
+	currency         *currencyFormat
+	reuseExisting    bool // Keep reports already in the week's output and only generate the missing kids
+	consensus        *consensusPlanner
+	unit             *unitofwork.UnitOfWork // When set, reports are staged until the week's unit commits
+	outputs          *outputstore.Store     // Also stores reports in gold_reports (nil = files only)
+	checkpoint       *checkpoint.Store      // Records each generated report for --resume (nil = off)
+	resumeCheckpoint bool
+	suggestions      *suggestionHistory // Parent suggestions from previous weeks
+	identities       *identityHistory   // Kid names in previous weeks' reports (gold.identity)
+	outage           *outageBreaker     // Queues prompts while the provider is down (nil = off)
+	budget           *costBudget        // Downgrades non-urgent reports when the run would overrun (nil = off)
+	history          string             // gold.prompt_history.mode
+	metadata         *buildinfo.Metadata
+}
+
+// ErrSoftStopped is returned when the run deadline stopped a week before all kids were processed
+var ErrSoftStopped = errors.New("run deadline reached, remaining kids deferred")
+
+// DeferredKid is a kid left unprocessed because the run deadline was reached or the AI provider was down
+type DeferredKid struct {
+	ProfileID string `json:"profile_id"`
+	Nickname  string `json:"nickname"`
+	Reason    string `json:"reason,omitempty"` // "provider unavailable" when the prompt was queued
+
+	queued *QueuedPrompt // Rendered prompt for flush-deferred (nil when not queued)
+}
+
+// SetSoftStop sets a context whose cancellation stops scheduling new kids without aborting in-flight calls
+func (gl *GoldLayer) SetSoftStop(ctx context.Context) {
+	gl.softStop = ctx
+}
+
+// softStopped reports whether the soft-stop deadline has been reached
+func (gl *GoldLayer) softStopped() bool {
+	return gl.softStop != nil && gl.softStop.Err() != nil
+}
+
+// SetUnitOfWork stages report writes in uow instead of writing them directly (nil writes directly)
+func (gl *GoldLayer) SetUnitOfWork(uow *unitofwork.UnitOfWork) {
+	gl.unit = uow
+}
+
+// SetOutputStore also writes each saved week's reports to the database, inside the unit of work's
+// transaction when it has one
+func (gl *GoldLayer) SetOutputStore(store *outputstore.Store) {
+	gl.outputs = store
+}
+
+// SetMetadata stamps build and config metadata into report outputs
+func (gl *GoldLayer) SetMetadata(metadata buildinfo.Metadata) {
+	gl.metadata = &metadata
+	gl.stampPromptHashes()
+}
+
+// SetProgressTracker reports per-kid progress and cost to the run tracker
+func (gl *GoldLayer) SetProgressTracker(tracker *progress.Tracker) {
+	gl.progress = tracker
 }
 
 // GetAIProcessor returns the AI processor for external access (e.g., token reporting)
@@ -29,34 +112,143 @@ func (gl *GoldLayer) GetAIProcessor() *processor.AIProcessor {
 	return gl.aiProcessor
 }
 
+// GetDefaultLanguage returns the language of reports for kids without a supported language preference
+func (gl *GoldLayer) GetDefaultLanguage() string {
+	return gl.defaultLanguage
+}
+
+// GetConsensusProcessor returns the secondary-model processor (nil when consensus mode is off)
+func (gl *GoldLayer) GetConsensusProcessor() *processor.AIProcessor {
+	if gl.consensus == nil {
+		return nil
+	}
+	return gl.consensus.secondary
+}
+
+// Preflight verifies the API key, models and JSON mode with one tiny call per model, before any data work
+func (gl *GoldLayer) Preflight(ctx context.Context) error {
+	if err := gl.aiProcessor.Preflight(ctx, ""); err != nil {
+		return err
+	}
+	if secondary := gl.GetConsensusProcessor(); secondary != nil {
+		if err := secondary.Preflight(ctx, ""); err != nil {
+			return fmt.Errorf("consensus model: %w", err)
+		}
+	}
+	return nil
+}
+
+// WeekTokenUsage is the token usage and cost recorded in a week's report output
+type WeekTokenUsage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// weekTokenUsage sums token usage for a week across primary and consensus models
+func (gl *GoldLayer) weekTokenUsage(weekLabel string) WeekTokenUsage {
+	var usage WeekTokenUsage
+	for _, proc := range []*processor.AIProcessor{gl.aiProcessor, gl.GetConsensusProcessor()} {
+		if proc == nil {
+			continue
+		}
+		summary := proc.GetTokenTracker().GetWeekSummary(weekLabel)
+		usage.PromptTokens += summary.PromptTokens
+		usage.CompletionTokens += summary.CompletionTokens
+		usage.EstimatedCostUSD += summary.EstimatedCost
+	}
+	return usage
+}
+
+// estimatedCost returns the cost so far across primary and consensus models
+func (gl *GoldLayer) estimatedCost() float64 {
+	return gl.runUsage().EstimatedCost
+}
+
 // KidDataV2 represents enriched kid data for AI prompt
 type KidDataV2 struct {
-	Nickname           string  `json:"nickname"`
-	Age                int     `json:"age"`
-	JoyWallet          float64 `json:"joy_wallet"`
-	SpendingWallet     float64 `json:"spending_wallet"`
-	CharityWallet      float64 `json:"charity_wallet"`
-	StudyWallet        float64 `json:"study_wallet"`
-	MoneyReceived      float64 `json:"money_received"`
-	MoneyReceivedCount int     `json:"money_received_count"`
-	JoySpent           float64 `json:"joy_spent"`
-	SpendingSpent      float64 `json:"spending_spent"`
-	CharitySpent       float64 `json:"charity_spent"`
-	StudySpent         float64 `json:"study_spent"`
-	MissionsCompleted  int     `json:"missions_completed"`
-	MissionsTotal      int     `json:"missions_total"`
-	ActivityScore      float64 `json:"activity_score"`
+	ProfileID          string           `json:"-"` // Not sent to the AI
+	ParentID           string           `json:"-"` // Parent profile from Silver (silver.parent_column)
+	Language           string           `json:"-"` // App language preference; selects the template
+	DataQuality        []string         `json:"-"` // Silver data quality flags (unknown_age, missing_name, ...)
+	RequestedSections  []SectionRequest `json:"-"` // Optional sections the parent switched on
+	OperatorNote       string           `json:"-"` // Customer-success note, copied to the report untouched
+	WeekType           string           `json:"-"` // Calendar week type; selects the system message and template
+	Identity           *KidIdentity     `json:"-"` // Earlier weeks' names for the profile ID (gold.identity)
+	Nickname           string           `json:"nickname"`
+	Age                *int             `json:"age,omitempty"` // Omitted from the prompt when unknown
+	JoyWallet          float64          `json:"joy_wallet"`
+	SpendingWallet     float64          `json:"spending_wallet"`
+	CharityWallet      float64          `json:"charity_wallet"`
+	StudyWallet        float64          `json:"study_wallet"`
+	MoneyReceived      float64          `json:"money_received"`
+	MoneyReceivedCount int              `json:"money_received_count"`
+	InterestEarned     float64          `json:"interest_earned,omitempty"`   // Interest on the study wallet, not part of money_received
+	StudyGrowthRate    float64          `json:"study_growth_rate,omitempty"` // Interest as % of the study wallet at the start of the week
+	JoySpent           float64          `json:"joy_spent"`
+	SpendingSpent      float64          `json:"spending_spent"`
+	CharitySpent       float64          `json:"charity_spent"`
+	StudySpent         float64          `json:"study_spent"`
+	MissionsCompleted  int              `json:"missions_completed"`
+	MissionsTotal      int              `json:"missions_total"`
+	ActivityScore      float64          `json:"activity_score"`
+
+	SpendingByCategory map[string]float64 `json:"spending_by_category,omitempty"` // From the categorization stage, when enabled
+	Currency           string             `json:"currency,omitempty"`             // ISO 4217 code of every amount above
+	Features           map[string]float64 `json:"features,omitempty"`             // Derived metrics with include_in_prompt (silver.features)
+
+	// Earlier weeks (gold.prompt_history): in full, or as changes against the previous week
+	PreviousWeek *HistoryWeek `json:"previous_week,omitempty"`
+	TwoWeeksAgo  *HistoryWeek `json:"two_weeks_ago,omitempty"`
+	Changes      *WeekChanges `json:"changes_vs_previous_week,omitempty"`
+
+	fullHistory [2]*HistoryWeek        // Previous and two-weeks-ago weeks in delta mode, to measure the savings
+	source      map[string]interface{} // The kid's Silver V3 entry, for templates using .Silver
+}
+
+// KidDataV3 is the kid data sent to the AI: KidDataV2 plus the kid's week-over-week history from
+// Silver, so reports can mention progress. A kid's first week has no history.
+type KidDataV3 struct {
+	KidDataV2
+	Trends     *PromptTrends     `json:"trends,omitempty"`     // Against the previous week
+	Statistics *PromptStatistics `json:"statistics,omitempty"` // Spending shares and multi-week averages
 }
 
 // AIReport represents the structured Vietnamese AI report for a kid
 type AIReport struct {
+	ProfileID           string               `json:"profile_id,omitempty"`
+	ParentID            string               `json:"parent_id,omitempty"`   // Parent profile, groups siblings for the parent digest
+	SourceName          string               `json:"source_name,omitempty"` // Kid's nickname from Silver (child_name is as the AI wrote it)
+	SourceWeek          string               `json:"source_week,omitempty"` // Week label the report was generated for (week is as the AI wrote it)
+	SourceHash          string               `json:"source_hash,omitempty"` // Fingerprint of the Silver entry the report was generated from
 	ChildName           string               `json:"child_name"`
 	Week                string               `json:"week"`
+	Language            string               `json:"language"`            // Language the report was written in
+	WeekType            string               `json:"week_type,omitempty"` // Calendar week type whose prompts were used (normal, holiday, exam, ...)
 	FinancialTendencies []FinancialTendency  `json:"financial_tendencies"`
 	PerformanceSections []PerformanceSection `json:"performance_sections"`
 	NextWeekGoals       []string             `json:"next_week_goals"`
 	ParentSuggestions   []string             `json:"parent_suggestions"`
+	Strengths           []string             `json:"strengths,omitempty"`         // Top two strengths of the week
+	TopRisk             string               `json:"top_risk,omitempty"`          // The money behavior most worth working on
+	Badge               *BadgeRecommendation `json:"badge,omitempty"`             // From gold.badges.catalog; dropped when not in it
+	OptionalSections    []OptionalSection    `json:"optional_sections,omitempty"` // Parent-requested sections that were generated
+	MissingSections     []string             `json:"missing_sections,omitempty"`  // Requested section keys the AI did not generate
+	DataQuality         []string             `json:"data_quality,omitempty"`      // Profile data issues carried from Silver
+	OperatorNote        string               `json:"operator_note,omitempty"`     // Human-written note appended verbatim (not generated)
+	TemplateHash        string               `json:"template_hash,omitempty"`     // Prompt template the report was generated with
 	GeneratedAt         string               `json:"generated_at"`
+	Consensus           *ConsensusInfo       `json:"consensus,omitempty"` // Set when generated by multiple models
+	Quality             *ReportQuality       `json:"quality,omitempty"`   // Numeric guard result (when enabled)
+	Identity            *KidIdentity         `json:"identity,omitempty"`  // Profile tracked across earlier weeks (gold.identity)
+	Downgrade           *ReportDowngrade     `json:"downgrade,omitempty"` // Generated on the cheaper tier to stay within gold.cost_budget
+}
+
+// ReportQuality records the numeric guard outcome for one report
+type ReportQuality struct {
+	UnsupportedNumbers       int     `json:"unsupported_numbers"` // Left in the final report
+	Reprompts                int     `json:"reprompts"`
+	SuggestionOverlapPercent float64 `json:"suggestion_overlap_percent,omitempty"` // Parent suggestions repeating previous weeks
 }
 
 // FinancialTendency represents a financial behavior tendency
@@ -68,54 +260,164 @@ type FinancialTendency struct {
 
 // PerformanceSection represents a performance evaluation section
 type PerformanceSection struct {
-	Title   string `json:"title"`
-	Level   string `json:"level"`
-	Score   int    `json:"score"`
-	Summary string `json:"summary"`
+	Key      string `json:"key,omitempty"` // Stable section key from the taxonomy (when enabled)
+	Title    string `json:"title"`
+	Level    string `json:"level"`
+	LevelKey string `json:"level_key,omitempty"` // Stable level key from the taxonomy (when enabled)
+	Score    int    `json:"score"`               // Calibrated score (equals the AI score when calibration is off)
+	RawScore int    `json:"raw_score,omitempty"` // Score as returned by the AI
+	Summary  string `json:"summary"`
 }
 
 func NewGoldLayer(cfg *config.Config, logger *logrus.Logger) (*GoldLayer, error) {
-	// Get OpenAI API key from environment
-	apiKey := os.Getenv("OPENAI_API_KEY")
+	// Get the AI provider's API key from environment
+	keyEnv := processor.APIKeyEnv(cfg.OpenAI.Provider)
+	apiKey := os.Getenv(keyEnv)
 	if apiKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is required")
+		return nil, fmt.Errorf("%s environment variable is required", keyEnv)
+	}
+
+	// Load prompt templates and system messages (default plus per-language)
+	prompts, defaultLanguage, skipped, err := loadPromptSets(cfg)
+	if err != nil {
+		return nil, err
+	}
+	promptTemplate := prompts[defaultLanguage].template
+	systemMessage := prompts[defaultLanguage].systemMessage
+	for _, lang := range skipped {
+		logger.Warnf("⚠️  %s prompt files not found, %s kids get %s reports", lang, lang, defaultLanguage)
+	}
+	weekTypes, err := loadWeekTypes(cfg, prompts, defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load optional campaign notes for this run
+	campaign, err := loadCampaignContext(cfg.Prompts.ExtraContextFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign context: %w", err)
+	}
+	if campaign != "" {
+		logger.WithField("extra_context_file", cfg.Prompts.ExtraContextFile).Info("📣 Loaded campaign context")
+	}
+
+	// Template blocks for parent-requested optional sections
+	optional, err := loadOptionalSections(cfg.Gold.OptionalSections, defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verbosity, reading level and tone instructions
+	style, err := newReportStyle(cfg.Gold.ReportStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	// Currency code, symbol and separators for amounts in the prompt
+	currency, err := newCurrencyFormat(cfg.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := parsePromptHistory(cfg.Gold.PromptHistory)
+	if err != nil {
+		return nil, err
 	}
 
-	// Load prompt template from file
-	promptTemplate, err := loadPromptTemplate(cfg.Prompts.TemplateFile)
+	// Templates for the per-parent digest across all their kids
+	digester, err := loadParentDigester(cfg.Gold.ParentDigest, defaultLanguage)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load prompt template: %w", err)
+		return nil, err
 	}
-	logger.WithField("template_file", cfg.Prompts.TemplateFile).Info("✅ Loaded prompt template")
 
-	// Load system message from file
-	systemMessage, err := loadSystemMessage(cfg.Prompts.SystemMessageFile)
+	// Templates for the household report comparing a parent's kids
+	families, err := loadFamilyReporter(cfg.Gold.FamilyReport, defaultLanguage)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load system message: %w", err)
+		return nil, err
+	}
+
+	// Short kid-facing version of each report, from the parent report or the cheap model
+	kidVersions, err := loadKidVersioner(cfg.Gold.KidVersion, defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Development response cache (off in production configs)
+	cacheDir, cacheTTL, err := cfg.OpenAI.ResponseCache.Settings()
+	if err != nil {
+		return nil, err
+	}
+	faults, err := FaultConfig(cfg.OpenAI.FaultInjection)
+	if err != nil {
+		return nil, err
+	}
+	autoTune, err := AutoTuneConfig(cfg.Batch)
+	if err != nil {
+		return nil, err
 	}
-	logger.WithField("system_message_file", cfg.Prompts.SystemMessageFile).Info("✅ Loaded system message")
 
 	// Configure AI Processor
 	aiConfig := processor.Config{
 		APIKey:             apiKey,
 		Model:              cfg.OpenAI.Model, // Use model from config
-		MaxTokens:          cfg.OpenAI.MaxTokens,
+		MaxTokens:          style.maxTokens(cfg.Gold.ReportStyle, cfg.OpenAI.MaxTokens),
 		Temperature:        cfg.OpenAI.Temperature,
 		MaxRetries:         cfg.Retry.MaxAttempts,
 		InitialRetryDelay:  time.Duration(cfg.Retry.InitialDelaySeconds) * time.Second,
 		MaxRetryDelay:      time.Duration(cfg.Retry.MaxDelaySeconds) * time.Second,
 		ExponentialBackoff: cfg.Retry.ExponentialBackoff,
 		Timeout:            time.Duration(cfg.OpenAI.TimeoutSeconds) * time.Second,
+		ItemBudget:         time.Duration(cfg.OpenAI.ItemBudgetSecs) * time.Second,
 		BatchSize:          cfg.Batch.Size,
 		MaxConcurrent:      cfg.Batch.MaxConcurrent,
+		AutoTune:           autoTune,
 		RateLimitPerMin:    cfg.RateLimit.RequestsPerMinute,
 		TrackTokenUsage:    cfg.Monitoring.TrackTokenUsage,
 		TrackTiming:        cfg.Monitoring.TrackTiming,
 		ShowProgress:       cfg.Monitoring.ShowProgress,
 		SystemMessage:      systemMessage, // Pass loaded system message
+		Provider:           cfg.OpenAI.Provider,
+		BaseURL:            cfg.OpenAI.BaseURL,
+		AuthStyle:          cfg.OpenAI.AuthStyle,
+		APIVersion:         cfg.OpenAI.APIVersion,
+		ExtraHeaders:       cfg.OpenAI.ExtraHeaders,
+		ProxyURL:           cfg.HTTP.Proxy,
+		CABundleFile:       cfg.HTTP.CABundleFile,
+		MaxRequestBytes:    cfg.HTTP.MaxRequestBytes,
+		MaxResponseBytes:   cfg.HTTP.MaxResponseBytes,
+		StoreResponses:     cfg.OpenAI.StoreResponses,
+		Stream:             cfg.OpenAI.Stream,
+		StreamIdleTimeout:  time.Duration(cfg.OpenAI.StreamIdleSecs) * time.Second,
+		CacheDir:           cacheDir,
+		CacheTTL:           cacheTTL,
+		Faults:             faults,
+	}
+
+	aiProcessor, err := processor.NewAIProcessor(aiConfig, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AI processor: %w", err)
 	}
 
-	aiProcessor := processor.NewAIProcessor(aiConfig, logger)
+	// Secondary model for consensus reports on flagged kids
+	var secondary *processor.AIProcessor
+	if consensusCfg := cfg.Gold.Consensus; consensusCfg.Enabled {
+		secondaryConfig := aiConfig
+		secondaryConfig.Model = consensusCfg.SecondaryModel
+		if consensusCfg.SecondaryBaseURL != "" {
+			secondaryConfig.BaseURL = consensusCfg.SecondaryBaseURL
+		}
+		if consensusCfg.SecondaryAPIKeyEnv != "" {
+			secondaryConfig.APIKey = os.Getenv(consensusCfg.SecondaryAPIKeyEnv)
+			if secondaryConfig.APIKey == "" {
+				return nil, fmt.Errorf("%s environment variable is required for consensus mode", consensusCfg.SecondaryAPIKeyEnv)
+			}
+		}
+		secondary, err = processor.NewAIProcessor(secondaryConfig, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create consensus AI processor: %w", err)
+		}
+		logger.WithField("secondary_model", secondaryConfig.Model).Info("🤝 Consensus mode enabled for flagged kids")
+	}
 
 	logger.Info("✅ Gold Layer V2 initialized successfully")
 	logger.WithFields(logrus.Fields{
@@ -124,15 +426,50 @@ func NewGoldLayer(cfg *config.Config, logger *logrus.Logger) (*GoldLayer, error)
 		"max_concurrent": aiConfig.MaxConcurrent,
 		"rate_limit":     aiConfig.RateLimitPerMin,
 		"max_retries":    aiConfig.MaxRetries,
+		"max_tokens":     aiConfig.MaxTokens,
+		"verbosity":      style.verbosity,
+		"reading_level":  style.readingLevel,
+		"tone":           style.tone,
 	}).Info("AI Processor V2 Configuration")
 
-	return &GoldLayer{
-		config:         cfg,
-		logger:         logger,
-		aiProcessor:    aiProcessor,
-		promptTemplate: promptTemplate,
-		systemMessage:  systemMessage,
-	}, nil
+	badges, err := newBadgeCatalog(cfg.Gold.Badges, defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cheaper tier for non-urgent reports once the run is projected over budget
+	budget, err := newCostBudget(cfg.Gold.CostBudget, cfg.OpenAI, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	gl := &GoldLayer{
+		config:          cfg,
+		logger:          logger,
+		aiProcessor:     aiProcessor,
+		promptTemplate:  promptTemplate,
+		systemMessage:   systemMessage,
+		prompts:         prompts,
+		weekTypes:       weekTypes,
+		defaultLanguage: defaultLanguage,
+		campaign:        campaign,
+		calibrator:      newScoreCalibrator(cfg.Gold.ScoreCalibration),
+		taxonomy:        newSectionTaxonomy(cfg.Gold.SectionTaxonomy, defaultLanguage),
+		badges:          badges,
+		consensus:       newConsensusPlanner(cfg.Gold.Consensus, secondary),
+		optional:        optional,
+		digester:        digester,
+		families:        families,
+		kidVersions:     kidVersions,
+		style:           style,
+		currency:        currency,
+		reuseExisting:   cfg.Gold.ReuseExisting,
+		outage:          newOutageBreaker(cfg.Gold.OutageQueue, logger),
+		budget:          budget,
+		history:         history,
+	}
+	gl.logPromptSources()
+	return gl, nil
 }
 
 // GenerateReports generates AI reports using enhanced prompts
@@ -165,7 +502,11 @@ func (gl *GoldLayer) GenerateReports(ctx context.Context) (int, int, error) {
 		if !ok {
 			return ""
 		}
-		return gl.createEnhancedPromptForKid(kid)
+		prompt, err := gl.createEnhancedPromptForKid(kid)
+		if err != nil {
+			gl.logger.Errorf("   ❌ %s: %v", kid.Nickname, err)
+		}
+		return prompt
 	}
 
 	// Process all kids with batching and controlled concurrency
@@ -216,7 +557,7 @@ func (gl *GoldLayer) GenerateReports(ctx context.Context) (int, int, error) {
 
 // readSilverData reads and parses the Silver layer output
 func (gl *GoldLayer) readSilverData(inputPath string) ([]KidDataV2, error) {
-	data, err := os.ReadFile(inputPath)
+	data, err := fileio.ReadFile(inputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", inputPath, err)
 	}
@@ -298,7 +639,7 @@ func (gl *GoldLayer) readSilverData(inputPath string) ([]KidDataV2, error) {
 
 		kid := KidDataV2{
 			Nickname:           getString(profileMap, "nickname"),
-			Age:                int(getFloat64(profileMap, "age")),
+			Age:                getAge(profileMap),
 			JoyWallet:          joyWallet,
 			SpendingWallet:     spendingWallet,
 			CharityWallet:      charityWallet,
@@ -320,18 +661,64 @@ func (gl *GoldLayer) readSilverData(inputPath string) ([]KidDataV2, error) {
 	return kids, nil
 }
 
-// createEnhancedPromptForKid creates detailed Vietnamese prompt for financial education app
-func (gl *GoldLayer) createEnhancedPromptForKid(kid KidDataV2) string {
+// createEnhancedPromptForKid renders the template for the kid's language and week type (text/template,
+// see promptData) into the kid's prompt
+func (gl *GoldLayer) createEnhancedPromptForKid(kid KidDataV2) (string, error) {
 	// Convert kid data to JSON for prompt
-	kidJSON, _ := json.MarshalIndent(kid, "", "  ")
+	promptKid := gl.kidDataV3(kid)
+	kidJSON, _ := json.MarshalIndent(promptKid, "", "  ")
+
+	language := gl.reportLanguage(kid)
+	data := &promptData{
+		Kid:              promptKid,
+		Language:         language,
+		WeekType:         kid.WeekType,
+		KidsData:         string(kidJSON) + dataQualityNotes(kid, language) + renameNote(kid, language) + trendNotes(promptKid, language),
+		ChildName:        childName(kid, language),
+		Week:             gl.config.Prompts.Week,
+		Campaign:         campaignBlock(gl.campaign, language),
+		SectionTaxonomy:  gl.taxonomy.promptBlock(language),
+		OptionalSections: gl.optional.promptBlock(kid, language),
+		ReportStyle:      gl.style.promptBlock(language),
+		Currency:         gl.currency.promptBlock(language),
+		BadgeCatalog:     gl.badges.promptBlock(language),
+	}
+	if gl.config.Gold.SuggestionDedup.Enabled {
+		data.PreviousSuggestions = previousSuggestionsBlock(gl.suggestions.previous(kid), language)
+	}
 
-	// Replace placeholders in template
-	prompt := gl.promptTemplate
-	prompt = strings.ReplaceAll(prompt, "{{KIDS_DATA}}", string(kidJSON))
-	prompt = strings.ReplaceAll(prompt, "{{CHILD_NAME}}", kid.Nickname)
-	prompt = strings.ReplaceAll(prompt, "{{WEEK}}", gl.config.Prompts.Week)
+	tmpl, err := gl.parsedPrompt(gl.promptSetFor(kid).template)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return prompt.String(), nil
+}
 
-	return prompt
+// campaignBlock formats campaign notes for the prompt ("" when there is no campaign)
+func campaignBlock(campaign, language string) string {
+	if campaign == "" {
+		return ""
+	}
+	if language == "en" {
+		return "Ongoing in-app campaign (may be mentioned naturally in goals or suggestions, optional):\n" + campaign + "\n"
+	}
+	return "Chiến dịch đang diễn ra trong ứng dụng (có thể nhắc đến một cách tự nhiên trong mục tiêu hoặc gợi ý, không bắt buộc):\n" + campaign + "\n"
+}
+
+// loadCampaignContext loads per-run campaign notes ("" when no file is configured)
+func loadCampaignContext(filePath string) (string, error) {
+	if filePath == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read extra context file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
 }
 
 // loadPromptTemplate loads prompt template from file
@@ -352,12 +739,13 @@ func loadSystemMessage(filePath string) (string, error) {
 	return string(data), nil
 }
 
-// GenerateReportsFromFile reads Silver V3 output and generates AI reports
+// GenerateReportsFromFile reads Silver V3 output and generates AI reports, running kids concurrently
+// in batches (batch.size, batch.max_concurrent)
 func (gl *GoldLayer) GenerateReportsFromFile(ctx context.Context, silverOutputPath, reportOutputPath, weekLabel string) (int, error) {
 	gl.logger.Infof("📖 Loading Silver V3 data from: %s", silverOutputPath)
 
-	// Read Silver V3 JSON output
-	data, err := os.ReadFile(silverOutputPath)
+	// Read Silver V3 JSON output (gzip/zstd detected transparently)
+	data, err := fileio.ReadFile(silverOutputPath)
 	if err != nil {
 		return 0, fmt.Errorf("failed to read silver output: %w", err)
 	}
@@ -373,59 +761,291 @@ func (gl *GoldLayer) GenerateReportsFromFile(ctx context.Context, silverOutputPa
 	}
 
 	gl.logger.Infof("✅ Loaded %d kids from Silver V3", len(kids))
+	gl.progress.SetWeekKids(len(kids))
 
-	// Generate reports for each kid
-	var reports []AIReport
-	successCount := 0
-
+	var kidMaps []map[string]interface{}
 	for i, kidData := range kids {
 		kidMap, ok := kidData.(map[string]interface{})
 		if !ok {
 			gl.logger.Warnf("Skipping invalid kid data at index %d", i)
 			continue
 		}
+		kidMaps = append(kidMaps, kidMap)
+	}
 
-		nickname := getString(kidMap, "nickname")
-		gl.logger.Infof("   Processing: %s (%d/%d)", nickname, i+1, len(kids))
+	// Reused reports are taken up front; the rest are generated in batches. Each kid has a slot so
+	// the output keeps the Silver order however the batch finishes.
+	existing := gl.loadReusableReports(reportOutputPath, weekLabel)
+	generated := make([]*AIReport, len(kidMaps))
+	deferredKids := make([]*DeferredKid, len(kidMaps))
+	reused := make([]bool, len(kidMaps))
+	var pending []interface{}
+	for i, kidMap := range kidMaps {
+		if report, ok := gl.reuseReport(existing, kidMap, weekLabel); ok {
+			generated[i] = &report
+			reused[i] = true
+			continue
+		}
+		pending = append(pending, i)
+	}
 
-		// Convert to KidDataV2 format for existing prompt system
-		kid := gl.convertEnhancedToV2(kidMap, weekLabel)
+	if len(pending) > 0 {
+		gl.aiProcessor.ProcessBatchFunc(ctx, pending, func(ctx context.Context, _ int, item interface{}) error {
+			i := item.(int)
+			var err error
+			generated[i], deferredKids[i], err = gl.processKid(ctx, kidMaps[i], weekLabel, i+1)
+			return err
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("week interrupted, checkpointed reports are kept for --resume: %w", err)
+	}
 
-		// Generate AI report with week label for token tracking
-		report, err := gl.generateReportForKid(ctx, kid, weekLabel)
-		if err != nil {
-			gl.logger.Errorf("   ❌ Failed to generate report for %s: %v", nickname, err)
-			continue
+	var reports []AIReport
+	var deferred []DeferredKid
+	reusedCount := 0
+	for i := range kidMaps {
+		switch {
+		case deferredKids[i] != nil:
+			deferred = append(deferred, *deferredKids[i])
+		case generated[i] != nil:
+			reports = append(reports, *generated[i])
+			if reused[i] {
+				reusedCount++
+			}
 		}
+	}
+	return gl.saveWeekReports(reports, deferred, reusedCount, len(kidMaps), reportOutputPath, weekLabel)
+}
 
-		reports = append(reports, *report)
-		successCount++
-		gl.logger.Infof("   ✅ Completed: %s", nickname)
+// GenerateReportsFromStream generates AI reports for Silver V3 kid entries as they arrive on kids,
+// concurrently within the processor's concurrency limit. It reads until kids is closed (deferring the
+// rest after a soft stop) and then saves the week's reports in the order Silver sent the kids.
+func (gl *GoldLayer) GenerateReportsFromStream(ctx context.Context, kids <-chan map[string]interface{}, reportOutputPath, weekLabel string) (int, error) {
+	existing := gl.loadReusableReports(reportOutputPath, weekLabel)
+
+	// Each kid gets a slot as it arrives; reused reports are taken here, the rest are generated
+	var mu sync.Mutex
+	var generated []*AIReport
+	var deferredKids []*DeferredKid
+	var reused []bool
+	next := func() (interface{}, bool) {
+		for kidMap := range kids {
+			mu.Lock()
+			i := len(generated)
+			generated = append(generated, nil)
+			deferredKids = append(deferredKids, nil)
+			reused = append(reused, false)
+			if report, ok := gl.reuseReport(existing, kidMap, weekLabel); ok {
+				generated[i] = &report
+				reused[i] = true
+				mu.Unlock()
+				continue
+			}
+			mu.Unlock()
+			return streamedKid{index: i, kid: kidMap}, true
+		}
+		return nil, false
 	}
 
-	// Save reports to specified output path
-	if err := gl.saveReportsToPath(reports, reportOutputPath, weekLabel); err != nil {
+	gl.aiProcessor.ProcessStream(ctx, next, func(ctx context.Context, _ int, item interface{}) error {
+		streamed := item.(streamedKid)
+		report, deferredKid, err := gl.processKid(ctx, streamed.kid, weekLabel, streamed.index+1)
+		mu.Lock()
+		defer mu.Unlock()
+		generated[streamed.index], deferredKids[streamed.index] = report, deferredKid
+		return err
+	})
+	for range kids {
+		// Drain until Silver notices the cancellation and closes kids
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("week interrupted, checkpointed reports are kept for --resume: %w", err)
+	}
+
+	var reports []AIReport
+	var deferred []DeferredKid
+	reusedCount := 0
+	for i := range generated {
+		switch {
+		case deferredKids[i] != nil:
+			deferred = append(deferred, *deferredKids[i])
+		case generated[i] != nil:
+			reports = append(reports, *generated[i])
+			if reused[i] {
+				reusedCount++
+			}
+		}
+	}
+	return gl.saveWeekReports(reports, deferred, reusedCount, len(generated), reportOutputPath, weekLabel)
+}
+
+// streamedKid is a kid from the Silver stream with its position in the week
+type streamedKid struct {
+	index int
+	kid   map[string]interface{}
+}
+
+// loadReusableReports returns the reports a previous run of this week already wrote or checkpointed;
+// they are kept as they are
+func (gl *GoldLayer) loadReusableReports(reportOutputPath, weekLabel string) *existingReports {
+	existing, err := gl.loadExistingReports(reportOutputPath)
+	if err != nil {
+		gl.logger.Warnf("⚠️  Could not load existing reports, regenerating all kids: %v", err)
+	}
+	if existing, err = gl.addCheckpointedReports(existing, weekLabel); err != nil {
+		gl.logger.Warnf("⚠️  Could not load checkpointed reports: %v", err)
+	}
+	return existing
+}
+
+// reuseReport takes a kid's report from an earlier run, if there is one and the kid's Silver metrics
+// are still the ones it was generated from (late transactions change them)
+func (gl *GoldLayer) reuseReport(existing *existingReports, kidMap map[string]interface{}, weekLabel string) (AIReport, bool) {
+	report, ok := existing.take(getString(kidMap, "profile_id"))
+	if !ok {
+		return AIReport{}, false
+	}
+	if report.SourceHash != sourceHash(kidMap) {
+		gl.logger.Infof("   🔄 %s: Silver metrics changed since the stored report, regenerating", getString(kidMap, "nickname"))
+		return AIReport{}, false
+	}
+	report.OperatorNote = getString(kidMap, "operator_note") // Notes can be added after the report
+	gl.progress.KidDone(true)
+	gl.progress.RecordKid(weekLabel, report.ProfileID, getString(kidMap, "nickname"), progress.DispositionReused, "")
+	return report, true
+}
+
+// processKid generates one kid's report and records its disposition. It returns the deferred kid
+// (and ErrSoftStopped) instead once the run deadline has been reached, or the kid with its prompt
+// queued (and ErrProviderUnavailable) while the AI provider is down. A cancelled ctx returns its
+// error without recording the kid. Safe for concurrent use.
+func (gl *GoldLayer) processKid(ctx context.Context, kidMap map[string]interface{}, weekLabel string, position int) (*AIReport, *DeferredKid, error) {
+	nickname := getString(kidMap, "nickname")
+
+	// Deadline reached: defer this and all remaining kids
+	if gl.softStopped() {
+		gl.progress.RecordKid(weekLabel, getString(kidMap, "profile_id"), nickname,
+			progress.DispositionDeferred, "run deadline reached")
+		return nil, &DeferredKid{ProfileID: getString(kidMap, "profile_id"), Nickname: nickname}, ErrSoftStopped
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Convert to KidDataV2 format for existing prompt system
+	kid := gl.convertEnhancedToV2(kidMap, weekLabel)
+
+	// Provider down: queue the prompt without calling
+	if gl.outage.isDown() {
+		return nil, gl.queueKid(kidMap, kid, weekLabel, nil), ErrProviderUnavailable
+	}
+
+	gl.logger.Infof("   Processing: %s (#%d)", nickname, position)
+
+	// Generate AI report with week label for token tracking
+	report, err := gl.generateReportForKid(ctx, kid, weekLabel, nil)
+	if err != nil && ctx.Err() != nil {
+		return nil, nil, ctx.Err() // Interrupted mid-call, not a failure of this kid
+	}
+	if gl.outage.record(err) && err != nil {
+		return nil, gl.queueKid(kidMap, kid, weekLabel, err), ErrProviderUnavailable
+	}
+	gl.progress.KidDone(err == nil)
+	gl.progress.SetCost(gl.estimatedCost())
+	if err != nil {
+		gl.logger.Errorf("   ❌ Failed to generate report for %s: %v", nickname, err)
+		gl.progress.RecordKid(weekLabel, kid.ProfileID, nickname, progress.DispositionGoldFailed, err.Error())
+		telemetry.LayerKids.Inc(telemetry.LayerGold, telemetry.ResultFailure)
+		return nil, nil, err
+	}
+	telemetry.LayerKids.Inc(telemetry.LayerGold, telemetry.ResultSuccess)
+	if reason := gl.languageFallback(kid); reason != "" {
+		gl.progress.RecordKid(weekLabel, kid.ProfileID, nickname, progress.DispositionTemplateFallback, reason)
+	} else {
+		gl.progress.RecordKid(weekLabel, kid.ProfileID, nickname, progress.DispositionReported, "")
+	}
+
+	if err := gl.checkpoint.AppendKid(weekLabel, report.ProfileID, report); err != nil {
+		gl.logger.Warnf("⚠️  %v", err)
+	}
+
+	gl.logger.Infof("   ✅ Completed: %s", nickname)
+	return report, nil, nil
+}
+
+// saveWeekReports saves a week's reports (completed work is always flushed) and logs the outcome
+func (gl *GoldLayer) saveWeekReports(reports []AIReport, deferred []DeferredKid, reused, received int, reportOutputPath, weekLabel string) (int, error) {
+	successCount := len(reports)
+	if reused > 0 {
+		gl.logger.Infof("♻️  Reused %d existing reports, generated %d missing", reused, successCount-reused)
+	}
+
+	if err := gl.saveReportsToPath(reports, reportOutputPath, weekLabel, deferred); err != nil {
 		return successCount, fmt.Errorf("failed to save reports: %w", err)
 	}
 
-	gl.logger.Infof("✅ Generated %d/%d reports successfully", successCount, len(kids))
+	queued, err := gl.writeQueue(reportOutputPath, deferred)
+	if err != nil {
+		return successCount, err
+	}
+	if queued > 0 {
+		gl.logger.Warnf("⏸️  AI provider unavailable: %d/%d reports generated, %d prompts queued for flush-deferred", successCount, received, queued)
+		return successCount, ErrProviderUnavailable
+	}
+
+	if len(deferred) > 0 {
+		gl.logger.Warnf("⏰ Run deadline reached: %d/%d reports generated, %d kids deferred", successCount, received, len(deferred))
+		return successCount, ErrSoftStopped
+	}
+
+	gl.logger.Infof("✅ Generated %d/%d reports successfully", successCount, received)
+	if downgraded := countDowngraded(reports); downgraded > 0 {
+		gl.logger.Infof("💸 %d reports generated on the cheaper tier (gold.cost_budget)", downgraded)
+	}
+	if gl.config.Gold.NumericGuard.Enabled {
+		quality := gl.quality.snapshot()
+		gl.logger.WithFields(logrus.Fields{
+			"reports_checked":  quality.ReportsChecked,
+			"with_mismatches":  quality.ReportsWithMismatches,
+			"reprompts":        quality.Reprompts,
+			"unresolved":       quality.UnresolvedReports,
+			"invented_numbers": quality.InventedNumbers,
+		}).Info("🔢 Numeric consistency (run totals)")
+	}
 	return successCount, nil
 }
 
+// GenerateReport generates a single report from one Silver V3 kid entry
+func (gl *GoldLayer) GenerateReport(ctx context.Context, kidMap map[string]interface{}, weekLabel string) (*AIReport, error) {
+	kid := gl.convertEnhancedToV2(kidMap, weekLabel)
+	return gl.generateReportForKid(ctx, kid, weekLabel, nil)
+}
+
 // convertEnhancedToV2 converts Silver V3 enhanced data to V2 format
 func (gl *GoldLayer) convertEnhancedToV2(kidMap map[string]interface{}, weekLabel string) KidDataV2 {
 	// Get current week data
 	currentWeek, _ := kidMap["current_week"].(map[string]interface{})
 
-	return KidDataV2{
+	kid := KidDataV2{
+		ProfileID:          getString(kidMap, "profile_id"),
+		ParentID:           getString(kidMap, "parent_id"),
+		Language:           getString(kidMap, "language"),
+		DataQuality:        getStrings(kidMap, "data_quality"),
+		RequestedSections:  getSectionRequests(kidMap),
+		OperatorNote:       getString(kidMap, "operator_note"),
+		WeekType:           gl.weekTypes.typeOf(getString(currentWeek, "start_date"), getString(currentWeek, "end_date")),
 		Nickname:           getString(kidMap, "nickname"),
-		Age:                int(getFloat64(kidMap, "age")),
+		Age:                getAge(kidMap),
 		JoyWallet:          getFloat64(currentWeek, "joy_wallet"),
 		SpendingWallet:     getFloat64(currentWeek, "spending_wallet"),
 		CharityWallet:      getFloat64(currentWeek, "charity_wallet"),
 		StudyWallet:        getFloat64(currentWeek, "study_wallet"),
 		MoneyReceived:      getFloat64(currentWeek, "money_received"),
 		MoneyReceivedCount: int(getFloat64(currentWeek, "money_received_count")),
+		InterestEarned:     getFloat64(currentWeek, "interest_earned"),
+		StudyGrowthRate:    getFloat64(currentWeek, "study_growth_rate"),
 		JoySpent:           getFloat64(currentWeek, "joy_spent"),
 		SpendingSpent:      getFloat64(currentWeek, "spending_spent"),
 		CharitySpent:       getFloat64(currentWeek, "charity_spent"),
@@ -433,52 +1053,268 @@ func (gl *GoldLayer) convertEnhancedToV2(kidMap map[string]interface{}, weekLabe
 		MissionsCompleted:  int(getFloat64(currentWeek, "missions_completed")),
 		MissionsTotal:      int(getFloat64(currentWeek, "missions_total")),
 		ActivityScore:      getFloat64(kidMap, "activity_score"),
+		SpendingByCategory: getFloatMap(currentWeek, "spending_by_category"),
+		Features:           promptFeatures(gl.config.Silver.Features, getFloatMap(currentWeek, "features"), gl.currency),
 	}
+	kid.source = kidMap
+	gl.currency.roundAmounts(&kid)
+	gl.addHistory(&kid, kidMap)
+	gl.addIdentity(&kid)
+	return kid
 }
 
-// generateReportForKid generates report for a single kid
-func (gl *GoldLayer) generateReportForKid(ctx context.Context, kid KidDataV2, weekLabel string) (*AIReport, error) {
-	// Create prompt
-	prompt := gl.createEnhancedPromptForKid(kid)
+// generateReportForKid generates report for a single kid. queued replays a prompt rendered by an earlier
+// run (nil renders it now).
+func (gl *GoldLayer) generateReportForKid(ctx context.Context, kid KidDataV2, weekLabel string, queued *renderedPrompt) (*AIReport, error) {
+	var report *AIReport
+	if reason := gl.consensus.reason(kid); reason != "" {
+		var err error
+		report, err = gl.generateConsensusReport(ctx, kid, weekLabel, reason, queued)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		downgrade := gl.budget.downgradeFor(kid)
+		if downgrade != nil {
+			ctx = processor.WithTier(ctx, gl.budget.tier)
+		}
+		var err error
+		var result generation
+		report, result, err = gl.generateWithProcessor(ctx, gl.aiProcessor, kid, weekLabel, queued)
+		if err != nil {
+			return nil, err
+		}
+		report.Downgrade = downgrade
+		if downgrade == nil {
+			gl.budget.record(result.usage, gl.runUsage(), gl.progress.Snapshot())
+		}
+	}
+
+	gl.taxonomy.NormalizeTitles(report, gl.logger)
+	gl.calibrator.Apply(report, kid)
+	gl.taxonomy.AssignLevels(report)
+	normalizeHighlights(report)
+	gl.badges.apply(report, gl.logger)
+
+	report.MissingSections = gl.optional.reconcile(report, kid)
+	if len(report.MissingSections) > 0 {
+		gl.logger.Warnf("   ⚠️  %s: requested sections not generated: %v", kid.Nickname, report.MissingSections)
+	}
+
+	report.ProfileID = kid.ProfileID
+	report.ParentID = kid.ParentID
+	report.SourceName = kid.Nickname
+	report.SourceWeek = weekLabel
+	report.SourceHash = sourceHash(kid.source)
+	report.DataQuality = kid.DataQuality
+	report.OperatorNote = kid.OperatorNote
+	report.WeekType = kid.WeekType
+	report.Identity = kid.Identity
+	if kid.Identity.Renamed() {
+		gl.logger.Infof("   🪪 %s was reported as %s in earlier weeks (same profile ID)", kid.Nickname, strings.Join(kid.Identity.PreviousNames, ", "))
+	}
+	report.GeneratedAt = time.Now().Format(time.RFC3339)
+	if gl.metadata != nil {
+		report.TemplateHash = gl.metadata.TemplateHash
+	}
+	return report, nil
+}
+
+// renderedPrompt is a kid's prompt and system message as sent to the model
+type renderedPrompt struct {
+	prompt        string
+	systemMessage string
+}
 
-	// Call AI with week tracking
-	response, err := gl.aiProcessor.ProcessSingleWithWeek(ctx, prompt, gl.systemMessage, weekLabel)
+// renderPrompt renders a kid's prompt with the system message of the report language and week type
+func (gl *GoldLayer) renderPrompt(kid KidDataV2) (renderedPrompt, error) {
+	prompt, err := gl.createEnhancedPromptForKid(kid)
 	if err != nil {
-		return nil, err
+		return renderedPrompt{}, err
+	}
+	return renderedPrompt{prompt: prompt, systemMessage: gl.promptSetFor(kid).systemMessage}, nil
+}
+
+// generateWithProcessor generates a report with one model, re-prompting on invented numbers.
+// It returns what the report took: the unsupported numbers left in it and the cost of its own calls.
+func (gl *GoldLayer) generateWithProcessor(ctx context.Context, proc *processor.AIProcessor, kid KidDataV2, weekLabel string, queued *renderedPrompt) (*AIReport, generation, error) {
+	// Create prompt (or replay the queued one)
+	var base renderedPrompt
+	if queued != nil {
+		base = *queued
+	} else {
+		var err error
+		if base, err = gl.renderPrompt(kid); err != nil {
+			return nil, generation{}, err
+		}
+	}
+	prompt, systemMessage := base.prompt, base.systemMessage
+	language := gl.reportLanguage(kid)
+
+	guardCfg := gl.config.Gold.NumericGuard
+	var guard *numericGuard
+	if guardCfg.Enabled {
+		guard = newNumericGuard(gl.kidDataV3(kid), guardCfg.TolerancePercent, gl.campaign)
+	}
+
+	dedupCfg := gl.config.Gold.SuggestionDedup
+	var previous []string
+	if dedupCfg.Enabled {
+		previous = gl.suggestions.previous(kid)
 	}
 
-	// Parse response
 	var report AIReport
-	if err := json.Unmarshal([]byte(response), &report); err != nil {
-		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	var result generation
+	var unsupported []string
+	var overlap float64
+	reprompts := 0
+	for attempt := 0; ; attempt++ {
+		// Call AI with week tracking
+		response, usage, err := proc.ProcessSingleWithUsage(ctx, prompt, systemMessage, weekLabel)
+		result.usage.PromptTokens += usage.PromptTokens
+		result.usage.CompletionTokens += usage.CompletionTokens
+		result.usage.TotalTokens += usage.TotalTokens
+		result.usage.EstimatedCost += usage.EstimatedCost
+		if err != nil {
+			return nil, result, err
+		}
+		if attempt == 0 && queued == nil {
+			gl.recordHistorySavings(proc, kid, prompt)
+		}
+
+		// Parse response
+		report = AIReport{}
+		if err := json.Unmarshal([]byte(response), &report); err != nil {
+			return nil, result, fmt.Errorf("failed to parse AI response: %w", err)
+		}
+
+		var instructions []string
+
+		// Numeric consistency: every figure must come from the kid's metrics
+		if guard != nil {
+			unsupported = guard.unsupportedNumbers(&report)
+			gl.quality.record(func(s *QualityStats) {
+				if attempt == 0 {
+					s.ReportsChecked++
+					if len(unsupported) > 0 {
+						s.ReportsWithMismatches++
+					}
+				}
+				s.InventedNumbers += len(unsupported)
+			})
+			if len(unsupported) > 0 && attempt < guardCfg.MaxReprompts {
+				gl.quality.record(func(s *QualityStats) { s.Reprompts++ })
+				gl.logger.Warnf("   🔁 %s: AI used numbers not in the data %v, re-prompting", kid.Nickname, unsupported)
+				instructions = append(instructions, numericRepromptInstruction(unsupported, language))
+			}
+		}
+
+		// Parent suggestions must not repeat the previous weeks
+		if len(previous) > 0 {
+			overlap = suggestionOverlap(report.ParentSuggestions, previous, dedupCfg.SimilarityThreshold)
+			if overlap*100 > dedupCfg.MaxOverlapPercent && attempt < dedupCfg.MaxReprompts {
+				gl.logger.Warnf("   🔁 %s: %.0f%% of parent suggestions repeat previous weeks, re-prompting", kid.Nickname, overlap*100)
+				instructions = append(instructions, repeatedSuggestionsInstruction(overlap, language))
+			}
+		}
+
+		if len(instructions) == 0 {
+			break
+		}
+		reprompts++
+		prompt = base.prompt + strings.Join(instructions, "")
 	}
 
-	report.GeneratedAt = time.Now().Format(time.RFC3339)
-	return &report, nil
+	if len(unsupported) > 0 {
+		gl.quality.record(func(s *QualityStats) { s.UnresolvedReports++ })
+		gl.logger.Warnf("   ⚠️  %s: report still contains unsupported numbers %v", kid.Nickname, unsupported)
+	}
+
+	report.Language = language
+	if guard != nil || len(previous) > 0 {
+		report.Quality = &ReportQuality{UnsupportedNumbers: len(unsupported), Reprompts: reprompts}
+		if len(previous) > 0 {
+			report.Quality.SuggestionOverlapPercent = math.Round(overlap * 100)
+		}
+	}
+	result.unsupported = len(unsupported)
+	return &report, result, nil
+}
+
+// generation is what generating one report took
+type generation struct {
+	unsupported int                  // Numbers in the final report that are not in the kid's data
+	usage       processor.TokenUsage // This report's own calls, re-prompts included
+}
+
+// GetQualityStats returns numeric-consistency quality metrics for reports generated so far
+func (gl *GoldLayer) GetQualityStats() QualityStats {
+	return gl.quality.snapshot()
 }
 
 // saveReportsToPath saves reports to a specific file path
-func (gl *GoldLayer) saveReportsToPath(reports []AIReport, outputPath, weekLabel string) error {
+func (gl *GoldLayer) saveReportsToPath(reports []AIReport, outputPath, weekLabel string, deferred []DeferredKid) error {
 	output := map[string]interface{}{
 		"generated_at":  time.Now().Format(time.RFC3339),
 		"week":          weekLabel,
 		"total_reports": len(reports),
 		"reports":       reports,
 	}
+	if gl.metadata != nil {
+		output["metadata"] = gl.metadata
+	}
+	if gl.campaign != "" {
+		output["campaign_context"] = gl.campaign
+	}
+	output["token_usage"] = gl.weekTokenUsage(weekLabel)
+	if len(deferred) > 0 {
+		output["status"] = "partial"
+		output["deferred_kids"] = deferred
+	}
 
 	data, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal reports: %w", err)
 	}
 
-	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+	if err := gl.storeReports(reports, weekLabel); err != nil {
+		return err
+	}
+
+	if gl.unit != nil {
+		stagedPath, err := gl.unit.StageFile(outputPath, data, gl.config.Data.CompressionCodec())
+		if err != nil {
+			return fmt.Errorf("failed to stage file %s: %w", outputPath, err)
+		}
+		gl.logger.Infof("📝 Reports staged for: %s", stagedPath)
+		return nil
+	}
+
+	writtenPath, err := fileio.WriteFile(outputPath, data, gl.config.Data.CompressionCodec())
+	if err != nil {
 		return fmt.Errorf("failed to write file %s: %w", outputPath, err)
 	}
 
-	gl.logger.Infof("✅ Reports saved to: %s", outputPath)
+	gl.logger.Infof("✅ Reports saved to: %s", writtenPath)
 	return nil
 }
 
+// storeReports replaces the week's rows in the output store, if one is set
+func (gl *GoldLayer) storeReports(reports []AIReport, weekLabel string) error {
+	if gl.outputs == nil {
+		return nil
+	}
+	var exec outputstore.Execer = gl.outputs.DB()
+	if gl.unit != nil && gl.unit.Tx() != nil {
+		exec = gl.unit.Tx()
+	}
+	rows := make([]outputstore.Row, 0, len(reports))
+	for _, report := range reports {
+		rows = append(rows, outputstore.Row{ProfileID: report.ProfileID, Payload: report})
+	}
+	return gl.outputs.SaveGold(exec, weekLabel, rows)
+}
+
 // saveReports saves the generated reports to a JSON file
 func (gl *GoldLayer) saveReports(reports []AIReport) error {
 	timestamp := time.Now().Format("20060102_150405")
@@ -518,3 +1354,59 @@ func getFloat64(m map[string]interface{}, key string) float64 {
 	}
 	return 0
 }
+
+func getStrings(m map[string]interface{}, key string) []string {
+	values, _ := m[key].([]interface{})
+	var out []string
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func getFloatMap(m map[string]interface{}, key string) map[string]float64 {
+	values, _ := m[key].(map[string]interface{})
+	var out map[string]float64
+	for k, v := range values {
+		if f, ok := v.(float64); ok {
+			if out == nil {
+				out = make(map[string]float64)
+			}
+			out[k] = f
+		}
+	}
+	return out
+}
+
+// promptFeatures keeps the derived metrics whose definition has include_in_prompt, with amounts
+// rounded like the other money figures
+func promptFeatures(defs []config.FeatureConfig, features map[string]float64, currency *currencyFormat) map[string]float64 {
+	var out map[string]float64
+	for _, def := range defs {
+		field := valueOr(def.OutputField, def.Name)
+		value, ok := features[field]
+		if !def.IncludeInPrompt || !ok {
+			continue
+		}
+		if def.Amount && currency != nil {
+			value = currency.round(value)
+		}
+		if out == nil {
+			out = make(map[string]float64)
+		}
+		out[field] = value
+	}
+	return out
+}
+
+// getAge returns the kid's age, or nil when unknown (null, or 0 in outputs written before ages were nullable)
+func getAge(m map[string]interface{}) *int {
+	age, ok := m["age"].(float64)
+	if !ok || age <= 0 {
+		return nil
+	}
+	a := int(age)
+	return &a
+}
@@ -0,0 +1,88 @@
+package gold
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// benchGoldLayer returns a Gold layer with the real templates and no AI processor
+func benchGoldLayer(b *testing.B) *GoldLayer {
+	template, err := os.ReadFile("../../prompts/vietnamese_financial_report.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Prompts.Week = "Tuần 3 - Tháng 10/2025"
+	return &GoldLayer{config: cfg, promptTemplate: string(template)}
+}
+
+func benchKidData() KidDataV2 {
+	return KidDataV2{
+		ProfileID: "00000000-0000-0000-0000-000000000001", Nickname: "Bé An", Age: intPtr(9),
+		JoyWallet: 26754.74, SpendingWallet: 120000, CharityWallet: 15000, StudyWallet: 43000,
+		MoneyReceived: 50000, MoneyReceivedCount: 3, JoySpent: 15615.07, SpendingSpent: 10000, CharitySpent: 5615.43,
+		MissionsCompleted: 4, MissionsTotal: 6, ActivityScore: 72.5,
+	}
+}
+
+func BenchmarkCreateEnhancedPrompt(b *testing.B) {
+	gl := benchGoldLayer(b)
+	kid := benchKidData()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gl.createEnhancedPromptForKid(kid)
+	}
+}
+
+func BenchmarkNumericGuard(b *testing.B) {
+	kid := benchKidData()
+	report := benchReport(0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		newNumericGuard(KidDataV3{KidDataV2: kid}, 1, "").unsupportedNumbers(&report)
+	}
+}
+
+func BenchmarkMarshalReports(b *testing.B) {
+	for _, count := range []int{100, 1000} {
+		count := count
+		reports := make([]AIReport, count)
+		for i := range reports {
+			reports[i] = benchReport(i)
+		}
+		b.Run(fmt.Sprintf("reports=%d", count), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				data, err := json.MarshalIndent(map[string]interface{}{"reports": reports}, "", "  ")
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.SetBytes(int64(len(data)))
+			}
+		})
+	}
+}
+
+func benchReport(i int) AIReport {
+	return AIReport{
+		ChildName: fmt.Sprintf("Bé %d", i),
+		Week:      "Tuần 3 - Tháng 10/2025",
+		FinancialTendencies: []FinancialTendency{{
+			Type:        "Nhà đầu tư tương lai",
+			Description: "Bé đã chi tiêu từ ví tiêu vặt là 15.615,07 đồng trong tổng số 50.000 đồng nhận được.",
+			Suggestion:  "Tiếp tục duy trì thói quen tiết kiệm 120.000 đồng.",
+		}},
+		PerformanceSections: []PerformanceSection{
+			{Title: "Tự quản lý tài chính", Level: "Tiến bộ ổn định", Score: 3, Summary: "Bé nhận tiền 3 lần và phân bổ đều vào các ví."},
+			{Title: "Mức độ tiến bộ", Level: "Sắp thành thạo", Score: 4, Summary: "Hoàn thành 4/6 nhiệm vụ, đạt 67%."},
+		},
+		NextWeekGoals:     []string{"Hoàn thành thêm 2 nhiệm vụ", "Tiết kiệm thêm 10.000 đồng"},
+		ParentSuggestions: []string{"Cùng con lập kế hoạch chi tiêu", "Khen ngợi khi con đóng góp từ thiện"},
+	}
+}
+
+func intPtr(v int) *int { return &v }
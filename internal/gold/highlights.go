@@ -0,0 +1,120 @@
+package gold
+
+import (
+	"fmt"
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxStrengths is how many strengths a report keeps (the prompt asks for the top two)
+const maxStrengths = 2
+
+// BadgeRecommendation is the gamification badge the AI picked for the kid, from gold.badges.catalog
+type BadgeRecommendation struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`             // Localized catalog name (the AI's wording is not kept)
+	Reason string `json:"reason,omitempty"` // Why the kid earned it, in the report language
+}
+
+// badgeCatalog checks badge recommendations against the configured catalog
+type badgeCatalog struct {
+	badges          []config.Badge
+	byName          map[string]config.Badge // Key or name (any language), normalized → badge
+	defaultLanguage string
+}
+
+// newBadgeCatalog returns nil when badges are disabled or the catalog is empty
+func newBadgeCatalog(cfg config.BadgesConfig, defaultLanguage string) (*badgeCatalog, error) {
+	if !cfg.Enabled || len(cfg.Catalog) == 0 {
+		return nil, nil
+	}
+	c := &badgeCatalog{badges: cfg.Catalog, byName: make(map[string]config.Badge), defaultLanguage: defaultLanguage}
+	keys := make(map[string]bool)
+	for _, badge := range cfg.Catalog {
+		if badge.Key == "" {
+			return nil, fmt.Errorf("gold.badges.catalog: every badge needs a key")
+		}
+		if keys[badge.Key] {
+			return nil, fmt.Errorf("gold.badges.catalog: duplicate key %q", badge.Key)
+		}
+		keys[badge.Key] = true
+		c.byName[normalizeTitle(badge.Key)] = badge
+		for _, name := range badge.Names {
+			c.byName[normalizeTitle(name)] = badge
+		}
+	}
+	return c, nil
+}
+
+// promptBlock lists the badges the AI may pick from ("" when there is no catalog)
+func (c *badgeCatalog) promptBlock(language string) string {
+	if c == nil {
+		if language == "en" {
+			return `Leave "badge" out: there is no badge catalog.` + "\n"
+		}
+		return `Bỏ trường "badge": chưa có danh mục huy hiệu.` + "\n"
+	}
+	var b strings.Builder
+	if language == "en" {
+		b.WriteString(`For "badge", pick exactly one key from this catalog (never invent one):` + "\n")
+	} else {
+		b.WriteString(`Với "badge", chọn đúng một key trong danh mục huy hiệu sau (không tự tạo huy hiệu mới):` + "\n")
+	}
+	for _, badge := range c.badges {
+		fmt.Fprintf(&b, "- %s: %s", badge.Key, c.localized(badge.Names, language, badge.Key))
+		if description := c.localized(badge.Descriptions, language, ""); description != "" {
+			fmt.Fprintf(&b, " (%s)", description)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// localized returns the value for language, falling back to the default language, then fallback
+func (c *badgeCatalog) localized(values map[string]string, language, fallback string) string {
+	if v := values[language]; v != "" {
+		return v
+	}
+	if v := values[c.defaultLanguage]; v != "" {
+		return v
+	}
+	return fallback
+}
+
+// apply keeps the badge only when it is in the catalog, with the catalog's key and localized name
+func (c *badgeCatalog) apply(report *AIReport, logger *logrus.Logger) {
+	if report.Badge == nil {
+		return
+	}
+	if c == nil {
+		report.Badge = nil
+		return
+	}
+	badge, ok := c.byName[normalizeTitle(report.Badge.Key)]
+	if !ok {
+		badge, ok = c.byName[normalizeTitle(report.Badge.Name)]
+	}
+	if !ok {
+		logger.Warnf("   ⚠️  %s: dropped badge %q, it is not in gold.badges.catalog", report.ChildName, report.Badge.Key)
+		report.Badge = nil
+		return
+	}
+	report.Badge.Key = badge.Key
+	report.Badge.Name = c.localized(badge.Names, report.Language, badge.Key)
+	report.Badge.Reason = strings.TrimSpace(report.Badge.Reason)
+}
+
+// normalizeHighlights trims the strengths to the top two and drops blank entries
+func normalizeHighlights(report *AIReport) {
+	strengths := report.Strengths[:0]
+	for _, strength := range report.Strengths {
+		if strength = strings.TrimSpace(strength); strength != "" && len(strengths) < maxStrengths {
+			strengths = append(strengths, strength)
+		}
+	}
+	report.Strengths = strengths
+	report.TopRisk = strings.TrimSpace(report.TopRisk)
+}
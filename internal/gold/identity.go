@@ -0,0 +1,123 @@
+package gold
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ai-production-pipeline/internal/fileio"
+)
+
+// KidIdentity ties a report to the kid's earlier weekly reports by profile ID, so a kid whose display
+// name changed is still recognized as the same child
+type KidIdentity struct {
+	ProfileID        string   `json:"profile_id"`
+	WeeksTracked     int      `json:"weeks_tracked"`                // Earlier weeks with a report for this profile ID
+	PreviousNames    []string `json:"previous_names,omitempty"`     // Other display names in those weeks, newest first
+	PreviousNameWeek string   `json:"previous_name_week,omitempty"` // Latest of those weeks reported under a previous name
+}
+
+// Renamed reports whether the kid had another display name in the tracked weeks
+func (id *KidIdentity) Renamed() bool {
+	return id != nil && len(id.PreviousNames) > 0
+}
+
+// identityHistory holds the names earlier weeks' reports used per profile ID, newest week first
+type identityHistory struct {
+	byKid map[string][]namedWeek
+}
+
+// namedWeek is the name a kid was reported under in one week
+type namedWeek struct {
+	Week string
+	Name string
+}
+
+// LoadIdentityHistory reads kid names from earlier weeks' report files (newest first). Names and weeks
+// come from the source data recorded with each report, not from what the AI wrote. Missing files are
+// skipped, and reports without a profile ID or source name cannot be tracked.
+func (gl *GoldLayer) LoadIdentityHistory(reportPaths ...string) error {
+	history := &identityHistory{byKid: make(map[string][]namedWeek)}
+	loaded := 0
+
+	for _, path := range reportPaths {
+		if _, err := fileio.ResolvePath(path); err != nil {
+			continue
+		}
+		data, err := fileio.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read identity history %s: %w", path, err)
+		}
+		var output reportOutput
+		if err := json.Unmarshal(data, &output); err != nil {
+			return fmt.Errorf("failed to parse identity history %s: %w", path, err)
+		}
+		for _, report := range output.Reports {
+			if report.ProfileID == "" || report.SourceName == "" {
+				continue
+			}
+			week := report.SourceWeek
+			if week == "" {
+				week = output.Week
+			}
+			history.byKid[report.ProfileID] = append(history.byKid[report.ProfileID], namedWeek{Week: week, Name: report.SourceName})
+		}
+		loaded++
+	}
+
+	gl.identities = history
+	if loaded > 0 {
+		gl.logger.Infof("🪪 Loaded kid identity history from %d previous week(s)", loaded)
+	}
+	return nil
+}
+
+// identify returns the kid's identity across the loaded weeks (nil when history is not loaded)
+func (h *identityHistory) identify(profileID, nickname string) *KidIdentity {
+	if h == nil || profileID == "" {
+		return nil
+	}
+	weeks := h.byKid[profileID]
+	identity := &KidIdentity{ProfileID: profileID, WeeksTracked: len(weeks)}
+	current := normalizeName(nickname)
+	if current == "" {
+		return identity // A missing name is a data quality issue, not a rename
+	}
+	seen := map[string]bool{current: true}
+	for _, week := range weeks {
+		name := normalizeName(week.Name)
+		if name == "" || name == current {
+			continue
+		}
+		if identity.PreviousNameWeek == "" {
+			identity.PreviousNameWeek = week.Week
+		}
+		if !seen[name] {
+			seen[name] = true
+			identity.PreviousNames = append(identity.PreviousNames, strings.TrimSpace(week.Name))
+		}
+	}
+	return identity
+}
+
+// normalizeName compares display names ignoring case and surrounding spaces
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// addIdentity tracks the kid across earlier weeks' reports (gold.identity)
+func (gl *GoldLayer) addIdentity(kid *KidDataV2) {
+	kid.Identity = gl.identities.identify(kid.ProfileID, kid.Nickname)
+}
+
+// renameNote tells the AI that a renamed kid is the same child, so trend narratives stay about one kid
+func renameNote(kid KidDataV2, language string) string {
+	if !kid.Identity.Renamed() {
+		return ""
+	}
+	previous := `"` + strings.Join(kid.Identity.PreviousNames, `", "`) + `"`
+	if language == "en" {
+		return fmt.Sprintf("\n\nNote: the display name changed (previously %s, now %q). This is still the same child: compare with earlier weeks as usual and only use the current name.", previous, kid.Nickname)
+	}
+	return fmt.Sprintf("\n\nLưu ý: tên hiển thị đã đổi (trước đây là %s, nay là %q). Đây vẫn là cùng một bạn nhỏ: so sánh với các tuần trước như bình thường và chỉ gọi con bằng tên hiện tại.", previous, kid.Nickname)
+}
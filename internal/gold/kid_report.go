@@ -0,0 +1,33 @@
+package gold
+
+import "context"
+
+// GenerateKidReport generates one kid's report for a week and merges it into the week's output at
+// reportOutputPath, replacing the kid's earlier report and leaving every other kid's as it is
+// (pipeline report --profile-id). Its cost is added to the week's token_usage and the report is
+// listed under kid_reports.
+func (gl *GoldLayer) GenerateKidReport(ctx context.Context, kidMap map[string]interface{}, reportOutputPath, weekLabel string) (*AIReport, error) {
+	usageBefore := gl.weekTokenUsage(weekLabel)
+	report, err := gl.GenerateReport(ctx, kidMap, weekLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := gl.weekTokenUsage(weekLabel)
+	usage.PromptTokens -= usageBefore.PromptTokens
+	usage.CompletionTokens -= usageBefore.CompletionTokens
+	usage.EstimatedCostUSD -= usageBefore.EstimatedCostUSD
+	entry := map[string]interface{}{
+		"generated_at": report.GeneratedAt,
+		"profile_id":   report.ProfileID,
+		"reports":      1,
+	}
+	if gl.metadata != nil {
+		entry["template_hash"] = gl.metadata.TemplateHash
+	}
+	if err := gl.mergeWeekReports(reportOutputPath, weekLabel, []AIReport{*report}, usage, "kid_reports", entry); err != nil {
+		return nil, err
+	}
+	gl.logger.Infof("✅ %s: report for %s replaced, other kids' reports kept", weekLabel, report.ChildName)
+	return report, nil
+}
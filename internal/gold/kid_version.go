@@ -0,0 +1,224 @@
+package gold
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/processor"
+)
+
+// Kid version modes (gold.kid_version.mode)
+const (
+	KidVersionTemplate = "template" // Built from the parent report, no AI call
+	KidVersionAI       = "ai"       // Rewritten for the kid by the cheap model
+)
+
+// kidVersionUsageLabel is the token tracking bucket for kid versions
+const kidVersionUsageLabel = "kid_version"
+
+// maxKidBullets is the most a kid version says
+const maxKidBullets = 3
+
+// KidVersion is the short kid-facing version of one kid's weekly report
+type KidVersion struct {
+	ProfileID string   `json:"profile_id"`
+	ChildName string   `json:"child_name"`
+	Language  string   `json:"language"`
+	Bullets   []string `json:"bullets"` // At most three, simple words, emoji allowed
+	Source    string   `json:"source"`  // template or ai
+}
+
+// kidVersionInput is what the model sees of a parent report: the upbeat parts, no scores
+type kidVersionInput struct {
+	Strengths []string `json:"strengths,omitempty"`
+	Badge     string   `json:"badge,omitempty"`
+	Goal      string   `json:"goal,omitempty"`
+	TopRisk   string   `json:"top_risk,omitempty"`
+}
+
+// kidVersioner writes kid versions in the configured mode
+type kidVersioner struct {
+	cfg             config.KidVersionConfig
+	templates       map[string]string // ai mode: language → prompt template
+	defaultLanguage string
+}
+
+// loadKidVersioner checks the mode and loads the ai mode templates (nil when disabled)
+func loadKidVersioner(cfg config.KidVersionConfig, defaultLanguage string) (*kidVersioner, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	v := &kidVersioner{cfg: cfg, templates: make(map[string]string), defaultLanguage: defaultLanguage}
+	switch cfg.Mode {
+	case "", KidVersionTemplate:
+		v.cfg.Mode = KidVersionTemplate
+		return v, nil
+	case KidVersionAI:
+	default:
+		return nil, fmt.Errorf("gold.kid_version.mode must be template or ai, got %q", cfg.Mode)
+	}
+	for lang, file := range cfg.TemplateFiles {
+		template, err := loadPromptTemplate(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kid version template (%s): %w", lang, err)
+		}
+		v.templates[NormalizeLanguage(lang)] = template
+	}
+	if _, ok := v.templates[defaultLanguage]; !ok {
+		return nil, fmt.Errorf("gold.kid_version.template_files has no %q template", defaultLanguage)
+	}
+	return v, nil
+}
+
+// kidVersionInputOf picks the parts of a parent report a kid version is made from
+func kidVersionInputOf(report AIReport) kidVersionInput {
+	input := kidVersionInput{Strengths: report.Strengths, TopRisk: report.TopRisk}
+	if report.Badge != nil {
+		input.Badge = report.Badge.Name
+	}
+	if len(report.NextWeekGoals) > 0 {
+		input.Goal = report.NextWeekGoals[0]
+	}
+	return input
+}
+
+// templateBullets builds the kid version from the report without an AI call: badge, top strength, goal
+func templateBullets(report AIReport) []string {
+	input := kidVersionInputOf(report)
+	var bullets []string
+	if input.Badge != "" {
+		if report.Language == "en" {
+			bullets = append(bullets, "🏅 You earned the "+input.Badge+" badge!")
+		} else {
+			bullets = append(bullets, "🏅 Con nhận được huy hiệu "+input.Badge+"!")
+		}
+	}
+	if len(input.Strengths) > 0 {
+		bullets = append(bullets, "⭐ "+input.Strengths[0])
+	}
+	if input.Goal != "" {
+		if report.Language == "en" {
+			bullets = append(bullets, "🎯 Next week: "+input.Goal)
+		} else {
+			bullets = append(bullets, "🎯 Tuần tới: "+input.Goal)
+		}
+	}
+	return bullets
+}
+
+// prompt renders the ai mode prompt for one report
+func (v *kidVersioner) prompt(report AIReport) (string, error) {
+	data, err := json.Marshal(kidVersionInputOf(report))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal kid version input: %w", err)
+	}
+	template, ok := v.templates[report.Language]
+	if !ok {
+		template = v.templates[v.defaultLanguage]
+	}
+	prompt := strings.ReplaceAll(template, "{{REPORT}}", string(data))
+	prompt = strings.ReplaceAll(prompt, "{{CHILD_NAME}}", report.ChildName)
+	prompt = strings.ReplaceAll(prompt, "{{MAX_BULLETS}}", strconv.Itoa(maxKidBullets))
+	return prompt, nil
+}
+
+// GenerateKidVersions reads a week's reports and writes a kid-facing version of each to kidPath.
+// In ai mode, kids whose call fails get the template version instead.
+func (gl *GoldLayer) GenerateKidVersions(ctx context.Context, reportPath, kidPath, weekLabel string) (int, error) {
+	if gl.kidVersions == nil {
+		return 0, fmt.Errorf("kid versions are disabled (gold.kid_version.enabled)")
+	}
+
+	data, err := fileio.ReadFile(reportPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read reports: %w", err)
+	}
+	var output reportOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return 0, fmt.Errorf("failed to parse reports: %w", err)
+	}
+
+	gl.logger.Infof("🧒 Writing kid versions for %d reports (%s mode)", len(output.Reports), gl.kidVersions.cfg.Mode)
+	versions := make([]KidVersion, 0, len(output.Reports))
+	fallbacks := 0
+	for _, report := range output.Reports {
+		if ctx.Err() != nil {
+			return len(versions), ctx.Err()
+		}
+		version := KidVersion{ProfileID: report.ProfileID, ChildName: report.ChildName, Language: report.Language, Source: KidVersionTemplate}
+		if gl.kidVersions.cfg.Mode == KidVersionAI {
+			bullets, err := gl.generateKidBullets(ctx, report)
+			if err == nil {
+				version.Bullets, version.Source = bullets, KidVersionAI
+			} else {
+				gl.logger.Warnf("   ⚠️  Kid version for %s fell back to the template: %v", report.ChildName, err)
+				fallbacks++
+			}
+		}
+		if version.Bullets == nil {
+			version.Bullets = templateBullets(report)
+		}
+		if len(version.Bullets) == 0 {
+			continue // Nothing upbeat to tell the kid (old report without strengths, badge or goals)
+		}
+		versions = append(versions, version)
+	}
+
+	result := map[string]interface{}{
+		"generated_at":   time.Now().Format(time.RFC3339),
+		"week":           weekLabel,
+		"mode":           gl.kidVersions.cfg.Mode,
+		"total_versions": len(versions),
+		"versions":       versions,
+	}
+	if gl.metadata != nil {
+		result["metadata"] = gl.metadata
+	}
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return len(versions), fmt.Errorf("failed to marshal kid versions: %w", err)
+	}
+	writtenPath, err := fileio.WriteFile(kidPath, encoded, gl.config.Data.CompressionCodec())
+	if err != nil {
+		return len(versions), fmt.Errorf("failed to write file %s: %w", kidPath, err)
+	}
+
+	if fallbacks > 0 {
+		gl.logger.Warnf("   ⚠️  %d kid versions used the template after the AI call failed", fallbacks)
+	}
+	gl.logger.Infof("✅ Kid versions saved to: %s (%d/%d)", writtenPath, len(versions), len(output.Reports))
+	return len(versions), nil
+}
+
+// generateKidBullets asks the cheap model for a kid's bullets, keeping at most maxKidBullets
+func (gl *GoldLayer) generateKidBullets(ctx context.Context, report AIReport) ([]string, error) {
+	prompt, err := gl.kidVersions.prompt(report)
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := processor.DoJSON[struct {
+		Bullets []string `json:"bullets"`
+	}](ctx, gl.aiProcessor, processor.Request{
+		Messages:   []processor.Message{{Role: "user", Content: prompt}},
+		Model:      gl.kidVersions.cfg.Model,
+		MaxTokens:  200,
+		UsageLabel: kidVersionUsageLabel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bullets := nonBlank(result.Bullets)
+	if len(bullets) == 0 {
+		return nil, fmt.Errorf("no bullets")
+	}
+	if len(bullets) > maxKidBullets {
+		bullets = bullets[:maxKidBullets]
+	}
+	return bullets, nil
+}
@@ -0,0 +1,117 @@
+package gold
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/prompts"
+)
+
+// DefaultLanguage is the report language when neither config nor the kid's profile sets one
+const DefaultLanguage = "vi"
+
+// promptSet is the template and system message for one report language
+type promptSet struct {
+	template      string
+	systemMessage string
+
+	templateOrigin promptOrigin
+	systemOrigin   promptOrigin
+}
+
+// loadPromptSets loads the default template plus every configured per-language template. The default
+// Vietnamese files fall back to the copies embedded in the binary; languages whose files are missing
+// are returned in skipped (their kids get the default language).
+func loadPromptSets(cfg *config.Config) (sets map[string]promptSet, defaultLanguage string, skipped []string, err error) {
+	defaultLanguage = NormalizeLanguage(cfg.Prompts.DefaultLanguage)
+	if defaultLanguage == "" {
+		defaultLanguage = DefaultLanguage
+	}
+
+	embeddedTemplate, embeddedSystem := "", ""
+	if defaultLanguage == DefaultLanguage {
+		embeddedTemplate, embeddedSystem = prompts.DefaultTemplate, prompts.DefaultSystemMessage
+	}
+	var set promptSet
+	set.template, set.templateOrigin, err = loadPromptText(cfg.Prompts.TemplateFile, embeddedTemplate, prompts.DefaultTemplateName)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to load prompt template: %w", err)
+	}
+	if err := checkPromptTemplate(set.template, set.templateOrigin.name); err != nil {
+		return nil, "", nil, err
+	}
+	set.systemMessage, set.systemOrigin, err = loadPromptText(cfg.Prompts.SystemMessageFile, embeddedSystem, prompts.DefaultSystemMessageName)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to load system message: %w", err)
+	}
+
+	sets = map[string]promptSet{defaultLanguage: set}
+	for lang, files := range cfg.Prompts.Languages {
+		code := NormalizeLanguage(lang)
+		if code == "" || code == defaultLanguage {
+			continue
+		}
+		var set promptSet
+		set.template, set.templateOrigin, err = loadPromptText(files.TemplateFile, "", "")
+		if errors.Is(err, fs.ErrNotExist) {
+			skipped = append(skipped, code)
+			continue
+		}
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to load %s prompt template: %w", code, err)
+		}
+		if err := checkPromptTemplate(set.template, set.templateOrigin.name); err != nil {
+			return nil, "", nil, err
+		}
+		set.systemMessage, set.systemOrigin, err = loadPromptText(files.SystemMessageFile, "", "")
+		if errors.Is(err, fs.ErrNotExist) {
+			skipped = append(skipped, code)
+			continue
+		}
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to load %s system message: %w", code, err)
+		}
+		sets[code] = set
+	}
+	sort.Strings(skipped)
+
+	return sets, defaultLanguage, skipped, nil
+}
+
+// NormalizeLanguage maps profile values like "en-US", "English" or "Tiếng Việt" to ISO codes
+func NormalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	switch lang {
+	case "english", "tiếng anh":
+		return "en"
+	case "vietnamese", "tiếng việt", "tieng viet":
+		return "vi"
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// reportLanguage returns the language a kid's report is written in (the default when unsupported)
+func (gl *GoldLayer) reportLanguage(kid KidDataV2) string {
+	if lang := NormalizeLanguage(kid.Language); lang != "" {
+		if _, ok := gl.prompts[lang]; ok {
+			return lang
+		}
+	}
+	return gl.defaultLanguage
+}
+
+// languageFallback describes why a kid's report is not in the kid's own language ("" when it is)
+func (gl *GoldLayer) languageFallback(kid KidDataV2) string {
+	requested := NormalizeLanguage(kid.Language)
+	if requested == "" || gl.reportLanguage(kid) == requested {
+		return ""
+	}
+	return fmt.Sprintf("no %s template, written in %s", requested, gl.defaultLanguage)
+}
@@ -0,0 +1,222 @@
+package gold
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// numberPattern matches numbers as the AI writes them ("26.754,74", "26,754.74", "50%", "12 nghìn")
+var numberPattern = regexp.MustCompile(`\d[\d.,]*(\s*(%|k\b|nghìn|ngàn|triệu))?`)
+
+// numericFreeThreshold: small integers (counts, ordinals, 1-5 scores) are not checked
+const numericFreeThreshold = 10
+
+// QualityStats counts numeric-consistency results across reports
+type QualityStats struct {
+	ReportsChecked        int `json:"reports_checked"`
+	ReportsWithMismatches int `json:"reports_with_mismatches"`
+	Reprompts             int `json:"reprompts"`
+	UnresolvedReports     int `json:"unresolved_reports"` // Still inconsistent after all re-prompts
+	InventedNumbers       int `json:"invented_numbers"`   // Total unsupported numbers seen
+}
+
+// qualityTracker accumulates QualityStats safely across goroutines
+type qualityTracker struct {
+	mu    sync.Mutex
+	stats QualityStats
+}
+
+func (q *qualityTracker) record(fn func(s *QualityStats)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	fn(&q.stats)
+}
+
+func (q *qualityTracker) snapshot() QualityStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.stats
+}
+
+// numericGuard verifies that numbers in a report come from the kid's metrics
+type numericGuard struct {
+	tolerancePercent float64
+	allowed          []float64
+}
+
+// newNumericGuard builds the set of allowed figures (raw metrics plus simple derivations).
+// Numbers quoted in extraContext (e.g. campaign notes) are allowed as well.
+func newNumericGuard(kid KidDataV3, tolerancePercent float64, extraContext string) *numericGuard {
+	money := []float64{
+		kid.JoyWallet, kid.SpendingWallet, kid.CharityWallet, kid.StudyWallet,
+		kid.MoneyReceived, kid.JoySpent, kid.SpendingSpent, kid.CharitySpent, kid.StudySpent,
+		kid.InterestEarned,
+	}
+	totalBalance := kid.JoyWallet + kid.SpendingWallet + kid.CharityWallet + kid.StudyWallet
+	totalSpent := kid.JoySpent + kid.SpendingSpent + kid.CharitySpent + kid.StudySpent
+	money = append(money, totalBalance, totalSpent)
+
+	allowed := append([]float64{}, money...)
+	if kid.Age != nil {
+		allowed = append(allowed, float64(*kid.Age))
+	}
+	allowed = append(allowed,
+		float64(kid.MoneyReceivedCount),
+		float64(kid.MissionsCompleted),
+		float64(kid.MissionsTotal),
+		kid.ActivityScore,
+		kid.StudyGrowthRate,
+	)
+
+	// Pairwise sums, differences and percentages between money figures
+	for i, a := range money {
+		for j, b := range money {
+			if i == j {
+				continue
+			}
+			allowed = append(allowed, a+b, math.Abs(a-b))
+			if b > 0 {
+				allowed = append(allowed, a/b*100)
+			}
+		}
+	}
+	if kid.MissionsTotal > 0 {
+		allowed = append(allowed, float64(kid.MissionsCompleted)/float64(kid.MissionsTotal)*100)
+	}
+
+	// Category amounts and their share of total spending
+	for _, amount := range kid.SpendingByCategory {
+		allowed = append(allowed, amount)
+		if totalSpent > 0 {
+			allowed = append(allowed, amount/totalSpent*100)
+		}
+	}
+	for _, value := range kid.Features {
+		allowed = append(allowed, value)
+	}
+	allowed = append(allowed, historyNumbers(kid)...)
+	for _, token := range numberPattern.FindAllString(extraContext, -1) {
+		allowed = append(allowed, parseNumberCandidates(strings.TrimRight(strings.TrimSpace(token), ".,"))...)
+	}
+
+	return &numericGuard{tolerancePercent: tolerancePercent, allowed: allowed}
+}
+
+// unsupportedNumbers returns numbers in the report text that can't be matched to the metrics
+func (g *numericGuard) unsupportedNumbers(report *AIReport) []string {
+	var unsupported []string
+	seen := make(map[string]bool)
+
+	for _, text := range reportTexts(report) {
+		for _, token := range numberPattern.FindAllString(text, -1) {
+			token = strings.TrimRight(strings.TrimSpace(token), ".,")
+			candidates := parseNumberCandidates(token)
+			if len(candidates) == 0 || seen[token] {
+				continue
+			}
+			if g.isFree(candidates) || g.matches(candidates) {
+				continue
+			}
+			seen[token] = true
+			unsupported = append(unsupported, token)
+		}
+	}
+
+	return unsupported
+}
+
+// isFree reports whether a number is too generic to check (small counts, scores, years)
+func (g *numericGuard) isFree(candidates []float64) bool {
+	for _, c := range candidates {
+		if c <= numericFreeThreshold || (c >= 2000 && c <= 2100 && c == math.Trunc(c)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether any interpretation of the number is within tolerance of an allowed figure
+func (g *numericGuard) matches(candidates []float64) bool {
+	for _, c := range candidates {
+		for _, a := range g.allowed {
+			if math.Abs(c-a) <= 1 || (a != 0 && math.Abs(c-a)/math.Abs(a)*100 <= g.tolerancePercent) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseNumberCandidates returns the plausible values of a token under both
+// Vietnamese ("1.234,5") and English ("1,234.5") separators, with unit multipliers applied
+func parseNumberCandidates(token string) []float64 {
+	multiplier := 1.0
+	isPercent := false
+	lower := strings.ToLower(token)
+	for suffix, m := range map[string]float64{"%": 1, "k": 1e3, "nghìn": 1e3, "ngàn": 1e3, "triệu": 1e6} {
+		if strings.HasSuffix(lower, suffix) {
+			multiplier = m
+			isPercent = suffix == "%"
+			lower = strings.TrimSpace(strings.TrimSuffix(lower, suffix))
+			break
+		}
+	}
+
+	var candidates []float64
+	add := func(s string) {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			candidates = append(candidates, v*multiplier)
+		}
+	}
+
+	// Vietnamese: '.' thousands, ',' decimal
+	add(strings.ReplaceAll(strings.ReplaceAll(lower, ".", ""), ",", "."))
+	// English: ',' thousands, '.' decimal
+	add(strings.ReplaceAll(lower, ",", ""))
+
+	if isPercent {
+		// Ratios may be written as percentages of 0-1 values
+		for _, c := range candidates {
+			candidates = append(candidates, c/100)
+		}
+	}
+	return candidates
+}
+
+// reportTexts returns all free-text fields of a report
+func reportTexts(report *AIReport) []string {
+	var texts []string
+	for _, t := range report.FinancialTendencies {
+		texts = append(texts, t.Description, t.Suggestion)
+	}
+	for _, p := range report.PerformanceSections {
+		texts = append(texts, p.Summary)
+	}
+	for _, o := range report.OptionalSections {
+		texts = append(texts, o.Summary)
+	}
+	texts = append(texts, report.NextWeekGoals...)
+	texts = append(texts, report.ParentSuggestions...)
+	texts = append(texts, report.Strengths...)
+	texts = append(texts, report.TopRisk)
+	if report.Badge != nil {
+		texts = append(texts, report.Badge.Reason)
+	}
+	return texts
+}
+
+// numericRepromptInstruction is appended to the prompt when invented numbers are detected
+func numericRepromptInstruction(unsupported []string, language string) string {
+	if language == "en" {
+		return fmt.Sprintf("\n\nIMPORTANT: Only use figures present in the provided data, "+
+			"do not invent or estimate new numbers. The following numbers are NOT in the data and must be removed: %s.",
+			strings.Join(unsupported, ", "))
+	}
+	return fmt.Sprintf("\n\nLƯU Ý QUAN TRỌNG: Chỉ sử dụng các số liệu có trong dữ liệu được cung cấp, "+
+		"không tự tạo hoặc ước đoán số liệu mới. Các số sau KHÔNG có trong dữ liệu và phải được loại bỏ: %s.",
+		strings.Join(unsupported, ", "))
+}
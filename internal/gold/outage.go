@@ -0,0 +1,400 @@
+package gold
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/processor"
+	"ai-production-pipeline/internal/progress"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrProviderUnavailable is returned when the AI provider was down and kids' prompts were queued
+var ErrProviderUnavailable = errors.New("AI provider unavailable, remaining kids queued for flush-deferred")
+
+// QueuedPrompt is a kid's rendered prompt waiting for the provider to recover
+type QueuedPrompt struct {
+	Week          string                 `json:"week"`        // Week label; usage is tracked under it when sent
+	ReportPath    string                 `json:"report_path"` // Week's Gold output the report is merged into
+	ProfileID     string                 `json:"profile_id"`
+	Nickname      string                 `json:"nickname"`
+	Prompt        string                 `json:"prompt"`
+	SystemMessage string                 `json:"system_message"`
+	Kid           map[string]interface{} `json:"kid"` // Silver entry, for the numeric guard and post-processing
+	QueuedAt      string                 `json:"queued_at"`
+	Error         string                 `json:"error,omitempty"` // Provider error that caused (or kept) the queueing
+}
+
+// outageBreaker counts kids failing in a row because the provider is unavailable. Once the threshold
+// is reached the provider counts as down for the rest of the run and kids are queued without a call.
+type outageBreaker struct {
+	threshold int
+	dir       string
+	logger    *logrus.Logger
+
+	mu          sync.Mutex
+	consecutive int
+	down        bool
+}
+
+// newOutageBreaker returns nil when the outage queue is disabled
+func newOutageBreaker(cfg config.OutageQueueConfig, logger *logrus.Logger) *outageBreaker {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.ConsecutiveFailures <= 0 {
+		cfg.ConsecutiveFailures = 5
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "data/deferred"
+	}
+	return &outageBreaker{threshold: cfg.ConsecutiveFailures, dir: cfg.Dir, logger: logger}
+}
+
+// record notes a kid's result and reports whether the provider counts as down
+func (b *outageBreaker) record(err error) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.down:
+	case err != nil && processor.IsUnavailable(err):
+		b.consecutive++
+		if b.consecutive >= b.threshold {
+			b.down = true
+			b.logger.Errorf("⏸️  AI provider unavailable for %d kids in a row, queueing the remaining prompts in %s", b.consecutive, b.dir)
+		}
+	default:
+		b.consecutive = 0
+	}
+	return b.down
+}
+
+// isDown reports whether the provider counts as down
+func (b *outageBreaker) isDown() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.down
+}
+
+// queueKid renders a kid's prompt for the queue and records the kid as deferred
+func (gl *GoldLayer) queueKid(kidMap map[string]interface{}, kid KidDataV2, weekLabel string, cause error) *DeferredKid {
+	// A prompt that fails to render is queued without one and rendered again when flushed
+	rendered, err := gl.renderPrompt(kid)
+	if err != nil {
+		gl.logger.Warnf("   ⚠️  %s: queued without a prompt: %v", kid.Nickname, err)
+	}
+	entry := &QueuedPrompt{
+		Week:          weekLabel,
+		ProfileID:     kid.ProfileID,
+		Nickname:      kid.Nickname,
+		Prompt:        rendered.prompt,
+		SystemMessage: rendered.systemMessage,
+		Kid:           kidMap,
+		QueuedAt:      time.Now().Format(time.RFC3339),
+	}
+	if cause != nil {
+		entry.Error = cause.Error()
+	}
+	gl.progress.RecordKid(weekLabel, kid.ProfileID, kid.Nickname, progress.DispositionDeferred, "provider unavailable")
+	return &DeferredKid{ProfileID: kid.ProfileID, Nickname: kid.Nickname, Reason: "provider unavailable", queued: entry}
+}
+
+// queuePath returns the queue file for a week's report output
+func (b *outageBreaker) queuePath(reportOutputPath string) string {
+	base := filepath.Base(reportOutputPath)
+	return filepath.Join(b.dir, strings.TrimSuffix(base, filepath.Ext(base))+".jsonl")
+}
+
+// writeQueue replaces the week's queue with the queued kids among deferred (removing it when there are
+// none) and returns how many were queued
+func (gl *GoldLayer) writeQueue(reportOutputPath string, deferred []DeferredKid) (int, error) {
+	if gl.outage == nil {
+		return 0, nil
+	}
+	var entries []QueuedPrompt
+	for _, kid := range deferred {
+		if kid.queued != nil {
+			entry := *kid.queued
+			entry.ReportPath = reportOutputPath
+			entries = append(entries, entry)
+		}
+	}
+	if err := writeQueueFile(gl.outage.queuePath(reportOutputPath), entries); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// writeQueueFile writes entries as JSONL to path; no entries removes the file
+func writeQueueFile(path string, entries []QueuedPrompt) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal queued prompt: %w", err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// readQueueFile reads a week's queued prompts
+func readQueueFile(path string) ([]QueuedPrompt, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var entries []QueuedPrompt
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry QueuedPrompt
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// FlushResult summarizes a flush-deferred run
+type FlushResult struct {
+	Queued    int `json:"queued"`    // Prompts found in the queue
+	Flushed   int `json:"flushed"`   // Reports generated and merged into their week's output
+	Failed    int `json:"failed"`    // Dropped because the provider rejected them or the report was unusable
+	Remaining int `json:"remaining"` // Still queued because the provider was unavailable again
+}
+
+// WeekLocker takes the run lock of the week with the given label and returns its release function
+type WeekLocker func(ctx context.Context, weekLabel string) (release func(), err error)
+
+// FlushDeferred sends the queued prompts week by week and merges the reports into each week's output.
+// Usage is tracked under each prompt's week label and added to the week's token_usage. Prompts the
+// provider still cannot serve stay queued; other failures are dropped, and the next run regenerates them.
+// Each week is flushed under lockWeek (when not nil); a week whose lock cannot be taken stays queued.
+func (gl *GoldLayer) FlushDeferred(ctx context.Context, lockWeek WeekLocker) (FlushResult, error) {
+	var result FlushResult
+	if gl.outage == nil {
+		return result, fmt.Errorf("the outage queue is disabled (gold.outage_queue.enabled)")
+	}
+	files, err := filepath.Glob(filepath.Join(gl.outage.dir, "*.jsonl"))
+	if err != nil {
+		return result, fmt.Errorf("failed to scan %s: %w", gl.outage.dir, err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err := gl.flushQueueFile(ctx, file, lockWeek, &result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// flushQueueFile flushes one week's queue file under the week's lock, adding to result
+func (gl *GoldLayer) flushQueueFile(ctx context.Context, file string, lockWeek WeekLocker, result *FlushResult) error {
+	entries, err := readQueueFile(file)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	week := entries[0].Week
+	if lockWeek != nil {
+		release, err := lockWeek(ctx, week)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			gl.logger.Warnf("   ⚠️  %s: left queued: %v", week, err)
+			result.Queued += len(entries)
+			result.Remaining += len(entries)
+			return nil
+		}
+		defer release()
+		// A run may have rewritten the queue while another session held the lock
+		if entries, err = readQueueFile(file); err != nil || len(entries) == 0 {
+			return err
+		}
+	}
+	result.Queued += len(entries)
+	gl.logger.Infof("📤 Flushing %d queued prompts for %s", len(entries), week)
+
+	usageBefore := gl.weekTokenUsage(week)
+	generated := make([]*AIReport, len(entries))
+	keep := make([]bool, len(entries))
+	items := make([]interface{}, len(entries))
+	for i := range entries {
+		items[i] = i
+	}
+	gl.aiProcessor.ProcessBatchFunc(ctx, items, func(ctx context.Context, _ int, item interface{}) error {
+		i := item.(int)
+		entry := &entries[i]
+		if gl.outage.isDown() || ctx.Err() != nil {
+			keep[i] = true
+			return ErrProviderUnavailable
+		}
+		kid := gl.convertEnhancedToV2(entry.Kid, entry.Week)
+		var queued *renderedPrompt
+		if entry.Prompt != "" {
+			queued = &renderedPrompt{prompt: entry.Prompt, systemMessage: entry.SystemMessage}
+		}
+		report, err := gl.generateReportForKid(ctx, kid, entry.Week, queued)
+		gl.outage.record(err)
+		switch {
+		case err != nil && (processor.IsUnavailable(err) || ctx.Err() != nil):
+			keep[i] = true
+			entry.Error = err.Error()
+		case err != nil:
+			gl.logger.Errorf("   ❌ Queued report for %s failed and was dropped: %v", entry.Nickname, err)
+		default:
+			generated[i] = report
+		}
+		return err
+	})
+
+	var reports []AIReport
+	var remaining []QueuedPrompt
+	for i, entry := range entries {
+		switch {
+		case generated[i] != nil:
+			reports = append(reports, *generated[i])
+		case keep[i]:
+			remaining = append(remaining, entry)
+		default:
+			result.Failed++
+		}
+	}
+	if len(reports) > 0 {
+		usage := gl.weekTokenUsage(week)
+		usage.PromptTokens -= usageBefore.PromptTokens
+		usage.CompletionTokens -= usageBefore.CompletionTokens
+		usage.EstimatedCostUSD -= usageBefore.EstimatedCostUSD
+		entry := map[string]interface{}{"flushed_at": time.Now().Format(time.RFC3339), "reports": len(reports)}
+		if err := gl.mergeWeekReports(entries[0].ReportPath, week, reports, usage, "flushes", entry); err != nil {
+			return err
+		}
+		result.Flushed += len(reports)
+	}
+	if err := writeQueueFile(file, remaining); err != nil {
+		return err
+	}
+	result.Remaining += len(remaining)
+	gl.logger.Infof("   ✅ %s: %d reports merged, %d still queued", week, len(reports), len(remaining))
+	return nil
+}
+
+// mergeWeekReports adds reports to a week's output (replacing the kids' earlier reports), removes them
+// from deferred_kids, adds usage to the week's token usage and appends entry to the history list
+// ("flushes", "kid_reports"). A missing output (e.g. the week was rolled back) is created.
+func (gl *GoldLayer) mergeWeekReports(path, weekLabel string, reports []AIReport, usage WeekTokenUsage, history string, entry map[string]interface{}) error {
+	output := map[string]interface{}{"generated_at": time.Now().Format(time.RFC3339), "week": weekLabel}
+	var stored reportOutput
+	format := gl.config.Data.CompressionCodec()
+	if resolved, err := fileio.ResolvePath(path); err == nil {
+		data, err := fileio.ReadFile(resolved)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", resolved, err)
+		}
+		if err := json.Unmarshal(data, &output); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", resolved, err)
+		}
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", resolved, err)
+		}
+		format = compressionOf(resolved)
+	} else if gl.metadata != nil {
+		output["metadata"] = gl.metadata
+	}
+
+	added := make(map[string]bool, len(reports))
+	for _, report := range reports {
+		added[report.ProfileID] = true
+	}
+	var merged []AIReport
+	for _, report := range stored.Reports {
+		if !added[report.ProfileID] {
+			merged = append(merged, report)
+		}
+	}
+	merged = append(merged, reports...)
+	output["reports"] = merged
+	output["total_reports"] = len(merged)
+
+	// Kids with a report are no longer deferred
+	var stillDeferred []interface{}
+	deferred, _ := output["deferred_kids"].([]interface{})
+	for _, kid := range deferred {
+		if kidMap, ok := kid.(map[string]interface{}); ok && added[getString(kidMap, "profile_id")] {
+			continue
+		}
+		stillDeferred = append(stillDeferred, kid)
+	}
+	if len(stillDeferred) > 0 {
+		output["deferred_kids"] = stillDeferred
+	} else {
+		delete(output, "deferred_kids")
+		delete(output, "status")
+	}
+
+	// The week's cost includes what the added reports cost
+	if stored.TokenUsage != nil {
+		usage.PromptTokens += stored.TokenUsage.PromptTokens
+		usage.CompletionTokens += stored.TokenUsage.CompletionTokens
+		usage.EstimatedCostUSD += stored.TokenUsage.EstimatedCostUSD
+	}
+	output["token_usage"] = usage
+	entries, _ := output[history].([]interface{})
+	output[history] = append(entries, entry)
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal reports: %w", err)
+	}
+	if err := gl.storeReports(merged, weekLabel); err != nil {
+		return err
+	}
+	writtenPath, err := fileio.WriteFile(path, data, format)
+	if err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	gl.logger.Infof("✅ Reports saved to: %s", writtenPath)
+	return nil
+}
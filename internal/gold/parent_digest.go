@@ -0,0 +1,205 @@
+package gold
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/processor"
+)
+
+// digestUsageLabel is the token tracking bucket for parent digests
+const digestUsageLabel = "parent_digest"
+
+// ParentDigest is the short weekly summary for one parent across all their kids' reports
+type ParentDigest struct {
+	ParentID   string   `json:"parent_id"`
+	ProfileIDs []string `json:"profile_ids"`
+	Language   string   `json:"language"`
+	Digest     string   `json:"digest"`
+}
+
+// parentDigester holds the digest templates per language
+type parentDigester struct {
+	cfg             config.ParentDigestConfig
+	templates       map[string]string
+	defaultLanguage string
+}
+
+// loadParentDigester loads the digest templates (nil when disabled)
+func loadParentDigester(cfg config.ParentDigestConfig, defaultLanguage string) (*parentDigester, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.MaxChars <= 0 {
+		cfg.MaxChars = 240
+	}
+	d := &parentDigester{cfg: cfg, templates: make(map[string]string), defaultLanguage: defaultLanguage}
+	for lang, file := range cfg.TemplateFiles {
+		template, err := loadPromptTemplate(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load parent digest template (%s): %w", lang, err)
+		}
+		d.templates[NormalizeLanguage(lang)] = template
+	}
+	if _, ok := d.templates[defaultLanguage]; !ok {
+		return nil, fmt.Errorf("gold.parent_digest.template_files has no %q template", defaultLanguage)
+	}
+	return d, nil
+}
+
+// digestKid is the minimal per-kid input for the digest prompt
+type digestKid struct {
+	Name     string   `json:"name"`
+	Sections []string `json:"sections"` // "title: level"
+	Goal     string   `json:"goal,omitempty"`
+}
+
+// prompt renders the digest prompt for one parent's kids
+func (d *parentDigester) prompt(reports []AIReport, language, weekLabel string) (string, error) {
+	kids := make([]digestKid, 0, len(reports))
+	for _, report := range reports {
+		kid := digestKid{Name: report.ChildName}
+		for _, section := range report.PerformanceSections {
+			kid.Sections = append(kid.Sections, section.Title+": "+section.Level)
+		}
+		if len(report.NextWeekGoals) > 0 {
+			kid.Goal = report.NextWeekGoals[0]
+		}
+		kids = append(kids, kid)
+	}
+	data, err := json.Marshal(kids)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal digest input: %w", err)
+	}
+
+	template, ok := d.templates[language]
+	if !ok {
+		template = d.templates[d.defaultLanguage]
+	}
+	prompt := strings.ReplaceAll(template, "{{KIDS}}", string(data))
+	prompt = strings.ReplaceAll(prompt, "{{WEEK}}", weekLabel)
+	prompt = strings.ReplaceAll(prompt, "{{MAX_CHARS}}", strconv.Itoa(d.cfg.MaxChars))
+	return prompt, nil
+}
+
+// GenerateParentDigests reads a week's reports and writes one digest per parent (kids grouped by
+// parent_id) to digestPath. Kids without a parent are skipped; failed digests are logged and skipped.
+func (gl *GoldLayer) GenerateParentDigests(ctx context.Context, reportPath, digestPath, weekLabel string) (int, error) {
+	if gl.digester == nil {
+		return 0, fmt.Errorf("parent digests are disabled (gold.parent_digest.enabled)")
+	}
+
+	data, err := fileio.ReadFile(reportPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read reports: %w", err)
+	}
+	var output reportOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return 0, fmt.Errorf("failed to parse reports: %w", err)
+	}
+
+	// Group by parent, keeping first-seen order
+	var parents []string
+	byParent := make(map[string][]AIReport)
+	for _, report := range output.Reports {
+		if report.ParentID == "" {
+			continue
+		}
+		if _, ok := byParent[report.ParentID]; !ok {
+			parents = append(parents, report.ParentID)
+		}
+		byParent[report.ParentID] = append(byParent[report.ParentID], report)
+	}
+	if len(parents) == 0 {
+		gl.logger.Warn("⚠️  No reports with a parent_id, skipping parent digests (set silver.parent_column)")
+		return 0, nil
+	}
+
+	gl.logger.Infof("👪 Generating parent digests for %d parents", len(parents))
+	var digests []ParentDigest
+	for _, parentID := range parents {
+		if ctx.Err() != nil {
+			return len(digests), ctx.Err()
+		}
+		reports := byParent[parentID]
+		language := reports[0].Language
+		if language == "" {
+			language = gl.defaultLanguage
+		}
+
+		text, err := gl.generateDigest(ctx, reports, language, weekLabel)
+		if err != nil {
+			gl.logger.Errorf("   ❌ Parent digest failed for %s: %v", parentID, err)
+			continue
+		}
+		digest := ParentDigest{ParentID: parentID, Language: language, Digest: text}
+		for _, report := range reports {
+			digest.ProfileIDs = append(digest.ProfileIDs, report.ProfileID)
+		}
+		digests = append(digests, digest)
+	}
+
+	result := map[string]interface{}{
+		"generated_at":  time.Now().Format(time.RFC3339),
+		"week":          weekLabel,
+		"model":         gl.digester.cfg.Model,
+		"total_digests": len(digests),
+		"digests":       digests,
+	}
+	if gl.metadata != nil {
+		result["metadata"] = gl.metadata
+	}
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return len(digests), fmt.Errorf("failed to marshal digests: %w", err)
+	}
+	writtenPath, err := fileio.WriteFile(digestPath, encoded, gl.config.Data.CompressionCodec())
+	if err != nil {
+		return len(digests), fmt.Errorf("failed to write file %s: %w", digestPath, err)
+	}
+
+	gl.logger.Infof("✅ Parent digests saved to: %s (%d/%d)", writtenPath, len(digests), len(parents))
+	return len(digests), nil
+}
+
+// generateDigest asks the digest model for one parent's summary and trims it to the push limit
+func (gl *GoldLayer) generateDigest(ctx context.Context, reports []AIReport, language, weekLabel string) (string, error) {
+	prompt, err := gl.digester.prompt(reports, language, weekLabel)
+	if err != nil {
+		return "", err
+	}
+	resp, err := gl.aiProcessor.Do(ctx, processor.Request{
+		Messages:   []processor.Message{{Role: "user", Content: prompt}},
+		Model:      gl.digester.cfg.Model,
+		MaxTokens:  300,
+		Text:       true,
+		UsageLabel: digestUsageLabel,
+	})
+	if err != nil {
+		return "", err
+	}
+	text := strings.Join(strings.Fields(resp.Content), " ")
+	if text == "" {
+		return "", fmt.Errorf("empty digest")
+	}
+	return limitDigest(text, gl.digester.cfg.MaxChars), nil
+}
+
+// limitDigest cuts text to maxChars runes, at the last sentence end when there is one
+func limitDigest(text string, maxChars int) string {
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	cut := string(runes[:maxChars])
+	if end := strings.LastIndexAny(cut, ".!?"); end > 0 {
+		return cut[:end+1]
+	}
+	return string(runes[:maxChars-1]) + "…"
+}
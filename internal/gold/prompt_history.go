@@ -0,0 +1,288 @@
+package gold
+
+import (
+	"fmt"
+	"math"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/processor"
+)
+
+// Prompt history modes (gold.prompt_history.mode)
+const (
+	historyNone  = "none"  // Current week only
+	historyFull  = "full"  // Previous weeks' metrics in full
+	historyDelta = "delta" // Current week plus changes and trends against the previous week
+)
+
+// HistoryWeek is an earlier week's metrics as sent to the AI in full history mode
+type HistoryWeek struct {
+	JoyWallet         float64 `json:"joy_wallet"`
+	SpendingWallet    float64 `json:"spending_wallet"`
+	CharityWallet     float64 `json:"charity_wallet"`
+	StudyWallet       float64 `json:"study_wallet"`
+	MoneyReceived     float64 `json:"money_received"`
+	JoySpent          float64 `json:"joy_spent"`
+	SpendingSpent     float64 `json:"spending_spent"`
+	CharitySpent      float64 `json:"charity_spent"`
+	StudySpent        float64 `json:"study_spent"`
+	MissionsCompleted int     `json:"missions_completed"`
+	MissionsTotal     int     `json:"missions_total"`
+}
+
+// WeekChanges is the current week against the previous one, sent instead of HistoryWeek in delta mode
+type WeekChanges struct {
+	TotalBalance      float64 `json:"total_balance"`  // Current minus previous week
+	MoneyReceived     float64 `json:"money_received"` // Current minus previous week
+	TotalSpent        float64 `json:"total_spent"`    // Current minus previous week
+	MissionsCompleted int     `json:"missions_completed"`
+
+	BalanceTrend           string  `json:"balance_trend,omitempty"` // From Silver trends: increasing, decreasing, stable
+	BalanceChangePercent   float64 `json:"balance_change_percent,omitempty"`
+	SpendingTrend          string  `json:"spending_trend,omitempty"`
+	SpendingChangePercent  float64 `json:"spending_change_percent,omitempty"`
+	MissionCompletionTrend string  `json:"mission_completion_trend,omitempty"`
+	ActivityTrend          string  `json:"activity_trend,omitempty"`
+}
+
+// PromptTrends is Silver's comparison with the previous week, as sent to the AI
+type PromptTrends struct {
+	BalanceTrend           string  `json:"balance_trend"` // increasing, decreasing, stable
+	BalanceChangePercent   float64 `json:"balance_change_percent"`
+	SpendingTrend          string  `json:"spending_trend"`
+	SpendingChangePercent  float64 `json:"spending_change_percent"`
+	MissionCompletionTrend string  `json:"mission_completion_trend"` // improving, declining, stable
+	CompletionRateChange   float64 `json:"completion_rate_change"`   // Percentage points
+	ActivityTrend          string  `json:"activity_trend"`
+	TransactionCountChange int     `json:"transaction_count_change"`
+	ConsistencyLevel       string  `json:"consistency_level,omitempty"` // high, medium, low
+}
+
+// PromptStatistics is Silver's spending shares and multi-week averages, as sent to the AI. Silver's
+// 0-1 ratios are sent as percentages, the form the AI writes them in.
+type PromptStatistics struct {
+	JoySpendingPercent     float64 `json:"joy_spending_percent"` // Share of the week's spending per wallet
+	CharitySpendingPercent float64 `json:"charity_spending_percent"`
+	StudySpendingPercent   float64 `json:"study_spending_percent"`
+	SavingsPercent         float64 `json:"savings_percent"` // Spending (Tiết kiệm) and study wallets' share of the total balance
+	AvgWeeklyIncome        float64 `json:"avg_weekly_income"`
+	AvgWeeklySpending      float64 `json:"avg_weekly_spending"`
+	AvgMissionCompletion   float64 `json:"avg_mission_completion"` // %
+	IncomeGrowthRate       float64 `json:"income_growth_rate"`     // % against the oldest week available
+	SavingsGrowthRate      float64 `json:"savings_growth_rate"`
+	SavingsBehavior        string  `json:"savings_behavior"`    // aggressive, moderate, minimal
+	CharityInvolvement     string  `json:"charity_involvement"` // high, medium, low
+}
+
+// parsePromptHistory validates gold.prompt_history.mode
+func parsePromptHistory(cfg config.PromptHistoryConfig) (string, error) {
+	switch cfg.Mode {
+	case "", historyNone:
+		return historyNone, nil
+	case historyFull, historyDelta:
+		return cfg.Mode, nil
+	default:
+		return "", fmt.Errorf("gold.prompt_history.mode must be none, full or delta, got %q", cfg.Mode)
+	}
+}
+
+// historyWeek reads an earlier week from a Silver V3 kid entry (nil when Silver had none)
+func (gl *GoldLayer) historyWeek(kidMap map[string]interface{}, key string) *HistoryWeek {
+	week, ok := kidMap[key].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	h := &HistoryWeek{
+		JoyWallet:         getFloat64(week, "joy_wallet"),
+		SpendingWallet:    getFloat64(week, "spending_wallet"),
+		CharityWallet:     getFloat64(week, "charity_wallet"),
+		StudyWallet:       getFloat64(week, "study_wallet"),
+		MoneyReceived:     getFloat64(week, "money_received"),
+		JoySpent:          getFloat64(week, "joy_spent"),
+		SpendingSpent:     getFloat64(week, "spending_spent"),
+		CharitySpent:      getFloat64(week, "charity_spent"),
+		StudySpent:        getFloat64(week, "study_spent"),
+		MissionsCompleted: int(getFloat64(week, "missions_completed")),
+		MissionsTotal:     int(getFloat64(week, "missions_total")),
+	}
+	if gl.currency != nil {
+		for _, amount := range []*float64{
+			&h.JoyWallet, &h.SpendingWallet, &h.CharityWallet, &h.StudyWallet, &h.MoneyReceived,
+			&h.JoySpent, &h.SpendingSpent, &h.CharitySpent, &h.StudySpent,
+		} {
+			*amount = gl.currency.round(*amount)
+		}
+	}
+	return h
+}
+
+// addHistory attaches earlier weeks to the kid according to gold.prompt_history.mode. In delta mode
+// the full history is kept aside, so the tokens saved can be measured.
+func (gl *GoldLayer) addHistory(kid *KidDataV2, kidMap map[string]interface{}) {
+	if gl.history == historyNone || gl.history == "" {
+		return
+	}
+	previous := gl.historyWeek(kidMap, "previous_week")
+	twoWeeksAgo := gl.historyWeek(kidMap, "two_weeks_ago")
+	if gl.history == historyFull {
+		kid.PreviousWeek, kid.TwoWeeksAgo = previous, twoWeeksAgo
+		return
+	}
+	if previous == nil {
+		return
+	}
+
+	changes := &WeekChanges{
+		TotalBalance:      kid.totalBalance() - previous.totalBalance(),
+		MoneyReceived:     kid.MoneyReceived - previous.MoneyReceived,
+		TotalSpent:        kid.totalSpent() - previous.totalSpent(),
+		MissionsCompleted: kid.MissionsCompleted - previous.MissionsCompleted,
+	}
+	if trends, ok := kidMap["trends"].(map[string]interface{}); ok {
+		changes.BalanceTrend = getString(trends, "balance_trend")
+		changes.BalanceChangePercent = roundPercent(getFloat64(trends, "balance_change_percent"))
+		changes.SpendingTrend = getString(trends, "spending_trend")
+		changes.SpendingChangePercent = roundPercent(getFloat64(trends, "spending_change_percent"))
+		changes.MissionCompletionTrend = getString(trends, "mission_completion_trend")
+		changes.ActivityTrend = getString(trends, "activity_trend")
+	}
+	kid.Changes = changes
+	kid.fullHistory = [2]*HistoryWeek{previous, twoWeeksAgo}
+}
+
+// kidDataV3 adds Silver's trends and statistics to the kid. Silver only computes them when the kid has
+// earlier weeks, so a kid's first week (or a kid not read from Silver) gets neither.
+func (gl *GoldLayer) kidDataV3(kid KidDataV2) KidDataV3 {
+	v3 := KidDataV3{KidDataV2: kid}
+	if trends, ok := kid.source["trends"].(map[string]interface{}); ok {
+		v3.Trends = &PromptTrends{
+			BalanceTrend:           getString(trends, "balance_trend"),
+			BalanceChangePercent:   roundPercent(getFloat64(trends, "balance_change_percent")),
+			SpendingTrend:          getString(trends, "spending_trend"),
+			SpendingChangePercent:  roundPercent(getFloat64(trends, "spending_change_percent")),
+			MissionCompletionTrend: getString(trends, "mission_completion_trend"),
+			CompletionRateChange:   roundPercent(getFloat64(trends, "completion_rate_change")),
+			ActivityTrend:          getString(trends, "activity_trend"),
+			TransactionCountChange: int(getFloat64(trends, "activity_change")),
+			ConsistencyLevel:       getString(trends, "consistency_level"),
+		}
+	}
+	if stats, ok := kid.source["statistics"].(map[string]interface{}); ok {
+		v3.Statistics = &PromptStatistics{
+			JoySpendingPercent:     roundPercent(getFloat64(stats, "joy_spending_ratio") * 100),
+			CharitySpendingPercent: roundPercent(getFloat64(stats, "charity_ratio") * 100),
+			StudySpendingPercent:   roundPercent(getFloat64(stats, "study_ratio") * 100),
+			SavingsPercent:         roundPercent(getFloat64(stats, "savings_ratio") * 100),
+			AvgWeeklyIncome:        getFloat64(stats, "avg_weekly_income"),
+			AvgWeeklySpending:      getFloat64(stats, "avg_weekly_spending"),
+			AvgMissionCompletion:   roundPercent(getFloat64(stats, "avg_mission_completion")),
+			IncomeGrowthRate:       roundPercent(getFloat64(stats, "income_growth_rate")),
+			SavingsGrowthRate:      roundPercent(getFloat64(stats, "savings_growth_rate")),
+			SavingsBehavior:        getString(stats, "savings_behavior"),
+			CharityInvolvement:     getString(stats, "charity_involvement"),
+		}
+		if gl.currency != nil {
+			v3.Statistics.AvgWeeklyIncome = gl.currency.round(v3.Statistics.AvgWeeklyIncome)
+			v3.Statistics.AvgWeeklySpending = gl.currency.round(v3.Statistics.AvgWeeklySpending)
+		}
+	}
+	return v3
+}
+
+// trendNotes tells the AI how to use the trends and statistics sent with the kid ("" when none were sent)
+func trendNotes(kid KidDataV3, language string) string {
+	if kid.Trends == nil && kid.Statistics == nil {
+		return ""
+	}
+	if language == "en" {
+		return "\n\ntrends compares this week with the previous one and statistics averages the weeks available: " +
+			"mention the child's week-over-week progress (what improved, what slipped) using only these figures."
+	}
+	return "\n\ntrends so sánh tuần này với tuần trước, statistics là trung bình các tuần có dữ liệu: " +
+		"hãy nhắc đến tiến bộ của bé so với tuần trước (điều gì tốt lên, điều gì giảm đi) và chỉ dùng các số liệu này."
+}
+
+// recordHistorySavings reports to the token tracker how many prompt tokens delta mode saved
+// against sending the previous weeks in full
+func (gl *GoldLayer) recordHistorySavings(proc *processor.AIProcessor, kid KidDataV2, sent string) {
+	if kid.Changes == nil || kid.fullHistory[0] == nil {
+		return
+	}
+	full := kid
+	full.Changes = nil
+	full.PreviousWeek, full.TwoWeeksAgo = kid.fullHistory[0], kid.fullHistory[1]
+	fullPrompt, err := gl.createEnhancedPromptForKid(full)
+	if err != nil {
+		return
+	}
+	proc.GetTokenTracker().RecordPromptSavings(
+		processor.EstimateTokens(fullPrompt),
+		processor.EstimateTokens(sent))
+}
+
+// totalBalance sums the four wallets
+func (h *HistoryWeek) totalBalance() float64 {
+	return h.JoyWallet + h.SpendingWallet + h.CharityWallet + h.StudyWallet
+}
+
+// totalSpent sums spending across the four wallets
+func (h *HistoryWeek) totalSpent() float64 {
+	return h.JoySpent + h.SpendingSpent + h.CharitySpent + h.StudySpent
+}
+
+// totalBalance sums the four wallets
+func (kid *KidDataV2) totalBalance() float64 {
+	return kid.JoyWallet + kid.SpendingWallet + kid.CharityWallet + kid.StudyWallet
+}
+
+// totalSpent sums spending across the four wallets
+func (kid *KidDataV2) totalSpent() float64 {
+	return kid.JoySpent + kid.SpendingSpent + kid.CharitySpent + kid.StudySpent
+}
+
+// roundPercent keeps one decimal, enough for the AI to quote a change
+func roundPercent(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+// historyNumbers lists the figures the AI may quote from the history sent with the kid, for the
+// numeric guard: earlier weeks' amounts and totals, the changes against the previous week, and
+// the trends and statistics
+func historyNumbers(kid KidDataV3) []float64 {
+	var numbers []float64
+	for _, week := range []*HistoryWeek{kid.PreviousWeek, kid.TwoWeeksAgo} {
+		if week == nil {
+			continue
+		}
+		numbers = append(numbers,
+			week.JoyWallet, week.SpendingWallet, week.CharityWallet, week.StudyWallet, week.MoneyReceived,
+			week.JoySpent, week.SpendingSpent, week.CharitySpent, week.StudySpent,
+			week.totalBalance(), week.totalSpent(),
+			float64(week.MissionsCompleted), float64(week.MissionsTotal),
+			math.Abs(kid.totalBalance()-week.totalBalance()),
+			math.Abs(kid.totalSpent()-week.totalSpent()),
+			math.Abs(kid.MoneyReceived-week.MoneyReceived),
+		)
+	}
+	if c := kid.Changes; c != nil {
+		numbers = append(numbers,
+			math.Abs(c.TotalBalance), math.Abs(c.MoneyReceived), math.Abs(c.TotalSpent),
+			math.Abs(float64(c.MissionsCompleted)),
+			math.Abs(c.BalanceChangePercent), math.Abs(c.SpendingChangePercent),
+		)
+	}
+	if t := kid.Trends; t != nil {
+		numbers = append(numbers,
+			math.Abs(t.BalanceChangePercent), math.Abs(t.SpendingChangePercent),
+			math.Abs(t.CompletionRateChange), math.Abs(float64(t.TransactionCountChange)),
+		)
+	}
+	if st := kid.Statistics; st != nil {
+		numbers = append(numbers,
+			st.JoySpendingPercent, st.CharitySpendingPercent, st.StudySpendingPercent, st.SavingsPercent,
+			st.AvgWeeklyIncome, st.AvgWeeklySpending, st.AvgMissionCompletion,
+			math.Abs(st.IncomeGrowthRate), math.Abs(st.SavingsGrowthRate),
+		)
+	}
+	return numbers
+}
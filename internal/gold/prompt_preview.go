@@ -0,0 +1,113 @@
+package gold
+
+import (
+	"fmt"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/processor"
+)
+
+// PromptPreview is the fully rendered request for one kid, with token and cost estimates
+type PromptPreview struct {
+	Model               string  `json:"model"`
+	Language            string  `json:"language"`
+	WeekType            string  `json:"week_type"`
+	SystemMessage       string  `json:"system_message"`
+	Prompt              string  `json:"prompt"`
+	SystemTokens        int     `json:"system_tokens"`
+	PromptTokens        int     `json:"prompt_tokens"`
+	MaxCompletionTokens int     `json:"max_completion_tokens"`
+	EstimatedCostUSD    float64 `json:"estimated_cost_usd"` // Upper bound: assumes the completion uses all max_tokens
+}
+
+// PreviewPrompt renders the prompt and system message for one Silver V3 kid entry without calling the API
+func PreviewPrompt(cfg *config.Config, kidMap map[string]interface{}, weekLabel string) (*PromptPreview, error) {
+	gl, err := newOfflineLayer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return gl.previewPrompt(kidMap, weekLabel)
+}
+
+// newOfflineLayer builds a Gold layer that renders prompts but has no AI processor
+func newOfflineLayer(cfg *config.Config) (*GoldLayer, error) {
+	prompts, defaultLanguage, _, err := loadPromptSets(cfg)
+	if err != nil {
+		return nil, err
+	}
+	weekTypes, err := loadWeekTypes(cfg, prompts, defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+	campaign, err := loadCampaignContext(cfg.Prompts.ExtraContextFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign context: %w", err)
+	}
+
+	optional, err := loadOptionalSections(cfg.Gold.OptionalSections, defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	style, err := newReportStyle(cfg.Gold.ReportStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := newCurrencyFormat(cfg.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := parsePromptHistory(cfg.Gold.PromptHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	badges, err := newBadgeCatalog(cfg.Gold.Badges, defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GoldLayer{
+		config:          cfg,
+		promptTemplate:  prompts[defaultLanguage].template,
+		systemMessage:   prompts[defaultLanguage].systemMessage,
+		prompts:         prompts,
+		weekTypes:       weekTypes,
+		defaultLanguage: defaultLanguage,
+		campaign:        campaign,
+		taxonomy:        newSectionTaxonomy(cfg.Gold.SectionTaxonomy, defaultLanguage),
+		badges:          badges,
+		optional:        optional,
+		style:           style,
+		currency:        currency,
+		history:         history,
+	}, nil
+}
+
+// previewPrompt renders the request for one kid with token and cost estimates
+func (gl *GoldLayer) previewPrompt(kidMap map[string]interface{}, weekLabel string) (*PromptPreview, error) {
+	cfg := gl.config
+	kid := gl.convertEnhancedToV2(kidMap, weekLabel)
+	language := gl.reportLanguage(kid)
+	prompt, err := gl.createEnhancedPromptForKid(kid)
+	if err != nil {
+		return nil, err
+	}
+	systemMessage := gl.promptSetFor(kid).systemMessage
+
+	preview := &PromptPreview{
+		Model:               cfg.OpenAI.Model,
+		Language:            language,
+		WeekType:            kid.WeekType,
+		SystemMessage:       systemMessage,
+		Prompt:              prompt,
+		SystemTokens:        processor.EstimateTokens(systemMessage),
+		PromptTokens:        processor.EstimateTokens(prompt),
+		MaxCompletionTokens: gl.style.maxTokens(cfg.Gold.ReportStyle, cfg.OpenAI.MaxTokens),
+	}
+	preview.EstimatedCostUSD = processor.EstimateCost(cfg.OpenAI.Model,
+		preview.SystemTokens+preview.PromptTokens, preview.MaxCompletionTokens)
+	return preview, nil
+}
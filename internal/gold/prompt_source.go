@@ -0,0 +1,166 @@
+package gold
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+
+	"ai-production-pipeline/internal/buildinfo"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Where a template or system message was loaded from
+const (
+	PromptSourceEmbedded = "embedded" // Built into the binary (prompts/embed.go)
+	PromptSourceFile     = "file"
+	PromptSourceDB       = "db" // prompts.db_table
+)
+
+// promptTableName guards the configurable prompt override table name
+var promptTableName = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+
+// promptOrigin records where one template or system message came from
+type promptOrigin struct {
+	source  string
+	name    string // File path, embedded file name or table
+	hash    string
+	missing string // Configured file that was not found (embedded default used instead)
+}
+
+// loadPromptText reads path, falling back to embedded when the file does not exist ("" = no default)
+func loadPromptText(path, embedded, embeddedName string) (string, promptOrigin, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return string(data), promptOrigin{source: PromptSourceFile, name: path, hash: buildinfo.ContentHash(data)}, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) || embedded == "" {
+			return "", promptOrigin{}, err
+		}
+	}
+	if embedded == "" {
+		return "", promptOrigin{}, fmt.Errorf("no file configured and no embedded default")
+	}
+	origin := promptOrigin{source: PromptSourceEmbedded, name: embeddedName, hash: buildinfo.ContentHash([]byte(embedded)), missing: path}
+	return embedded, origin, nil
+}
+
+// logPromptSources logs which source each language's template and system message came from
+func (gl *GoldLayer) logPromptSources() {
+	languages := make([]string, 0, len(gl.prompts))
+	for lang := range gl.prompts {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	for _, lang := range languages {
+		set := gl.prompts[lang]
+		for _, item := range []struct {
+			kind   string
+			origin promptOrigin
+		}{{"prompt template", set.templateOrigin}, {"system message", set.systemOrigin}} {
+			if item.origin.missing != "" {
+				gl.logger.Warnf("⚠️  %s not found, using the embedded default %s", item.origin.missing, item.kind)
+			}
+			gl.logger.WithFields(logrus.Fields{
+				"language": lang,
+				"source":   item.origin.source,
+				"name":     item.origin.name,
+				"hash":     item.origin.hash,
+			}).Infof("✅ Loaded %s", item.kind)
+		}
+	}
+}
+
+// LoadPromptOverrides replaces templates and system messages with the latest active rows of table:
+//
+//	language text, kind text ('template' | 'system_message'), content text, active bool, updated_at timestamptz
+//
+// Only languages that are already loaded can be overridden.
+func (gl *GoldLayer) LoadPromptOverrides(db *sql.DB, table string) error {
+	if !promptTableName.MatchString(table) {
+		return fmt.Errorf("invalid prompts.db_table %q", table)
+	}
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT DISTINCT ON (language, kind) language, kind, content
+		FROM %s
+		WHERE active
+		ORDER BY language, kind, updated_at DESC
+	`, table))
+	if err != nil {
+		return fmt.Errorf("failed to query prompt overrides: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var language, kind, content string
+		if err := rows.Scan(&language, &kind, &content); err != nil {
+			return fmt.Errorf("failed to scan prompt override: %w", err)
+		}
+		lang := NormalizeLanguage(language)
+		set, ok := gl.prompts[lang]
+		if !ok {
+			gl.logger.Warnf("⚠️  Ignoring %s override for %q: no template configured for that language", kind, language)
+			continue
+		}
+		origin := promptOrigin{source: PromptSourceDB, name: table, hash: buildinfo.ContentHash([]byte(content))}
+		switch kind {
+		case "template":
+			if err := checkPromptTemplate(content, "in "+table); err != nil {
+				gl.logger.Warnf("⚠️  Ignoring template override for %q: %v", language, err)
+				continue
+			}
+			set.template, set.templateOrigin = content, origin
+		case "system_message":
+			set.systemMessage, set.systemOrigin = content, origin
+		default:
+			gl.logger.Warnf("⚠️  Ignoring prompt override of unknown kind %q", kind)
+			continue
+		}
+		gl.prompts[lang] = set
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read prompt overrides: %w", err)
+	}
+
+	gl.promptTemplate = gl.prompts[gl.defaultLanguage].template
+	gl.systemMessage = gl.prompts[gl.defaultLanguage].systemMessage
+	gl.stampPromptHashes()
+	gl.logPromptSources()
+	return nil
+}
+
+// TemplateHash returns the hash of every template and system message prompts are rendered from, as
+// loaded (file, embedded default or prompts.db_table override): each language's and each week type's.
+// It is the template_hash recorded with each report, so editing any of them outdates stored reports.
+func (gl *GoldLayer) TemplateHash() string {
+	texts := make(map[string]string)
+	for lang, set := range gl.prompts {
+		texts["template/"+lang] = set.template
+		texts["system_message/"+lang] = set.systemMessage
+	}
+	if gl.weekTypes != nil {
+		for name, byLanguage := range gl.weekTypes.prompts {
+			for lang, override := range byLanguage {
+				texts["week_type/"+name+"/template/"+lang] = override.template
+				texts["week_type/"+name+"/system_message/"+lang] = override.systemMessage
+			}
+		}
+	}
+	return buildinfo.PromptsHash(texts)
+}
+
+// stampPromptHashes records the hashes of the prompts as loaded, whatever their source
+func (gl *GoldLayer) stampPromptHashes() {
+	set, ok := gl.prompts[gl.defaultLanguage]
+	if gl.metadata == nil || !ok {
+		return
+	}
+	gl.metadata.TemplateHash = gl.TemplateHash()
+	gl.metadata.SystemMessageHash = set.systemOrigin.hash
+}
@@ -0,0 +1,123 @@
+package gold
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"ai-production-pipeline/internal/silver"
+)
+
+// legacyPlaceholders rewrites the original {{NAME}} placeholders to fields of promptData, so
+// templates written before text/template keep rendering the same prompt
+var legacyPlaceholders = strings.NewReplacer(
+	"{{KIDS_DATA}}", "{{.KidsData}}",
+	"{{CHILD_NAME}}", "{{.ChildName}}",
+	"{{WEEK}}", "{{.Week}}",
+	"{{CAMPAIGN}}", "{{.Campaign}}",
+	"{{PREVIOUS_SUGGESTIONS}}", "{{.PreviousSuggestions}}",
+	"{{SECTION_TAXONOMY}}", "{{.SectionTaxonomy}}",
+	"{{OPTIONAL_SECTIONS}}", "{{.OptionalSections}}",
+	"{{REPORT_STYLE}}", "{{.ReportStyle}}",
+	"{{CURRENCY}}", "{{.Currency}}",
+	"{{BADGE_CATALOG}}", "{{.BadgeCatalog}}",
+)
+
+// promptData is what a report template is executed with. The string fields are the blocks the
+// legacy placeholders stand for; Kid is the prompt data and Silver the kid's full Silver entry.
+type promptData struct {
+	Kid      KidDataV3
+	Language string
+	WeekType string
+
+	KidsData            string // Kid as JSON, with the data quality notes
+	ChildName           string
+	Week                string
+	Campaign            string
+	PreviousSuggestions string
+	SectionTaxonomy     string
+	OptionalSections    string
+	ReportStyle         string
+	Currency            string
+	BadgeCatalog        string
+
+	silver  *silver.EnhancedKidData
+	decoded bool
+}
+
+// Silver returns the kid's Silver V3 entry (trends, statistics, previous weeks, history), decoded on
+// first use. It is nil when the kid did not come from a Silver file, so guard it with {{with .Silver}}.
+func (d *promptData) Silver() *silver.EnhancedKidData {
+	if d.decoded {
+		return d.silver
+	}
+	d.decoded = true
+	if d.Kid.source == nil {
+		return nil
+	}
+	data, err := json.Marshal(d.Kid.source)
+	if err != nil {
+		return nil
+	}
+	var kid silver.EnhancedKidData
+	if err := json.Unmarshal(data, &kid); err != nil {
+		return nil
+	}
+	d.silver = &kid
+	return d.silver
+}
+
+// promptFuncs are the helpers report templates can call; money formats in the tenant's currency
+func promptFuncs(currency *currencyFormat) template.FuncMap {
+	return template.FuncMap{
+		"money": func(amount float64) string {
+			if currency == nil {
+				return strconv.FormatFloat(amount, 'f', -1, 64)
+			}
+			return currency.format(amount)
+		},
+		"percent": func(value float64) string {
+			return strconv.FormatFloat(roundPercent(value), 'f', -1, 64) + "%"
+		},
+		"round": func(value float64, decimals int) float64 {
+			scale := math.Pow10(decimals)
+			return math.Round(value*scale) / scale
+		},
+		"json": func(v interface{}) (string, error) {
+			data, err := json.MarshalIndent(v, "", "  ")
+			return string(data), err
+		},
+		"join": func(values []string, sep string) string {
+			return strings.Join(values, sep)
+		},
+	}
+}
+
+// parsePromptTemplate parses a report template, legacy placeholders included
+func parsePromptTemplate(text string, currency *currencyFormat) (*template.Template, error) {
+	return template.New("prompt").Funcs(promptFuncs(currency)).Parse(legacyPlaceholders.Replace(text))
+}
+
+// checkPromptTemplate reports a template that does not parse, naming where it came from
+func checkPromptTemplate(text, source string) error {
+	if _, err := parsePromptTemplate(text, nil); err != nil {
+		return fmt.Errorf("invalid prompt template %s: %w", source, err)
+	}
+	return nil
+}
+
+// parsedPrompt returns the parsed form of a template, parsing each distinct template once
+func (gl *GoldLayer) parsedPrompt(text string) (*template.Template, error) {
+	if tmpl, ok := gl.parsedPrompts.Load(text); ok {
+		return tmpl.(*template.Template), nil
+	}
+	tmpl, err := parsePromptTemplate(text, gl.currency)
+	if err != nil {
+		return nil, err
+	}
+	gl.parsedPrompts.Store(text, tmpl)
+	return tmpl, nil
+}
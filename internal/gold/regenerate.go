@@ -0,0 +1,430 @@
+package gold
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/sirupsen/logrus"
+)
+
+// unknownTemplate marks reports written before template hashes were recorded (older than any version)
+const unknownTemplate = "unknown"
+
+// TemplateVersion is one prompt template hash found in the report store
+type TemplateVersion struct {
+	Hash      string    `json:"hash"`
+	FirstSeen time.Time `json:"first_seen"` // Earliest generated_at of a report with this hash; versions are ordered by it
+	Reports   int       `json:"reports"`
+}
+
+// RegenerationItem is one stored report planned for regeneration
+type RegenerationItem struct {
+	Week             string  `json:"week"` // Week key (start date)
+	WeekLabel        string  `json:"week_label"`
+	ReportPath       string  `json:"report_path"`
+	ProfileID        string  `json:"profile_id"`
+	ChildName        string  `json:"child_name"`
+	TemplateHash     string  `json:"template_hash"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"` // Upper bound, as in prompt previews
+
+	kidMap map[string]interface{} // Silver V3 entry the report is regenerated from
+}
+
+// SkippedReport is an outdated report that cannot be regenerated
+type SkippedReport struct {
+	Week      string `json:"week"` // Week key (start date)
+	ProfileID string `json:"profile_id"`
+	Reason    string `json:"reason"`
+}
+
+// RegenerationPlan lists the stored reports generated with templates older than OlderThan
+type RegenerationPlan struct {
+	OlderThan        string             `json:"older_than"`
+	OlderThanSeen    bool               `json:"older_than_seen"` // False when no stored report uses OlderThan (every other version counts as older)
+	Versions         []TemplateVersion  `json:"versions"`
+	Items            []RegenerationItem `json:"items"`
+	Skipped          []SkippedReport    `json:"skipped"`
+	EstimatedCostUSD float64            `json:"estimated_cost_usd"`
+}
+
+// Weeks returns the plan's weeks (label and start date), for attributing its cost
+func (p *RegenerationPlan) Weeks() []weekmanager.WeekRange {
+	seen := make(map[string]bool)
+	var weeks []weekmanager.WeekRange
+	for _, item := range p.Items {
+		if seen[item.Week] {
+			continue
+		}
+		seen[item.Week] = true
+		if start, err := weekmanager.ParseKey(item.Week); err == nil {
+			weeks = append(weeks, weekmanager.WeekRange{Label: item.WeekLabel, StartDate: start, EndDate: start.AddDate(0, 0, 7)})
+		}
+	}
+	return weeks
+}
+
+// storedReports is one week's Gold output in the report store
+type storedReports struct {
+	Week   string // Week key (start date)
+	Path   string // Canonical path (without compression suffix)
+	Label  string
+	Hashes []string // Template hash per report
+	Output reportOutput
+}
+
+// PlanRegeneration scans the week outputs in outputDir for reports generated with a template older
+// than olderThan ("" = the template runs use, with the prompts.db_table overrides read from db when
+// set) and estimates the cost of regenerating them from the stored Silver output. It makes no API calls.
+func PlanRegeneration(cfg *config.Config, db *sql.DB, logger *logrus.Logger, outputDir, olderThan string) (*RegenerationPlan, error) {
+	gl, err := newOfflineLayer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	gl.logger = logger
+	if cfg.Prompts.DBTable != "" {
+		if err := gl.LoadPromptOverrides(db, cfg.Prompts.DBTable); err != nil {
+			return nil, fmt.Errorf("failed to load prompt overrides: %w", err)
+		}
+	}
+	if olderThan == "" {
+		olderThan = gl.TemplateHash()
+	}
+
+	weeks, err := scanReportStore(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RegenerationPlan{OlderThan: olderThan, Versions: templateVersions(weeks)}
+	older := make(map[string]bool)
+	for _, version := range plan.Versions {
+		if version.Hash == olderThan {
+			plan.OlderThanSeen = true
+		}
+	}
+	for _, version := range plan.Versions {
+		if version.Hash == olderThan {
+			break
+		}
+		older[version.Hash] = true
+	}
+
+	for _, week := range weeks {
+		var kids map[string]map[string]interface{}
+		var silverErr error
+		for i, report := range week.Output.Reports {
+			if !older[week.Hashes[i]] {
+				continue
+			}
+			if report.ProfileID == "" {
+				plan.Skipped = append(plan.Skipped, SkippedReport{Week: week.Week, Reason: fmt.Sprintf("report for %s has no profile_id", report.ChildName)})
+				continue
+			}
+
+			if kids == nil && silverErr == nil {
+				kids, silverErr = loadSilverKids(filepath.Join(outputDir, weekmanager.OutputFile("kids_analysis", week.Week)))
+			}
+			if silverErr != nil {
+				plan.Skipped = append(plan.Skipped, SkippedReport{Week: week.Week, ProfileID: report.ProfileID, Reason: silverErr.Error()})
+				continue
+			}
+			kidMap, ok := kids[report.ProfileID]
+			if !ok {
+				plan.Skipped = append(plan.Skipped, SkippedReport{Week: week.Week, ProfileID: report.ProfileID, Reason: "not in Silver output"})
+				continue
+			}
+
+			preview, err := gl.previewPrompt(kidMap, week.Label)
+			if err != nil {
+				plan.Skipped = append(plan.Skipped, SkippedReport{Week: week.Week, ProfileID: report.ProfileID, Reason: err.Error()})
+				continue
+			}
+
+			item := RegenerationItem{
+				Week:             week.Week,
+				WeekLabel:        week.Label,
+				ReportPath:       week.Path,
+				ProfileID:        report.ProfileID,
+				ChildName:        report.ChildName,
+				TemplateHash:     week.Hashes[i],
+				EstimatedCostUSD: preview.EstimatedCostUSD,
+				kidMap:           kidMap,
+			}
+			plan.Items = append(plan.Items, item)
+			plan.EstimatedCostUSD += item.EstimatedCostUSD
+		}
+	}
+
+	return plan, nil
+}
+
+// Format renders the plan for the terminal
+func (p *RegenerationPlan) Format() string {
+	var b strings.Builder
+	b.WriteString("Template versions in the report store (oldest first):\n")
+	for _, version := range p.Versions {
+		marker := ""
+		if version.Hash == p.OlderThan {
+			marker = "  <- target"
+		}
+		fmt.Fprintf(&b, "  %-14s first seen %s  %5d reports%s\n", version.Hash, version.FirstSeen.Format("2006-01-02"), version.Reports, marker)
+	}
+	if !p.OlderThanSeen {
+		fmt.Fprintf(&b, "  (no stored report uses %s yet; every version above counts as older)\n", p.OlderThan)
+	}
+
+	weeks := make(map[string]int)
+	for _, item := range p.Items {
+		weeks[item.Week]++
+	}
+	fmt.Fprintf(&b, "\nReports to regenerate: %d across %d weeks\n", len(p.Items), len(weeks))
+	keys := make([]string, 0, len(weeks))
+	for week := range weeks {
+		keys = append(keys, week)
+	}
+	sort.Strings(keys)
+	for _, week := range keys {
+		fmt.Fprintf(&b, "  week %s %5d reports\n", week, weeks[week])
+	}
+	if len(p.Skipped) > 0 {
+		fmt.Fprintf(&b, "Skipped (cannot regenerate): %d\n", len(p.Skipped))
+		for _, skipped := range p.Skipped {
+			fmt.Fprintf(&b, "  week %s %s: %s\n", skipped.Week, skipped.ProfileID, skipped.Reason)
+		}
+	}
+	fmt.Fprintf(&b, "Max cost: ~$%.4f\n", p.EstimatedCostUSD)
+	return b.String()
+}
+
+// Regenerate regenerates the planned reports in batches of batchSize. Each batch is written back
+// into its week's report file before the next one starts, so an interrupted run keeps finished batches.
+func (gl *GoldLayer) Regenerate(ctx context.Context, plan *RegenerationPlan, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+
+	regenerated := 0
+	for start := 0; start < len(plan.Items); {
+		// A batch never spans two week files
+		end := start + 1
+		for end < len(plan.Items) && end-start < batchSize && plan.Items[end].ReportPath == plan.Items[start].ReportPath {
+			end++
+		}
+		batch := plan.Items[start:end]
+		start = end
+
+		if ctx.Err() != nil {
+			return regenerated, ctx.Err()
+		}
+		if gl.softStopped() {
+			gl.logger.Warnf("⏰ Run deadline reached: %d/%d reports regenerated", regenerated, len(plan.Items))
+			return regenerated, ErrSoftStopped
+		}
+
+		gl.logger.Infof("🔄 Regenerating week %s (%s): %d reports", batch[0].Week, batch[0].WeekLabel, len(batch))
+		costBefore := gl.estimatedCost()
+		var reports []AIReport
+		for _, item := range batch {
+			report, err := gl.GenerateReport(ctx, item.kidMap, item.WeekLabel)
+			if err != nil {
+				gl.logger.Errorf("   ❌ Failed to regenerate report for %s: %v", item.ChildName, err)
+				continue
+			}
+			reports = append(reports, *report)
+		}
+		if len(reports) == 0 {
+			continue
+		}
+
+		if err := gl.mergeReports(batch[0].ReportPath, reports, gl.estimatedCost()-costBefore); err != nil {
+			return regenerated, err
+		}
+		regenerated += len(reports)
+		gl.logger.Infof("   ✅ Batch saved: %d/%d reports regenerated", regenerated, len(plan.Items))
+	}
+	return regenerated, nil
+}
+
+// mergeReports replaces reports (matched by profile ID) in a stored week output and records the regeneration
+func (gl *GoldLayer) mergeReports(path string, reports []AIReport, costUSD float64) error {
+	resolved, err := fileio.ResolvePath(path)
+	if err != nil {
+		return err
+	}
+	data, err := fileio.ReadFile(resolved)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", resolved, err)
+	}
+
+	var output map[string]interface{}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", resolved, err)
+	}
+	var stored reportOutput
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", resolved, err)
+	}
+
+	byProfile := make(map[string]AIReport, len(reports))
+	for _, report := range reports {
+		byProfile[report.ProfileID] = report
+	}
+	for i, report := range stored.Reports {
+		if replacement, ok := byProfile[report.ProfileID]; ok && report.ProfileID != "" {
+			stored.Reports[i] = replacement
+		}
+	}
+	output["reports"] = stored.Reports
+
+	templateHash := ""
+	if gl.metadata != nil {
+		templateHash = gl.metadata.TemplateHash
+	}
+	history, _ := output["regenerations"].([]interface{})
+	output["regenerations"] = append(history, map[string]interface{}{
+		"generated_at":       time.Now().Format(time.RFC3339),
+		"template_hash":      templateHash,
+		"reports":            len(reports),
+		"estimated_cost_usd": costUSD,
+	})
+
+	data, err = json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal reports: %w", err)
+	}
+
+	// Keep the file's existing compression so no stale variant is left next to it
+	if _, err := fileio.WriteFile(path, data, compressionOf(resolved)); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	return nil
+}
+
+// compressionOf returns the compression format of a resolved output path, from its extension
+func compressionOf(resolved string) string {
+	switch filepath.Ext(resolved) {
+	case ".gz":
+		return fileio.CompressionGzip
+	case ".zst":
+		return fileio.CompressionZstd
+	}
+	return fileio.CompressionNone
+}
+
+// scanReportStore loads every complete week's Gold output in outputDir, oldest week first
+func scanReportStore(outputDir string) ([]storedReports, error) {
+	matches, err := filepath.Glob(filepath.Join(outputDir, "kids_reports_week_*.json*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", outputDir, err)
+	}
+
+	seen := make(map[string]bool)
+	var weeks []storedReports
+	for _, match := range matches {
+		path := strings.TrimSuffix(strings.TrimSuffix(match, ".gz"), ".zst")
+		week := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "kids_reports_week_"), ".json")
+		if _, err := weekmanager.ParseKey(week); err != nil {
+			continue // Partial (week-to-date) outputs and other files
+		}
+		if filepath.Base(path) != weekmanager.OutputFile("kids_reports", week) || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		data, err := fileio.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var output reportOutput
+		if err := json.Unmarshal(data, &output); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		stored := storedReports{Week: week, Path: path, Label: output.Week, Output: output}
+		for _, report := range output.Reports {
+			hash := report.TemplateHash
+			if hash == "" && output.Metadata != nil {
+				hash = output.Metadata.TemplateHash
+			}
+			if hash == "" {
+				hash = unknownTemplate
+			}
+			stored.Hashes = append(stored.Hashes, hash)
+		}
+		weeks = append(weeks, stored)
+	}
+
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].Week < weeks[j].Week })
+	return weeks, nil
+}
+
+// templateVersions orders the template hashes in the store by when they were first used
+func templateVersions(weeks []storedReports) []TemplateVersion {
+	byHash := make(map[string]*TemplateVersion)
+	for _, week := range weeks {
+		for i, report := range week.Output.Reports {
+			hash := week.Hashes[i]
+			version, ok := byHash[hash]
+			if !ok {
+				version = &TemplateVersion{Hash: hash}
+				byHash[hash] = version
+			}
+			version.Reports++
+			generatedAt, err := time.Parse(time.RFC3339, report.GeneratedAt)
+			if err == nil && (version.FirstSeen.IsZero() || generatedAt.Before(version.FirstSeen)) {
+				version.FirstSeen = generatedAt
+			}
+		}
+	}
+
+	versions := make([]TemplateVersion, 0, len(byHash))
+	for _, version := range byHash {
+		versions = append(versions, *version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		// Reports without a hash predate hash tracking
+		if (versions[i].Hash == unknownTemplate) != (versions[j].Hash == unknownTemplate) {
+			return versions[i].Hash == unknownTemplate
+		}
+		if !versions[i].FirstSeen.Equal(versions[j].FirstSeen) {
+			return versions[i].FirstSeen.Before(versions[j].FirstSeen)
+		}
+		return versions[i].Hash < versions[j].Hash
+	})
+	return versions
+}
+
+// loadSilverKids reads a Silver V3 output and indexes its kid entries by profile ID
+func loadSilverKids(path string) (map[string]map[string]interface{}, error) {
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read silver output: %w", err)
+	}
+
+	var silverData map[string]interface{}
+	if err := json.Unmarshal(data, &silverData); err != nil {
+		return nil, fmt.Errorf("failed to parse silver output: %w", err)
+	}
+	entries, ok := silverData["kids"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid silver output format: missing 'kids' array")
+	}
+
+	kids := make(map[string]map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		if kidMap, ok := entry.(map[string]interface{}); ok {
+			kids[getString(kidMap, "profile_id")] = kidMap
+		}
+	}
+	return kids, nil
+}
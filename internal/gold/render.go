@@ -0,0 +1,177 @@
+package gold
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/prompts"
+)
+
+// Rendered report formats (gold.render.formats)
+const (
+	RenderHTML = "html"
+	RenderPDF  = "pdf"
+)
+
+// renderLabels are the headings of the rendered report, per report language
+var renderLabels = map[string]map[string]string{
+	"vi": {
+		"title":       "Báo cáo tài chính tuần",
+		"performance": "Đánh giá",
+		"tendencies":  "Thói quen tài chính",
+		"badge":       "Huy hiệu tuần này",
+		"strengths":   "Điểm mạnh",
+		"risk":        "Điều cần lưu ý",
+		"goals":       "Mục tiêu tuần tới",
+		"suggestions": "Gợi ý cho ba mẹ",
+		"note":        "Ghi chú",
+		"generated":   "Tạo lúc",
+	},
+	"en": {
+		"title":       "Weekly money report",
+		"performance": "How it went",
+		"tendencies":  "Money habits",
+		"badge":       "Badge of the week",
+		"strengths":   "Strengths",
+		"risk":        "Worth working on",
+		"goals":       "Goals for next week",
+		"suggestions": "Suggestions for parents",
+		"note":        "Note",
+		"generated":   "Generated at",
+	},
+}
+
+// ReportRenderer writes parent-readable HTML (and optionally PDF) copies of generated reports
+type ReportRenderer struct {
+	template   *template.Template
+	pdf        bool
+	pdfCommand []string
+}
+
+// renderData is what the HTML template sees for one report
+type renderData struct {
+	Report   AIReport
+	Language string
+	L        map[string]string
+}
+
+// NewReportRenderer parses the HTML template and checks the configured formats
+func NewReportRenderer(cfg config.RenderConfig) (*ReportRenderer, error) {
+	r := &ReportRenderer{}
+	for _, format := range cfg.Formats {
+		switch format {
+		case RenderHTML:
+		case RenderPDF:
+			r.pdf = true
+		default:
+			return nil, fmt.Errorf("gold.render.formats: unknown format %q (html or pdf)", format)
+		}
+	}
+	if r.pdf {
+		r.pdfCommand = strings.Fields(cfg.PDFCommand)
+		if len(r.pdfCommand) == 0 {
+			r.pdfCommand = []string{"wkhtmltopdf", "--quiet"}
+		}
+	}
+
+	text := prompts.DefaultReportHTML
+	if cfg.TemplateFile != "" {
+		data, err := os.ReadFile(cfg.TemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gold.render.template_file: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("report").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report HTML template: %w", err)
+	}
+	r.template = tmpl
+	return r, nil
+}
+
+// RenderDir is the directory next to a week's report file that holds its rendered copies
+// (kids_reports_week_2025-10-06.json.gz → kids_reports_week_2025-10-06/)
+func RenderDir(reportPath string) string {
+	base := filepath.Base(reportPath)
+	if i := strings.Index(base, ".json"); i > 0 {
+		base = base[:i]
+	}
+	return filepath.Join(filepath.Dir(reportPath), base)
+}
+
+// RenderFile renders every report in a week's output into RenderDir(reportPath), one file per kid
+// and format. Failed kids are skipped and returned together; the count is of kids rendered.
+func (r *ReportRenderer) RenderFile(ctx context.Context, reportPath string) (int, error) {
+	data, err := fileio.ReadFile(reportPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read reports: %w", err)
+	}
+	var output reportOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return 0, fmt.Errorf("failed to parse reports: %w", err)
+	}
+
+	dir := RenderDir(reportPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	rendered := 0
+	var failures []string
+	for i, report := range output.Reports {
+		if ctx.Err() != nil {
+			return rendered, ctx.Err()
+		}
+		name := report.ProfileID
+		if name == "" {
+			name = fmt.Sprintf("report_%d", i+1)
+		}
+		if err := r.render(ctx, report, filepath.Join(dir, name)); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		rendered++
+	}
+	if len(failures) > 0 {
+		return rendered, fmt.Errorf("%d reports not rendered: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return rendered, nil
+}
+
+// render writes base.html, and base.pdf when PDF output is on
+func (r *ReportRenderer) render(ctx context.Context, report AIReport, base string) error {
+	language := NormalizeLanguage(report.Language)
+	labels, ok := renderLabels[language]
+	if !ok {
+		language = "vi"
+		labels = renderLabels[language]
+	}
+
+	var buf bytes.Buffer
+	if err := r.template.Execute(&buf, renderData{Report: report, Language: language, L: labels}); err != nil {
+		return fmt.Errorf("template: %w", err)
+	}
+	htmlPath := base + ".html"
+	if err := os.WriteFile(htmlPath, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if !r.pdf {
+		return nil
+	}
+
+	args := append(append([]string{}, r.pdfCommand[1:]...), htmlPath, base+".pdf")
+	if out, err := exec.CommandContext(ctx, r.pdfCommand[0], args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", r.pdfCommand[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
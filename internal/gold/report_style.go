@@ -0,0 +1,118 @@
+package gold
+
+import (
+	"fmt"
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// Report style values (gold.report_style). The "standard"/"encouraging" defaults add no instructions,
+// so the templates behave exactly as written.
+const (
+	VerbosityShort    = "short"
+	VerbosityStandard = "standard"
+	VerbosityDetailed = "detailed"
+
+	ReadingLevelEasy     = "easy"
+	ReadingLevelStandard = "standard"
+	ReadingLevelAdvanced = "advanced"
+
+	ToneEncouraging = "encouraging"
+	ToneNeutral     = "neutral"
+)
+
+// styleInstructions maps each style value to its prompt instruction per language
+var styleInstructions = map[string]map[string]string{
+	VerbosityShort: {
+		"vi": "Báo cáo NGẮN: mỗi summary/description/suggestion tối đa 1 câu; tối đa 2 next_week_goals và 2 parent_suggestions.",
+		"en": "SHORT report: at most 1 sentence per summary/description/suggestion; at most 2 next_week_goals and 2 parent_suggestions.",
+	},
+	VerbosityDetailed: {
+		"vi": "Báo cáo CHI TIẾT: mỗi summary 3–4 câu, giải thích lý do của từng nhận xét bằng dữ liệu của trẻ.",
+		"en": "DETAILED report: 3–4 sentences per summary, explaining each observation with the child's data.",
+	},
+	ReadingLevelEasy: {
+		"vi": "Dùng câu ngắn, từ ngữ đơn giản mà trẻ 6–9 tuổi hiểu được; tránh thuật ngữ tài chính.",
+		"en": "Use short sentences and simple words a 6–9 year old understands; avoid financial jargon.",
+	},
+	ReadingLevelAdvanced: {
+		"vi": "Có thể dùng thuật ngữ tài chính cơ bản (ngân sách, tỷ lệ tiết kiệm) và giải thích ngắn gọn.",
+		"en": "Basic financial terms (budget, savings rate) are fine; explain them briefly.",
+	},
+	ToneNeutral: {
+		"vi": "Giọng văn trung lập, khách quan: nêu sự việc và gợi ý, không khen ngợi hay dùng từ cảm thán.",
+		"en": "Neutral, factual tone: state observations and suggestions without praise or exclamations.",
+	},
+}
+
+// reportStyle renders the configured verbosity, reading level and tone as prompt instructions
+type reportStyle struct {
+	verbosity    string
+	readingLevel string
+	tone         string
+}
+
+// newReportStyle validates the style config, filling in defaults
+func newReportStyle(cfg config.ReportStyleConfig) (reportStyle, error) {
+	style := reportStyle{
+		verbosity:    valueOr(cfg.Verbosity, VerbosityStandard),
+		readingLevel: valueOr(cfg.ReadingLevel, ReadingLevelStandard),
+		tone:         valueOr(cfg.Tone, ToneEncouraging),
+	}
+	switch style.verbosity {
+	case VerbosityShort, VerbosityStandard, VerbosityDetailed:
+	default:
+		return style, fmt.Errorf("gold.report_style.verbosity must be short, standard or detailed, got %q", cfg.Verbosity)
+	}
+	switch style.readingLevel {
+	case ReadingLevelEasy, ReadingLevelStandard, ReadingLevelAdvanced:
+	default:
+		return style, fmt.Errorf("gold.report_style.reading_level must be easy, standard or advanced, got %q", cfg.ReadingLevel)
+	}
+	switch style.tone {
+	case ToneEncouraging, ToneNeutral:
+	default:
+		return style, fmt.Errorf("gold.report_style.tone must be encouraging or neutral, got %q", cfg.Tone)
+	}
+	return style, nil
+}
+
+// maxTokens returns the completion limit for the verbosity (fallback when not configured)
+func (s reportStyle) maxTokens(cfg config.ReportStyleConfig, fallback int) int {
+	if limit := cfg.MaxTokens[s.verbosity]; limit > 0 {
+		return limit
+	}
+	return fallback
+}
+
+// promptBlock returns the style instructions for the prompt ("" for the defaults)
+func (s reportStyle) promptBlock(language string) string {
+	var lines []string
+	for _, value := range []string{s.verbosity, s.readingLevel, s.tone} {
+		instructions, ok := styleInstructions[value]
+		if !ok {
+			continue
+		}
+		instruction, ok := instructions[language]
+		if !ok {
+			instruction = instructions["vi"]
+		}
+		lines = append(lines, "- "+instruction)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	header := "Phong cách báo cáo tuần này:"
+	if language == "en" {
+		header = "Report style for this week:"
+	}
+	return header + "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+func valueOr(value, fallback string) string {
+	if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+		return value
+	}
+	return fallback
+}
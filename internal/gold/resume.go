@@ -0,0 +1,141 @@
+package gold
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/checkpoint"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/silver"
+)
+
+// existingReports holds the reports a previous run of the week already wrote, keyed by profile ID
+type existingReports struct {
+	byProfile map[string]AIReport
+	stale     int // Reports skipped because an older template generated them
+}
+
+// take returns the stored report for a kid and removes it, so each report is reused at most once
+func (e *existingReports) take(profileID string) (AIReport, bool) {
+	if e == nil || profileID == "" {
+		return AIReport{}, false
+	}
+	report, ok := e.byProfile[profileID]
+	if ok {
+		delete(e.byProfile, profileID)
+	}
+	return report, ok
+}
+
+// loadExistingReports reads the week's previous Gold output so a rerun only generates the missing
+// kids. Reports from another template version are left out and regenerated; nil when there is no file,
+// and for a week-to-date output, whose reports are refreshed on every run while the week goes on.
+func (gl *GoldLayer) loadExistingReports(path string) (*existingReports, error) {
+	if !gl.reuseExisting || silver.IsPartialOutputPath(path) {
+		return nil, nil
+	}
+	resolved, err := fileio.ResolvePath(path)
+	if err != nil {
+		return nil, nil // First run of this week
+	}
+	data, err := fileio.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", resolved, err)
+	}
+	var stored reportOutput
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", resolved, err)
+	}
+
+	templateHash := ""
+	if gl.metadata != nil {
+		templateHash = gl.metadata.TemplateHash
+	}
+	existing := &existingReports{byProfile: make(map[string]AIReport, len(stored.Reports))}
+	for _, report := range stored.Reports {
+		if report.ProfileID == "" {
+			continue
+		}
+		version := report.TemplateHash
+		if version == "" && stored.Metadata != nil {
+			version = stored.Metadata.TemplateHash
+		}
+		if templateHash != "" && version != templateHash {
+			existing.stale++
+			continue
+		}
+		existing.byProfile[report.ProfileID] = report
+	}
+
+	gl.logger.Infof("♻️  Found %d existing reports in %s (%d from an older template will be regenerated)",
+		len(existing.byProfile), resolved, existing.stale)
+	return existing, nil
+}
+
+// sourceHash fingerprints a kid's Silver V3 entry, so a report is only reused for the metrics it was
+// generated from. The operator note is left out: reused reports take the current one as it is.
+func sourceHash(kidMap map[string]interface{}) string {
+	fields := make(map[string]interface{}, len(kidMap))
+	for key, value := range kidMap {
+		if key != "operator_note" {
+			fields[key] = value
+		}
+	}
+	// Round-trip through JSON so streamed entries and entries read back from a file hash the same
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return ""
+	}
+	if data, err = json.Marshal(normalized); err != nil {
+		return ""
+	}
+	return buildinfo.ContentHash(data)
+}
+
+// SetCheckpoint records every generated report in store. With resume, reports an interrupted run
+// already checkpointed are reused instead of generated again.
+func (gl *GoldLayer) SetCheckpoint(store *checkpoint.Store, resume bool) {
+	gl.checkpoint = store
+	gl.resumeCheckpoint = resume
+}
+
+// addCheckpointedReports adds the week's checkpointed reports (same template only) to existing
+func (gl *GoldLayer) addCheckpointedReports(existing *existingReports, weekLabel string) (*existingReports, error) {
+	if !gl.resumeCheckpoint {
+		return existing, nil
+	}
+	kids, err := gl.checkpoint.Kids(weekLabel)
+	if err != nil || len(kids) == 0 {
+		return existing, err
+	}
+	if existing == nil {
+		existing = &existingReports{byProfile: make(map[string]AIReport, len(kids))}
+	}
+
+	templateHash := ""
+	if gl.metadata != nil {
+		templateHash = gl.metadata.TemplateHash
+	}
+	added := 0
+	for profileID, data := range kids {
+		var report AIReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			continue
+		}
+		if templateHash != "" && report.TemplateHash != templateHash {
+			existing.stale++
+			continue
+		}
+		if _, ok := existing.byProfile[profileID]; !ok {
+			existing.byProfile[profileID] = report
+			added++
+		}
+	}
+	gl.logger.Infof("🔁 Resuming %s: %d reports recovered from checkpoints", weekLabel, added)
+	return existing, nil
+}
@@ -0,0 +1,136 @@
+package gold
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/checkpoint"
+	"ai-production-pipeline/internal/silver"
+
+	"github.com/sirupsen/logrus"
+)
+
+// storedWeek is a week output a previous run wrote with template "new", except kid-b's report
+const storedWeek = `{
+	"week": "Tuần 1 - Tháng 10/2025",
+	"metadata": {"template_hash": "new"},
+	"reports": [
+		{"profile_id": "kid-a", "child_name": "An", "template_hash": "new"},
+		{"profile_id": "kid-b", "child_name": "Bình", "template_hash": "old"},
+		{"profile_id": "kid-c", "child_name": "Chi"},
+		{"child_name": "No profile"}
+	]
+}`
+
+func newResumeTestLayer(reuse bool) *GoldLayer {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &GoldLayer{logger: logger, reuseExisting: reuse, metadata: &buildinfo.Metadata{TemplateHash: "new"}}
+}
+
+func writeStoredWeek(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(storedWeek), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func profileIDs(existing *existingReports) []string {
+	var ids []string
+	for id := range existing.byProfile {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestLoadExistingReportsKeepsCurrentTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kids_reports_week_2025-10-06.json")
+	writeStoredWeek(t, path)
+
+	existing, err := newResumeTestLayer(true).loadExistingReports(path)
+	if err != nil {
+		t.Fatalf("loadExistingReports: %v", err)
+	}
+	// kid-c has no hash of its own and takes the output's
+	if got := profileIDs(existing); len(got) != 2 || got[0] != "kid-a" || got[1] != "kid-c" {
+		t.Errorf("reused %v, want [kid-a kid-c]", got)
+	}
+	if existing.stale != 1 {
+		t.Errorf("stale = %d, want 1", existing.stale)
+	}
+
+	if _, ok := existing.take("kid-a"); !ok {
+		t.Error("take(kid-a) found nothing")
+	}
+	if _, ok := existing.take("kid-a"); ok {
+		t.Error("kid-a's report reused twice")
+	}
+}
+
+func TestLoadExistingReportsSkipsPartialWeek(t *testing.T) {
+	path := silver.PartialOutputPath(filepath.Join(t.TempDir(), "kids_reports_week_2025-10-06.json"))
+	writeStoredWeek(t, path)
+
+	existing, err := newResumeTestLayer(true).loadExistingReports(path)
+	if err != nil || existing != nil {
+		t.Errorf("loadExistingReports on a week-to-date output = %v, %v; want nothing reused", existing, err)
+	}
+}
+
+func TestLoadExistingReportsOff(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kids_reports_week_2025-10-06.json")
+	writeStoredWeek(t, path)
+
+	if existing, err := newResumeTestLayer(false).loadExistingReports(path); err != nil || existing != nil {
+		t.Errorf("with reuse_existing off = %v, %v", existing, err)
+	}
+	missing := filepath.Join(dir, "kids_reports_week_2025-10-13.json")
+	if existing, err := newResumeTestLayer(true).loadExistingReports(missing); err != nil || existing != nil {
+		t.Errorf("first run of a week = %v, %v", existing, err)
+	}
+}
+
+func TestAddCheckpointedReports(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kids_reports_week_2025-10-06.json")
+	writeStoredWeek(t, path)
+
+	gl := newResumeTestLayer(true)
+	store, err := checkpoint.NewStore(filepath.Join(dir, "checkpoints"), gl.logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	week := "Tuần 1 - Tháng 10/2025"
+	store.AppendKid(week, "kid-a", AIReport{ProfileID: "kid-a", ChildName: "An (checkpoint)", TemplateHash: "new"})
+	store.AppendKid(week, "kid-d", AIReport{ProfileID: "kid-d", ChildName: "Dũng", TemplateHash: "new"})
+	store.AppendKid(week, "kid-e", AIReport{ProfileID: "kid-e", ChildName: "Em", TemplateHash: "old"})
+
+	// Without --resume the checkpoints are not read
+	gl.SetCheckpoint(store, false)
+	existing, _ := gl.loadExistingReports(path)
+	if existing, _ = gl.addCheckpointedReports(existing, week); len(existing.byProfile) != 2 {
+		t.Errorf("without resume reused %v", profileIDs(existing))
+	}
+
+	gl.SetCheckpoint(store, true)
+	existing, _ = gl.loadExistingReports(path)
+	existing, err = gl.addCheckpointedReports(existing, week)
+	if err != nil {
+		t.Fatalf("addCheckpointedReports: %v", err)
+	}
+	if got := profileIDs(existing); len(got) != 3 || got[2] != "kid-d" {
+		t.Errorf("reused %v, want [kid-a kid-c kid-d]", got)
+	}
+	if report, _ := existing.take("kid-a"); report.ChildName != "An" {
+		t.Errorf("kid-a = %q, want the stored report to win over its checkpoint", report.ChildName)
+	}
+	if existing.stale != 2 {
+		t.Errorf("stale = %d, want 2", existing.stale)
+	}
+}
@@ -0,0 +1,147 @@
+package gold
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"ai-production-pipeline/internal/fileio"
+)
+
+// suggestionHistory holds parent suggestions from previous weeks' reports, newest week first
+type suggestionHistory struct {
+	byKid map[string][][]string // profile ID (or child name for old reports) -> per-week suggestions
+}
+
+// LoadSuggestionHistory reads parent suggestions from earlier weeks' report files (newest first).
+// Missing files are skipped so the first weeks of a run work without history.
+func (gl *GoldLayer) LoadSuggestionHistory(reportPaths ...string) error {
+	history := &suggestionHistory{byKid: make(map[string][][]string)}
+	loaded := 0
+
+	for weekIndex, path := range reportPaths {
+		if _, err := fileio.ResolvePath(path); err != nil {
+			continue
+		}
+		data, err := fileio.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read report history %s: %w", path, err)
+		}
+		var output reportOutput
+		if err := json.Unmarshal(data, &output); err != nil {
+			return fmt.Errorf("failed to parse report history %s: %w", path, err)
+		}
+
+		for _, report := range output.Reports {
+			key := historyKey(report.ProfileID, report.ChildName)
+			weeks := history.byKid[key]
+			for len(weeks) <= weekIndex {
+				weeks = append(weeks, nil)
+			}
+			weeks[weekIndex] = report.ParentSuggestions
+			history.byKid[key] = weeks
+		}
+		loaded++
+	}
+
+	gl.suggestions = history
+	if loaded > 0 {
+		gl.logger.Infof("🗂️  Loaded parent suggestion history from %d previous week(s)", loaded)
+	}
+	return nil
+}
+
+// previous returns a kid's suggestions from earlier weeks (flattened, newest first)
+func (h *suggestionHistory) previous(kid KidDataV2) []string {
+	if h == nil {
+		return nil
+	}
+	weeks, ok := h.byKid[historyKey(kid.ProfileID, kid.Nickname)]
+	if !ok {
+		weeks = h.byKid[historyKey("", kid.Nickname)]
+	}
+	var all []string
+	for _, week := range weeks {
+		all = append(all, week...)
+	}
+	return all
+}
+
+// historyKey prefers the profile ID; reports written before profile IDs were recorded match by name
+func historyKey(profileID, childName string) string {
+	if profileID != "" {
+		return "id:" + profileID
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(childName))
+}
+
+// suggestionOverlap returns the share (0-1) of suggestions similar to a previous one
+func suggestionOverlap(suggestions, previous []string, similarityThreshold float64) float64 {
+	if len(suggestions) == 0 || len(previous) == 0 {
+		return 0
+	}
+	previousWords := make([]map[string]bool, len(previous))
+	for i, p := range previous {
+		previousWords[i] = wordSet(p)
+	}
+
+	repeated := 0
+	for _, s := range suggestions {
+		words := wordSet(s)
+		for _, p := range previousWords {
+			if jaccard(words, p) >= similarityThreshold {
+				repeated++
+				break
+			}
+		}
+	}
+	return float64(repeated) / float64(len(suggestions))
+}
+
+// wordSet splits text into lowercase words
+func wordSet(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// jaccard returns |a∩b| / |a∪b|
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	intersection := 0
+	for w := range a {
+		if b[w] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// previousSuggestionsBlock lists earlier suggestions for the prompt ("" when there are none)
+func previousSuggestionsBlock(previous []string, language string) string {
+	if len(previous) == 0 {
+		return ""
+	}
+	header := "Các gợi ý cho phụ huynh đã gợi ý trước đây (KHÔNG lặp lại, hãy đưa ra gợi ý mới):"
+	if language == "en" {
+		header = "Parent suggestions already given in previous weeks (do NOT repeat them, give new ones):"
+	}
+	return header + "\n- " + strings.Join(previous, "\n- ") + "\n"
+}
+
+// repeatedSuggestionsInstruction is appended to the prompt when suggestions repeat earlier weeks
+func repeatedSuggestionsInstruction(overlap float64, language string) string {
+	if language == "en" {
+		return fmt.Sprintf("\n\nIMPORTANT: %.0f%% of parent_suggestions repeat previous weeks. "+
+			"Write new parent_suggestions that differ from the ones already given.", overlap*100)
+	}
+	return fmt.Sprintf("\n\nLƯU Ý QUAN TRỌNG: %.0f%% gợi ý cho phụ huynh bị lặp lại so với các tuần trước. "+
+		"Hãy viết parent_suggestions mới, khác với các gợi ý đã đưa ra trước đây.", overlap*100)
+}
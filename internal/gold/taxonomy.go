@@ -0,0 +1,114 @@
+package gold
+
+import (
+	"fmt"
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sectionTaxonomy maps free-form AI section titles and levels to enumerated, localized values
+type sectionTaxonomy struct {
+	cfg             config.SectionTaxonomyConfig
+	defaultLanguage string
+	byTitle         map[string]config.TaxonomySection // Normalized title or alias (any language) → section
+}
+
+// newSectionTaxonomy returns nil when the taxonomy is disabled or empty
+func newSectionTaxonomy(cfg config.SectionTaxonomyConfig, defaultLanguage string) *sectionTaxonomy {
+	if !cfg.Enabled || (len(cfg.Sections) == 0 && len(cfg.Levels) == 0) {
+		return nil
+	}
+	t := &sectionTaxonomy{
+		cfg:             cfg,
+		defaultLanguage: defaultLanguage,
+		byTitle:         make(map[string]config.TaxonomySection),
+	}
+	for _, section := range cfg.Sections {
+		for _, title := range section.Titles {
+			t.byTitle[normalizeTitle(title)] = section
+		}
+		for _, alias := range section.Aliases {
+			t.byTitle[normalizeTitle(alias)] = section
+		}
+	}
+	return t
+}
+
+// NormalizeTitles replaces recognized section titles with the canonical title for the report
+// language and sets Key. Unknown sections keep their title (or are dropped if configured).
+func (t *sectionTaxonomy) NormalizeTitles(report *AIReport, logger *logrus.Logger) {
+	if t == nil || len(t.cfg.Sections) == 0 {
+		return
+	}
+
+	sections := report.PerformanceSections[:0]
+	for _, section := range report.PerformanceSections {
+		entry, ok := t.byTitle[normalizeTitle(section.Title)]
+		if !ok {
+			if t.cfg.DropUnknownSections {
+				logger.Warnf("   ⚠️  %s: dropped section with unknown title %q", report.ChildName, section.Title)
+				continue
+			}
+			logger.Warnf("   ⚠️  %s: section title %q is not in the taxonomy", report.ChildName, section.Title)
+			sections = append(sections, section)
+			continue
+		}
+		section.Key = entry.Key
+		section.Title = t.localized(entry.Titles, report.Language, entry.Key)
+		sections = append(sections, section)
+	}
+	report.PerformanceSections = sections
+}
+
+// AssignLevels sets Level and LevelKey from each section's final score, so level and score agree
+// even after calibration. Sections whose score falls outside every range keep the AI's level.
+func (t *sectionTaxonomy) AssignLevels(report *AIReport) {
+	if t == nil || len(t.cfg.Levels) == 0 {
+		return
+	}
+	for i := range report.PerformanceSections {
+		section := &report.PerformanceSections[i]
+		for _, level := range t.cfg.Levels {
+			if section.Score >= level.MinScore && section.Score <= level.MaxScore {
+				section.LevelKey = level.Key
+				section.Level = t.localized(level.Labels, report.Language, level.Key)
+				break
+			}
+		}
+	}
+}
+
+// promptBlock lists the allowed titles for the prompt ("" when there are no sections)
+func (t *sectionTaxonomy) promptBlock(language string) string {
+	if t == nil || len(t.cfg.Sections) == 0 {
+		return ""
+	}
+	titles := make([]string, 0, len(t.cfg.Sections))
+	for _, section := range t.cfg.Sections {
+		titles = append(titles, fmt.Sprintf("%q", t.localized(section.Titles, language, section.Key)))
+	}
+	if language == "en" {
+		return "Use exactly these performance section titles: " + strings.Join(titles, ", ") + "\n"
+	}
+	return "Dùng chính xác các tiêu đề performance_sections sau: " + strings.Join(titles, ", ") + "\n"
+}
+
+// localized returns the value for language, falling back to the default language, then the key
+func (t *sectionTaxonomy) localized(values map[string]string, language, key string) string {
+	if v := values[language]; v != "" {
+		return v
+	}
+	if v := values[t.defaultLanguage]; v != "" {
+		return v
+	}
+	return key
+}
+
+// normalizeTitle makes titles that differ only in case, spacing or trailing punctuation compare equal
+func normalizeTitle(title string) string {
+	title = strings.ToLower(strings.Join(strings.Fields(title), " "))
+	return strings.TrimRight(title, ".:!")
+}
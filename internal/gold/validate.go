@@ -0,0 +1,36 @@
+package gold
+
+import (
+	"fmt"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// ValidateConfig loads everything Gold reads from config (templates, campaign notes, taxonomy,
+// optional sections, report style, digest templates) without an API key
+func ValidateConfig(cfg *config.Config) error {
+	if cfg.Prompts.DBTable != "" && !promptTableName.MatchString(cfg.Prompts.DBTable) {
+		return fmt.Errorf("invalid prompts.db_table %q", cfg.Prompts.DBTable)
+	}
+	if _, _, err := cfg.OpenAI.ResponseCache.Settings(); err != nil {
+		return err
+	}
+	if _, err := FaultConfig(cfg.OpenAI.FaultInjection); err != nil {
+		return err
+	}
+	if _, err := newCostBudget(cfg.Gold.CostBudget, cfg.OpenAI, nil); err != nil {
+		return err
+	}
+	gl, err := newOfflineLayer(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := loadParentDigester(cfg.Gold.ParentDigest, gl.defaultLanguage); err != nil {
+		return err
+	}
+	if _, err := loadFamilyReporter(cfg.Gold.FamilyReport, gl.defaultLanguage); err != nil {
+		return err
+	}
+	_, err = loadKidVersioner(cfg.Gold.KidVersion, gl.defaultLanguage)
+	return err
+}
@@ -0,0 +1,145 @@
+package gold
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// Week types every calendar has; others (exam, ...) come from calendar.week_types
+const (
+	WeekTypeNormal  = "normal"
+	WeekTypeHoliday = "holiday" // Weeks listed in calendar.holiday_weeks
+)
+
+var weekTypeName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// weekTypes picks a week's type from the school calendar and the prompts configured for that type
+type weekTypes struct {
+	order   []string                             // Configured types sorted, then holiday: the first match wins
+	dates   map[string][]time.Time               // Type → any date inside each week of that type
+	prompts map[string]map[string]weekTypePrompt // Type → language → prompt overrides
+}
+
+// weekTypePrompt replaces a language's template and/or system message ("" keeps the normal one)
+type weekTypePrompt struct {
+	template      string
+	systemMessage string
+}
+
+// loadWeekTypes parses calendar.week_types and loads prompts.week_types for the loaded languages
+func loadWeekTypes(cfg *config.Config, sets map[string]promptSet, defaultLanguage string) (*weekTypes, error) {
+	wt := &weekTypes{dates: map[string][]time.Time{}, prompts: map[string]map[string]weekTypePrompt{}}
+
+	addDates := func(name string, entries []string, key string) error {
+		for _, entry := range entries {
+			date, err := time.Parse("2006-01-02", entry)
+			if err != nil {
+				return fmt.Errorf("invalid %s entry %q: %w", key, entry, err)
+			}
+			wt.dates[name] = append(wt.dates[name], date)
+		}
+		return nil
+	}
+	for name, entries := range cfg.Calendar.WeekTypes {
+		if !weekTypeName.MatchString(name) || name == WeekTypeNormal || name == WeekTypeHoliday {
+			return nil, fmt.Errorf("invalid calendar.week_types name %q (lowercase, not normal or holiday)", name)
+		}
+		if err := addDates(name, entries, "calendar.week_types."+name); err != nil {
+			return nil, err
+		}
+		wt.order = append(wt.order, name)
+	}
+	sort.Strings(wt.order)
+	if err := addDates(WeekTypeHoliday, cfg.Calendar.HolidayWeeks, "calendar.holiday_weeks"); err != nil {
+		return nil, err
+	}
+	wt.order = append(wt.order, WeekTypeHoliday)
+
+	for name, files := range cfg.Prompts.WeekTypes {
+		if _, ok := cfg.Calendar.WeekTypes[name]; !ok && name != WeekTypeHoliday {
+			return nil, fmt.Errorf("prompts.week_types.%s: no such week type in calendar.week_types", name)
+		}
+		byLanguage := map[string]config.LanguagePromptConfig{
+			defaultLanguage: {TemplateFile: files.TemplateFile, SystemMessageFile: files.SystemMessageFile},
+		}
+		for lang, langFiles := range files.Languages {
+			byLanguage[NormalizeLanguage(lang)] = langFiles
+		}
+
+		wt.prompts[name] = map[string]weekTypePrompt{}
+		for lang, langFiles := range byLanguage {
+			if _, ok := sets[lang]; !ok {
+				continue // Kids of languages without normal prompts get default-language reports
+			}
+			var override weekTypePrompt
+			var err error
+			if langFiles.TemplateFile != "" {
+				if override.template, _, err = loadPromptText(langFiles.TemplateFile, "", ""); err != nil {
+					return nil, fmt.Errorf("failed to load prompts.week_types.%s %s template: %w", name, lang, err)
+				}
+				if err := checkPromptTemplate(override.template, langFiles.TemplateFile); err != nil {
+					return nil, err
+				}
+			}
+			if langFiles.SystemMessageFile != "" {
+				if override.systemMessage, _, err = loadPromptText(langFiles.SystemMessageFile, "", ""); err != nil {
+					return nil, fmt.Errorf("failed to load prompts.week_types.%s %s system message: %w", name, lang, err)
+				}
+			}
+			wt.prompts[name][lang] = override
+		}
+	}
+	return wt, nil
+}
+
+// typeOf returns the type of the week [start, end) (dates as Silver writes them, end exclusive)
+func (wt *weekTypes) typeOf(start, end string) string {
+	if wt == nil {
+		return WeekTypeNormal
+	}
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return WeekTypeNormal
+	}
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return WeekTypeNormal
+	}
+	for _, name := range wt.order {
+		for _, date := range wt.dates[name] {
+			if !date.Before(startDate) && date.Before(endDate) {
+				return name
+			}
+		}
+	}
+	return WeekTypeNormal
+}
+
+// apply swaps in the week type's template and system message for a language, where configured
+func (wt *weekTypes) apply(set promptSet, weekType, language string) promptSet {
+	if wt == nil {
+		return set
+	}
+	override := wt.prompts[weekType][language]
+	if override.template != "" {
+		set.template = override.template
+	}
+	if override.systemMessage != "" {
+		set.systemMessage = override.systemMessage
+	}
+	return set
+}
+
+// promptSetFor returns the template and system message for a kid's language and week type
+func (gl *GoldLayer) promptSetFor(kid KidDataV2) promptSet {
+	language := gl.reportLanguage(kid)
+	set, ok := gl.prompts[language]
+	if !ok {
+		set = promptSet{template: gl.promptTemplate, systemMessage: gl.systemMessage}
+	}
+	return gl.weekTypes.apply(set, kid.WeekType, language)
+}
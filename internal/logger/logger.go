@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
-	"time"
+
+	"ai-production-pipeline/internal/redact"
 
 	"github.com/sirupsen/logrus"
 )
@@ -18,11 +18,13 @@ type LoggingConfig struct {
 	Output    string
 	LogToFile bool
 	LogDir    string
+	Rotation  RotationConfig
 }
 
 // InitLogger initializes the global logger
 func InitLogger(cfg *LoggingConfig) error {
 	globalLogger = logrus.New()
+	globalLogger.AddHook(redact.NewHook())
 
 	// Set log level
 	level, err := logrus.ParseLevel(cfg.Level)
@@ -33,16 +35,12 @@ func InitLogger(cfg *LoggingConfig) error {
 
 	// Configure output
 	if cfg.LogToFile && cfg.LogDir != "" {
-		if err := os.MkdirAll(cfg.LogDir, 0755); err != nil {
-			return fmt.Errorf("failed to create log directory: %w", err)
-		}
-
-		timestamp := time.Now().Format("20060102_150405")
-		logFile := filepath.Join(cfg.LogDir, fmt.Sprintf("pipeline_%s.log", timestamp))
+		rotation := cfg.Rotation
+		rotation.Dir = cfg.LogDir
 
-		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		file, err := NewRotatingWriter(rotation)
 		if err != nil {
-			return fmt.Errorf("failed to create log file: %w", err)
+			return err
 		}
 
 		// Log to both console and file
@@ -69,6 +67,7 @@ func GetLogger() *logrus.Logger {
 	if globalLogger == nil {
 		// Create default logger if not initialized
 		globalLogger = logrus.New()
+		globalLogger.AddHook(redact.NewHook())
 		globalLogger.SetLevel(logrus.InfoLevel)
 		globalLogger.SetFormatter(&logrus.TextFormatter{
 			FullTimestamp:   true,
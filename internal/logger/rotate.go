@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotationConfig holds log file rotation settings
+type RotationConfig struct {
+	Dir        string
+	Prefix     string        // File name prefix, e.g. "pipeline"
+	MaxSizeMB  int           // Rotate when the current file exceeds this size (0 = no size limit)
+	MaxAge     time.Duration // Rotate when the current file is older than this (0 = no time limit)
+	MaxBackups int           // Number of rotated files to keep (0 = keep all)
+	Compress   bool          // Gzip rotated files
+}
+
+// RotatingWriter is a concurrency-safe io.Writer that rotates log files by size and age.
+// Each Write call is written under a single lock, so log lines never interleave.
+type RotatingWriter struct {
+	mu       sync.Mutex
+	cfg      RotationConfig
+	file     *os.File
+	path     string
+	size     int64
+	openedAt time.Time
+}
+
+// NewRotatingWriter creates the log directory and opens the first log file
+func NewRotatingWriter(cfg RotationConfig) (*RotatingWriter, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "pipeline"
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	w := &RotatingWriter{cfg: cfg}
+	if err := w.openNew(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Path returns the path of the file currently being written
+func (w *RotatingWriter) Path() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.path
+}
+
+// Write writes p atomically, rotating the file first if a limit was reached
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// shouldRotate checks size and age limits before writing n more bytes
+func (w *RotatingWriter) shouldRotate(n int64) bool {
+	if w.cfg.MaxSizeMB > 0 && w.size > 0 && w.size+n > int64(w.cfg.MaxSizeMB)*1024*1024 {
+		return true
+	}
+	if w.cfg.MaxAge > 0 && time.Since(w.openedAt) >= w.cfg.MaxAge {
+		return true
+	}
+	return false
+}
+
+// rotate closes the current file, compresses it if configured, and opens a new one
+func (w *RotatingWriter) rotate() error {
+	oldPath := w.path
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	if err := w.openNew(); err != nil {
+		return err
+	}
+
+	if w.cfg.Compress {
+		if err := compressFile(oldPath); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to compress rotated log %s: %v\n", oldPath, err)
+		}
+	}
+
+	w.removeOldBackups()
+	return nil
+}
+
+// openNew opens a fresh timestamped log file
+func (w *RotatingWriter) openNew() error {
+	base := fmt.Sprintf("%s_%s", w.cfg.Prefix, time.Now().Format("20060102_150405"))
+	path := filepath.Join(w.cfg.Dir, base+".log")
+	for i := 1; fileExists(path) || fileExists(path+".gz"); i++ {
+		path = filepath.Join(w.cfg.Dir, fmt.Sprintf("%s_%d.log", base, i))
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	w.file = file
+	w.path = path
+	w.size = 0
+	w.openedAt = time.Now()
+	return nil
+}
+
+// removeOldBackups deletes the oldest rotated files beyond MaxBackups
+func (w *RotatingWriter) removeOldBackups() {
+	if w.cfg.MaxBackups <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(filepath.Join(w.cfg.Dir, w.cfg.Prefix+"_*.log*"))
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, m := range matches {
+		if m != w.path {
+			backups = append(backups, m)
+		}
+	}
+	if len(backups) <= w.cfg.MaxBackups {
+		return
+	}
+
+	// Oldest first by modification time
+	sort.Slice(backups, func(i, j int) bool {
+		return modTime(backups[i]).Before(modTime(backups[j]))
+	})
+	for _, old := range backups[:len(backups)-w.cfg.MaxBackups] {
+		os.Remove(old)
+	}
+}
+
+// compressFile gzips path into path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	gz.Name = strings.TrimSuffix(filepath.Base(path), ".gz")
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,215 @@
+package monthly
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/silver"
+)
+
+// KidMonth is one kid's month rolled up from their weekly Silver metrics
+type KidMonth struct {
+	ProfileID string `json:"profile_id"`
+	Nickname  string `json:"nickname"`
+	Age       *int   `json:"age"`
+	Language  string `json:"language,omitempty"`
+	ParentID  string `json:"parent_id,omitempty"`
+
+	Weeks      int      `json:"weeks"` // Weeks of the month the kid has data for
+	WeekLabels []string `json:"week_labels"`
+
+	// Totals across the month's weeks
+	MoneyReceived      float64            `json:"money_received"`
+	TotalSpent         float64            `json:"total_spent"`
+	JoySpent           float64            `json:"joy_spent"`
+	SpendingSpent      float64            `json:"spending_spent"`
+	CharitySpent       float64            `json:"charity_spent"`
+	StudySpent         float64            `json:"study_spent"`
+	SpendingByCategory map[string]float64 `json:"spending_by_category,omitempty"`
+	MissionsTotal      int                `json:"missions_total"`
+	MissionsCompleted  int                `json:"missions_completed"`
+	TransactionCount   int                `json:"transaction_count"`
+	ActiveDays         int                `json:"active_days"`
+
+	AvgCompletionRate float64 `json:"avg_completion_rate"` // Mean of the weekly completion rates
+	EndBalance        float64 `json:"end_balance"`         // Total balance at the end of the month's last week
+
+	Trends *MonthTrends `json:"trends,omitempty"` // Compared with the previous month (nil without one)
+}
+
+// MonthTrends compares a kid's month with the previous month
+type MonthTrends struct {
+	PreviousMonth string `json:"previous_month"`
+
+	IncomeChangePercent   float64 `json:"income_change_percent"`
+	SpendingChangePercent float64 `json:"spending_change_percent"`
+	SpendingTrend         string  `json:"spending_trend"` // increasing, decreasing, stable
+
+	CompletionRateChange float64 `json:"completion_rate_change"` // Percentage points
+	CompletionTrend      string  `json:"completion_trend"`       // improving, declining, stable
+
+	BalanceChangePercent float64 `json:"balance_change_percent"`
+	BalanceTrend         string  `json:"balance_trend"` // increasing, decreasing, stable
+
+	ActiveDaysChange int `json:"active_days_change"`
+}
+
+// Output is a month's rollup for every kid (kids_monthly_YYYY-MM.json)
+type Output struct {
+	GeneratedAt string              `json:"generated_at"`
+	Month       string              `json:"month"`
+	Weeks       []string            `json:"weeks"`
+	Complete    bool                `json:"complete"` // False while the month is still running
+	TotalKids   int                 `json:"total_kids"`
+	Kids        []KidMonth          `json:"kids"`
+	Metadata    *buildinfo.Metadata `json:"metadata,omitempty"`
+}
+
+// Aggregate rolls a month's weekly Silver outputs (oldest first) up per kid. previous is the previous
+// month's rollup, used for month-over-month trends (nil = no trends).
+func Aggregate(month Month, weeks []*silver.EnhancedOutput, previous *Output) *Output {
+	output := &Output{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Month:       month.Key,
+		Complete:    month.Complete,
+	}
+
+	var order []string
+	byKid := make(map[string]*KidMonth)
+	completion := make(map[string]float64)
+	for _, week := range weeks {
+		output.Weeks = append(output.Weeks, week.Week)
+		for _, kid := range week.Kids {
+			summary, ok := byKid[kid.ProfileID]
+			if !ok {
+				summary = &KidMonth{ProfileID: kid.ProfileID}
+				byKid[kid.ProfileID] = summary
+				order = append(order, kid.ProfileID)
+			}
+			// Profile fields come from the latest week
+			summary.Nickname, summary.Age, summary.Language, summary.ParentID = kid.Nickname, kid.Age, kid.Language, kid.ParentID
+			addWeek(summary, kid.CurrentWeek)
+			completion[kid.ProfileID] += kid.CurrentWeek.CompletionRate
+		}
+	}
+
+	var previousKids map[string]KidMonth
+	if previous != nil {
+		previousKids = make(map[string]KidMonth, len(previous.Kids))
+		for _, kid := range previous.Kids {
+			previousKids[kid.ProfileID] = kid
+		}
+	}
+
+	for _, profileID := range order {
+		summary := byKid[profileID]
+		summary.AvgCompletionRate = round2(completion[profileID] / float64(summary.Weeks))
+		if before, ok := previousKids[profileID]; ok {
+			summary.Trends = compare(summary, &before, previous.Month)
+		}
+		output.Kids = append(output.Kids, *summary)
+	}
+	output.TotalKids = len(output.Kids)
+	return output
+}
+
+// addWeek adds one week's metrics to a kid's month
+func addWeek(summary *KidMonth, week silver.WeekMetrics) {
+	summary.Weeks++
+	summary.WeekLabels = append(summary.WeekLabels, week.WeekLabel)
+	summary.MoneyReceived += week.MoneyReceived
+	summary.TotalSpent += week.TotalSpent
+	summary.JoySpent += week.JoySpent
+	summary.SpendingSpent += week.SpendingSpent
+	summary.CharitySpent += week.CharitySpent
+	summary.StudySpent += week.StudySpent
+	for category, amount := range week.SpendingByCategory {
+		if summary.SpendingByCategory == nil {
+			summary.SpendingByCategory = make(map[string]float64)
+		}
+		summary.SpendingByCategory[category] += amount
+	}
+	summary.MissionsTotal += week.MissionsTotal
+	summary.MissionsCompleted += week.MissionsCompleted
+	summary.TransactionCount += week.TransactionCount
+	summary.ActiveDays += week.ActiveDays
+	summary.EndBalance = week.TotalBalance
+}
+
+// compare computes month-over-month trends, with the same thresholds Silver uses week over week
+func compare(current, previous *KidMonth, previousMonth string) *MonthTrends {
+	trends := &MonthTrends{
+		PreviousMonth:        previousMonth,
+		CompletionRateChange: round2(current.AvgCompletionRate - previous.AvgCompletionRate),
+		ActiveDaysChange:     current.ActiveDays - previous.ActiveDays,
+		SpendingTrend:        "stable",
+		BalanceTrend:         "stable",
+	}
+	if previous.MoneyReceived > 0 {
+		trends.IncomeChangePercent = percentChange(current.MoneyReceived, previous.MoneyReceived)
+	}
+	if previous.TotalSpent > 0 {
+		trends.SpendingChangePercent = percentChange(current.TotalSpent, previous.TotalSpent)
+		if trends.SpendingChangePercent >= 10 {
+			trends.SpendingTrend = "increasing"
+		} else if trends.SpendingChangePercent <= -10 {
+			trends.SpendingTrend = "decreasing"
+		}
+	}
+	if previous.EndBalance > 0 {
+		trends.BalanceChangePercent = percentChange(current.EndBalance, previous.EndBalance)
+		if trends.BalanceChangePercent > 0 {
+			trends.BalanceTrend = "increasing"
+		} else if trends.BalanceChangePercent < 0 {
+			trends.BalanceTrend = "decreasing"
+		}
+	}
+
+	if math.Abs(trends.CompletionRateChange) < 5 {
+		trends.CompletionTrend = "stable"
+	} else if trends.CompletionRateChange > 0 {
+		trends.CompletionTrend = "improving"
+	} else {
+		trends.CompletionTrend = "declining"
+	}
+	return trends
+}
+
+// percentChange returns the change from previous to current in percent, rounded to 2 decimals
+func percentChange(current, previous float64) float64 {
+	return round2((current - previous) / previous * 100)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// LoadWeek reads a week's Silver output (compressed files are detected transparently)
+func LoadWeek(path string) (*silver.EnhancedOutput, error) {
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var week silver.EnhancedOutput
+	if err := json.Unmarshal(data, &week); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &week, nil
+}
+
+// LoadOutput reads a month's rollup written by an earlier run
+func LoadOutput(path string) (*Output, error) {
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var output Output
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &output, nil
+}
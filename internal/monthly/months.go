@@ -0,0 +1,82 @@
+package monthly
+
+import (
+	"time"
+
+	"ai-production-pipeline/internal/weekmanager"
+)
+
+// Month is a calendar month and the weeks that belong to it
+type Month struct {
+	Key      string                  // "2006-01"
+	Weeks    []weekmanager.WeekRange // Oldest first
+	Complete bool                    // The month has ended and none of its weeks is partial
+}
+
+// MonthOf returns the month a week belongs to: the month of its midpoint, so a week spanning two
+// months is counted once, in the month holding most of its days (4-5 weeks per month) whichever
+// day weeks start on
+func MonthOf(week weekmanager.WeekRange) string {
+	return midpoint(week).Format("2006-01")
+}
+
+// midpoint returns the middle of a week; a partial week counts as the full week it will become
+func midpoint(week weekmanager.WeekRange) time.Time {
+	end := week.EndDate
+	if week.IsPartial || !end.After(week.StartDate) {
+		end = week.StartDate.AddDate(0, 0, 7)
+	}
+	return week.StartDate.Add(end.Sub(week.StartDate) / 2)
+}
+
+// GroupWeeks groups weeks by month, oldest month first. A month is complete once the last week
+// whose midpoint falls in it has ended (as of now) and none of its weeks is partial.
+func GroupWeeks(weeks []weekmanager.WeekRange, now time.Time) []Month {
+	var months []Month
+	index := make(map[string]int)
+	for _, week := range weeks {
+		key := MonthOf(week)
+		pos, ok := index[key]
+		if !ok {
+			pos = len(months)
+			index[key] = pos
+			months = append(months, Month{Key: key})
+		}
+		months[pos].Weeks = append(months[pos].Weeks, week)
+	}
+
+	for i := range months {
+		month := &months[i]
+		month.Complete = !now.Before(monthEnd(month.Key, month.Weeks[0].StartDate.Weekday()))
+		for _, week := range month.Weeks {
+			if week.IsPartial {
+				month.Complete = false
+			}
+		}
+	}
+	return months
+}
+
+// monthEnd returns when the last week starting on weekStart whose midpoint falls in the month ends
+func monthEnd(key string, weekStart time.Weekday) time.Time {
+	first, err := time.ParseInLocation("2006-01", key, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	// A week's midpoint is 3.5 days after its start, so the last such week starts 4 or more days
+	// before the next month
+	start := first.AddDate(0, 1, -4)
+	for start.Weekday() != weekStart {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+// PreviousKey returns the key of the month before key ("" when key is invalid)
+func PreviousKey(key string) string {
+	month, err := time.Parse("2006-01", key)
+	if err != nil {
+		return ""
+	}
+	return month.AddDate(0, -1, 0).Format("2006-01")
+}
@@ -0,0 +1,242 @@
+package monthly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/gold"
+	"ai-production-pipeline/internal/outputstore"
+	"ai-production-pipeline/internal/processor"
+
+	"github.com/sirupsen/logrus"
+)
+
+// usageLabel is the token tracking bucket for a month's report calls, so each month's cost is tracked
+// on its own
+func usageLabel(month string) string {
+	return "monthly_" + month
+}
+
+// Report is a kid's monthly AI report
+type Report struct {
+	ProfileID         string   `json:"profile_id"`
+	ParentID          string   `json:"parent_id,omitempty"`
+	ChildName         string   `json:"child_name"`
+	Month             string   `json:"month"`
+	Language          string   `json:"language"`
+	Summary           string   `json:"summary"`
+	Highlights        []string `json:"highlights"`
+	NextMonthGoals    []string `json:"next_month_goals"`
+	ParentSuggestions []string `json:"parent_suggestions"`
+	WeeklyReports     int      `json:"weekly_reports"` // Weekly reports of the month the prompt summarized
+	GeneratedAt       string   `json:"generated_at"`
+}
+
+// reportResponse is what the model returns for one kid
+type reportResponse struct {
+	Summary           string   `json:"summary"`
+	Highlights        []string `json:"highlights"`
+	NextMonthGoals    []string `json:"next_month_goals"`
+	ParentSuggestions []string `json:"parent_suggestions"`
+}
+
+// Reporter writes monthly AI reports from a month's rollup
+type Reporter struct {
+	proc            *processor.AIProcessor
+	logger          *logrus.Logger
+	cfg             config.MonthlyConfig
+	currency        string
+	templates       map[string]string // Language -> prompt template
+	defaultLanguage string
+	metadata        *buildinfo.Metadata
+	outputs         *outputstore.Store // Optional: reports are also stored with period type month
+}
+
+// NewReporter loads the monthly prompt templates; proc is reused with cfg.Model as a per-call override
+func NewReporter(proc *processor.AIProcessor, logger *logrus.Logger, cfg config.MonthlyConfig, defaultLanguage, currency string) (*Reporter, error) {
+	r := &Reporter{
+		proc:            proc,
+		logger:          logger,
+		cfg:             cfg,
+		currency:        currency,
+		templates:       make(map[string]string),
+		defaultLanguage: defaultLanguage,
+	}
+	for lang, file := range cfg.TemplateFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load monthly template (%s): %w", lang, err)
+		}
+		r.templates[gold.NormalizeLanguage(lang)] = string(data)
+	}
+	if _, ok := r.templates[defaultLanguage]; !ok {
+		return nil, fmt.Errorf("monthly.template_files has no %q template", defaultLanguage)
+	}
+	return r, nil
+}
+
+// SetMetadata stamps build and config metadata into the written reports
+func (r *Reporter) SetMetadata(metadata buildinfo.Metadata) {
+	r.metadata = &metadata
+}
+
+// SetOutputStore stores the monthly reports in the Gold table as well, with period type month
+func (r *Reporter) SetOutputStore(store *outputstore.Store) {
+	r.outputs = store
+}
+
+// prompt renders the monthly prompt for one kid; weekly holds the kid's weekly report summaries
+func (r *Reporter) prompt(kid KidMonth, weekly []WeekSummary, month, language string) (string, error) {
+	data, err := json.Marshal(kid)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal monthly data: %w", err)
+	}
+	if weekly == nil {
+		weekly = []WeekSummary{}
+	}
+	weeklyData, err := json.Marshal(weekly)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal weekly summaries: %w", err)
+	}
+	template, ok := r.templates[language]
+	if !ok {
+		template = r.templates[r.defaultLanguage]
+	}
+	prompt := strings.ReplaceAll(template, "{{KID}}", string(data))
+	prompt = strings.ReplaceAll(prompt, "{{WEEKLY_REPORTS}}", string(weeklyData))
+	prompt = strings.ReplaceAll(prompt, "{{MONTH}}", month)
+	prompt = strings.ReplaceAll(prompt, "{{CURRENCY}}", r.currency)
+	return prompt, nil
+}
+
+// generate asks the model for one kid's monthly report
+func (r *Reporter) generate(ctx context.Context, kid KidMonth, weekly []WeekSummary, month string) (*Report, error) {
+	language := gold.NormalizeLanguage(kid.Language)
+	if _, ok := r.templates[language]; !ok {
+		language = r.defaultLanguage
+	}
+	prompt, err := r.prompt(kid, weekly, month, language)
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := processor.DoJSON[reportResponse](ctx, r.proc, processor.Request{
+		Messages:   []processor.Message{{Role: "user", Content: prompt}},
+		Model:      r.cfg.Model,
+		MaxTokens:  r.cfg.MaxTokens,
+		UsageLabel: usageLabel(month),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(result.Summary) == "" {
+		return nil, fmt.Errorf("monthly report has no summary")
+	}
+	return &Report{
+		ProfileID:         kid.ProfileID,
+		ParentID:          kid.ParentID,
+		ChildName:         kid.Nickname,
+		Month:             month,
+		Language:          language,
+		Summary:           result.Summary,
+		Highlights:        result.Highlights,
+		NextMonthGoals:    result.NextMonthGoals,
+		ParentSuggestions: result.ParentSuggestions,
+		WeeklyReports:     len(weekly),
+		GeneratedAt:       time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// Generate writes one report per kid in the rollup to path, running kids through the batch processor.
+// weekly holds each kid's weekly report summaries (see LoadWeeklySummaries). Failed kids are logged
+// and left out.
+func (r *Reporter) Generate(ctx context.Context, rollup *Output, weekly map[string][]WeekSummary, path, compression string) (int, error) {
+	r.logger.Infof("🗓️  Generating monthly reports for %s (%d kids)", rollup.Month, len(rollup.Kids))
+
+	items := make([]interface{}, len(rollup.Kids))
+	for i, kid := range rollup.Kids {
+		items[i] = kid
+	}
+	slots := make([]*Report, len(items))
+	results := r.proc.ProcessBatchFunc(ctx, items, func(ctx context.Context, index int, item interface{}) error {
+		kid := item.(KidMonth)
+		report, err := r.generate(ctx, kid, weekly[kid.ProfileID], rollup.Month)
+		if err != nil {
+			return err
+		}
+		slots[index] = report
+		return nil
+	})
+
+	var reports []Report
+	for i, result := range results {
+		if !result.Success {
+			r.logger.Errorf("   ❌ Monthly report failed for %s: %v", rollup.Kids[i].ProfileID, result.Error)
+			continue
+		}
+		reports = append(reports, *slots[i])
+	}
+
+	output := map[string]interface{}{
+		"generated_at":  time.Now().Format(time.RFC3339),
+		"month":         rollup.Month,
+		"complete":      rollup.Complete,
+		"weeks":         rollup.Weeks,
+		"total_reports": len(reports),
+		"reports":       reports,
+		"token_usage":   r.tokenUsage(rollup.Month),
+	}
+	if r.metadata != nil {
+		output["metadata"] = r.metadata
+	}
+	encoded, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return len(reports), fmt.Errorf("failed to marshal monthly reports: %w", err)
+	}
+	writtenPath, err := fileio.WriteFile(path, encoded, compression)
+	if err != nil {
+		return len(reports), fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+
+	r.logger.Infof("✅ Monthly reports saved to: %s (%d/%d)", writtenPath, len(reports), len(rollup.Kids))
+
+	if r.outputs != nil {
+		rows := make([]outputstore.Row, 0, len(reports))
+		for _, report := range reports {
+			rows = append(rows, outputstore.Row{ProfileID: report.ProfileID, Payload: report})
+		}
+		if err := r.outputs.SaveGoldMonth(r.outputs.DB(), rollup.Month, rows); err != nil {
+			return len(reports), fmt.Errorf("failed to store monthly reports: %w", err)
+		}
+	}
+	return len(reports), nil
+}
+
+// tokenUsage is the month's report cost so far, from its token tracking bucket
+func (r *Reporter) tokenUsage(month string) gold.WeekTokenUsage {
+	summary := r.proc.GetTokenTracker().GetWeekSummary(usageLabel(month))
+	return gold.WeekTokenUsage{
+		PromptTokens:     summary.PromptTokens,
+		CompletionTokens: summary.CompletionTokens,
+		EstimatedCostUSD: summary.EstimatedCost,
+	}
+}
+
+// Save writes a month's rollup to path
+func Save(rollup *Output, path, compression string) (string, error) {
+	encoded, err := json.MarshalIndent(rollup, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal monthly rollup: %w", err)
+	}
+	writtenPath, err := fileio.WriteFile(path, encoded, compression)
+	if err != nil {
+		return "", fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	return writtenPath, nil
+}
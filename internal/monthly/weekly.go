@@ -0,0 +1,62 @@
+package monthly
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/gold"
+)
+
+// WeekSummary is the part of a kid's weekly AI report fed into the monthly prompt: the section
+// levels and highlights, without the full text
+type WeekSummary struct {
+	Week      string   `json:"week"`
+	Sections  []string `json:"sections"` // "title: level"
+	Strengths []string `json:"strengths,omitempty"`
+	TopRisk   string   `json:"top_risk,omitempty"`
+	Goals     []string `json:"goals,omitempty"` // The week's next-week goals
+}
+
+// summarize keeps the parts of a weekly report the monthly prompt needs
+func summarize(report gold.AIReport) WeekSummary {
+	summary := WeekSummary{
+		Week:      report.Week,
+		Sections:  make([]string, 0, len(report.PerformanceSections)),
+		Strengths: report.Strengths,
+		TopRisk:   report.TopRisk,
+		Goals:     report.NextWeekGoals,
+	}
+	for _, section := range report.PerformanceSections {
+		summary.Sections = append(summary.Sections, section.Title+": "+section.Level)
+	}
+	return summary
+}
+
+// LoadWeeklySummaries reads the month's weekly Gold report files (oldest first) and returns each kid's
+// week summaries by profile ID, oldest first. Weeks without a report file are skipped.
+func LoadWeeklySummaries(paths []string) (map[string][]WeekSummary, error) {
+	summaries := make(map[string][]WeekSummary)
+	for _, path := range paths {
+		if _, err := fileio.ResolvePath(path); err != nil {
+			continue
+		}
+		data, err := fileio.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read weekly reports %s: %w", path, err)
+		}
+		var output struct {
+			Reports []gold.AIReport `json:"reports"`
+		}
+		if err := json.Unmarshal(data, &output); err != nil {
+			return nil, fmt.Errorf("failed to parse weekly reports %s: %w", path, err)
+		}
+		for _, report := range output.Reports {
+			if report.ProfileID == "" {
+				continue
+			}
+			summaries[report.ProfileID] = append(summaries[report.ProfileID], summarize(report))
+		}
+	}
+	return summaries, nil
+}
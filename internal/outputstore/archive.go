@@ -0,0 +1,181 @@
+package outputstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/fileio"
+)
+
+// ErrNotFound is returned when a week has no stored report for the kid
+var ErrNotFound = errors.New("report not found")
+
+// ColdStore keeps archived report payloads outside Postgres. DirColdStore writes to a directory,
+// which can be an S3 bucket mounted with mountpoint-s3 or s3fs.
+type ColdStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+}
+
+// DirColdStore stores each archived payload as a gzipped file under a root directory
+type DirColdStore struct {
+	root string
+}
+
+// NewDirColdStore creates the archive root if missing
+func NewDirColdStore(root string) (*DirColdStore, error) {
+	if root == "" {
+		return nil, fmt.Errorf("database_output.archive.dir is required")
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create archive dir %s: %w", root, err)
+	}
+	return &DirColdStore{root: root}, nil
+}
+
+// Put writes data to key, replacing an earlier copy
+func (d *DirColdStore) Put(key string, data []byte) error {
+	path := filepath.Join(d.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	_, err := fileio.WriteFile(path, data, fileio.CompressionGzip)
+	return err
+}
+
+// Get reads the data stored under key
+func (d *DirColdStore) Get(key string) ([]byte, error) {
+	return fileio.ReadFile(filepath.Join(d.root, filepath.FromSlash(key)))
+}
+
+// archiveStub replaces an archived payload in the database, so downstream readers see where it went
+type archiveStub struct {
+	Archived   bool      `json:"archived"`
+	ArchiveKey string    `json:"archive_key"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// archiveKey names a report in cold storage: <table>/<week>/<profile_id>.json
+func archiveKey(table, week, profileID string) string {
+	return fmt.Sprintf("%s/%s/%s.json", table, keySegment(week), keySegment(profileID))
+}
+
+// keySegment makes a week label or ID safe as one path segment
+func keySegment(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// ArchiveGold moves Gold reports last written (or rehydrated) before cutoff to cold storage,
+// batchSize rows per query, leaving a stub payload and archive_key in each row. It returns the
+// number of reports archived.
+func (s *Store) ArchiveGold(cold ColdStore, cutoff time.Time, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	selectQuery := fmt.Sprintf(`
+		SELECT week, profile_id, payload
+		FROM %s
+		WHERE archive_key IS NULL
+		  AND COALESCE(rehydrated_at, updated_at) < $1
+		ORDER BY updated_at
+		LIMIT $2
+	`, s.goldTable)
+	updateQuery := fmt.Sprintf(`
+		UPDATE %s SET payload = $3, archive_key = $4, rehydrated_at = NULL
+		WHERE week = $1 AND profile_id = $2
+	`, s.goldTable)
+
+	archived := 0
+	for {
+		rows, err := s.db.Query(selectQuery, cutoff, batchSize)
+		if err != nil {
+			return archived, fmt.Errorf("failed to list reports to archive: %w", err)
+		}
+		type pending struct {
+			week, profileID string
+			payload         []byte
+		}
+		var batch []pending
+		for rows.Next() {
+			var p pending
+			if err := rows.Scan(&p.week, &p.profileID, &p.payload); err != nil {
+				rows.Close()
+				return archived, fmt.Errorf("failed to scan report to archive: %w", err)
+			}
+			batch = append(batch, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return archived, fmt.Errorf("failed to list reports to archive: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		// Upload before replacing the payload, so a failure never loses a report
+		for _, p := range batch {
+			key := archiveKey(s.goldTable, p.week, p.profileID)
+			if err := cold.Put(key, p.payload); err != nil {
+				return archived, fmt.Errorf("failed to archive %s for %s: %w", p.profileID, p.week, err)
+			}
+			stub, err := json.Marshal(archiveStub{Archived: true, ArchiveKey: key, ArchivedAt: time.Now().UTC()})
+			if err != nil {
+				return archived, err
+			}
+			if _, err := s.db.Exec(updateQuery, p.week, p.profileID, stub, key); err != nil {
+				return archived, fmt.Errorf("failed to stub archived report %s for %s: %w", p.profileID, p.week, err)
+			}
+			archived++
+		}
+		s.logger.Infof("🧊 Archived %d reports from %s", archived, s.goldTable)
+	}
+	return archived, nil
+}
+
+// GoldReport returns a kid's stored report for a week. An archived report is read back from cold
+// storage and restored in the database (rehydrated), where it stays until it ages out again.
+func (s *Store) GoldReport(cold ColdStore, week, profileID string) (json.RawMessage, error) {
+	var payload []byte
+	var key sql.NullString
+	query := fmt.Sprintf(`SELECT payload, archive_key FROM %s WHERE week = $1 AND profile_id = $2`, s.goldTable)
+	err := s.db.QueryRow(query, week, profileID).Scan(&payload, &key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	if !key.Valid {
+		return payload, nil
+	}
+
+	if cold == nil {
+		return nil, fmt.Errorf("report %s for %s is archived and database_output.archive is not configured", profileID, week)
+	}
+	payload, err = cold.Get(key.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rehydrate %s: %w", key.String, err)
+	}
+	restore := fmt.Sprintf(`
+		UPDATE %s SET payload = $3, archive_key = NULL, rehydrated_at = now()
+		WHERE week = $1 AND profile_id = $2 AND archive_key = $4
+	`, s.goldTable)
+	if _, err := s.db.Exec(restore, week, profileID, payload, key.String); err != nil {
+		// The report can still be served; it is rehydrated again next time
+		s.logger.Warnf("⚠️  Failed to restore rehydrated report %s for %s: %v", profileID, week, err)
+	} else {
+		s.logger.Infof("🔥 Rehydrated report %s for %s from %s", profileID, week, key.String)
+	}
+	return payload, nil
+}
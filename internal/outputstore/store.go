@@ -0,0 +1,323 @@
+package outputstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"ai-production-pipeline/internal/fileio"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tableNamePattern guards the configurable table names
+var tableNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+
+// Execer is satisfied by *sql.DB and *sql.Tx, so rows can be written inside a week's unit of work
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Row is one kid's output for a week
+type Row struct {
+	ProfileID string
+	Payload   interface{} // Marshaled to JSONB
+}
+
+// Write batching defaults (data.database_output.write_batch_size and max_in_flight_batches)
+const (
+	DefaultWriteBatchSize     = 500
+	DefaultMaxInFlightBatches = 4
+
+	maxWriteBatchSize = (65535 - 2) / 2 // Postgres allows 65535 parameters per statement: the week and period type plus two per row
+)
+
+// Report periods, stored in the Gold table's period_type column. A period's rows are keyed by its label
+// in the week column: the week label, or YYYY-MM for a month.
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// WriteBatching controls how a week's rows are written: multi-row upserts of BatchSize rows, with at most
+// MaxInFlight batches marshaled ahead of the database. A week is replaced in one transaction, a single
+// connection, so its batches are written one at a time.
+type WriteBatching struct {
+	BatchSize   int
+	MaxInFlight int
+}
+
+// Validate checks the batch size against Postgres' parameter limit (0 means the default)
+func (b WriteBatching) Validate() error {
+	if b.BatchSize < 0 || b.BatchSize > maxWriteBatchSize {
+		return fmt.Errorf("database_output.write_batch_size must be between 1 and %d, got %d", maxWriteBatchSize, b.BatchSize)
+	}
+	if b.MaxInFlight < 0 {
+		return fmt.Errorf("database_output.max_in_flight_batches must be positive, got %d", b.MaxInFlight)
+	}
+	return nil
+}
+
+// Store writes Silver analyses and Gold reports to Postgres, one row per (week, profile), so
+// downstream apps can query them instead of parsing the files in data/
+type Store struct {
+	db          *sql.DB
+	logger      *logrus.Logger
+	silverTable string
+	goldTable   string
+	batching    WriteBatching
+}
+
+// NewStore creates the store and both tables if missing
+func NewStore(db *sql.DB, logger *logrus.Logger, silverTable, goldTable string) (*Store, error) {
+	if silverTable == "" {
+		silverTable = "silver_analysis"
+	}
+	if goldTable == "" {
+		goldTable = "gold_reports"
+	}
+	for _, table := range []string{silverTable, goldTable} {
+		if !tableNamePattern.MatchString(table) {
+			return nil, fmt.Errorf("invalid output table %q", table)
+		}
+		query := fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %[1]s (
+				week       TEXT NOT NULL,
+				profile_id TEXT NOT NULL,
+				payload    JSONB NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+				PRIMARY KEY (week, profile_id)
+			)
+		`, table)
+		if _, err := db.Exec(query); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", table, err)
+		}
+	}
+
+	// Gold reports can be archived to cold storage (see ArchiveGold), and are weekly or monthly
+	archiveColumns := fmt.Sprintf(`
+		ALTER TABLE %[1]s
+			ADD COLUMN IF NOT EXISTS archive_key TEXT,
+			ADD COLUMN IF NOT EXISTS rehydrated_at TIMESTAMPTZ,
+			ADD COLUMN IF NOT EXISTS period_type TEXT NOT NULL DEFAULT 'week';
+		CREATE INDEX IF NOT EXISTS %[2]s_unarchived_idx ON %[1]s (updated_at) WHERE archive_key IS NULL
+	`, goldTable, strings.ReplaceAll(goldTable, ".", "_"))
+	if _, err := db.Exec(archiveColumns); err != nil {
+		return nil, fmt.Errorf("failed to add archive columns to %s: %w", goldTable, err)
+	}
+
+	return &Store{
+		db:          db,
+		logger:      logger,
+		silverTable: silverTable,
+		goldTable:   goldTable,
+		batching:    WriteBatching{BatchSize: DefaultWriteBatchSize, MaxInFlight: DefaultMaxInFlightBatches},
+	}, nil
+}
+
+// SetWriteBatching replaces the default batching of week writes (zero fields keep the defaults)
+func (s *Store) SetWriteBatching(batching WriteBatching) error {
+	if err := batching.Validate(); err != nil {
+		return err
+	}
+	if batching.BatchSize > 0 {
+		s.batching.BatchSize = batching.BatchSize
+	}
+	if batching.MaxInFlight > 0 {
+		s.batching.MaxInFlight = batching.MaxInFlight
+	}
+	return nil
+}
+
+// DB returns the store's connection, for writes outside a unit of work
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// SaveSilver replaces the week's Silver rows
+func (s *Store) SaveSilver(exec Execer, week string, rows []Row) error {
+	return s.replaceWeek(exec, s.silverTable, week, "", rows)
+}
+
+// SaveGold replaces the week's Gold report rows
+func (s *Store) SaveGold(exec Execer, week string, rows []Row) error {
+	return s.replaceWeek(exec, s.goldTable, week, PeriodWeek, rows)
+}
+
+// SaveGoldMonth replaces the month's Gold report rows (month is YYYY-MM, stored with period_type month)
+func (s *Store) SaveGoldMonth(exec Execer, month string, rows []Row) error {
+	return s.replaceWeek(exec, s.goldTable, month, PeriodMonth, rows)
+}
+
+// SaveSilverFile reads a Silver output file and replaces the week's Silver rows with its kids
+func (s *Store) SaveSilverFile(exec Execer, week, path string) error {
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var output struct {
+		Kids []json.RawMessage `json:"kids"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	rows := make([]Row, 0, len(output.Kids))
+	for _, kid := range output.Kids {
+		var id struct {
+			ProfileID string `json:"profile_id"`
+		}
+		if err := json.Unmarshal(kid, &id); err != nil || id.ProfileID == "" {
+			s.logger.Warn("⚠️  Skipping Silver row without profile_id")
+			continue
+		}
+		rows = append(rows, Row{ProfileID: id.ProfileID, Payload: kid})
+	}
+	return s.SaveSilver(exec, week, rows)
+}
+
+// replaceWeek deletes the week's rows and inserts rows, so kids dropped from a rerun do not linger.
+// Rows go out as multi-row upserts; marshaling blocks while MaxInFlight batches wait for the database.
+// periodType is written to the period_type column ("" for tables without one). Given the pool rather
+// than a transaction, it opens one, so readers never see the week without its rows.
+func (s *Store) replaceWeek(exec Execer, table, week, periodType string, rows []Row) error {
+	if db, ok := exec.(*sql.DB); ok {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin writing %s for %s: %w", table, week, err)
+		}
+		defer tx.Rollback()
+		if err := s.replaceWeek(tx, table, week, periodType, rows); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit %s for %s: %w", table, week, err)
+		}
+		return nil
+	}
+
+	if _, err := exec.Exec(fmt.Sprintf(`DELETE FROM %s WHERE week = $1`, table), week); err != nil {
+		return fmt.Errorf("failed to clear %s for %s: %w", table, week, err)
+	}
+	rows = lastPerProfile(rows)
+
+	fixed := 1 // Parameters before the rows: the week, and the period type when written
+	if periodType != "" {
+		fixed = 2
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		failed   = make(chan struct{})
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			close(failed)
+		}
+	}
+
+	batches := make(chan []interface{}, s.batching.MaxInFlight)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for args := range batches {
+			select {
+			case <-failed:
+				continue // Drain: the week is failing anyway
+			default:
+			}
+			count := (len(args) - fixed) / 2
+			if _, err := exec.Exec(upsertQuery(table, periodType != "", count), args...); err != nil {
+				fail(fmt.Errorf("failed to write %d %s rows for %s: %w", count, table, week, err))
+			}
+		}
+	}()
+
+	statements := 0
+produce:
+	for start := 0; start < len(rows); start += s.batching.BatchSize {
+		end := start + s.batching.BatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		args := make([]interface{}, 0, fixed+2*(end-start))
+		args = append(args, week)
+		if periodType != "" {
+			args = append(args, periodType)
+		}
+		for _, row := range rows[start:end] {
+			payload, err := json.Marshal(row.Payload)
+			if err != nil {
+				fail(fmt.Errorf("failed to marshal %s row for %s: %w", table, row.ProfileID, err))
+				break produce
+			}
+			args = append(args, row.ProfileID, payload)
+		}
+		select {
+		case batches <- args:
+			statements++
+		case <-failed:
+			break produce
+		}
+	}
+	close(batches)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+
+	s.logger.Infof("🗄️  Stored %d rows in %s for %s (%d statements)", len(rows), table, week, statements)
+	return nil
+}
+
+// upsertQuery inserts count rows in one statement: $1 is the week, $2 the period type when withPeriod,
+// then profile_id and payload per row
+func upsertQuery(table string, withPeriod bool, count int) string {
+	columns, period, first := "week, profile_id, payload, updated_at", "", 2
+	if withPeriod {
+		columns, period, first = "week, period_type, profile_id, payload, updated_at", "$2, ", 3
+	}
+	var values strings.Builder
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			values.WriteString(", ")
+		}
+		fmt.Fprintf(&values, "($1, %s$%d, $%d, now())", period, 2*i+first, 2*i+first+1)
+	}
+	update := ""
+	if withPeriod {
+		update = "period_type = EXCLUDED.period_type,\n\t\t\t"
+	}
+	return fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES %s
+		ON CONFLICT (week, profile_id) DO UPDATE SET
+			%spayload = EXCLUDED.payload,
+			updated_at = EXCLUDED.updated_at
+	`, table, columns, values.String(), update)
+}
+
+// lastPerProfile keeps each profile's last row: one upsert statement cannot update the same key twice
+func lastPerProfile(rows []Row) []Row {
+	last := make(map[string]int, len(rows))
+	for i, row := range rows {
+		last[row.ProfileID] = i
+	}
+	if len(last) == len(rows) {
+		return rows
+	}
+	kept := make([]Row, 0, len(last))
+	for i, row := range rows {
+		if last[row.ProfileID] == i {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/gold"
+	"ai-production-pipeline/internal/silver"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Pipeline exposes the Silver and Gold layers as a Go API for on-demand use
+type Pipeline struct {
+	config  *config.Config
+	logger  *logrus.Logger
+	weekMgr *weekmanager.WeekManager
+	silver  *silver.SilverLayer
+	gold    *gold.GoldLayer
+}
+
+// New creates a pipeline using an existing database connection
+func New(cfg *config.Config, db *sql.DB, logger *logrus.Logger) (*Pipeline, error) {
+	goldLayer, err := gold.NewGoldLayer(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Gold layer: %w", err)
+	}
+	if cfg.Prompts.DBTable != "" {
+		if err := goldLayer.LoadPromptOverrides(db, cfg.Prompts.DBTable); err != nil {
+			return nil, fmt.Errorf("failed to load prompt overrides: %w", err)
+		}
+	}
+
+	return &Pipeline{
+		config:  cfg,
+		logger:  logger,
+		weekMgr: weekmanager.NewWeekManager(db, logger, cfg.Calendar),
+		silver:  silver.NewSilverLayer(db, logger, cfg.Silver),
+		gold:    goldLayer,
+	}, nil
+}
+
+// GenerateNow computes week-to-date metrics for one kid and returns an interim AI report.
+// Nothing is written to disk; the report is meant for "preview my report" mid-week.
+func (p *Pipeline) GenerateNow(ctx context.Context, profileID string) (*gold.AIReport, error) {
+	startTime := time.Now()
+	weekData := p.weekMgr.GetWeekToDate(startTime)
+
+	kidData, err := p.silver.AnalyzeProfile(ctx, profileID, weekData)
+	if err != nil {
+		return nil, fmt.Errorf("silver analysis failed: %w", err)
+	}
+
+	kidMap, err := toMap(kidData)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := p.gold.GenerateReport(ctx, kidMap, weekData.CurrentWeek.Label)
+	if err != nil {
+		return nil, fmt.Errorf("report generation failed: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"profile_id": profileID,
+		"week":       weekData.CurrentWeek.Label,
+		"duration":   time.Since(startTime),
+	}).Info("✅ Interim report generated")
+
+	return report, nil
+}
+
+// PreviewPrompt runs Silver for one kid and week and renders the Gold prompt without calling the API.
+// It needs only the database, so it works without an OpenAI key.
+func PreviewPrompt(ctx context.Context, cfg *config.Config, db *sql.DB, logger *logrus.Logger, profileID, weekKey string) (*gold.PromptPreview, error) {
+	weekMgr := weekmanager.NewWeekManager(db, logger, cfg.Calendar)
+	weeks, err := weekMgr.GetAvailableWeeks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available weeks: %w", err)
+	}
+
+	var week *weekmanager.WeekRange
+	for i := range weeks {
+		if weeks[i].Key() == weekKey {
+			week = &weeks[i]
+			break
+		}
+	}
+	if week == nil {
+		return nil, fmt.Errorf("week %s not found (%d weeks available)", weekKey, len(weeks))
+	}
+
+	silverLayer := silver.NewSilverLayer(db, logger, cfg.Silver)
+	kidData, err := silverLayer.AnalyzeProfile(ctx, profileID, weekMgr.GetWeekData(*week, weeks))
+	if err != nil {
+		return nil, fmt.Errorf("silver analysis failed: %w", err)
+	}
+
+	kidMap, err := toMap(kidData)
+	if err != nil {
+		return nil, err
+	}
+
+	return gold.PreviewPrompt(cfg, kidMap, week.Label)
+}
+
+// GenerateKidReport runs Silver and Gold for one kid and week and merges the report into the week's
+// Gold output; the week's Silver output and every other kid's report are left as they are
+func GenerateKidReport(ctx context.Context, silverLayer *silver.SilverLayer, goldLayer *gold.GoldLayer,
+	weekData *weekmanager.WeekData, profileID, reportOutputPath string) (*gold.AIReport, error) {
+	kidData, err := silverLayer.AnalyzeProfile(ctx, profileID, weekData)
+	if err != nil {
+		return nil, fmt.Errorf("silver analysis failed: %w", err)
+	}
+
+	kidMap, err := toMap(kidData)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := goldLayer.GenerateKidReport(ctx, kidMap, reportOutputPath, weekData.CurrentWeek.Label)
+	if err != nil {
+		return nil, fmt.Errorf("report generation failed: %w", err)
+	}
+	return report, nil
+}
+
+// SilverError is StreamWeek's Silver failure, which means the week's data is incomplete
+type SilverError struct {
+	Err error
+}
+
+func (e *SilverError) Error() string {
+	return fmt.Sprintf("silver layer failed: %v", e.Err)
+}
+
+func (e *SilverError) Unwrap() error {
+	return e.Err
+}
+
+// StreamWeek runs Silver and Gold for one week concurrently: each kid is handed to Gold through a
+// bounded queue as soon as Silver has analyzed it, overlapping database time with API time.
+// A full queue blocks Silver until Gold catches up. It returns the reports generated and Silver's
+// and Gold's errors joined; Silver's is a *SilverError (find it with errors.As).
+func StreamWeek(ctx context.Context, logger *logrus.Logger, silverLayer *silver.SilverLayer, goldLayer *gold.GoldLayer,
+	weekData *weekmanager.WeekData, silverOutputPath, reportOutputPath string, queueSize int) (int, error) {
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	queue := make(chan map[string]interface{}, queueSize)
+	silverDone := make(chan error, 1)
+
+	go func() {
+		defer close(queue)
+		silverDone <- silverLayer.TransformStream(ctx, weekData, silverOutputPath, func(kidData silver.EnhancedKidData) {
+			kidMap, err := toMap(&kidData)
+			if err != nil {
+				logger.Errorf("   ❌ Not queued for Gold: %s: %v", kidData.ProfileID, err)
+				return
+			}
+			select {
+			case queue <- kidMap:
+			case <-ctx.Done(): // Gold has stopped reading; Silver stops at its next kid
+			}
+		})
+	}()
+
+	successCount, goldErr := goldLayer.GenerateReportsFromStream(ctx, queue, reportOutputPath, weekData.CurrentWeek.Label)
+	if silverErr := <-silverDone; silverErr != nil {
+		return successCount, errors.Join(&SilverError{Err: silverErr}, goldErr)
+	}
+	return successCount, goldErr
+}
+
+// toMap converts Silver output to the generic map form Gold consumes from files
+func toMap(kidData *silver.EnhancedKidData) (map[string]interface{}, error) {
+	data, err := json.Marshal(kidData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal kid data: %w", err)
+	}
+
+	var kidMap map[string]interface{}
+	if err := json.Unmarshal(data, &kidMap); err != nil {
+		return nil, fmt.Errorf("failed to convert kid data: %w", err)
+	}
+	return kidMap, nil
+}
@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultAnthropicBaseURL is the Anthropic API base URL used when no gateway is configured
+const DefaultAnthropicBaseURL = "https://api.anthropic.com/v1"
+
+// anthropicVersion is the Messages API version sent with every request
+const anthropicVersion = "2023-06-01"
+
+// anthropicDefaultMaxTokens is used when no limit is configured (the Messages API requires one)
+const anthropicDefaultMaxTokens = 4096
+
+// anthropicJSONInstruction replaces response_format, which the Messages API does not have
+const anthropicJSONInstruction = "Respond with a single JSON object only: no markdown code fences and no text before or after it."
+
+// anthropicRequest is the Messages API request body
+type anthropicRequest struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature float64   `json:"temperature"`
+}
+
+// anthropicResponse is the Messages API response body
+type anthropicResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// anthropicProvider calls the Anthropic Messages API (Claude models)
+type anthropicProvider struct {
+	config Config
+	client *http.Client
+}
+
+// Name returns "anthropic"
+func (p *anthropicProvider) Name() string {
+	return ProviderAnthropic
+}
+
+// CallModel translates the request to the Messages API and returns the response text. JSON
+// responses are asked for in the system prompt and unwrapped from any code fence.
+func (p *anthropicProvider) CallModel(ctx context.Context, reqBody OpenAIRequest, meta requestMeta) (*Completion, error) {
+	body := anthropicRequest{
+		Model:       reqBody.Model,
+		MaxTokens:   reqBody.MaxCompletionTokens,
+		Temperature: reqBody.Temperature,
+	}
+	if body.MaxTokens <= 0 {
+		body.MaxTokens = anthropicDefaultMaxTokens
+	}
+	if body.Temperature > 1 {
+		body.Temperature = 1 // Messages API range is 0-1
+	}
+
+	// System messages go in the top-level system field
+	var system []string
+	for _, message := range reqBody.Messages {
+		if message.Role == "system" {
+			system = append(system, message.Content)
+			continue
+		}
+		body.Messages = append(body.Messages, message)
+	}
+	wantJSON := reqBody.ResponseFormat.Type != "text"
+	if wantJSON {
+		system = append(system, anthropicJSONInstruction)
+		if schema := reqBody.ResponseFormat.JSONSchema; schema != nil {
+			system = append(system, "The object must follow this JSON schema:\n"+string(schema.Schema))
+		}
+	}
+	body.System = strings.Join(system, "\n\n")
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if int64(len(jsonData)) > p.config.MaxRequestBytes {
+		return nil, fmt.Errorf("request too large: %d bytes exceeds limit of %d bytes", len(jsonData), p.config.MaxRequestBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(p.config.BaseURL, "/")+"/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", p.config.APIKey)
+	req.Header.Set("Anthropic-Version", anthropicVersion)
+	if meta.RequestID != "" {
+		req.Header.Set("X-Client-Request-Id", meta.RequestID)
+	}
+	for key, value := range p.config.ExtraHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := readResponseBody(resp, p.config.MaxResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResp anthropicResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response (status %d): %w: %s", resp.StatusCode, err, bodySnippet(respBody))
+	}
+	if apiResp.Error != nil {
+		return nil, &APIStatusError{
+			Status:  resp.StatusCode,
+			Message: apiResp.Error.Message,
+			Type:    apiResp.Error.Type,
+			Code:    apiResp.Error.Type,
+			Body:    string(respBody),
+
+			RetryAfter: parseRetryAfter(resp.Header),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIStatusError{Status: resp.StatusCode, Body: string(respBody), RetryAfter: parseRetryAfter(resp.Header)}
+	}
+
+	var text strings.Builder
+	for _, block := range apiResp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no text in response (stop_reason %s)", apiResp.StopReason)
+	}
+	content := text.String()
+	if wantJSON {
+		content = extractJSONObject(content)
+	}
+
+	input := apiResp.Usage.InputTokens + apiResp.Usage.CacheCreationInputTokens + apiResp.Usage.CacheReadInputTokens
+	return &Completion{
+		Content: content,
+		Usage: Usage{
+			PromptTokens:     input,
+			CompletionTokens: apiResp.Usage.OutputTokens,
+			TotalTokens:      input + apiResp.Usage.OutputTokens,
+		},
+		ResponseID:        apiResp.ID,
+		ProviderRequestID: resp.Header.Get("Request-Id"),
+	}, nil
+}
+
+// extractJSONObject returns the outermost {...} of text, dropping code fences or prose around it
+func extractJSONObject(text string) string {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return strings.TrimSpace(text)
+	}
+	return text[start : end+1]
+}
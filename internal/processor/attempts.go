@@ -0,0 +1,143 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Failure classes recorded per attempt, so slow or failing items can be told apart from self-throttling
+const (
+	FailureRateLimited     = "rate_limited"     // Provider answered 429
+	FailureServerError     = "server_error"     // Provider answered 5xx
+	FailureClientError     = "client_error"     // Provider rejected the request (other 4xx)
+	FailureTimeout         = "timeout"          // The attempt hit the HTTP timeout
+	FailureNetwork         = "network"          // Connection failed before a response
+	FailureBudget          = "budget"           // The item's time budget ran out
+	FailureInvalidResponse = "invalid_response" // Response could not be parsed or had no content
+)
+
+// AttemptInfo describes one API call made for an item
+type AttemptInfo struct {
+	Attempt       int           `json:"attempt"`
+	FailureClass  string        `json:"failure_class,omitempty"` // "" when the attempt succeeded
+	Status        int           `json:"status,omitempty"`        // HTTP status of an API error
+	RateLimitWait time.Duration `json:"rate_limit_wait"`         // Waiting on our own rate limiter before the call
+	APITime       time.Duration `json:"api_time"`                // Waiting on the provider
+	Backoff       time.Duration `json:"backoff,omitempty"`       // Sleep after this attempt before the next one
+}
+
+// classifyFailure returns the failure class of an attempt's error
+func classifyFailure(err error) (class string, status int) {
+	var apiErr *APIStatusError
+	switch {
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.Status == http.StatusTooManyRequests:
+			return FailureRateLimited, apiErr.Status
+		case apiErr.Status >= 500:
+			return FailureServerError, apiErr.Status
+		case apiErr.Status >= 400:
+			return FailureClientError, apiErr.Status
+		}
+		return FailureInvalidResponse, apiErr.Status
+	case errors.Is(err, ErrItemBudgetExceeded):
+		return FailureBudget, 0
+	case isTimeoutError(err):
+		return FailureTimeout, 0
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return FailureNetwork, 0
+	}
+	return FailureInvalidResponse, 0
+}
+
+// IsUnavailable reports whether err means the provider could not serve the request (rate limited, server
+// error, timeout, network failure) rather than rejecting it or returning a bad response
+func IsUnavailable(err error) bool {
+	switch class, _ := classifyFailure(err); class {
+	case FailureRateLimited, FailureServerError, FailureTimeout, FailureNetwork, FailureBudget:
+		return true
+	}
+	return false
+}
+
+// timeSplit sums where an item's time went across its attempts
+func timeSplit(attempts []AttemptInfo) (rateLimitWait, apiTime, backoff time.Duration) {
+	for _, a := range attempts {
+		rateLimitWait += a.RateLimitWait
+		apiTime += a.APITime
+		backoff += a.Backoff
+	}
+	return rateLimitWait, apiTime, backoff
+}
+
+type attemptLogKey struct{}
+
+// attemptLog collects the attempts of every API call made within one batch item (ProcessBatchFunc
+// items make their calls through callWithRetry, possibly several per item and on several processors)
+type attemptLog struct {
+	mu       sync.Mutex
+	attempts []AttemptInfo
+	owners   []*AIProcessor // Processor that made each attempt
+}
+
+// withAttemptLog returns a context whose API calls are recorded in the returned log
+func withAttemptLog(ctx context.Context) (context.Context, *attemptLog) {
+	log := &attemptLog{}
+	return context.WithValue(ctx, attemptLogKey{}, log), log
+}
+
+// attemptLogFrom returns the context's attempt log (nil when calls are not recorded)
+func attemptLogFrom(ctx context.Context) *attemptLog {
+	log, _ := ctx.Value(attemptLogKey{}).(*attemptLog)
+	return log
+}
+
+// add records the attempts of a call made by ap; a nil log records nothing
+func (l *attemptLog) add(ap *AIProcessor, attempts []AttemptInfo) {
+	if l == nil || len(attempts) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts = append(l.attempts, attempts...)
+	for range attempts {
+		l.owners = append(l.owners, ap)
+	}
+}
+
+// list returns the recorded attempts, in the order the calls finished
+func (l *attemptLog) list() []AttemptInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]AttemptInfo(nil), l.attempts...)
+}
+
+// madeBy returns the attempts ap made, e.g. without a consensus model's calls on another processor
+func (l *attemptLog) madeBy(ap *AIProcessor) []AttemptInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var attempts []AttemptInfo
+	for i, attempt := range l.attempts {
+		if l.owners[i] == ap {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts
+}
+
+// retries counts the failed attempts, like ProcessResult.Retries on the ProcessBatch path
+func retries(attempts []AttemptInfo) int {
+	count := 0
+	for _, attempt := range attempts {
+		if attempt.FailureClass != "" {
+			count++
+		}
+	}
+	return count
+}
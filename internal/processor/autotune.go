@@ -0,0 +1,147 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AutoTuneConfig lets the processor adjust its concurrency between bounds from observed p90 latency
+// and error rate: +1 after a healthy window, halved after a slow or failing one (AIMD)
+type AutoTuneConfig struct {
+	Enabled       bool
+	MinConcurrent int
+	MaxConcurrent int
+	TargetP90     time.Duration // Windows with a slower p90 API time count as unhealthy
+	MaxErrorRate  float64       // Share of attempts rate limited, 5xx, timed out or failed on the network
+	Window        int           // Attempts per evaluation
+}
+
+// Validate checks the bounds and thresholds
+func (c AutoTuneConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.MinConcurrent < 1 || c.MaxConcurrent < c.MinConcurrent {
+		return fmt.Errorf("auto_tune needs 1 <= min_concurrent <= max_concurrent, got %d and %d", c.MinConcurrent, c.MaxConcurrent)
+	}
+	if c.TargetP90 <= 0 {
+		return fmt.Errorf("auto_tune.target_p90 must be positive")
+	}
+	if c.MaxErrorRate < 0 || c.MaxErrorRate > 1 {
+		return fmt.Errorf("auto_tune.max_error_rate must be between 0 and 1, got %g", c.MaxErrorRate)
+	}
+	return nil
+}
+
+// concurrencyLimiter bounds in-flight items; with auto-tuning off the limit never changes
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	inFlight int
+
+	tune      AutoTuneConfig
+	latencies []time.Duration // API time of the window's attempts
+	errors    int
+	logger    *logrus.Logger
+}
+
+// newConcurrencyLimiter starts at maxConcurrent (clamped to the auto-tune bounds when enabled)
+func newConcurrencyLimiter(maxConcurrent int, tune AutoTuneConfig, logger *logrus.Logger) *concurrencyLimiter {
+	l := &concurrencyLimiter{limit: maxConcurrent, tune: tune, logger: logger}
+	l.cond = sync.NewCond(&l.mu)
+	if tune.Enabled {
+		if l.tune.Window <= 0 {
+			l.tune.Window = 20
+		}
+		if l.limit < tune.MinConcurrent {
+			l.limit = tune.MinConcurrent
+		}
+		if l.limit > tune.MaxConcurrent {
+			l.limit = tune.MaxConcurrent
+		}
+	}
+	return l
+}
+
+// Limit returns the current concurrency limit
+func (l *concurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// acquire waits for a slot; it returns false (holding no slot) once ctx is done
+func (l *concurrencyLimiter) acquire(ctx context.Context) bool {
+	stop := context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		l.cond.Broadcast()
+		l.mu.Unlock()
+	})
+	defer stop()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ctx.Err() == nil && l.inFlight >= l.limit {
+		l.cond.Wait()
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+// release frees a slot and feeds the item's attempts to the tuner
+func (l *concurrencyLimiter) release(attempts []AttemptInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if l.tune.Enabled {
+		for _, attempt := range attempts {
+			l.observe(attempt)
+		}
+	}
+	l.cond.Broadcast()
+}
+
+// observe records one attempt and re-evaluates the limit once the window is full (mu held)
+func (l *concurrencyLimiter) observe(attempt AttemptInfo) {
+	switch attempt.FailureClass {
+	case FailureRateLimited, FailureServerError, FailureTimeout, FailureNetwork:
+		l.errors++
+	}
+	l.latencies = append(l.latencies, attempt.APITime)
+	if len(l.latencies) < l.tune.Window {
+		return
+	}
+
+	sort.Slice(l.latencies, func(i, j int) bool { return l.latencies[i] < l.latencies[j] })
+	p90 := l.latencies[(len(l.latencies)*9+9)/10-1]
+	errorRate := float64(l.errors) / float64(len(l.latencies))
+	l.latencies = l.latencies[:0]
+	l.errors = 0
+
+	previous := l.limit
+	if p90 > l.tune.TargetP90 || errorRate > l.tune.MaxErrorRate {
+		l.limit /= 2
+		if l.limit < l.tune.MinConcurrent {
+			l.limit = l.tune.MinConcurrent
+		}
+	} else if l.limit < l.tune.MaxConcurrent {
+		l.limit++
+	}
+	if l.limit != previous {
+		l.logger.WithFields(logrus.Fields{
+			"p90":        p90.Round(time.Millisecond),
+			"error_rate": fmt.Sprintf("%.1f%%", errorRate*100),
+			"from":       previous,
+			"to":         l.limit,
+		}).Info("🎚️  Concurrency adjusted")
+	}
+}
@@ -0,0 +1,180 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Injected fault kinds
+const (
+	FaultTimeout     = "timeout"
+	FaultRateLimit   = "rate_limit"
+	FaultServerError = "server_error"
+	FaultMalformed   = "malformed_json"
+	FaultSlow        = "slow"
+)
+
+// FaultConfig makes provider calls fail at the given rates (0-1 each), to exercise retries, checkpoints
+// and partial flushes without a real outage. Zero rates inject nothing.
+type FaultConfig struct {
+	TimeoutRate     float64
+	RateLimitRate   float64
+	ServerErrorRate float64
+	MalformedRate   float64 // The real completion is returned cut in half
+	SlowRate        float64 // The real call is made after SlowDelay
+	SlowDelay       time.Duration
+	Seed            int64 // 0 = random; set it to replay the same sequence of faults
+}
+
+// enabled reports whether any fault has a non-zero rate
+func (c FaultConfig) enabled() bool {
+	return c.TimeoutRate+c.RateLimitRate+c.ServerErrorRate+c.MalformedRate+c.SlowRate > 0
+}
+
+// Validate checks every rate is between 0 and 1 and that they add up to at most 1
+func (c FaultConfig) Validate() error {
+	total := 0.0
+	for name, rate := range map[string]float64{
+		"timeout_rate": c.TimeoutRate, "rate_limit_rate": c.RateLimitRate, "server_error_rate": c.ServerErrorRate,
+		"malformed_json_rate": c.MalformedRate, "slow_rate": c.SlowRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("fault_injection.%s must be between 0 and 1, got %g", name, rate)
+		}
+		total += rate
+	}
+	if total > 1 {
+		return fmt.Errorf("fault_injection rates add up to %g, must be at most 1", total)
+	}
+	return nil
+}
+
+// injectedTimeout is a net.Error reporting a timeout, like the HTTP client's
+type injectedTimeout struct{}
+
+func (injectedTimeout) Error() string   { return "injected fault: request timed out" }
+func (injectedTimeout) Timeout() bool   { return true }
+func (injectedTimeout) Temporary() bool { return true }
+
+// faultyProvider wraps a provider and injects faults into its calls
+type faultyProvider struct {
+	Provider
+	cfg    FaultConfig
+	logger *logrus.Logger
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	injected map[string]int
+}
+
+// withFaults wraps provider when any fault rate is set
+func withFaults(provider Provider, cfg FaultConfig, logger *logrus.Logger) Provider {
+	if !cfg.enabled() {
+		return provider
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logger.WithFields(logrus.Fields{
+		"timeout":      cfg.TimeoutRate,
+		"rate_limit":   cfg.RateLimitRate,
+		"server_error": cfg.ServerErrorRate,
+		"malformed":    cfg.MalformedRate,
+		"slow":         cfg.SlowRate,
+		"slow_delay":   cfg.SlowDelay,
+		"seed":         seed,
+	}).Warn("🧪 Fault injection enabled: AI calls will fail on purpose")
+	return &faultyProvider{
+		Provider: provider,
+		cfg:      cfg,
+		logger:   logger,
+		rng:      rand.New(rand.NewSource(seed)),
+		injected: make(map[string]int),
+	}
+}
+
+// pick draws the fault for one call ("" = none)
+func (f *faultyProvider) pick() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	draw := f.rng.Float64()
+	for _, fault := range []struct {
+		kind string
+		rate float64
+	}{
+		{FaultTimeout, f.cfg.TimeoutRate},
+		{FaultRateLimit, f.cfg.RateLimitRate},
+		{FaultServerError, f.cfg.ServerErrorRate},
+		{FaultMalformed, f.cfg.MalformedRate},
+		{FaultSlow, f.cfg.SlowRate},
+	} {
+		if draw < fault.rate {
+			f.injected[fault.kind]++
+			return fault.kind
+		}
+		draw -= fault.rate
+	}
+	return ""
+}
+
+// CallModel implements Provider
+func (f *faultyProvider) CallModel(ctx context.Context, req OpenAIRequest, meta requestMeta) (*Completion, error) {
+	kind := f.pick()
+	if kind != "" {
+		f.logger.WithFields(logrus.Fields{
+			"request_id": meta.RequestID,
+			"attempt":    meta.Attempt,
+			"fault":      kind,
+		}).Debug("🧪 Injecting fault")
+	}
+
+	switch kind {
+	case FaultTimeout:
+		return nil, injectedTimeout{}
+	case FaultRateLimit:
+		return nil, &APIStatusError{Status: http.StatusTooManyRequests, Message: "injected rate limit", Type: "fault_injection"}
+	case FaultServerError:
+		return nil, &APIStatusError{Status: http.StatusServiceUnavailable, Message: "injected server error", Type: "fault_injection"}
+	case FaultSlow:
+		select {
+		case <-time.After(f.cfg.SlowDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	completion, err := f.Provider.CallModel(ctx, req, meta)
+	if err == nil && kind == FaultMalformed {
+		truncated := *completion
+		truncated.Content = completion.Content[:len(completion.Content)/2]
+		return &truncated, nil
+	}
+	return completion, err
+}
+
+// Injected returns how many faults of each kind were injected so far
+func (f *faultyProvider) Injected() map[string]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	counts := make(map[string]int, len(f.injected))
+	for kind, n := range f.injected {
+		counts[kind] = n
+	}
+	return counts
+}
+
+// InjectedFaults returns the faults injected per kind (nil when fault injection is off)
+func (ap *AIProcessor) InjectedFaults() map[string]int {
+	if f, ok := ap.provider.(*faultyProvider); ok {
+		return f.Injected()
+	}
+	return nil
+}
@@ -25,6 +25,10 @@ type ResultSummary struct {
 	AverageDuration  time.Duration
 	SuccessRate      float64
 	AvgTokensPerItem int
+	RateLimitWait    time.Duration  // Summed across items (items run concurrently)
+	APITime          time.Duration  // Summed across items
+	BackoffTime      time.Duration  // Summed across items
+	FailureClasses   map[string]int // Failed attempts per failure class
 }
 
 // NewTableFormatter creates a new table formatter instance
@@ -69,7 +73,8 @@ func (tf *TableFormatter) FormatResultsTable(results []ProcessResult) {
 // calculateSummary aggregates statistics from all results
 func (tf *TableFormatter) calculateSummary(results []ProcessResult) ResultSummary {
 	summary := ResultSummary{
-		TotalItems: len(results),
+		TotalItems:     len(results),
+		FailureClasses: make(map[string]int),
 	}
 
 	for _, result := range results {
@@ -81,6 +86,14 @@ func (tf *TableFormatter) calculateSummary(results []ProcessResult) ResultSummar
 		}
 		summary.TotalRetries += result.Retries
 		summary.TotalDuration += result.Duration
+		summary.RateLimitWait += result.RateLimitWait
+		summary.APITime += result.APITime
+		summary.BackoffTime += result.BackoffTime
+		for _, attempt := range result.Attempts {
+			if attempt.FailureClass != "" {
+				summary.FailureClasses[attempt.FailureClass]++
+			}
+		}
 	}
 
 	if summary.TotalItems > 0 {
@@ -110,8 +123,20 @@ func (tf *TableFormatter) displaySummaryStats(summary ResultSummary) {
 		"total_duration":   summary.TotalDuration,
 		"average_per_item": summary.AverageDuration,
 		"total_retries":    summary.TotalRetries,
+		"rate_limit_wait":  summary.RateLimitWait,
+		"api_time":         summary.APITime,
+		"backoff_time":     summary.BackoffTime,
 	}).Info("⚡ Performance Metrics")
 
+	// Failed attempts by class (429s and timeouts point at the provider, not at us)
+	if len(summary.FailureClasses) > 0 {
+		fields := logrus.Fields{}
+		for class, count := range summary.FailureClasses {
+			fields[class] = count
+		}
+		tf.logger.WithFields(fields).Info("🔁 Failed Attempts")
+	}
+
 	// Token usage
 	if summary.TotalTokens > 0 {
 		tf.logger.WithFields(logrus.Fields{
@@ -0,0 +1,138 @@
+package processor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultBaseURL is the OpenAI API base URL used when no gateway is configured
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// Default body size limits
+const (
+	DefaultMaxRequestBytes  = 1 << 20  // 1 MiB
+	DefaultMaxResponseBytes = 10 << 20 // 10 MiB
+)
+
+// bodySnippetLen is how much of an unexpected body is included in error messages
+const bodySnippetLen = 200
+
+// newHTTPClient builds the HTTP client with optional proxy and custom CA bundle
+func newHTTPClient(config Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if config.CABundleFile != "" {
+		pem, err := os.ReadFile(config.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in CA bundle %s", config.CABundleFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+// Authentication header styles (openai.auth_style)
+const (
+	AuthBearer = "bearer"  // Authorization: Bearer <key> (OpenAI and most gateways)
+	AuthAPIKey = "api-key" // api-key: <key> (Azure OpenAI)
+)
+
+// ParseAuthStyle validates an auth style ("" = bearer)
+func ParseAuthStyle(style string) (string, error) {
+	switch style {
+	case "", AuthBearer:
+		return AuthBearer, nil
+	case AuthAPIKey:
+		return AuthAPIKey, nil
+	}
+	return "", fmt.Errorf("openai.auth_style must be %s or %s, got %q", AuthBearer, AuthAPIKey, style)
+}
+
+// chatCompletionsURL returns the chat completions endpoint for the configured base URL, with
+// query and the api-version parameter (Azure OpenAI) when set
+func (c Config) chatCompletionsURL(query url.Values) string {
+	endpoint := strings.TrimRight(c.BaseURL, "/") + "/chat/completions"
+	if c.APIVersion != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("api-version", c.APIVersion)
+	}
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	return endpoint
+}
+
+// setAuth adds the API key header in the configured style
+func (c Config) setAuth(req *http.Request) {
+	if c.AuthStyle == AuthAPIKey {
+		req.Header.Set("api-key", c.APIKey)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+}
+
+// readResponseBody reads the response body up to maxBytes and verifies it is JSON.
+// Oversized or non-JSON bodies (e.g. proxy HTML error pages) are rejected with a short snippet.
+func readResponseBody(resp *http.Response, maxBytes int64) ([]byte, error) {
+	if resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("response too large: status %d, Content-Length %d exceeds limit of %d bytes",
+			resp.StatusCode, resp.ContentLength, maxBytes)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("response too large: status %d, body exceeds limit of %d bytes", resp.StatusCode, maxBytes)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return nil, fmt.Errorf("unexpected response content type %q (status %d): %s",
+				contentType, resp.StatusCode, bodySnippet(body))
+		}
+	}
+
+	return body, nil
+}
+
+// bodySnippet returns a short, single-line preview of a response body for error messages
+func bodySnippet(body []byte) string {
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if len(snippet) > bodySnippetLen {
+		snippet = snippet[:bodySnippetLen] + "..."
+	}
+	return snippet
+}
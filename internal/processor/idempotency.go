@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// requestMeta identifies one logical request across its retry attempts
+type requestMeta struct {
+	RequestID      string // Client-generated ID, sent as X-Client-Request-Id
+	IdempotencyKey string // Content-derived key, sent as Idempotency-Key
+	Attempt        int
+}
+
+// completedResponse is a successful completion remembered for duplicate suppression
+type completedResponse struct {
+	Content    string
+	Usage      Usage
+	ResponseID string
+}
+
+// idempotencyLedger remembers completed responses by idempotency key so that a retry of the same
+// request (e.g. after a timeout-then-success race) is not billed twice. Entries only live while their
+// logical request is retried (see Forget), so a deliberate re-prompt with the same content reaches the
+// provider again and the ledger stays small in a long-running process.
+type idempotencyLedger struct {
+	mu        sync.Mutex
+	completed map[string]completedResponse
+	timedOut  map[string]bool // Keys whose last attempt timed out client-side
+}
+
+func newIdempotencyLedger() *idempotencyLedger {
+	return &idempotencyLedger{
+		completed: make(map[string]completedResponse),
+		timedOut:  make(map[string]bool),
+	}
+}
+
+// MarkTimedOut records that a request may have completed server-side after a client timeout
+func (l *idempotencyLedger) MarkTimedOut(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timedOut[key] = true
+}
+
+// TimedOut reports whether the last attempt for key timed out
+func (l *idempotencyLedger) TimedOut(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.timedOut[key]
+}
+
+// Get returns the completed response for key, if any
+func (l *idempotencyLedger) Get(key string) (completedResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	resp, ok := l.completed[key]
+	return resp, ok
+}
+
+// Store records a completed response for key
+func (l *idempotencyLedger) Store(key string, resp completedResponse) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.completed[key] = resp
+	delete(l.timedOut, key)
+}
+
+// Forget drops key once its logical request has ended
+func (l *idempotencyLedger) Forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.completed, key)
+	delete(l.timedOut, key)
+}
+
+// newRequestMeta creates request metadata for a logical request
+func newRequestMeta(model, systemMessage, prompt, scope string) requestMeta {
+	return requestMeta{
+		RequestID:      newRequestID(),
+		IdempotencyKey: idempotencyKey(model, systemMessage, prompt, scope),
+	}
+}
+
+// newRequestID returns a random UUIDv4 string
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("req-%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// idempotencyKey derives a stable key from the request content
+func idempotencyKey(model, systemMessage, prompt, scope string) string {
+	h := sha256.New()
+	for _, part := range []string{model, systemMessage, prompt, scope} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
@@ -0,0 +1,122 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// openAIProvider calls the OpenAI chat completions endpoint (or a compatible gateway, or Azure OpenAI)
+type openAIProvider struct {
+	config Config
+	client *http.Client
+}
+
+// Name returns "openai"
+func (p *openAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+// CallModel posts a chat completion request and returns the first choice's content
+func (p *openAIProvider) CallModel(ctx context.Context, reqBody OpenAIRequest, meta requestMeta) (*Completion, error) {
+	// Streamed completions fail as soon as the connection goes quiet, not at the end of the attempt timeout
+	var watchdog *time.Timer
+	if p.config.Stream {
+		reqBody.Stream = true
+		reqBody.StreamOptions = &StreamOptions{IncludeUsage: true}
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+		watchdog = time.AfterFunc(p.config.StreamIdleTimeout, func() {
+			cancel(&streamIdleError{idle: p.config.StreamIdleTimeout})
+		})
+		defer watchdog.Stop()
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if int64(len(jsonData)) > p.config.MaxRequestBytes {
+		return nil, fmt.Errorf("request too large: %d bytes exceeds limit of %d bytes", len(jsonData), p.config.MaxRequestBytes)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", p.config.chatCompletionsURL(nil), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	p.config.setAuth(req)
+	if meta.RequestID != "" {
+		req.Header.Set("X-Client-Request-Id", meta.RequestID)
+		req.Header.Set("Idempotency-Key", meta.IdempotencyKey)
+	}
+	for key, value := range p.config.ExtraHeaders {
+		req.Header.Set(key, value)
+	}
+
+	// Execute request
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if cause := context.Cause(ctx); watchdog != nil && cause != nil {
+			return nil, fmt.Errorf("API request failed: %w", cause)
+		}
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if watchdog != nil && isEventStream(resp) {
+		return readStream(ctx, resp, watchdog, p.config.StreamIdleTimeout, p.config.MaxResponseBytes)
+	}
+
+	// Read response (size-capped, JSON only)
+	body, err := readResponseBody(resp, p.config.MaxResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse response
+	var apiResp struct {
+		OpenAIResponse
+		Error *struct {
+			APIError
+			Param string `json:"param"`
+		} `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response (status %d): %w: %s", resp.StatusCode, err, bodySnippet(body))
+	}
+
+	// Check for API errors and response status
+	if apiResp.Error != nil {
+		return nil, &APIStatusError{
+			Status:  resp.StatusCode,
+			Message: apiResp.Error.Message,
+			Type:    apiResp.Error.Type,
+			Code:    apiResp.Error.Code,
+			Param:   apiResp.Error.Param,
+			Body:    string(body),
+
+			RetryAfter: parseRetryAfter(resp.Header),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIStatusError{Status: resp.StatusCode, Body: string(body), RetryAfter: parseRetryAfter(resp.Header)}
+	}
+
+	// Extract content
+	if len(apiResp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	return &Completion{
+		Content:           apiResp.Choices[0].Message.Content,
+		Usage:             apiResp.Usage,
+		ResponseID:        apiResp.ID,
+		ProviderRequestID: resp.Header.Get("X-Request-Id"),
+	}, nil
+}
@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/redact"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrPreflightFailed is wrapped by every preflight error, so callers can tell misconfiguration from data errors
+var ErrPreflightFailed = errors.New("AI preflight failed")
+
+// preflightTimeout bounds the preflight call regardless of the per-attempt timeout
+const preflightTimeout = 30 * time.Second
+
+// Preflight sends one tiny JSON-mode completion to verify the API key, that model (""
+// = the configured model) is available, and that it supports JSON mode. It does not retry.
+func (ap *AIProcessor) Preflight(ctx context.Context, model string) error {
+	if model == "" {
+		model = ap.config.Model
+	}
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	start := time.Now()
+	completion, err := ap.provider.CallModel(ctx, OpenAIRequest{
+		Model: model,
+		Messages: []Message{
+			{Role: "user", Content: `Reply with the JSON object {"ok": true}.`},
+		},
+		ResponseFormat:      ResponseFormat{Type: "json_object"},
+		Temperature:         ap.config.Temperature,
+		MaxCompletionTokens: 16,
+	}, requestMeta{})
+	var apiErr *APIStatusError
+	if errors.As(err, &apiErr) {
+		message := apiErr.Message
+		if message == "" {
+			message = bodySnippet([]byte(apiErr.Body))
+		}
+		return redact.Error(preflightError(apiErr.Status, model, message, apiErr.Code, apiErr.Param))
+	}
+	if err != nil {
+		return redact.Error(fmt.Errorf("%w: %s (%s): %v", ErrPreflightFailed, ap.provider.Name(), ap.config.BaseURL, err))
+	}
+
+	ap.tokenTracker.RecordUsageForModel("preflight", model, completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
+	ap.logger.WithFields(logrus.Fields{
+		"provider": ap.provider.Name(),
+		"model":    model,
+		"endpoint": ap.config.BaseURL,
+		"duration": time.Since(start).Round(time.Millisecond),
+	}).Info("✅ AI preflight passed (key, model and JSON mode)")
+	return nil
+}
+
+// preflightError turns a failed preflight response into an actionable message
+func preflightError(status int, model, message, code, param string) error {
+	lower := strings.ToLower(message)
+	switch {
+	case status == http.StatusUnauthorized || code == "invalid_api_key" || code == "authentication_error":
+		return fmt.Errorf("%w: the API key was rejected (status %d): %s", ErrPreflightFailed, status, message)
+	case status == http.StatusForbidden:
+		return fmt.Errorf("%w: the API key has no access to model %q (status %d): %s", ErrPreflightFailed, model, status, message)
+	case code == "model_not_found" || code == "not_found_error" || status == http.StatusNotFound:
+		return fmt.Errorf("%w: model %q is not available (status %d): %s", ErrPreflightFailed, model, status, message)
+	case param == "response_format" || strings.Contains(lower, "response_format") || strings.Contains(lower, "json mode"):
+		return fmt.Errorf("%w: model %q does not support JSON mode (response_format json_object): %s", ErrPreflightFailed, model, message)
+	case code == "insufficient_quota":
+		return fmt.Errorf("%w: the account has no quota left: %s", ErrPreflightFailed, message)
+	case param != "":
+		return fmt.Errorf("%w: model %q rejected parameter %q (status %d): %s", ErrPreflightFailed, model, param, status, message)
+	default:
+		return fmt.Errorf("%w: API returned status %d: %s", ErrPreflightFailed, status, message)
+	}
+}
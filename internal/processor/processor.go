@@ -1,16 +1,18 @@
 package processor
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"ai-production-pipeline/internal/redact"
+	"ai-production-pipeline/internal/telemetry"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,12 +23,41 @@ type Config struct {
 	Model         string
 	MaxTokens     int
 	Temperature   float64
-	Timeout       time.Duration
-	SystemMessage string // System message for AI model
+	Timeout       time.Duration     // Single attempt (HTTP client timeout)
+	ItemBudget    time.Duration     // Total time for one item across all retries (0 = unlimited)
+	SystemMessage string            // System message for AI model
+	Provider      string            // openai (default) or anthropic
+	BaseURL       string            // API base URL (default: the provider's public API)
+	AuthStyle     string            // bearer (default) or api-key (Azure OpenAI)
+	APIVersion    string            // api-version query parameter (Azure OpenAI; "" = none)
+	ExtraHeaders  map[string]string // Additional headers sent with every request
+
+	// HTTP client settings
+	ProxyURL         string // Egress proxy URL (empty = environment proxy settings)
+	CABundleFile     string // Extra PEM CA certificates to trust
+	MaxRequestBytes  int64  // Maximum request body size
+	MaxResponseBytes int64  // Maximum response body size
+
+	// StoreResponses stores completions with the provider so a retry after a client-side
+	// timeout can recover the original completion instead of paying for a new one
+	StoreResponses bool
+
+	// Stream completions as server-sent events (openai provider): content reaches WithStreamHandler
+	// callbacks as it is generated, and a connection that goes quiet for StreamIdleTimeout fails early
+	Stream            bool
+	StreamIdleTimeout time.Duration
+
+	// Response cache (development): identical requests are answered from disk instead of billed again
+	CacheDir string        // "" = no cache
+	CacheTTL time.Duration // 0 = entries never expire
+
+	// Fault injection (resilience testing): provider calls fail on purpose at these rates
+	Faults FaultConfig
 
 	// Batch settings
 	BatchSize     int
 	MaxConcurrent int
+	AutoTune      AutoTuneConfig // Adjust concurrency from observed latency and errors (starts at MaxConcurrent)
 
 	// Rate limit settings
 	RateLimitPerMin int
@@ -48,24 +79,37 @@ type AIProcessor struct {
 	config       Config
 	logger       *logrus.Logger
 	httpClient   *http.Client
+	provider     Provider
 	rateLimiter  *RateLimiter
 	tokenTracker *TokenTracker
+	ledger       *idempotencyLedger
+	cache        *responseCache // nil = no response cache
+	limiter      *concurrencyLimiter
 }
 
 // RateLimiter implements token bucket algorithm for rate limiting
 type RateLimiter struct {
 	tokens     chan struct{}
 	refillRate time.Duration
-	mu         sync.Mutex
+	logger     *logrus.Logger
+
+	mu          sync.Mutex
+	limit       int       // Current bucket size, below capacity after the provider rate limited us
+	pausedUntil time.Time // No tokens are handed out before this (provider's Retry-After)
 }
 
 // OpenAIRequest represents the API request structure
 type OpenAIRequest struct {
-	Model               string         `json:"model"`
-	Messages            []Message      `json:"messages"`
-	ResponseFormat      ResponseFormat `json:"response_format,omitempty"`
-	Temperature         float64        `json:"temperature,omitempty"`
-	MaxCompletionTokens int            `json:"max_completion_tokens,omitempty"` // Updated for newer models
+	Model               string            `json:"model"`
+	Messages            []Message         `json:"messages"`
+	ResponseFormat      ResponseFormat    `json:"response_format,omitempty"`
+	Temperature         float64           `json:"temperature,omitempty"`
+	MaxCompletionTokens int               `json:"max_completion_tokens,omitempty"` // Updated for newer models
+	Store               bool              `json:"store,omitempty"`                 // Store completion so it can be recovered after timeouts
+	Stream              bool              `json:"stream,omitempty"`                // Server-sent events, set by the provider (Config.Stream)
+	StreamOptions       *StreamOptions    `json:"stream_options,omitempty"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
+	ServiceTier         string            `json:"service_tier,omitempty"` // e.g. "flex" for downgraded requests (see WithTier)
 }
 
 // Message represents a chat message
@@ -76,7 +120,15 @@ type Message struct {
 
 // ResponseFormat specifies JSON response format
 type ResponseFormat struct {
-	Type string `json:"type"`
+	Type       string      `json:"type"`                  // "json_object", "json_schema" or "text"
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"` // Required when Type is "json_schema"
+}
+
+// JSONSchema describes a structured output schema
+type JSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict,omitempty"`
 }
 
 // OpenAIResponse represents the API response structure
@@ -121,10 +173,26 @@ type ProcessResult struct {
 	Retries    int
 	Duration   time.Duration
 	TokenUsage Usage
+
+	// Where the time went, so "the API is slow" can be told apart from "we throttled ourselves"
+	Attempts      []AttemptInfo // One per API call, with its failure class
+	RateLimitWait time.Duration // Waiting on our own rate limiter
+	APITime       time.Duration // Waiting on the provider
+	BackoffTime   time.Duration // Sleeping between retries
+}
+
+// withAttempts fills in the attempt details and time split
+func (r ProcessResult) withAttempts(attempts []AttemptInfo) ProcessResult {
+	r.Attempts = attempts
+	r.RateLimitWait, r.APITime, r.BackoffTime = timeSplit(attempts)
+	return r
 }
 
 // NewAIProcessor creates a new AI processor instance with all production features
-func NewAIProcessor(config Config, logger *logrus.Logger) *AIProcessor {
+func NewAIProcessor(config Config, logger *logrus.Logger) (*AIProcessor, error) {
+	// The key must never show up in logs or errors (e.g. echoed back in a 401 body)
+	redact.Register(config.APIKey)
+
 	// Set defaults if not provided
 	if config.MaxRetries == 0 {
 		config.MaxRetries = 3
@@ -147,26 +215,112 @@ func NewAIProcessor(config Config, logger *logrus.Logger) *AIProcessor {
 	if config.RateLimitPerMin == 0 {
 		config.RateLimitPerMin = 60
 	}
+	provider, err := ParseProvider(config.Provider)
+	if err != nil {
+		return nil, err
+	}
+	config.Provider = provider
+	authStyle, err := ParseAuthStyle(config.AuthStyle)
+	if err != nil {
+		return nil, err
+	}
+	if authStyle == AuthAPIKey && provider != ProviderOpenAI {
+		return nil, fmt.Errorf("openai.auth_style %s is only supported by %s", AuthAPIKey, ProviderOpenAI)
+	}
+	config.AuthStyle = authStyle
+	if config.BaseURL == "" {
+		config.BaseURL = defaultBaseURL(provider)
+	}
+	if config.StoreResponses && provider != ProviderOpenAI {
+		logger.Warnf("⚠️  store_responses is only supported by %s, ignoring it for %s", ProviderOpenAI, provider)
+		config.StoreResponses = false
+	}
+	if config.Stream && provider != ProviderOpenAI {
+		logger.Warnf("⚠️  stream is only supported by %s, ignoring it for %s", ProviderOpenAI, provider)
+		config.Stream = false
+	}
+	if config.StreamIdleTimeout == 0 {
+		config.StreamIdleTimeout = DefaultStreamIdleTimeout
+	}
+	if config.MaxRequestBytes == 0 {
+		config.MaxRequestBytes = DefaultMaxRequestBytes
+	}
+	if config.MaxResponseBytes == 0 {
+		config.MaxResponseBytes = DefaultMaxResponseBytes
+	}
+
+	httpClient, err := newHTTPClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	cache, err := newResponseCache(config.CacheDir, config.CacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Faults.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.AutoTune.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Faults.enabled() && cache != nil {
+		logger.Warn("⚠️  Response cache disabled while fault injection is on (it would hide or store injected faults)")
+		cache = nil
+	}
 
 	logger.WithFields(logrus.Fields{
+		"provider":         config.Provider,
 		"model":            config.Model,
 		"batch_size":       config.BatchSize,
 		"max_concurrent":   config.MaxConcurrent,
 		"rate_limit":       config.RateLimitPerMin,
 		"max_retries":      config.MaxRetries,
 		"timeout":          config.Timeout,
+		"item_budget":      config.ItemBudget,
 		"exponential_back": config.ExponentialBackoff,
+		"base_url":         config.BaseURL,
+		"auth_style":       config.AuthStyle,
+		"proxy":            config.ProxyURL != "",
+		"response_cache":   config.CacheDir,
+		"stream":           config.Stream,
 	}).Info("✅ AI Processor initialized")
 
 	return &AIProcessor{
-		config: config,
-		logger: logger,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
+		config:       config,
+		logger:       logger,
+		httpClient:   httpClient,
+		provider:     withFaults(newProvider(config, httpClient), config.Faults, logger),
 		rateLimiter:  NewRateLimiter(config.RateLimitPerMin, logger),
 		tokenTracker: NewTokenTracker(config.Model),
+		ledger:       newIdempotencyLedger(),
+		cache:        cache,
+		limiter:      newConcurrencyLimiter(config.MaxConcurrent, config.AutoTune, logger),
+	}, nil
+}
+
+// ErrItemBudgetExceeded is returned when an item's total time budget runs out before a retry succeeds
+var ErrItemBudgetExceeded = errors.New("item time budget exceeded")
+
+// itemContext bounds one item's attempts and retry delays by the configured item budget
+func (ap *AIProcessor) itemContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ap.config.ItemBudget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, ap.config.ItemBudget)
+}
+
+// checkItemBudget returns ErrItemBudgetExceeded (wrapping lastErr) when there is no time left
+// for the retry delay plus a useful attempt
+func (ap *AIProcessor) checkItemBudget(ctx context.Context, delay time.Duration, lastErr error) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	if remaining := time.Until(deadline); remaining <= delay {
+		return fmt.Errorf("%w (%v left, next retry in %v): %v", ErrItemBudgetExceeded, remaining.Round(time.Millisecond), delay, lastErr)
 	}
+	return nil
 }
 
 // NewRateLimiter creates a new token bucket rate limiter
@@ -174,6 +328,8 @@ func NewRateLimiter(requestsPerMinute int, logger *logrus.Logger) *RateLimiter {
 	rl := &RateLimiter{
 		tokens:     make(chan struct{}, requestsPerMinute),
 		refillRate: time.Minute / time.Duration(requestsPerMinute),
+		logger:     logger,
+		limit:      requestsPerMinute,
 	}
 
 	// Fill initial tokens
@@ -182,18 +338,32 @@ func NewRateLimiter(requestsPerMinute int, logger *logrus.Logger) *RateLimiter {
 	}
 
 	// Start refilling goroutine
-	go rl.refill(logger)
+	go rl.refill()
 
 	logger.WithField("rate_limit", requestsPerMinute).Info("✅ Rate limiter initialized")
 	return rl
 }
 
-// refill continuously adds tokens to the bucket
-func (rl *RateLimiter) refill(logger *logrus.Logger) {
+// refill continuously adds tokens to the bucket, except while throttled
+func (rl *RateLimiter) refill() {
 	ticker := time.NewTicker(rl.refillRate)
 	defer ticker.Stop()
 
 	for range ticker.C {
+		rl.mu.Lock()
+		if time.Now().Before(rl.pausedUntil) {
+			rl.mu.Unlock()
+			continue
+		}
+		if rl.limit < cap(rl.tokens) {
+			rl.limit++
+		}
+		limit := rl.limit
+		rl.mu.Unlock()
+		if len(rl.tokens) >= limit {
+			continue
+		}
+
 		select {
 		case rl.tokens <- struct{}{}:
 			// Token added successfully
@@ -203,9 +373,28 @@ func (rl *RateLimiter) refill(logger *logrus.Logger) {
 	}
 }
 
-// Wait blocks until a token is available
-func (rl *RateLimiter) Wait() {
-	<-rl.tokens
+// Wait blocks until a token is available (and the provider's Retry-After has passed), or until ctx
+// is done, in which case it returns ctx's error
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		select {
+		case <-rl.tokens:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		rl.mu.Lock()
+		pause := time.Until(rl.pausedUntil)
+		rl.mu.Unlock()
+		if pause <= 0 {
+			return nil
+		}
+		// Throttled after this caller started waiting: the token is spent, wait out the pause for another
+		select {
+		case <-time.After(pause):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 }
 
 // GetTokenTracker returns the token tracker for reporting
@@ -217,55 +406,143 @@ func (ap *AIProcessor) GetTokenTracker() *TokenTracker {
 func (ap *AIProcessor) PrintTokenReport() {
 	report := ap.tokenTracker.GetDetailedReport()
 	ap.logger.Info("\n" + report)
+	if faults := ap.InjectedFaults(); faults != nil {
+		ap.logger.WithFields(logrus.Fields{
+			FaultTimeout:     faults[FaultTimeout],
+			FaultRateLimit:   faults[FaultRateLimit],
+			FaultServerError: faults[FaultServerError],
+			FaultMalformed:   faults[FaultMalformed],
+			FaultSlow:        faults[FaultSlow],
+		}).Warn("🧪 Injected faults")
+	}
 }
 
 // ProcessSingleWithWeek processes a single prompt and returns response with week tracking
 func (ap *AIProcessor) ProcessSingleWithWeek(ctx context.Context, prompt, systemMessage, weekLabel string) (string, error) {
+	response, _, err := ap.ProcessSingleWithUsage(ctx, prompt, systemMessage, weekLabel)
+	return response, err
+}
+
+// ProcessSingleWithUsage is ProcessSingleWithWeek that also returns the call's own token usage and
+// cost, which the shared tracker's totals cannot tell apart from concurrent calls
+func (ap *AIProcessor) ProcessSingleWithUsage(ctx context.Context, prompt, systemMessage, weekLabel string) (string, TokenUsage, error) {
 	// Wait for rate limit token
-	ap.rateLimiter.Wait()
+	waitStart := time.Now()
+	if err := ap.rateLimiter.Wait(ctx); err != nil {
+		return "", TokenUsage{}, err
+	}
+	rateLimitWait := time.Since(waitStart)
 
 	startTime := time.Now()
 
+	// Create request with system message
+	fullPrompt := prompt
+	if systemMessage != "" {
+		fullPrompt = fmt.Sprintf("System: %s\n\nUser: %s", systemMessage, prompt)
+	}
+
+	// A downgraded request runs on the tier's model (see WithTier)
+	tier := tierFrom(ctx)
+	model := ap.config.Model
+	if tier.Model != "" {
+		model = tier.Model
+	}
+
+	// Same logical request keeps its IDs across retries
+	meta := newRequestMeta(model, ap.config.SystemMessage, fullPrompt, weekLabel)
+
 	// Call OpenAI with retry
+	response, usage, err := ap.callWithRetry(ctx, &meta, rateLimitWait, func(ctx context.Context, meta requestMeta) (string, Usage, error) {
+		return ap.callOpenAI(ctx, fullPrompt, meta)
+	})
+	duration := time.Since(startTime)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+
+	// Record token usage
+	var recorded TokenUsage
+	if tier != (Tier{}) {
+		recorded = ap.tokenTracker.RecordUsageForTier(weekLabel, model, tier, usage.PromptTokens, usage.CompletionTokens)
+	} else {
+		recorded = ap.tokenTracker.RecordUsage(weekLabel, usage.PromptTokens, usage.CompletionTokens)
+	}
+
+	if ap.config.TrackTiming {
+		ap.logger.Infof("✅ Processed in %v", duration)
+	}
+
+	return response, recorded, nil
+}
+
+// callWithRetry runs call with the configured retries and backoff. The item budget bounds all
+// attempts together; each attempt is also bounded by the HTTP timeout. meta.Attempt is updated per
+// attempt, and every attempt (rateLimitWait goes to the first) is added to the context's attempt log.
+// A retry whose request already completed is answered from the idempotency ledger, which forgets the
+// request when it ends.
+func (ap *AIProcessor) callWithRetry(ctx context.Context, meta *requestMeta, rateLimitWait time.Duration, call func(ctx context.Context, meta requestMeta) (string, Usage, error)) (string, Usage, error) {
+	itemCtx, cancel := ap.itemContext(ctx)
+	defer cancel()
+	defer ap.ledger.Forget(meta.IdempotencyKey)
+
 	var response string
 	var usage Usage
 	var err error
+	var attempts []AttemptInfo
+	defer func() { attemptLogFrom(ctx).add(ap, attempts) }()
 
 	for attempt := 0; attempt < ap.config.MaxRetries; attempt++ {
 		if attempt > 0 {
-			delay := ap.calculateRetryDelay(attempt)
+			delay := ap.retryDelay(attempt, err)
+			if budgetErr := ap.checkItemBudget(itemCtx, delay, err); budgetErr != nil {
+				err = budgetErr
+				break
+			}
 			ap.logger.Warnf("Retry attempt %d/%d after %v", attempt, ap.config.MaxRetries, delay)
-			time.Sleep(delay)
+			backoffStart := time.Now()
+			select {
+			case <-time.After(delay):
+			case <-itemCtx.Done():
+			}
+			attempts[len(attempts)-1].Backoff = time.Since(backoffStart)
+
+			if cached, ok := ap.ledger.Get(meta.IdempotencyKey); ok {
+				ap.logger.WithFields(logrus.Fields{
+					"idempotency_key": meta.IdempotencyKey,
+					"response_id":     cached.ResponseID,
+				}).Warn("♻️  Request already completed, reusing its response instead of retrying")
+				return cached.Content, cached.Usage, nil
+			}
 		}
 
-		// Create request with system message
-		fullPrompt := prompt
-		if systemMessage != "" {
-			fullPrompt = fmt.Sprintf("System: %s\n\nUser: %s", systemMessage, prompt)
+		meta.Attempt = attempt + 1
+		info := AttemptInfo{Attempt: attempt + 1}
+		if attempt == 0 {
+			info.RateLimitWait = rateLimitWait
 		}
-
-		response, usage, err = ap.callOpenAI(ctx, fullPrompt)
+		callStart := time.Now()
+		response, usage, err = call(itemCtx, *meta)
+		info.APITime = time.Since(callStart)
+		if err != nil {
+			info.FailureClass, info.Status = classifyFailure(err)
+		}
+		attempts = append(attempts, info)
 		if err == nil {
-			// Record token usage
-			ap.tokenTracker.RecordUsage(weekLabel, usage.PromptTokens, usage.CompletionTokens)
-			break
+			return response, usage, nil
 		}
 
-		ap.logger.Warnf("Attempt %d failed: %v", attempt+1, err)
-	}
-
-	duration := time.Since(startTime)
-
-	if err != nil {
-		ap.logger.Errorf("All %d attempts failed: %v", ap.config.MaxRetries, err)
-		return "", fmt.Errorf("failed after %d attempts: %w", ap.config.MaxRetries, err)
+		ap.logger.WithField("request_id", meta.RequestID).Warnf("Attempt %d failed: %v", attempt+1, err)
+		if isQuotaExhausted(err) {
+			break
+		}
 	}
 
-	if ap.config.TrackTiming {
-		ap.logger.Infof("✅ Processed in %v", duration)
+	if errors.Is(err, ErrItemBudgetExceeded) {
+		ap.logger.WithField("request_id", meta.RequestID).Errorf("Item budget exhausted after %d attempt(s): %v", meta.Attempt, err)
+		return "", Usage{}, fmt.Errorf("request_id %s: %w", meta.RequestID, err)
 	}
-
-	return response, nil
+	ap.logger.WithField("request_id", meta.RequestID).Errorf("All %d attempts failed: %v", ap.config.MaxRetries, err)
+	return "", Usage{}, fmt.Errorf("failed after %d attempts (request_id %s): %w", ap.config.MaxRetries, meta.RequestID, err)
 }
 
 // ProcessSingle processes a single prompt and returns response (legacy, without week tracking)
@@ -276,7 +553,9 @@ func (ap *AIProcessor) ProcessSingle(ctx context.Context, prompt, systemMessage
 // ProcessSingleDeprecated is the old implementation kept for compatibility
 func (ap *AIProcessor) ProcessSingleDeprecated(ctx context.Context, prompt, systemMessage string) (string, error) {
 	// Wait for rate limit token
-	ap.rateLimiter.Wait()
+	if err := ap.rateLimiter.Wait(ctx); err != nil {
+		return "", err
+	}
 
 	startTime := time.Now()
 
@@ -284,6 +563,14 @@ func (ap *AIProcessor) ProcessSingleDeprecated(ctx context.Context, prompt, syst
 	var response string
 	var err error
 
+	// Create request with system message
+	fullPrompt := prompt
+	if systemMessage != "" {
+		fullPrompt = fmt.Sprintf("System: %s\n\nUser: %s", systemMessage, prompt)
+	}
+	meta := newRequestMeta(ap.config.Model, ap.config.SystemMessage, fullPrompt, "")
+	defer ap.ledger.Forget(meta.IdempotencyKey)
+
 	for attempt := 0; attempt < ap.config.MaxRetries; attempt++ {
 		if attempt > 0 {
 			delay := ap.calculateRetryDelay(attempt)
@@ -291,13 +578,8 @@ func (ap *AIProcessor) ProcessSingleDeprecated(ctx context.Context, prompt, syst
 			time.Sleep(delay)
 		}
 
-		// Create request with system message
-		fullPrompt := prompt
-		if systemMessage != "" {
-			fullPrompt = fmt.Sprintf("System: %s\n\nUser: %s", systemMessage, prompt)
-		}
-
-		response, _, err = ap.callOpenAI(ctx, fullPrompt)
+		meta.Attempt = attempt + 1
+		response, _, err = ap.callOpenAI(ctx, fullPrompt, meta)
 		if err == nil {
 			break
 		}
@@ -321,15 +603,109 @@ func (ap *AIProcessor) ProcessSingleDeprecated(ctx context.Context, prompt, syst
 
 // ProcessBatch processes multiple items in batches with controlled concurrency and resilience
 func (ap *AIProcessor) ProcessBatch(ctx context.Context, items []interface{}, promptTemplate func(interface{}) string) []ProcessResult {
+	return ap.runBatch(ctx, items, func(ctx context.Context, index int, item interface{}) (ProcessResult, []AttemptInfo) {
+		result := ap.processItemWithRetry(ctx, index, item, promptTemplate)
+		return result, result.Attempts
+	})
+}
+
+// ItemFunc does the work for one batch item, including its own API calls, retries and token tracking
+type ItemFunc func(ctx context.Context, index int, item interface{}) error
+
+// ProcessBatchFunc runs fn for every item with the same batching, concurrency limit and progress
+// logging as ProcessBatch, for callers whose items need more than a single prompt. The attempts of
+// every API call fn makes through the processor are recorded in the item's result, and this
+// processor's own attempts feed its concurrency auto-tuning.
+func (ap *AIProcessor) ProcessBatchFunc(ctx context.Context, items []interface{}, fn ItemFunc) []ProcessResult {
+	return ap.runBatch(ctx, items, func(ctx context.Context, index int, item interface{}) (ProcessResult, []AttemptInfo) {
+		startTime := time.Now()
+		ctx, log := withAttemptLog(ctx)
+		err := fn(ctx, index, item)
+		attempts := log.list()
+		return ProcessResult{
+			Index:    index,
+			Input:    item,
+			Success:  err == nil,
+			Error:    err,
+			Retries:  retries(attempts),
+			Duration: time.Since(startTime),
+		}.withAttempts(attempts), log.madeBy(ap)
+	})
+}
+
+// ProcessStream runs fn for each item next returns, as soon as a concurrency slot is free (the same
+// limit and auto-tuning as ProcessBatchFunc), until next reports no more items or ctx is done. next is
+// only called with a slot held, so at most the concurrency limit of items are in memory at once, and
+// no per-item results are kept: fn stores what it needs.
+func (ap *AIProcessor) ProcessStream(ctx context.Context, next func() (interface{}, bool), fn ItemFunc) {
+	ap.logger.WithField("max_concurrent", ap.limiter.Limit()).Info("🚀 Starting stream processing")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var successful, failed, totalRetries int
+	var rateLimitWait, apiTime, backoffTime time.Duration
+	startTime := time.Now()
+
+	for index := 0; ; index++ {
+		if !ap.limiter.acquire(ctx) {
+			break
+		}
+		item, ok := next()
+		if !ok {
+			ap.limiter.release(nil)
+			break
+		}
+
+		wg.Add(1)
+		go func(index int, item interface{}) {
+			defer wg.Done()
+			itemCtx, log := withAttemptLog(ctx)
+			err := fn(itemCtx, index, item)
+			ap.limiter.release(log.madeBy(ap))
+
+			attempts := log.list()
+			wait, api, backoff := timeSplit(attempts)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				successful++
+			} else {
+				failed++
+			}
+			totalRetries += retries(attempts)
+			rateLimitWait += wait
+			apiTime += api
+			backoffTime += backoff
+		}(index, item)
+	}
+	wg.Wait()
+
+	ap.logger.WithFields(logrus.Fields{
+		"total_items":     successful + failed,
+		"successful":      successful,
+		"failed":          failed,
+		"total_retries":   totalRetries,
+		"total_duration":  time.Since(startTime),
+		"rate_limit_wait": rateLimitWait,
+		"api_time":        apiTime,
+		"backoff_time":    backoffTime,
+	}).Info("🎉 STREAM PROCESSING COMPLETED")
+}
+
+// runBatch processes items in batches of BatchSize, at most MaxConcurrent at a time (or the auto-tuned
+// limit), and logs a summary. process returns the item's result and the attempts the limiter learns from.
+func (ap *AIProcessor) runBatch(ctx context.Context, items []interface{}, process func(ctx context.Context, index int, item interface{}) (ProcessResult, []AttemptInfo)) []ProcessResult {
+	if len(items) == 0 {
+		return nil
+	}
 	ap.logger.WithFields(logrus.Fields{
 		"total_items":    len(items),
 		"batch_size":     ap.config.BatchSize,
-		"max_concurrent": ap.config.MaxConcurrent,
+		"max_concurrent": ap.limiter.Limit(),
 	}).Info("🚀 Starting batch processing")
 
 	results := make([]ProcessResult, len(items))
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, ap.config.MaxConcurrent)
 
 	startTime := time.Now()
 	processedCount := 0
@@ -358,12 +734,8 @@ func (ap *AIProcessor) ProcessBatch(ctx context.Context, items []interface{}, pr
 			go func(index int, item interface{}) {
 				defer wg.Done()
 
-				// Acquire semaphore slot
-				semaphore <- struct{}{}
-				defer func() { <-semaphore }()
-
-				// Check context cancellation
-				if ctx.Err() != nil {
+				// Acquire a concurrency slot, or give up on cancellation
+				if !ap.limiter.acquire(ctx) {
 					results[index] = ProcessResult{
 						Index:   index,
 						Input:   item,
@@ -373,9 +745,9 @@ func (ap *AIProcessor) ProcessBatch(ctx context.Context, items []interface{}, pr
 					return
 				}
 
-				// Process item with retry logic
-				result := ap.processItemWithRetry(ctx, index, item, promptTemplate)
-				results[index] = result
+				var observed []AttemptInfo
+				results[index], observed = process(ctx, index, item)
+				ap.limiter.release(observed)
 
 				// Update progress
 				if ap.config.ShowProgress {
@@ -409,6 +781,7 @@ func (ap *AIProcessor) ProcessBatch(ctx context.Context, items []interface{}, pr
 	failed := 0
 	totalRetries := 0
 	totalTokens := 0
+	var rateLimitWait, apiTime, backoffTime time.Duration
 
 	for _, result := range results {
 		if result.Success {
@@ -418,18 +791,24 @@ func (ap *AIProcessor) ProcessBatch(ctx context.Context, items []interface{}, pr
 			failed++
 		}
 		totalRetries += result.Retries
+		rateLimitWait += result.RateLimitWait
+		apiTime += result.APITime
+		backoffTime += result.BackoffTime
 	}
 
 	ap.logger.Info("=" + strings.Repeat("=", 100))
 	ap.logger.WithFields(logrus.Fields{
-		"total_items":    len(items),
-		"successful":     successful,
-		"failed":         failed,
-		"success_rate":   fmt.Sprintf("%.2f%%", float64(successful)/float64(len(items))*100),
-		"total_retries":  totalRetries,
-		"total_tokens":   totalTokens,
-		"total_duration": duration,
-		"avg_per_item":   duration / time.Duration(len(items)),
+		"total_items":     len(items),
+		"successful":      successful,
+		"failed":          failed,
+		"success_rate":    fmt.Sprintf("%.2f%%", float64(successful)/float64(len(items))*100),
+		"total_retries":   totalRetries,
+		"total_tokens":    totalTokens,
+		"total_duration":  duration,
+		"avg_per_item":    duration / time.Duration(len(items)),
+		"rate_limit_wait": rateLimitWait,
+		"api_time":        apiTime,
+		"backoff_time":    backoffTime,
 	}).Info("🎉 BATCH PROCESSING COMPLETED")
 	ap.logger.Info("=" + strings.Repeat("=", 100))
 
@@ -440,10 +819,21 @@ func (ap *AIProcessor) ProcessBatch(ctx context.Context, items []interface{}, pr
 func (ap *AIProcessor) processItemWithRetry(ctx context.Context, index int, item interface{}, promptTemplate func(interface{}) string) ProcessResult {
 	startTime := time.Now()
 	var lastError error
+	var meta requestMeta
+	var attempts []AttemptInfo
 	retryCount := 0
 
+	// Bound the time this item holds a concurrency slot across all retries
+	parentCtx := ctx
+	ctx, cancel := ap.itemContext(ctx)
+	defer cancel()
+
 	for attempt := 0; attempt <= ap.config.MaxRetries; attempt++ {
 		// Check context before attempting
+		if ctx.Err() != nil && parentCtx.Err() == nil {
+			lastError = fmt.Errorf("%w: %v", ErrItemBudgetExceeded, lastError)
+			break
+		}
 		if ctx.Err() != nil {
 			return ProcessResult{
 				Index:    index,
@@ -452,11 +842,22 @@ func (ap *AIProcessor) processItemWithRetry(ctx context.Context, index int, item
 				Error:    ctx.Err(),
 				Retries:  retryCount,
 				Duration: time.Since(startTime),
-			}
+			}.withAttempts(attempts)
 		}
 
 		// Wait for rate limiter
-		ap.rateLimiter.Wait()
+		waitStart := time.Now()
+		if err := ap.rateLimiter.Wait(ctx); err != nil {
+			return ProcessResult{
+				Index:    index,
+				Input:    item,
+				Success:  false,
+				Error:    err,
+				Retries:  retryCount,
+				Duration: time.Since(startTime),
+			}.withAttempts(attempts)
+		}
+		info := AttemptInfo{Attempt: attempt + 1, RateLimitWait: time.Since(waitStart)}
 
 		// Generate prompt
 		prompt := promptTemplate(item)
@@ -468,11 +869,24 @@ func (ap *AIProcessor) processItemWithRetry(ctx context.Context, index int, item
 				Error:    fmt.Errorf("empty prompt generated"),
 				Retries:  0,
 				Duration: time.Since(startTime),
-			}
+			}.withAttempts(attempts)
+		}
+
+		// Keep request IDs stable across retries of the same item
+		if meta.RequestID == "" {
+			meta = newRequestMeta(ap.config.Model, ap.config.SystemMessage, prompt, "")
+			defer ap.ledger.Forget(meta.IdempotencyKey)
 		}
+		meta.Attempt = attempt + 1
 
 		// Call OpenAI API
-		output, usage, err := ap.callOpenAI(ctx, prompt)
+		callStart := time.Now()
+		output, usage, err := ap.callOpenAI(ctx, prompt, meta)
+		info.APITime = time.Since(callStart)
+		if err != nil {
+			info.FailureClass, info.Status = classifyFailure(err)
+		}
+		attempts = append(attempts, info)
 		if err == nil {
 			// Success
 			duration := time.Since(startTime)
@@ -491,30 +905,45 @@ func (ap *AIProcessor) processItemWithRetry(ctx context.Context, index int, item
 				Retries:    retryCount,
 				Duration:   duration,
 				TokenUsage: usage,
-			}
+			}.withAttempts(attempts)
 		}
 
 		// Handle error
 		lastError = err
 		retryCount++
 
+		if isQuotaExhausted(err) {
+			break
+		}
 		if attempt < ap.config.MaxRetries {
-			// Calculate retry delay
-			delay := ap.calculateRetryDelay(attempt)
+			// Calculate retry delay (the provider's Retry-After when it asked for longer)
+			delay := ap.retryDelay(attempt, err)
+			if budgetErr := ap.checkItemBudget(ctx, delay, err); budgetErr != nil {
+				lastError = budgetErr
+				break
+			}
 
 			ap.logger.WithFields(logrus.Fields{
-				"index":        index,
-				"attempt":      attempt + 1,
-				"max_attempts": ap.config.MaxRetries + 1,
-				"error":        err.Error(),
-				"retry_in":     delay,
+				"index":         index,
+				"attempt":       attempt + 1,
+				"max_attempts":  ap.config.MaxRetries + 1,
+				"error":         err.Error(),
+				"failure_class": info.FailureClass,
+				"retry_in":      delay,
 			}).Warn("⚠️ Request failed, retrying...")
 
 			// Wait before retry
+			backoffStart := time.Now()
 			select {
 			case <-time.After(delay):
 				// Continue to retry
+				attempts[len(attempts)-1].Backoff = time.Since(backoffStart)
 			case <-ctx.Done():
+				attempts[len(attempts)-1].Backoff = time.Since(backoffStart)
+				if parentCtx.Err() == nil {
+					// Item budget ran out; reported at the top of the loop
+					continue
+				}
 				return ProcessResult{
 					Index:    index,
 					Input:    item,
@@ -522,7 +951,7 @@ func (ap *AIProcessor) processItemWithRetry(ctx context.Context, index int, item
 					Error:    ctx.Err(),
 					Retries:  retryCount,
 					Duration: time.Since(startTime),
-				}
+				}.withAttempts(attempts)
 			}
 		}
 	}
@@ -543,7 +972,7 @@ func (ap *AIProcessor) processItemWithRetry(ctx context.Context, index int, item
 		Error:    lastError,
 		Retries:  retryCount,
 		Duration: duration,
-	}
+	}.withAttempts(attempts)
 }
 
 // calculateRetryDelay calculates the delay before next retry
@@ -561,13 +990,21 @@ func (ap *AIProcessor) calculateRetryDelay(attempt int) time.Duration {
 }
 
 // callOpenAI makes a call to the OpenAI API
-func (ap *AIProcessor) callOpenAI(ctx context.Context, prompt string) (string, Usage, error) {
+func (ap *AIProcessor) callOpenAI(ctx context.Context, prompt string, meta requestMeta) (string, Usage, error) {
 	// Use configured system message or default
 	systemMsg := ap.config.SystemMessage
 	if systemMsg == "" {
 		systemMsg = "Bạn là chuyên gia phân tích dữ liệu dành cho ứng dụng giáo dục tài chính trẻ em. Trả về CHÍNH XÁC định dạng JSON được yêu cầu, không thêm markdown hay text khác."
 	}
 
+	// A previous attempt timed out: the provider may have finished it anyway
+	if ap.config.StoreResponses && ap.ledger.TimedOut(meta.IdempotencyKey) {
+		if content, usage, ok := ap.recoverStoredCompletion(ctx, meta); ok {
+			ap.ledger.Store(meta.IdempotencyKey, completedResponse{Content: content, Usage: usage})
+			return content, usage, nil
+		}
+	}
+
 	// Prepare request
 	reqBody := OpenAIRequest{
 		Model: ap.config.Model,
@@ -585,57 +1022,79 @@ func (ap *AIProcessor) callOpenAI(ctx context.Context, prompt string) (string, U
 		Temperature:         ap.config.Temperature,
 		MaxCompletionTokens: ap.config.MaxTokens,
 	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
+	if tier := tierFrom(ctx); tier != (Tier{}) {
+		if tier.Model != "" {
+			reqBody.Model = tier.Model
+		}
+		reqBody.ServiceTier = tier.ServiceTier
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+ap.config.APIKey)
+	return ap.send(ctx, reqBody, meta)
+}
 
-	// Execute request
-	resp, err := ap.httpClient.Do(req)
-	if err != nil {
-		return "", Usage{}, fmt.Errorf("API request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// send posts a chat request to the provider and returns the response content
+func (ap *AIProcessor) send(ctx context.Context, reqBody OpenAIRequest, meta requestMeta) (_ string, _ Usage, err error) {
+	// API and transport errors can echo credentials; mask them before they reach logs
+	defer func() { err = redact.Error(err) }()
 
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", Usage{}, fmt.Errorf("failed to read response: %w", err)
+	// Identical request answered before: no tokens are billed, so no usage is reported
+	var cacheKey string
+	if ap.cache != nil {
+		cacheKey = responseCacheKey(ap.provider.Name(), reqBody)
+		if cached, ok := ap.cache.Get(cacheKey); ok {
+			ap.logger.WithFields(logrus.Fields{
+				"request_id": meta.RequestID,
+				"cache_key":  cacheKey[:12],
+			}).Debug("💾 Response served from cache")
+			telemetry.AIRequests.Inc(reqBody.Model, "cached")
+			return cached.Content, Usage{}, nil
+		}
 	}
 
-	// Parse response
-	var apiResp OpenAIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return "", Usage{}, fmt.Errorf("failed to parse response: %w", err)
+	if ap.config.StoreResponses {
+		reqBody.Store = true
+		reqBody.Metadata = storeMetadata(meta)
 	}
 
-	// Check for API errors
-	if apiResp.Error != nil {
-		return "", Usage{}, fmt.Errorf("API error: %s (%s)", apiResp.Error.Message, apiResp.Error.Type)
+	if meta.Attempt > 1 {
+		telemetry.AIRetries.Inc(reqBody.Model)
 	}
-
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		return "", Usage{}, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	callStart := time.Now()
+	completion, err := ap.provider.CallModel(ctx, reqBody, meta)
+	telemetry.AIRequestDuration.Since(callStart, reqBody.Model)
+	if err != nil {
+		class, _ := classifyFailure(err)
+		telemetry.AIRequests.Inc(reqBody.Model, class)
+		if class == FailureRateLimited && !isQuotaExhausted(err) {
+			ap.rateLimiter.Throttle(retryAfterOf(err))
+		}
+		if isTimeoutError(err) {
+			ap.ledger.MarkTimedOut(meta.IdempotencyKey)
+			return "", Usage{}, timeoutError(meta, err)
+		}
+		return "", Usage{}, err
 	}
 
-	// Extract content
-	if len(apiResp.Choices) == 0 {
-		return "", Usage{}, fmt.Errorf("no choices in response")
+	ap.logger.WithFields(logrus.Fields{
+		"request_id":          meta.RequestID,
+		"attempt":             meta.Attempt,
+		"provider":            ap.provider.Name(),
+		"response_id":         completion.ResponseID,
+		"provider_request_id": completion.ProviderRequestID,
+		"tokens":              completion.Usage.TotalTokens,
+	}).Debug("AI response received")
+	telemetry.AIRequests.Inc(reqBody.Model, "ok")
+	telemetry.AITokens.Add(float64(completion.Usage.PromptTokens), reqBody.Model, "prompt")
+	telemetry.AITokens.Add(float64(completion.Usage.CompletionTokens), reqBody.Model, "completion")
+
+	ap.ledger.Store(meta.IdempotencyKey, completedResponse{
+		Content:    completion.Content,
+		Usage:      completion.Usage,
+		ResponseID: completion.ResponseID,
+	})
+	if err := ap.cache.Put(cacheKey, cachedResponse{Model: reqBody.Model, Content: completion.Content, ResponseID: completion.ResponseID}); err != nil {
+		ap.logger.Warnf("⚠️  %v", err)
 	}
 
-	content := apiResp.Choices[0].Message.Content
-	usage := apiResp.Usage
-
-	return content, usage, nil
+	return completion.Content, completion.Usage, nil
 }
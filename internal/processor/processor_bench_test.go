@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newMockOpenAI serves canned chat completions after latency, like the real API but locally
+func newMockOpenAI(latency time.Duration) *httptest.Server {
+	body, _ := json.Marshal(OpenAIResponse{
+		ID:    "chatcmpl-bench",
+		Model: "gpt-4o-mini",
+		Choices: []Choice{{
+			Message:      Message{Role: "assistant", Content: `{"child_name":"Bé An","performance_sections":[]}`},
+			FinishReason: "stop",
+		}},
+		Usage: Usage{PromptTokens: 1500, CompletionTokens: 600, TotalTokens: 2100},
+	})
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		time.Sleep(latency)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+}
+
+func BenchmarkProcessBatch(b *testing.B) {
+	server := newMockOpenAI(5 * time.Millisecond)
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	items := make([]interface{}, 50)
+	for i := range items {
+		items[i] = i
+	}
+	promptTemplate := func(item interface{}) string { return fmt.Sprintf("prompt for kid %v", item) }
+
+	for _, concurrency := range []int{1, 5, 10, 25} {
+		concurrency := concurrency
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			ap, err := NewAIProcessor(Config{
+				APIKey:          "bench",
+				Model:           "gpt-4o-mini",
+				BaseURL:         server.URL,
+				MaxConcurrent:   concurrency,
+				BatchSize:       len(items),
+				RateLimitPerMin: 1_000_000,
+				MaxRetries:      1,
+			}, logger)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, result := range ap.ProcessBatch(context.Background(), items, promptTemplate) {
+					if !result.Success {
+						b.Fatal(result.Error)
+					}
+				}
+			}
+			b.ReportMetric(float64(len(items)*b.N)/b.Elapsed().Seconds(), "items/s")
+		})
+	}
+}
+
+func BenchmarkTokenTrackerRecordUsage(b *testing.B) {
+	tt := NewTokenTracker("gpt-4o")
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tt.RecordUsage("Tuần 3 - Tháng 10/2025", 1500, 600)
+		}
+	})
+}
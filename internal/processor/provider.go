@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Supported AI providers (openai.provider)
+const (
+	ProviderOpenAI    = "openai"    // OpenAI chat completions, or any compatible gateway
+	ProviderAnthropic = "anthropic" // Anthropic Messages API (Claude models)
+)
+
+// Provider sends one chat request to an AI API. Requests use the OpenAI shape; each provider
+// translates them to its own API. Retries, rate limiting and idempotency stay in AIProcessor.
+type Provider interface {
+	Name() string
+	CallModel(ctx context.Context, req OpenAIRequest, meta requestMeta) (*Completion, error)
+}
+
+// Completion is a provider's answer to one request
+type Completion struct {
+	Content           string
+	Usage             Usage
+	ResponseID        string
+	ProviderRequestID string
+}
+
+// APIStatusError is an error response from the provider's API
+type APIStatusError struct {
+	Status  int
+	Message string // Error message from the API ("" when the body had none)
+	Type    string
+	Code    string
+	Param   string
+	Body    string // Raw body, for errors without a message
+
+	RetryAfter time.Duration // Wait the provider asked for (Retry-After), 0 when it did not say
+}
+
+func (e *APIStatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("API error: %s (%s)", e.Message, e.Type)
+	}
+	return fmt.Sprintf("API returned status %d: %s", e.Status, e.Body)
+}
+
+// ParseProvider validates a provider name ("" = openai)
+func ParseProvider(name string) (string, error) {
+	switch name {
+	case "", ProviderOpenAI:
+		return ProviderOpenAI, nil
+	case ProviderAnthropic:
+		return ProviderAnthropic, nil
+	}
+	return "", fmt.Errorf("openai.provider must be %s or %s, got %q", ProviderOpenAI, ProviderAnthropic, name)
+}
+
+// APIKeyEnv returns the environment variable holding the provider's API key
+func APIKeyEnv(provider string) string {
+	if provider == ProviderAnthropic {
+		return "ANTHROPIC_API_KEY"
+	}
+	return "OPENAI_API_KEY"
+}
+
+// defaultBaseURL returns the provider's public API base URL
+func defaultBaseURL(provider string) string {
+	if provider == ProviderAnthropic {
+		return DefaultAnthropicBaseURL
+	}
+	return DefaultBaseURL
+}
+
+// newProvider returns the configured provider
+func newProvider(config Config, client *http.Client) Provider {
+	if config.Provider == ProviderAnthropic {
+		return &anthropicProvider{config: config, client: client}
+	}
+	return &openAIProvider{config: config, client: client}
+}
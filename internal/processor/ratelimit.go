@@ -0,0 +1,118 @@
+package processor
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryAfter caps a provider's Retry-After, so a bogus value cannot park a worker for hours
+const maxRetryAfter = 5 * time.Minute
+
+// quotaExhaustedCode is OpenAI's 429 for an account out of credit: waiting does not help
+const quotaExhaustedCode = "insufficient_quota"
+
+// tryAgainIn matches the wait in OpenAI rate limit messages ("Please try again in 1.2s", "in 350ms")
+var tryAgainIn = regexp.MustCompile(`try again in ([0-9.]+)(ms|s)\b`)
+
+// parseRetryAfter reads how long the provider asked us to wait: retry-after-ms (OpenAI),
+// then Retry-After in seconds or as an HTTP date. 0 when absent or unparseable.
+func parseRetryAfter(header http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return capRetryAfter(time.Duration(ms * float64(time.Millisecond)))
+	}
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return capRetryAfter(time.Duration(seconds * float64(time.Second)))
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return capRetryAfter(time.Until(date))
+	}
+	return 0
+}
+
+// capRetryAfter bounds a wait to (0, maxRetryAfter]
+func capRetryAfter(wait time.Duration) time.Duration {
+	switch {
+	case wait <= 0:
+		return 0
+	case wait > maxRetryAfter:
+		return maxRetryAfter
+	}
+	return wait
+}
+
+// retryAfterOf returns the wait a rate limit error asked for: its Retry-After header, else the
+// wait quoted in the error message (0 for other errors, or when the provider did not say)
+func retryAfterOf(err error) time.Duration {
+	var apiErr *APIStatusError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusTooManyRequests {
+		return 0
+	}
+	if apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	if m := tryAgainIn.FindStringSubmatch(apiErr.Message); m != nil {
+		value, _ := strconv.ParseFloat(m[1], 64)
+		unit := time.Second
+		if m[2] == "ms" {
+			unit = time.Millisecond
+		}
+		return capRetryAfter(time.Duration(value * float64(unit)))
+	}
+	return 0
+}
+
+// isQuotaExhausted reports a 429 that retries cannot fix (the account is out of credit)
+func isQuotaExhausted(err error) bool {
+	var apiErr *APIStatusError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusTooManyRequests &&
+		(apiErr.Code == quotaExhaustedCode || apiErr.Type == quotaExhaustedCode)
+}
+
+// retryDelay is the wait before retry attempt: the backoff delay, or longer when the provider's
+// rate limit error asked for more
+func (ap *AIProcessor) retryDelay(attempt int, err error) time.Duration {
+	delay := ap.calculateRetryDelay(attempt)
+	if wait := retryAfterOf(err); wait > delay {
+		delay = wait
+	}
+	return delay
+}
+
+// Throttle is called when the provider answers 429: no tokens are handed out until retryAfter has
+// passed (at least one refill interval), and the bucket shrinks to half its size. Once the pause is
+// over it grows back by one token per refill interval.
+func (rl *RateLimiter) Throttle(retryAfter time.Duration) {
+	if retryAfter < rl.refillRate {
+		retryAfter = rl.refillRate
+	}
+	rl.mu.Lock()
+	now := time.Now()
+	if !now.Before(rl.pausedUntil) {
+		// A new throttling episode; concurrent 429s of the same episode only extend the pause
+		if rl.limit = rl.limit / 2; rl.limit < 1 {
+			rl.limit = 1
+		}
+		rl.logger.WithField("bucket", rl.limit).Warnf("🐢 Rate limited by the provider, pausing requests for %v", retryAfter.Round(time.Millisecond))
+	}
+	if until := now.Add(retryAfter); until.After(rl.pausedUntil) {
+		rl.pausedUntil = until
+	}
+	rl.mu.Unlock()
+
+	// Tokens already in the bucket were granted at the rate the provider just refused
+	for {
+		select {
+		case <-rl.tokens:
+		default:
+			return
+		}
+	}
+}
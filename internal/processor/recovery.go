@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+)
+
+// storedCompletionList is the response of GET /chat/completions (stored completions)
+type storedCompletionList struct {
+	Data []OpenAIResponse `json:"data"`
+}
+
+// isTimeoutError reports whether err is a client-side timeout
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// recoverStoredCompletion looks up a completion the provider finished after we timed out.
+// Requires StoreResponses so requests are stored with their idempotency key as metadata.
+func (ap *AIProcessor) recoverStoredCompletion(ctx context.Context, meta requestMeta) (string, Usage, bool) {
+	query := url.Values{}
+	query.Set("metadata[idempotency_key]", meta.IdempotencyKey)
+	query.Set("limit", "1")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ap.config.chatCompletionsURL(query), nil)
+	if err != nil {
+		return "", Usage{}, false
+	}
+	ap.config.setAuth(req)
+	req.Header.Set("X-Client-Request-Id", meta.RequestID)
+	for key, value := range ap.config.ExtraHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := ap.httpClient.Do(req)
+	if err != nil {
+		ap.logger.WithField("request_id", meta.RequestID).Debugf("Stored completion lookup failed: %v", err)
+		return "", Usage{}, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", Usage{}, false
+	}
+
+	body, err := readResponseBody(resp, ap.config.MaxResponseBytes)
+	if err != nil {
+		return "", Usage{}, false
+	}
+
+	var list storedCompletionList
+	if err := json.Unmarshal(body, &list); err != nil || len(list.Data) == 0 || len(list.Data[0].Choices) == 0 {
+		return "", Usage{}, false
+	}
+
+	completion := list.Data[0]
+	ap.logger.WithFields(logrus.Fields{
+		"request_id":      meta.RequestID,
+		"idempotency_key": meta.IdempotencyKey,
+		"response_id":     completion.ID,
+	}).Info("♻️  Recovered completion that finished after client timeout")
+
+	return completion.Choices[0].Message.Content, completion.Usage, true
+}
+
+// storeMetadata returns request metadata used to find stored completions later
+func storeMetadata(meta requestMeta) map[string]string {
+	return map[string]string{
+		"idempotency_key":   meta.IdempotencyKey,
+		"client_request_id": meta.RequestID,
+	}
+}
+
+// timeoutError wraps a timeout with the request ID for correlation
+func timeoutError(meta requestMeta, err error) error {
+	return fmt.Errorf("API request timed out (request_id %s): %w", meta.RequestID, err)
+}
@@ -0,0 +1,152 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Request is a caller-built chat completion request, for tasks other than weekly reports
+// (e.g. categorizing transactions). It goes through the same rate limiting, retries,
+// item budget, idempotency and token tracking as report requests.
+type Request struct {
+	Messages []Message // Full conversation, including any system message
+
+	Model       string   // Overrides Config.Model ("" = processor default)
+	MaxTokens   int      // Overrides Config.MaxTokens (0 = processor default)
+	Temperature *float64 // Overrides Config.Temperature (nil = processor default)
+
+	// Response format: a JSON schema for structured output, plain JSON object, or free text
+	ResponseSchema json.RawMessage // JSON schema the response must follow (nil = any JSON object)
+	SchemaName     string          // Schema name sent to the API (default "response")
+	Text           bool            // Free-text response instead of JSON
+
+	UsageLabel string // Token tracking bucket, e.g. "transactions" (default "adhoc")
+}
+
+// Response is the raw result of a Request
+type Response struct {
+	Content   string
+	Usage     Usage
+	Model     string
+	RequestID string
+	Attempts  int
+	Duration  time.Duration
+}
+
+// Do sends a pre-built request and returns the raw response
+func (ap *AIProcessor) Do(ctx context.Context, req Request) (*Response, error) {
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("request has no messages")
+	}
+
+	body, err := ap.buildRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	label := req.UsageLabel
+	if label == "" {
+		label = "adhoc"
+	}
+
+	// Wait for rate limit token
+	waitStart := time.Now()
+	if err := ap.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	startTime := time.Now()
+	rateLimitWait := startTime.Sub(waitStart)
+
+	// Same logical request keeps its IDs across retries
+	meta := newRequestMeta(body.Model, messagesKey(body.Messages), string(req.ResponseSchema), label)
+
+	content, usage, err := ap.callWithRetry(ctx, &meta, rateLimitWait, func(ctx context.Context, meta requestMeta) (string, Usage, error) {
+		return ap.send(ctx, body, meta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ap.tokenTracker.RecordUsageForModel(label, body.Model, usage.PromptTokens, usage.CompletionTokens)
+
+	return &Response{
+		Content:   content,
+		Usage:     usage,
+		Model:     body.Model,
+		RequestID: meta.RequestID,
+		Attempts:  meta.Attempt,
+		Duration:  time.Since(startTime),
+	}, nil
+}
+
+// DoJSON sends a pre-built request and decodes the JSON response into T
+func DoJSON[T any](ctx context.Context, ap *AIProcessor, req Request) (T, *Response, error) {
+	var result T
+	if req.Text {
+		return result, nil, fmt.Errorf("DoJSON needs a JSON response, but the request asks for text")
+	}
+
+	resp, err := ap.Do(ctx, req)
+	if err != nil {
+		return result, nil, err
+	}
+	if err := json.Unmarshal([]byte(resp.Content), &result); err != nil {
+		return result, resp, fmt.Errorf("failed to decode response (request_id %s): %w: %s", resp.RequestID, err, bodySnippet([]byte(resp.Content)))
+	}
+	return result, resp, nil
+}
+
+// buildRequest applies processor defaults to a caller-built request
+func (ap *AIProcessor) buildRequest(req Request) (OpenAIRequest, error) {
+	body := OpenAIRequest{
+		Model:               ap.config.Model,
+		Messages:            req.Messages,
+		ResponseFormat:      ResponseFormat{Type: "json_object"},
+		Temperature:         ap.config.Temperature,
+		MaxCompletionTokens: ap.config.MaxTokens,
+	}
+	if req.Model != "" {
+		body.Model = req.Model
+	}
+	if req.MaxTokens > 0 {
+		body.MaxCompletionTokens = req.MaxTokens
+	}
+	if req.Temperature != nil {
+		body.Temperature = *req.Temperature
+	}
+
+	switch {
+	case req.Text && len(req.ResponseSchema) > 0:
+		return body, fmt.Errorf("request cannot ask for both text and a response schema")
+	case req.Text:
+		body.ResponseFormat = ResponseFormat{Type: "text"}
+	case len(req.ResponseSchema) > 0:
+		if !json.Valid(req.ResponseSchema) {
+			return body, fmt.Errorf("response schema is not valid JSON")
+		}
+		name := req.SchemaName
+		if name == "" {
+			name = "response"
+		}
+		body.ResponseFormat = ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &JSONSchema{Name: name, Schema: req.ResponseSchema, Strict: true},
+		}
+	}
+
+	return body, nil
+}
+
+// messagesKey flattens messages for the idempotency key
+func messagesKey(messages []Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		b.WriteString(m.Role)
+		b.WriteByte(0)
+		b.WriteString(m.Content)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// responseCache stores completions on disk keyed by a hash of the request, so re-running the same
+// week during development answers identical requests without billing them again
+type responseCache struct {
+	dir string
+	ttl time.Duration // 0 = entries never expire
+}
+
+// cachedResponse is one cache file
+type cachedResponse struct {
+	Key        string    `json:"key"`
+	Model      string    `json:"model"`
+	Content    string    `json:"content"`
+	ResponseID string    `json:"response_id,omitempty"`
+	StoredAt   time.Time `json:"stored_at"`
+}
+
+// newResponseCache creates the cache directory ("" = no cache, nil)
+func newResponseCache(dir string, ttl time.Duration) (*responseCache, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create response cache %s: %w", dir, err)
+	}
+	return &responseCache{dir: dir, ttl: ttl}, nil
+}
+
+// responseCacheKey is the SHA256 of the provider and the full request (model, messages, format, sampling)
+func responseCacheKey(provider string, req OpenAIRequest) string {
+	body, _ := json.Marshal(req)
+	h := sha256.New()
+	h.Write([]byte(provider))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *responseCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns the cached response for key, if present and not expired
+func (c *responseCache) Get(key string) (cachedResponse, bool) {
+	if c == nil {
+		return cachedResponse{}, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return cachedResponse{}, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return cachedResponse{}, false
+	}
+	if c.ttl > 0 && time.Since(entry.StoredAt) > c.ttl {
+		os.Remove(c.path(key))
+		return cachedResponse{}, false
+	}
+	return entry, true
+}
+
+// Put stores a response under key, replacing the file atomically
+func (c *responseCache) Put(key string, entry cachedResponse) error {
+	if c == nil {
+		return nil
+	}
+	entry.Key = key
+	entry.StoredAt = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write response cache: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write response cache: %w", err)
+	}
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to write response cache: %w", err)
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,128 @@
+package processor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultStreamIdleTimeout fails a streamed completion that sends nothing for this long
+const DefaultStreamIdleTimeout = 20 * time.Second
+
+// StreamOptions asks for a final chunk with the request's token usage
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// StreamHandler receives a streamed completion's content as it arrives, one delta at a time.
+// Retried attempts start over, so a handler may see the beginning of a completion more than once.
+type StreamHandler func(delta string)
+
+type streamHandlerKey struct{}
+
+// WithStreamHandler returns a context whose streamed completions (openai.stream) are passed to fn
+func WithStreamHandler(ctx context.Context, fn StreamHandler) context.Context {
+	return context.WithValue(ctx, streamHandlerKey{}, fn)
+}
+
+// streamHandlerFrom returns the context's stream handler (nil when none is set)
+func streamHandlerFrom(ctx context.Context) StreamHandler {
+	fn, _ := ctx.Value(streamHandlerKey{}).(StreamHandler)
+	return fn
+}
+
+// streamIdleError is a stream that went quiet; it counts as a timeout for retries and recovery
+type streamIdleError struct {
+	idle time.Duration
+}
+
+func (e *streamIdleError) Error() string {
+	return fmt.Sprintf("stream idle for %s", e.idle)
+}
+
+func (e *streamIdleError) Timeout() bool   { return true }
+func (e *streamIdleError) Temporary() bool { return true }
+
+// streamChunk is one server-sent event of a streamed chat completion
+type streamChunk struct {
+	ID      string `json:"id"`
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *Usage    `json:"usage"`
+	Error *APIError `json:"error"`
+}
+
+// isEventStream reports whether a response is a server-sent event stream (errors come back as JSON)
+func isEventStream(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+}
+
+// readStream assembles a streamed completion, passing each delta to the context's handler.
+// The watchdog cancels the request (with a streamIdleError cause) when no line arrives for idle.
+func readStream(ctx context.Context, resp *http.Response, watchdog *time.Timer, idle time.Duration, maxBytes int64) (*Completion, error) {
+	handler := streamHandlerFrom(ctx)
+	completion := &Completion{ProviderRequestID: resp.Header.Get("X-Request-Id")}
+	var content strings.Builder
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), int(maxBytes))
+	done := false
+	for !done && scanner.Scan() {
+		watchdog.Reset(idle)
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // Blank separators, comments and event names
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse stream event: %w: %s", err, bodySnippet([]byte(data)))
+		}
+		if chunk.Error != nil {
+			return nil, &APIStatusError{Status: resp.StatusCode, Message: chunk.Error.Message, Type: chunk.Error.Type, Code: chunk.Error.Code, Body: data}
+		}
+		if chunk.ID != "" {
+			completion.ResponseID = chunk.ID
+		}
+		if chunk.Usage != nil {
+			completion.Usage = *chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 || choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if int64(content.Len()) > maxBytes {
+				return nil, fmt.Errorf("response too large: streamed content exceeds limit of %d bytes", maxBytes)
+			}
+			if handler != nil {
+				handler(choice.Delta.Content)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			return nil, fmt.Errorf("failed to read stream: %w", cause)
+		}
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if !done {
+		return nil, fmt.Errorf("stream ended before completion after %d bytes", content.Len())
+	}
+
+	completion.Content = content.String()
+	return completion, nil
+}
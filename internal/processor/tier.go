@@ -0,0 +1,52 @@
+package processor
+
+import (
+	"context"
+	"strings"
+)
+
+// ServiceTierFlex is OpenAI's slower, discounted processing tier
+const ServiceTierFlex = "flex"
+
+// flexModels are the model families OpenAI offers the flex tier for (gpt-4o and gpt-4o-mini are not)
+var flexModels = []string{"o3", "o4-mini", "gpt-5"}
+
+// FlexOffered reports whether OpenAI runs model on the flex tier
+func FlexOffered(model string) bool {
+	for _, family := range flexModels {
+		if model == family || strings.HasPrefix(model, family+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// flexDiscount is the share of the standard price flex requests are billed at
+const flexDiscount = 0.5
+
+// Tier is a cheaper way to run a report request: another model and/or a provider service tier
+type Tier struct {
+	Model       string // "" = the processor's model
+	ServiceTier string // Sent as service_tier to OpenAI, e.g. "flex" ("" = default; ignored by Anthropic)
+}
+
+type tierKey struct{}
+
+// WithTier returns a context whose report requests run on tier instead of the configured model
+func WithTier(ctx context.Context, tier Tier) context.Context {
+	return context.WithValue(ctx, tierKey{}, tier)
+}
+
+// tierFrom returns the context's tier (the zero Tier when none is set)
+func tierFrom(ctx context.Context) Tier {
+	tier, _ := ctx.Value(tierKey{}).(Tier)
+	return tier
+}
+
+// usageModel is the model name usage is tracked under: flex requests are kept apart from standard ones
+func (t Tier) usageModel(model string) string {
+	if t.ServiceTier != "" {
+		return model + "@" + t.ServiceTier
+	}
+	return model
+}
@@ -2,12 +2,15 @@ package processor
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
 // TokenUsage tracks token usage and costs
 type TokenUsage struct {
+	Model            string // Model the request was priced for
 	PromptTokens     int
 	CompletionTokens int
 	TotalTokens      int
@@ -21,6 +24,7 @@ type TokenTracker struct {
 	usageByWeek map[string][]TokenUsage
 	totalUsage  TokenUsage
 	model       string
+	savings     PromptSavings // Prompt tokens saved by compact history (gold.prompt_history: delta)
 
 	// GPT-4o pricing (as of 2024)
 	// Input: $2.50 per 1M tokens
@@ -29,6 +33,26 @@ type TokenTracker struct {
 	outputPricePer1M float64
 }
 
+// PromptSavings compares prompts as sent with the same prompts carrying full history
+type PromptSavings struct {
+	Prompts    int
+	FullTokens int // Estimated tokens with the previous weeks in full
+	SentTokens int // Estimated tokens as sent
+}
+
+// SavedTokens returns the tokens not sent
+func (p PromptSavings) SavedTokens() int {
+	return p.FullTokens - p.SentTokens
+}
+
+// SavedPercent returns the share of full-history prompt tokens not sent
+func (p PromptSavings) SavedPercent() float64 {
+	if p.FullTokens == 0 {
+		return 0
+	}
+	return float64(p.SavedTokens()) / float64(p.FullTokens) * 100
+}
+
 // NewTokenTracker creates a new token tracker
 func NewTokenTracker(model string) *TokenTracker {
 	// Set pricing based on model
@@ -53,25 +77,77 @@ func getPricing(model string) (input, output float64) {
 		return 10.00, 30.00 // $10.00 input, $30.00 output per 1M tokens
 	case "gpt-3.5-turbo":
 		return 0.50, 1.50 // $0.50 input, $1.50 output per 1M tokens
+	}
+
+	// Claude model IDs carry a date suffix (claude-sonnet-4-20250514)
+	switch {
+	case strings.HasPrefix(model, "claude-opus-4"):
+		return 15.00, 75.00 // $15.00 input, $75.00 output per 1M tokens
+	case strings.HasPrefix(model, "claude-sonnet-4"), strings.HasPrefix(model, "claude-3-7-sonnet"), strings.HasPrefix(model, "claude-3-5-sonnet"):
+		return 3.00, 15.00 // $3.00 input, $15.00 output per 1M tokens
+	case strings.HasPrefix(model, "claude-3-5-haiku"):
+		return 0.80, 4.00 // $0.80 input, $4.00 output per 1M tokens
 	default:
 		// Default to GPT-4o pricing
 		return 2.50, 10.00
 	}
 }
 
-// RecordUsage records token usage for a request
-func (tt *TokenTracker) RecordUsage(weekLabel string, promptTokens, completionTokens int) {
+// EstimateTokens roughly estimates the token count of text (~4 bytes per token; Vietnamese
+// diacritics take more bytes but also split into more tokens, so the ratio holds reasonably well)
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// EstimateCost returns the estimated USD cost of a request for model
+func EstimateCost(model string, promptTokens, completionTokens int) float64 {
+	inputPrice, outputPrice := getPricing(model)
+	return float64(promptTokens)*inputPrice/1_000_000 + float64(completionTokens)*outputPrice/1_000_000
+}
+
+// RecordUsage records token usage for a request and returns it priced
+func (tt *TokenTracker) RecordUsage(weekLabel string, promptTokens, completionTokens int) TokenUsage {
+	return tt.record(weekLabel, promptTokens, completionTokens, tt.inputPricePer1M, tt.outputPricePer1M)
+}
+
+// RecordUsageForModel records token usage priced for model (per-call model overrides)
+func (tt *TokenTracker) RecordUsageForModel(label, model string, promptTokens, completionTokens int) TokenUsage {
+	if model == "" || model == tt.model {
+		return tt.RecordUsage(label, promptTokens, completionTokens)
+	}
+	inputPrice, outputPrice := getPricing(model)
+	return tt.recordModel(label, model, promptTokens, completionTokens, inputPrice, outputPrice)
+}
+
+// RecordUsageForTier records a downgraded request's usage, priced for model and the service tier
+// (flex at a discount) and tracked as model@tier
+func (tt *TokenTracker) RecordUsageForTier(label, model string, tier Tier, promptTokens, completionTokens int) TokenUsage {
+	inputPrice, outputPrice := getPricing(model)
+	if tier.ServiceTier == ServiceTierFlex {
+		inputPrice, outputPrice = inputPrice*flexDiscount, outputPrice*flexDiscount
+	}
+	return tt.recordModel(label, tier.usageModel(model), promptTokens, completionTokens, inputPrice, outputPrice)
+}
+
+// record adds one request's usage at the given prices for the tracker's model
+func (tt *TokenTracker) record(weekLabel string, promptTokens, completionTokens int, inputPricePer1M, outputPricePer1M float64) TokenUsage {
+	return tt.recordModel(weekLabel, tt.model, promptTokens, completionTokens, inputPricePer1M, outputPricePer1M)
+}
+
+// recordModel adds one request's usage for model at the given prices and returns it
+func (tt *TokenTracker) recordModel(weekLabel, model string, promptTokens, completionTokens int, inputPricePer1M, outputPricePer1M float64) TokenUsage {
 	tt.mu.Lock()
 	defer tt.mu.Unlock()
 
 	totalTokens := promptTokens + completionTokens
 
 	// Calculate cost
-	inputCost := float64(promptTokens) * tt.inputPricePer1M / 1_000_000
-	outputCost := float64(completionTokens) * tt.outputPricePer1M / 1_000_000
+	inputCost := float64(promptTokens) * inputPricePer1M / 1_000_000
+	outputCost := float64(completionTokens) * outputPricePer1M / 1_000_000
 	totalCost := inputCost + outputCost
 
 	usage := TokenUsage{
+		Model:            model,
 		PromptTokens:     promptTokens,
 		CompletionTokens: completionTokens,
 		TotalTokens:      totalTokens,
@@ -87,6 +163,24 @@ func (tt *TokenTracker) RecordUsage(weekLabel string, promptTokens, completionTo
 	tt.totalUsage.CompletionTokens += completionTokens
 	tt.totalUsage.TotalTokens += totalTokens
 	tt.totalUsage.EstimatedCost += totalCost
+	return usage
+}
+
+// RecordPromptSavings records one prompt sent in compact form: fullTokens is the estimate for the
+// same prompt with full history, sentTokens for the prompt as sent
+func (tt *TokenTracker) RecordPromptSavings(fullTokens, sentTokens int) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.savings.Prompts++
+	tt.savings.FullTokens += fullTokens
+	tt.savings.SentTokens += sentTokens
+}
+
+// GetPromptSavings returns the prompt tokens saved by compact history so far
+func (tt *TokenTracker) GetPromptSavings() PromptSavings {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+	return tt.savings
 }
 
 // GetWeekSummary returns summary for a specific week
@@ -110,6 +204,46 @@ func (tt *TokenTracker) GetWeekSummary(weekLabel string) TokenUsage {
 	return summary
 }
 
+// LabelUsage is the usage of one label (week, month or task) and model
+type LabelUsage struct {
+	Label    string
+	Requests int
+	TokenUsage
+}
+
+// GetUsageByLabel returns the usage so far per label and model, sorted by label then model
+func (tt *TokenTracker) GetUsageByLabel() []LabelUsage {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
+	var result []LabelUsage
+	for label, usages := range tt.usageByWeek {
+		byModel := make(map[string]*LabelUsage)
+		for _, usage := range usages {
+			entry, ok := byModel[usage.Model]
+			if !ok {
+				entry = &LabelUsage{Label: label, TokenUsage: TokenUsage{Model: usage.Model}}
+				byModel[usage.Model] = entry
+			}
+			entry.Requests++
+			entry.PromptTokens += usage.PromptTokens
+			entry.CompletionTokens += usage.CompletionTokens
+			entry.TotalTokens += usage.TotalTokens
+			entry.EstimatedCost += usage.EstimatedCost
+		}
+		for _, entry := range byModel {
+			result = append(result, *entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Label != result[j].Label {
+			return result[i].Label < result[j].Label
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result
+}
+
 // GetTotalSummary returns total summary across all weeks
 func (tt *TokenTracker) GetTotalSummary() TokenUsage {
 	tt.mu.RLock()
@@ -166,6 +300,11 @@ func (tt *TokenTracker) GetDetailedReport() string {
 		float64(tt.totalUsage.CompletionTokens)*tt.outputPricePer1M/1_000_000)
 	report += fmt.Sprintf("   Total tokens:      %10d\n", tt.totalUsage.TotalTokens)
 	report += fmt.Sprintf("   Estimated cost:    $%.4f USD\n", tt.totalUsage.EstimatedCost)
+	if tt.savings.Prompts > 0 {
+		report += fmt.Sprintf("   Delta history:     %10d prompt tokens saved (%.1f%% of %d full-history tokens, %d prompts, ~$%.4f)\n",
+			tt.savings.SavedTokens(), tt.savings.SavedPercent(), tt.savings.FullTokens, tt.savings.Prompts,
+			float64(tt.savings.SavedTokens())*tt.inputPricePer1M/1_000_000)
+	}
 	report += fmt.Sprintf("=" + repeatString("=", 80) + "\n")
 
 	return report
@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"math"
+	"testing"
+)
+
+func costEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestUsageByLabelAttributesWeeksAndModels(t *testing.T) {
+	tt := NewTokenTracker("gpt-4o")
+	tt.RecordUsage("Tuần 2", 1_000_000, 0)
+	tt.RecordUsage("Tuần 1", 1000, 500)
+	tt.RecordUsage("Tuần 1", 2000, 500)
+	tt.RecordUsageForModel("Tuần 1", "gpt-4o-mini", 1_000_000, 1_000_000)
+	tt.RecordUsageForModel("Tuần 1", "gpt-4o", 1000, 0) // The tracker's own model
+	tt.RecordUsageForTier("Tuần 1", "o4-mini", Tier{Model: "o4-mini", ServiceTier: ServiceTierFlex}, 1_000_000, 0)
+
+	usage := tt.GetUsageByLabel()
+	want := []struct {
+		label    string
+		model    string
+		requests int
+		prompt   int
+		cost     float64
+	}{
+		{"Tuần 1", "gpt-4o", 3, 4000, 4000*2.50/1e6 + 1000*10.00/1e6},
+		{"Tuần 1", "gpt-4o-mini", 1, 1_000_000, 0.15 + 0.60},
+		{"Tuần 1", "o4-mini@flex", 1, 1_000_000, 2.50 * flexDiscount}, // Unlisted models are priced as gpt-4o
+		{"Tuần 2", "gpt-4o", 1, 1_000_000, 2.50},
+	}
+	if len(usage) != len(want) {
+		t.Fatalf("GetUsageByLabel returned %d entries, want %d: %+v", len(usage), len(want), usage)
+	}
+	for i, w := range want {
+		got := usage[i]
+		if got.Label != w.label || got.Model != w.model || got.Requests != w.requests || got.PromptTokens != w.prompt || !costEqual(got.EstimatedCost, w.cost) {
+			t.Errorf("entry %d = %s %s %d requests, %d prompt tokens, $%f; want %s %s %d, %d, $%f",
+				i, got.Label, got.Model, got.Requests, got.PromptTokens, got.EstimatedCost, w.label, w.model, w.requests, w.prompt, w.cost)
+		}
+	}
+
+	total := tt.GetTotalSummary()
+	sum := 0.0
+	for _, entry := range usage {
+		sum += entry.EstimatedCost
+	}
+	if !costEqual(total.EstimatedCost, sum) {
+		t.Errorf("total $%f, entries sum to $%f", total.EstimatedCost, sum)
+	}
+	if week := tt.GetWeekSummary("Tuần 2"); week.PromptTokens != 1_000_000 || !costEqual(week.EstimatedCost, 2.50) {
+		t.Errorf("Tuần 2 summary = %+v", week)
+	}
+}
+
+func TestFlexOffered(t *testing.T) {
+	tests := map[string]bool{
+		"o3":                true,
+		"o4-mini":           true,
+		"o4-mini-2025-04":   true,
+		"gpt-5":             true,
+		"gpt-5-mini":        true,
+		"gpt-4o-mini":       false,
+		"gpt-4o":            false,
+		"o3x":               false,
+		"claude-sonnet-4-0": false,
+	}
+	for model, want := range tests {
+		if got := FlexOffered(model); got != want {
+			t.Errorf("FlexOffered(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
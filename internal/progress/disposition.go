@@ -0,0 +1,67 @@
+package progress
+
+// Kid dispositions: what happened to each kid in a week
+const (
+	DispositionReported         = "reported"
+	DispositionReused           = "reused"            // Report from an earlier run of the week kept (gold.reuse_existing)
+	DispositionTemplateFallback = "template_fallback" // Reported, but in the default language (no template for the kid's)
+	DispositionInvalidID        = "invalid_id"        // Profile ID is not a valid UUID
+	DispositionNotSelected      = "not_selected"      // Left out by --limit/--sample
+	DispositionDeletedProfile   = "deleted_profile"   // Soft-deleted kid left out (silver.deleted_profiles.mode: exclude)
+	DispositionSilverFailed     = "silver_failed"
+	DispositionGoldFailed       = "gold_failed"
+	DispositionDeferred         = "deferred" // Run deadline reached before the kid's report
+)
+
+// KidDisposition records why a kid did not get a regular report in a week
+type KidDisposition struct {
+	Week        string `json:"week"`
+	ProfileID   string `json:"profile_id"`
+	Nickname    string `json:"nickname,omitempty"`
+	Disposition string `json:"disposition"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// listed reports whether a disposition gets a per-kid entry; reports are only counted, and so are
+// kids left out by --limit/--sample or as deleted, which would otherwise repeat every week
+func listed(disposition string) bool {
+	switch disposition {
+	case DispositionReported, DispositionReused, DispositionNotSelected, DispositionDeletedProfile:
+		return false
+	}
+	return true
+}
+
+// RecordKid counts a kid's disposition for week and keeps the reason for kids without a regular report
+func (t *Tracker) RecordKid(week, profileID, nickname, disposition, reason string) {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) {
+		if s.Dispositions == nil {
+			s.Dispositions = make(map[string]int)
+		}
+		s.Dispositions[disposition]++
+		if listed(disposition) {
+			s.KidDispositions = append(s.KidDispositions, KidDisposition{
+				Week:        week,
+				ProfileID:   profileID,
+				Nickname:    nickname,
+				Disposition: disposition,
+				Reason:      reason,
+			})
+		}
+	})
+}
+
+// copyCounts copies m so snapshots can be encoded outside the tracker lock
+func copyCounts(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+	counts := make(map[string]int, len(m))
+	for k, v := range m {
+		counts[k] = v
+	}
+	return counts
+}
@@ -0,0 +1,84 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+)
+
+// SetMetricsPath also writes Prometheus metrics to path on every persist (node_exporter textfile collector)
+func (t *Tracker) SetMetricsPath(path string) {
+	if t == nil {
+		return
+	}
+	t.metricsPath = path
+}
+
+// ServeMetrics returns the run and SLO metrics in the Prometheus text format (metrics endpoint)
+func (t *Tracker) ServeMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if t == nil {
+		return
+	}
+	t.mu.RLock()
+	metrics := t.metrics()
+	t.mu.RUnlock()
+	w.Write(metrics)
+}
+
+// metrics renders the current state; the caller holds the lock
+func (t *Tracker) metrics() []byte {
+	var b bytes.Buffer
+	s := t.state
+	gauge := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	gauge("pipeline_run_percent_complete", "Progress of the current run")
+	fmt.Fprintf(&b, "pipeline_run_percent_complete %g\n", s.PercentComplete)
+	gauge("pipeline_run_kids_done", "Kids processed in the current run")
+	fmt.Fprintf(&b, "pipeline_run_kids_done %d\n", s.KidsDone)
+	gauge("pipeline_run_kids_failed", "Kids that failed in the current run")
+	fmt.Fprintf(&b, "pipeline_run_kids_failed %d\n", s.KidsFailed)
+	gauge("pipeline_run_cost_usd", "Estimated AI cost of the current run")
+	fmt.Fprintf(&b, "pipeline_run_cost_usd %g\n", s.CostSoFarUSD)
+
+	if t.slo == nil {
+		return b.Bytes()
+	}
+	gauge("pipeline_report_slo_target_seconds", "Time after week end within which reports should be available")
+	fmt.Fprintf(&b, "pipeline_report_slo_target_seconds %g\n", t.slo.target.Seconds())
+	gauge("pipeline_report_slo_objective_ratio", "Share of kids whose report should be available within the target")
+	fmt.Fprintf(&b, "pipeline_report_slo_objective_ratio %g\n", t.slo.objective/100)
+	if attainment, ok := s.SLOAttainment(); ok {
+		gauge("pipeline_report_slo_run_attainment_ratio", "Share of kids with a report within the target, across the run's weeks")
+		fmt.Fprintf(&b, "pipeline_report_slo_run_attainment_ratio %g\n", attainment/100)
+	}
+	if len(s.SLO) == 0 {
+		return b.Bytes()
+	}
+
+	// Only the latest measured week, so the series stay bounded however many weeks a run measures
+	last := s.SLO[len(s.SLO)-1]
+	met := 0
+	if last.Met {
+		met = 1
+	}
+	gauge("pipeline_report_slo_last_week_end_timestamp_seconds", "End of the latest measured week (Unix time)")
+	fmt.Fprintf(&b, "pipeline_report_slo_last_week_end_timestamp_seconds %d\n", last.WeekEnd.Unix())
+	gauge("pipeline_report_slo_last_week_attainment_ratio", "Share of the latest measured week's kids with a report within the target")
+	fmt.Fprintf(&b, "pipeline_report_slo_last_week_attainment_ratio %g\n", last.AttainmentPercent/100)
+	gauge("pipeline_report_slo_last_week_kids", "Kids that should have a report for the latest measured week")
+	fmt.Fprintf(&b, "pipeline_report_slo_last_week_kids %d\n", last.Kids)
+	gauge("pipeline_report_slo_last_week_reports_within_target", "Reports of the latest measured week available within the target")
+	fmt.Fprintf(&b, "pipeline_report_slo_last_week_reports_within_target %d\n", last.WithinTarget)
+	gauge("pipeline_report_slo_last_week_missing", "Kids without a report for the latest measured week")
+	fmt.Fprintf(&b, "pipeline_report_slo_last_week_missing %d\n", last.Missing)
+	gauge("pipeline_report_slo_last_week_met", "1 when the latest measured week met the objective")
+	fmt.Fprintf(&b, "pipeline_report_slo_last_week_met %d\n", met)
+	gauge("pipeline_report_availability_seconds", "Time from the latest measured week's end to report availability")
+	fmt.Fprintf(&b, "pipeline_report_availability_seconds{quantile=\"0.5\"} %g\n", last.P50Seconds)
+	fmt.Fprintf(&b, "pipeline_report_availability_seconds{quantile=\"0.99\"} %g\n", last.P99Seconds)
+	fmt.Fprintf(&b, "pipeline_report_availability_seconds{quantile=\"1\"} %g\n", last.MaxSeconds)
+	return b.Bytes()
+}
@@ -0,0 +1,197 @@
+package progress
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// WeekSLO is a week's report availability measured against the SLO: the share of kids whose report
+// was available within the target time after the week ended (Monday 00:00)
+type WeekSLO struct {
+	RunID             string    `json:"run_id,omitempty"`
+	Week              string    `json:"week"`
+	WeekEnd           time.Time `json:"week_end"`
+	Kids              int       `json:"kids"`          // Kids with a report plus kids that should have had one
+	Reports           int       `json:"reports"`       // Kids with a report
+	Missing           int       `json:"missing"`       // Kids without a report (failed or deferred); always misses
+	WithinTarget      int       `json:"within_target"` // Reports available within the target
+	AttainmentPercent float64   `json:"attainment_percent"`
+	TargetHours       float64   `json:"target_hours"`
+	ObjectivePercent  float64   `json:"objective_percent"`
+	Met               bool      `json:"met"`
+	P50Seconds        float64   `json:"p50_seconds"` // Time from week end to report availability
+	P99Seconds        float64   `json:"p99_seconds"`
+	MaxSeconds        float64   `json:"max_seconds"`
+}
+
+// sloSettings are the targets weeks are measured against
+type sloSettings struct {
+	target      time.Duration
+	objective   float64 // Percent of kids
+	historyPath string  // JSONL of every measured week, for monthly reporting ("" = off)
+}
+
+// SetSLO measures report availability against target (e.g. 6h after week end) and objective (e.g. 99
+// percent of kids), appending each measured week to historyPath ("" = no history)
+func (t *Tracker) SetSLO(target time.Duration, objectivePercent float64, historyPath string) {
+	if t == nil {
+		return
+	}
+	t.slo = &sloSettings{target: target, objective: objectivePercent, historyPath: historyPath}
+}
+
+// SLOEnabled reports whether weeks are measured against the availability SLO
+func (t *Tracker) SLOEnabled() bool {
+	return t != nil && t.slo != nil
+}
+
+// missedDisposition reports whether a kid with this disposition should have had a report but did not
+func missedDisposition(disposition string) bool {
+	switch disposition {
+	case DispositionSilverFailed, DispositionGoldFailed, DispositionDeferred:
+		return true
+	}
+	return false
+}
+
+// RecordWeekSLO measures a week against the SLO on its first delivery, given when each of its reports
+// became available. Kids recorded as failed or deferred for the week count as misses. A week already
+// in the history file was measured when it was first delivered, so it is left as it is and ok is false.
+func (t *Tracker) RecordWeekSLO(week string, weekEnd time.Time, available []time.Time) (result WeekSLO, ok bool) {
+	if !t.SLOEnabled() {
+		return WeekSLO{}, false
+	}
+	if t.slo.historyPath != "" {
+		measured, err := historyHasWeek(t.slo.historyPath, weekEnd)
+		if err != nil {
+			t.logger.Warnf("⚠️  Failed to read SLO history: %v", err)
+		} else if measured {
+			return WeekSLO{}, false
+		}
+	}
+
+	latencies := make([]float64, 0, len(available))
+	within := 0
+	for _, at := range available {
+		latency := at.Sub(weekEnd)
+		if latency < 0 {
+			latency = 0 // Clock skew between hosts; counts as on time
+		}
+		if latency <= t.slo.target {
+			within++
+		}
+		latencies = append(latencies, latency.Seconds())
+	}
+	sort.Float64s(latencies)
+
+	t.update(func(s *RunState) {
+		missing := 0
+		for _, kid := range s.KidDispositions {
+			if kid.Week == week && missedDisposition(kid.Disposition) {
+				missing++
+			}
+		}
+		result = WeekSLO{
+			RunID:            s.RunID,
+			Week:             week,
+			WeekEnd:          weekEnd,
+			Kids:             len(available) + missing,
+			Reports:          len(available),
+			Missing:          missing,
+			WithinTarget:     within,
+			TargetHours:      t.slo.target.Hours(),
+			ObjectivePercent: t.slo.objective,
+			P50Seconds:       percentile(latencies, 0.50),
+			P99Seconds:       percentile(latencies, 0.99),
+			MaxSeconds:       percentile(latencies, 1),
+		}
+		if result.Kids > 0 {
+			result.AttainmentPercent = math.Round(float64(within)/float64(result.Kids)*10000) / 100
+		} else {
+			result.AttainmentPercent = 100
+		}
+		result.Met = result.AttainmentPercent >= t.slo.objective
+		s.SLO = append(s.SLO, result)
+	})
+
+	if t.slo.historyPath != "" {
+		if err := appendJSONLine(t.slo.historyPath, result); err != nil {
+			t.logger.Warnf("⚠️  Failed to append SLO history: %v", err)
+		}
+	}
+	return result, true
+}
+
+// historyHasWeek reports whether the SLO history at path already holds the week ending at weekEnd
+func historyHasWeek(path string, weekEnd time.Time) (bool, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry WeekSLO
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil && entry.WeekEnd.Equal(weekEnd) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// SLOAttainment returns the run's attainment across all measured weeks (ok is false when none were)
+func (s RunState) SLOAttainment() (percent float64, ok bool) {
+	kids, within := 0, 0
+	for _, week := range s.SLO {
+		kids += week.Kids
+		within += week.WithinTarget
+	}
+	if len(s.SLO) == 0 {
+		return 0, false
+	}
+	if kids == 0 {
+		return 100, true
+	}
+	return math.Round(float64(within)/float64(kids)*10000) / 100, true
+}
+
+// percentile returns the nearest-rank percentile of sorted values (0 when empty)
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return math.Round(sorted[rank])
+}
+
+// appendJSONLine appends v as one JSON line to path
+func appendJSONLine(path string, v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,391 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"ai-production-pipeline/internal/buildinfo"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Run status values
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusPartial   = "partial"  // Stopped at the run deadline; remaining work deferred
+	StatusDeferred  = "deferred" // AI provider down; remaining prompts queued for flush-deferred
+)
+
+// RunState is a point-in-time snapshot of a pipeline run
+type RunState struct {
+	RunID           string    `json:"run_id"`
+	Status          string    `json:"status"`
+	StartedAt       time.Time `json:"started_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	TotalWeeks      int       `json:"total_weeks"`
+	WeeksCompleted  int       `json:"weeks_completed"`
+	CurrentWeek     string    `json:"current_week"`
+	CurrentWeekNum  int       `json:"current_week_number"` // Weeks started so far, including the current one
+	WeekKidsTotal   int       `json:"week_kids_total"`
+	WeekKidsDone    int       `json:"week_kids_done"`
+	WeekKidsFailed  int       `json:"week_kids_failed"`
+	KidsDone        int       `json:"kids_done"`
+	KidsFailed      int       `json:"kids_failed"`
+	PercentComplete float64   `json:"percent_complete"`
+	ETASeconds      float64   `json:"eta_seconds"`
+	CostSoFarUSD    float64   `json:"cost_so_far_usd"`
+	DeferredWeeks   []string  `json:"deferred_weeks,omitempty"` // Weeks not started before the run deadline
+
+	Dispositions    map[string]int   `json:"dispositions,omitempty"`     // Kid-weeks per disposition
+	KidDispositions []KidDisposition `json:"kid_dispositions,omitempty"` // Kids without a regular report, with the reason
+
+	SLO []WeekSLO `json:"slo,omitempty"` // Report availability per completed week (status.slo)
+
+	Build *buildinfo.Metadata `json:"build,omitempty"` // Binary, config and prompt fingerprint
+
+	// Set when a previous run was interrupted before completing
+	ResumedFrom      string  `json:"resumed_from,omitempty"`
+	ResumedAtPercent float64 `json:"resumed_at_percent,omitempty"`
+}
+
+// Tracker keeps the current run state, persists it periodically and serves it over HTTP.
+// All methods are safe for concurrent use and no-ops on a nil Tracker.
+type Tracker struct {
+	mu       sync.RWMutex
+	state    RunState
+	path     string
+	interval time.Duration
+	logger   *logrus.Logger
+	dirty    bool
+
+	summaryPath string // Compact ops status file (e.g. data/STATUS.json), "" = off
+	metricsPath string // Prometheus textfile (e.g. for node_exporter), "" = off
+
+	slo *sloSettings // nil = availability SLO not measured
+}
+
+// NewTracker creates a tracker that persists state to path every interval
+func NewTracker(path string, interval time.Duration, logger *logrus.Logger) *Tracker {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &Tracker{
+		path:     path,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// SetSummaryPath also writes a compact status summary to path on every persist
+func (t *Tracker) SetSummaryPath(path string) {
+	if t == nil {
+		return
+	}
+	t.summaryPath = path
+}
+
+// StatusSummary is the compact status file read by the ops dashboard
+type StatusSummary struct {
+	RunID          string    `json:"run_id"`
+	Status         string    `json:"status"`
+	CurrentWeek    string    `json:"current_week"`
+	Week           string    `json:"week"` // "3/7"
+	PercentDone    float64   `json:"percent_done"`
+	WeeksCompleted int       `json:"weeks_completed"`
+	KidsDone       int       `json:"kids_done"`
+	FailuresSoFar  int       `json:"failures_so_far"`
+	ETASeconds     float64   `json:"eta_seconds"`
+	ETA            string    `json:"eta,omitempty"` // Estimated finish time (RFC 3339)
+	CostSoFarUSD   float64   `json:"cost_so_far_usd"`
+	StartedAt      time.Time `json:"started_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	DeferredWeeks  []string  `json:"deferred_weeks,omitempty"`
+
+	Dispositions map[string]int `json:"dispositions,omitempty"` // Kid-weeks per disposition (reasons in run_state.json)
+
+	SLOAttainmentPercent *float64 `json:"slo_attainment_percent,omitempty"` // Reports within the SLO target, across measured weeks
+}
+
+// Summary returns the compact status derived from the run state
+func (s RunState) Summary() StatusSummary {
+	summary := StatusSummary{
+		RunID:          s.RunID,
+		Status:         s.Status,
+		CurrentWeek:    s.CurrentWeek,
+		Week:           fmt.Sprintf("%d/%d", s.CurrentWeekNum, s.TotalWeeks),
+		PercentDone:    math.Round(s.PercentComplete*10) / 10,
+		WeeksCompleted: s.WeeksCompleted,
+		KidsDone:       s.KidsDone,
+		FailuresSoFar:  s.KidsFailed,
+		ETASeconds:     math.Round(s.ETASeconds),
+		CostSoFarUSD:   s.CostSoFarUSD,
+		StartedAt:      s.StartedAt,
+		UpdatedAt:      s.UpdatedAt,
+		DeferredWeeks:  s.DeferredWeeks,
+		Dispositions:   copyCounts(s.Dispositions),
+	}
+	if attainment, ok := s.SLOAttainment(); ok {
+		summary.SLOAttainmentPercent = &attainment
+	}
+	if s.Status == StatusRunning && s.ETASeconds > 0 {
+		summary.ETA = s.UpdatedAt.Add(time.Duration(s.ETASeconds) * time.Second).Format(time.RFC3339)
+	}
+	return summary
+}
+
+// Start begins a new run, reporting any interrupted run found in the state file
+func (t *Tracker) Start(runID string, totalWeeks int) {
+	if t == nil {
+		return
+	}
+
+	previous, err := LoadState(t.path)
+	t.mu.Lock()
+	t.state = RunState{
+		RunID:      runID,
+		Status:     StatusRunning,
+		StartedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		TotalWeeks: totalWeeks,
+	}
+	if err == nil && previous.Status == StatusRunning {
+		t.state.ResumedFrom = previous.RunID
+		t.state.ResumedAtPercent = previous.PercentComplete
+		t.logger.Warnf("🔁 Resumed run %s at %.0f%% (interrupted at %s, last update %s)",
+			previous.RunID, previous.PercentComplete, previous.CurrentWeek, previous.UpdatedAt.Format(time.RFC3339))
+	}
+	t.dirty = true
+	t.mu.Unlock()
+
+	t.Persist()
+}
+
+// SetMetadata records the build and config fingerprint in the run state
+func (t *Tracker) SetMetadata(metadata buildinfo.Metadata) {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) { s.Build = &metadata })
+}
+
+// StartWeek marks the beginning of a week
+func (t *Tracker) StartWeek(weekLabel string) {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) {
+		s.CurrentWeek = weekLabel
+		s.CurrentWeekNum++
+		s.WeekKidsTotal = 0
+		s.WeekKidsDone = 0
+		s.WeekKidsFailed = 0
+	})
+	t.Persist()
+}
+
+// SetWeekKids sets the number of kids to process in the current week
+func (t *Tracker) SetWeekKids(total int) {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) { s.WeekKidsTotal = total })
+}
+
+// KidDone records one processed kid
+func (t *Tracker) KidDone(success bool) {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) {
+		s.WeekKidsDone++
+		s.KidsDone++
+		if !success {
+			s.WeekKidsFailed++
+			s.KidsFailed++
+		}
+	})
+}
+
+// SetCost records the estimated cost so far
+func (t *Tracker) SetCost(costUSD float64) {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) { s.CostSoFarUSD = costUSD })
+}
+
+// DeferWeek records a week skipped because the run deadline was reached
+func (t *Tracker) DeferWeek(weekLabel string) {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) { s.DeferredWeeks = append(s.DeferredWeeks, weekLabel) })
+}
+
+// FinishWeek marks the current week as completed
+func (t *Tracker) FinishWeek() {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) { s.WeeksCompleted++ })
+	t.Persist()
+}
+
+// Finish marks the run as completed or failed and persists immediately
+func (t *Tracker) Finish(status string) {
+	if t == nil {
+		return
+	}
+	t.update(func(s *RunState) {
+		s.Status = status
+		if status == StatusCompleted {
+			s.PercentComplete = 100
+			s.ETASeconds = 0
+		}
+	})
+	t.Persist()
+}
+
+// Snapshot returns a copy of the current state
+func (t *Tracker) Snapshot() RunState {
+	if t == nil {
+		return RunState{}
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	state := t.state
+	state.Dispositions = copyCounts(t.state.Dispositions)
+	state.KidDispositions = append([]KidDisposition(nil), t.state.KidDispositions...)
+	state.SLO = append([]WeekSLO(nil), t.state.SLO...)
+	return state
+}
+
+// Run persists state every interval until ctx is done
+func (t *Tracker) Run(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.Persist()
+			return
+		case <-ticker.C:
+			t.Persist()
+		}
+	}
+}
+
+// Persist writes the state file atomically if anything changed
+func (t *Tracker) Persist() {
+	if t == nil || (t.path == "" && t.summaryPath == "" && t.metricsPath == "") {
+		return
+	}
+
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return
+	}
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	summary := t.state.Summary()
+	metrics := t.metrics()
+	t.dirty = false
+	t.mu.Unlock()
+
+	if err != nil {
+		t.logger.Warnf("Failed to marshal run state: %v", err)
+		return
+	}
+	if t.path != "" {
+		if err := writeFileAtomic(t.path, data); err != nil {
+			t.logger.Warnf("Failed to persist run state: %v", err)
+		}
+	}
+
+	if t.summaryPath != "" {
+		summaryData, err := json.MarshalIndent(summary, "", "  ")
+		if err == nil {
+			err = writeFileAtomic(t.summaryPath, summaryData)
+		}
+		if err != nil {
+			t.logger.Warnf("Failed to write status summary: %v", err)
+		}
+	}
+
+	if t.metricsPath != "" {
+		if err := writeFileAtomic(t.metricsPath, metrics); err != nil {
+			t.logger.Warnf("Failed to write metrics file: %v", err)
+		}
+	}
+}
+
+// ServeHTTP returns the current run state as JSON (status endpoint)
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t.Snapshot())
+}
+
+// update applies fn under lock and recomputes progress and ETA
+func (t *Tracker) update(fn func(s *RunState)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fn(&t.state)
+	t.state.UpdatedAt = time.Now()
+
+	if t.state.TotalWeeks > 0 {
+		weekFraction := 0.0
+		if t.state.WeekKidsTotal > 0 {
+			weekFraction = float64(t.state.WeekKidsDone) / float64(t.state.WeekKidsTotal)
+		}
+		done := (float64(t.state.WeeksCompleted) + weekFraction) / float64(t.state.TotalWeeks)
+		if done > 1 {
+			done = 1
+		}
+		t.state.PercentComplete = done * 100
+
+		if done > 0 {
+			elapsed := time.Since(t.state.StartedAt).Seconds()
+			t.state.ETASeconds = elapsed/done - elapsed
+		}
+	}
+	t.dirty = true
+}
+
+// LoadState reads a persisted run state
+func LoadState(path string) (RunState, error) {
+	var state RunState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse run state: %w", err)
+	}
+	return state, nil
+}
+
+// writeFileAtomic writes to a temp file and renames it so readers never see partial JSON
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,175 @@
+package runlock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// What to do when another run holds the week (run.lock.on_conflict)
+const (
+	OnConflictWait = "wait" // Poll until the other run finishes (up to run.lock.wait_timeout)
+	OnConflictSkip = "skip" // Leave the week to the other run
+	OnConflictFail = "fail" // Stop the run with an error
+)
+
+// ErrLocked is returned when another pipeline run holds the week's lock
+var ErrLocked = errors.New("week is being processed by another pipeline run")
+
+// pollInterval is how often a waiting run retries the lock
+const pollInterval = 5 * time.Second
+
+// Locker takes a Postgres advisory lock per (namespace, week), so two pipeline instances against the
+// same database never process the same week at once. The lock belongs to a database session, so a
+// crashed run releases it when its connection drops: there are no stale leases to clean up.
+type Locker struct {
+	db          *sql.DB
+	logger      *logrus.Logger
+	namespace   string
+	onConflict  string
+	waitTimeout time.Duration
+}
+
+// NewLocker validates the lock settings (nil when run.lock.enabled is false)
+func NewLocker(db *sql.DB, logger *logrus.Logger, cfg config.RunLockConfig) (*Locker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	l := &Locker{db: db, logger: logger, namespace: cfg.Namespace, onConflict: cfg.OnConflict}
+	if l.namespace == "" {
+		l.namespace = "ai-production-pipeline"
+	}
+	switch l.onConflict {
+	case "":
+		l.onConflict = OnConflictFail
+	case OnConflictWait, OnConflictSkip, OnConflictFail:
+	default:
+		return nil, fmt.Errorf("run.lock.on_conflict must be wait, skip or fail, got %q", cfg.OnConflict)
+	}
+	if cfg.WaitTimeout != "" {
+		timeout, err := time.ParseDuration(cfg.WaitTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run.lock.wait_timeout %q: %w", cfg.WaitTimeout, err)
+		}
+		l.waitTimeout = timeout
+	}
+	return l, nil
+}
+
+// OnConflict returns the configured conflict behavior ("" when locking is disabled)
+func (l *Locker) OnConflict() string {
+	if l == nil {
+		return ""
+	}
+	return l.onConflict
+}
+
+// Lock is a held week lock
+type Lock struct {
+	conn   *sql.Conn
+	key    int64
+	week   string
+	logger *logrus.Logger
+}
+
+// Acquire locks week (identified by its start date). It returns ErrLocked when another run holds it:
+// immediately for skip and fail, after run.lock.wait_timeout for wait. A nil Locker returns a nil Lock.
+func (l *Locker) Acquire(ctx context.Context, weekStart time.Time, label string) (*Lock, error) {
+	if l == nil {
+		return nil, nil
+	}
+	key := lockKey(l.namespace, weekStart.Format("2006-01-02"))
+
+	var deadline time.Time
+	if l.waitTimeout > 0 {
+		deadline = time.Now().Add(l.waitTimeout)
+	}
+	for waited := false; ; waited = true {
+		lock, err := l.tryLock(ctx, key, label)
+		if err != nil || lock != nil {
+			return lock, err
+		}
+
+		holder := l.holder(ctx, key)
+		if l.onConflict != OnConflictWait || (!deadline.IsZero() && time.Now().After(deadline)) {
+			return nil, fmt.Errorf("%w: %s (held by %s)", ErrLocked, label, holder)
+		}
+		if !waited {
+			l.logger.Warnf("🔒 %s is locked by another run (%s), waiting...", label, holder)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// tryLock takes the lock on a dedicated connection (nil when it is held elsewhere)
+func (l *Locker) tryLock(ctx context.Context, key int64, label string) (*Lock, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a connection for the run lock: %w", err)
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take the run lock: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, nil
+	}
+	l.logger.Debugf("🔒 Locked %s (key %d)", label, key)
+	return &Lock{conn: conn, key: key, week: label, logger: l.logger}, nil
+}
+
+// holder describes the session holding key, for the conflict message
+func (l *Locker) holder(ctx context.Context, key int64) string {
+	var pid int
+	var application string
+	var since time.Time
+	err := l.db.QueryRowContext(ctx, `
+		SELECT a.pid, a.application_name, a.backend_start
+		FROM pg_locks l JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted AND l.objsubid = 1
+		  AND l.classid = (($1::bigint >> 32) & 4294967295)::oid
+		  AND l.objid = ($1::bigint & 4294967295)::oid
+		LIMIT 1
+	`, key).Scan(&pid, &application, &since)
+	if err != nil {
+		return "another session"
+	}
+	if application == "" {
+		application = "unknown application"
+	}
+	return fmt.Sprintf("pid %d, %s, connected %s", pid, application, since.Format(time.RFC3339))
+}
+
+// Release unlocks the week. If the unlock fails the connection is discarded, which releases it too.
+// Safe to call on a nil Lock.
+func (lk *Lock) Release() {
+	if lk == nil {
+		return
+	}
+	if _, err := lk.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lk.key); err != nil {
+		lk.logger.Warnf("⚠️  Could not release the lock for %s, closing its connection: %v", lk.week, err)
+		lk.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	lk.conn.Close()
+}
+
+// lockKey hashes namespace and week into the advisory lock key
+func lockKey(namespace, week string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(namespace + "\x00" + week))
+	return int64(h.Sum64())
+}
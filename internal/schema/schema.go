@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Type families a column may drift within without breaking the pipeline's scans
+const (
+	FamilyUUID    = "uuid"
+	FamilyText    = "text"
+	FamilyNumeric = "numeric"
+	FamilyTime    = "time"
+	FamilyAny     = "any" // Only compared with columns of the same type (join keys)
+)
+
+// Column is a source column the pipeline relies on
+type Column struct {
+	Table    string
+	Column   string
+	Family   string
+	Expected string // Type the queries were written against; other types in the family only warn
+}
+
+// RequiredColumns lists the source columns used by the week manager and Silver queries
+var RequiredColumns = []Column{
+	{"profiles", "id", FamilyUUID, "uuid"},
+	{"profiles", "full_name", FamilyText, "character varying"},
+	{"profiles", "date_of_birth", FamilyTime, "date"},
+	{"profiles", "profile_type", FamilyText, "character varying"},
+	{"profiles", "created_at", FamilyTime, "timestamp with time zone"},
+	{"wallets", "id", FamilyAny, "uuid"},
+	{"wallets", "profile_id", FamilyUUID, "uuid"},
+	{"wallets", "slug", FamilyText, "character varying"},
+	{"wallets", "balance", FamilyNumeric, "numeric"},
+	{"wallet_transactions", "wallet_id", FamilyAny, "uuid"},
+	{"wallet_transactions", "profile_id", FamilyUUID, "uuid"},
+	{"wallet_transactions", "type", FamilyText, "character varying"},
+	{"wallet_transactions", "amount", FamilyNumeric, "numeric"},
+	{"wallet_transactions", "created_at", FamilyTime, "timestamp with time zone"},
+	{"missions", "profile_id", FamilyUUID, "uuid"},
+	{"missions", "status", FamilyText, "character varying"},
+	{"missions", "created_at", FamilyTime, "timestamp with time zone"},
+}
+
+var families = map[string][]string{
+	FamilyUUID:    {"uuid"},
+	FamilyText:    {"text", "character varying", "character", "USER-DEFINED"}, // USER-DEFINED = enum
+	FamilyNumeric: {"numeric", "integer", "bigint", "smallint", "double precision", "real"},
+	FamilyTime:    {"timestamp with time zone", "timestamp without time zone", "date"},
+}
+
+// Check introspects information_schema and fails with one clear message listing every missing
+// or incompatible column. Type changes within a compatible family are logged as drift warnings.
+func Check(db *sql.DB, logger *logrus.Logger, columns []Column) error {
+	rows, err := db.Query(`
+		SELECT table_name, column_name, data_type
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to introspect schema: %w", err)
+	}
+	defer rows.Close()
+
+	actual := make(map[string]string)
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var table, column, dataType string
+		if err := rows.Scan(&table, &column, &dataType); err != nil {
+			return fmt.Errorf("failed to scan schema row: %w", err)
+		}
+		actual[table+"."+column] = dataType
+		tables[table] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating schema rows: %w", err)
+	}
+
+	var problems []string
+	for _, c := range columns {
+		name := c.Table + "." + c.Column
+		dataType, ok := actual[name]
+		switch {
+		case !tables[c.Table]:
+			problems = append(problems, fmt.Sprintf("table %s is missing", c.Table))
+		case !ok:
+			problems = append(problems, fmt.Sprintf("column %s is missing (renamed?)", name))
+		case dataType == c.Expected:
+			// OK
+		case inFamily(c.Family, dataType):
+			logger.Warnf("⚠️  Schema drift: %s is %s (expected %s) - still compatible", name, dataType, c.Expected)
+		default:
+			problems = append(problems, fmt.Sprintf("column %s has type %s, expected %s", name, dataType, c.Expected))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("source schema is incompatible with the pipeline:\n  - %s", strings.Join(dedupe(problems), "\n  - "))
+	}
+
+	logger.Infof("✅ Source schema verified (%d columns)", len(columns))
+	return nil
+}
+
+// inFamily reports whether dataType is compatible with the family
+func inFamily(family, dataType string) bool {
+	if family == FamilyAny {
+		return true
+	}
+	for _, t := range families[family] {
+		if t == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupe removes repeated messages (e.g. one per column of a missing table)
+func dedupe(items []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package silver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"ai-production-pipeline/internal/uuid"
+	"ai-production-pipeline/internal/weekmanager"
+)
+
+// Analyzer exposes Silver's weekly analytics as a library for teams that need the numbers without AI
+// reports. Nothing is written to disk; give the layer a store from OpenMetricStore so nothing is
+// written to the database either.
+type Analyzer struct {
+	silver *SilverLayer
+	weeks  *weekmanager.WeekManager
+}
+
+// NewAnalyzer wraps a configured Silver layer; weeks supplies the history for trends
+func NewAnalyzer(layer *SilverLayer, weeks *weekmanager.WeekManager) *Analyzer {
+	return &Analyzer{silver: layer, weeks: weeks}
+}
+
+// Weeks returns the weeks with data, numbered as in pipeline runs
+func (a *Analyzer) Weeks() ([]weekmanager.WeekRange, error) {
+	return a.weeks.GetAvailableWeeks()
+}
+
+// Week returns the week with the given number
+func (a *Analyzer) Week(number int) (weekmanager.WeekRange, error) {
+	weeks, err := a.Weeks()
+	if err != nil {
+		return weekmanager.WeekRange{}, fmt.Errorf("failed to get available weeks: %w", err)
+	}
+	for _, week := range weeks {
+		if week.WeekNumber == number {
+			return week, nil
+		}
+	}
+	return weekmanager.WeekRange{}, fmt.Errorf("week %d not found (%d weeks available)", number, len(weeks))
+}
+
+// AnalyzeKid returns one kid's analytics for week, with trends against the preceding weeks
+func (a *Analyzer) AnalyzeKid(ctx context.Context, profileID string, week weekmanager.WeekRange) (*EnhancedKidData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	weekData, err := a.weekData(week)
+	if err != nil {
+		return nil, err
+	}
+	return a.silver.AnalyzeProfile(ctx, profileID, weekData)
+}
+
+// AnalyzeWeek analyzes every kid (including inactive ones) for week and hands each to emit as soon as
+// it is ready, in completion order (silver.max_concurrent kids at a time). Kids that fail are logged and skipped; an emit error or cancelled ctx stops the week.
+func (a *Analyzer) AnalyzeWeek(ctx context.Context, week weekmanager.WeekRange, emit func(EnhancedKidData) error) error {
+	weekData, err := a.weekData(week)
+	if err != nil {
+		return err
+	}
+	profiles, invalid, err := a.silver.getAllKidProfiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get kid profiles: %w", err)
+	}
+	a.silver.reportInvalidProfiles(invalid)
+
+	prefetched := a.silver.prefetchWeeks(ctx, profiles, weekData)
+	return a.silver.analyzeAll(ctx, profiles, weekData, prefetched, func(index int, kidData *EnhancedKidData, err error) error {
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			a.silver.logger.Errorf("   ❌ Error analyzing %s: %v", profiles[index].ProfileID, err)
+			return nil
+		}
+		return emit(*kidData)
+	})
+}
+
+// weekData adds the two preceding weeks when week is one of the available weeks (matched by start date)
+func (a *Analyzer) weekData(week weekmanager.WeekRange) (*weekmanager.WeekData, error) {
+	weeks, err := a.Weeks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available weeks: %w", err)
+	}
+	for _, candidate := range weeks {
+		if candidate.StartDate.Equal(week.StartDate) {
+			return a.weeks.GetWeekData(candidate, weeks), nil
+		}
+	}
+	return &weekmanager.WeekData{CurrentWeek: week}, nil
+}
+
+// ServeHTTP serves the analytics API:
+//
+//	GET /weeks                              available weeks
+//	GET /analysis/kid?profile_id=ID&week=N  one kid's analytics
+//	GET /analysis/week?week=N               every kid, streamed as newline-delimited JSON
+func (a *Analyzer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Path == "/weeks" {
+		weeks, err := a.Weeks()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(weeks)
+		return
+	}
+
+	if r.URL.Path != "/analysis/kid" && r.URL.Path != "/analysis/week" {
+		http.NotFound(w, r)
+		return
+	}
+	number, err := strconv.Atoi(r.URL.Query().Get("week"))
+	if err != nil {
+		http.Error(w, "week must be a week number", http.StatusBadRequest)
+		return
+	}
+	week, err := a.Week(number)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if r.URL.Path == "/analysis/kid" {
+		profileID := r.URL.Query().Get("profile_id")
+		if profileID == "" {
+			http.Error(w, "profile_id is required", http.StatusBadRequest)
+			return
+		}
+		kidData, err := a.AnalyzeKid(r.Context(), profileID, week)
+		if errors.Is(err, uuid.ErrInvalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(kidData)
+		return
+	}
+
+	// Once streaming starts the status is sent, so later errors only end the stream
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	err = a.AnalyzeWeek(r.Context(), week, func(kidData EnhancedKidData) error {
+		if err := encoder.Encode(kidData); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		a.silver.logger.Warnf("⚠️  Week analysis stream for %s ended early: %v", week.Label, err)
+	}
+}
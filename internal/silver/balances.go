@@ -0,0 +1,85 @@
+package silver
+
+import (
+	"fmt"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// Balance modes (silver.balances.mode)
+const (
+	BalanceModeTransactions = "transactions" // Current balance with the transactions after the week undone
+	BalanceModeSnapshots    = "snapshots"    // Latest row of the balance snapshots table before the week end
+	BalanceModeCurrent      = "current"      // Current balance for every week (previous behavior)
+)
+
+// BalancePolicy decides how a wallet's balance at the end of a week is read
+type BalancePolicy struct {
+	mode          string
+	snapshotTable string // snapshots mode: table with wallet_id, balance and snapshot_at
+}
+
+// NewBalancePolicy checks silver.balances; the default reconstructs balances from transactions
+func NewBalancePolicy(cfg config.BalancesConfig) (BalancePolicy, error) {
+	policy := BalancePolicy{mode: cfg.Mode, snapshotTable: cfg.SnapshotTable}
+	switch cfg.Mode {
+	case "":
+		policy.mode = BalanceModeTransactions
+	case BalanceModeTransactions, BalanceModeCurrent:
+	case BalanceModeSnapshots:
+		if policy.snapshotTable == "" {
+			policy.snapshotTable = "balance_snapshots"
+		}
+		if !columnNamePattern.MatchString(policy.snapshotTable) {
+			return BalancePolicy{mode: BalanceModeTransactions},
+				fmt.Errorf("silver.balances.snapshot_table %q must be a lowercase identifier", cfg.SnapshotTable)
+		}
+	default:
+		return BalancePolicy{mode: BalanceModeTransactions},
+			fmt.Errorf("silver.balances.mode must be transactions, snapshots or current, got %q", cfg.Mode)
+	}
+	return policy, nil
+}
+
+// walletSelect returns the statement selecting profile_id, slug and balance of each wallet w as of
+// the week end (endParam, e.g. "$2"). In transactions mode this is the current balance: the later
+// flows are undone in Go, see laterFlows.
+func (p BalancePolicy) walletSelect(endParam string) string {
+	if p.mode != BalanceModeSnapshots {
+		return "SELECT w.profile_id, w.slug, w.balance FROM wallets w"
+	}
+	// A wallet without a snapshot before the week end did not exist yet
+	return fmt.Sprintf(`
+		SELECT w.profile_id, w.slug, COALESCE(bs.balance, 0) AS balance
+		FROM wallets w
+		LEFT JOIN LATERAL (
+			SELECT s.balance FROM %s s
+			WHERE s.wallet_id = w.id AND s.snapshot_at < %s::date
+			ORDER BY s.snapshot_at DESC
+			LIMIT 1
+		) bs ON true
+	`, p.snapshotTable, endParam)
+}
+
+// reconstructs reports whether the transactions after the week are needed
+func (p BalancePolicy) reconstructs() bool {
+	return p.mode == BalanceModeTransactions
+}
+
+// laterFlows is each wallet's net change after the week (deposits and interest in, withdrawals out),
+// which is subtracted from the current balance to get the balance at the end of the week
+func (s *SilverLayer) laterFlows(rows []txRow) map[string]Money {
+	flows := make(map[string]Money)
+	for _, row := range rows {
+		if row.Slug == "" {
+			continue // Deleted wallet: no balance to correct
+		}
+		switch {
+		case s.interest.Matches(row.Type, row.Source), row.Type == "deposit":
+			flows[row.Slug] += row.Amount
+		case row.Type == "withdraw":
+			flows[row.Slug] -= row.Amount
+		}
+	}
+	return flows
+}
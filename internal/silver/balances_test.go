@@ -0,0 +1,108 @@
+package silver
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fixtures"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/sirupsen/logrus"
+)
+
+const balanceKid = "3f1c2a9e-5b7d-4e21-9a60-1d2b3c4d5e01"
+
+// balanceFixtures is one kid whose wallets hold the current balances, with transactions before,
+// during and after the week of 2025-10-06
+var balanceFixtures = map[string]string{
+	"profiles.json": `[{"id": "` + balanceKid + `", "full_name": "An", "profile_type": "kid"}]`,
+	"wallets.json": `[
+		{"id": "w-joy", "profile_id": "` + balanceKid + `", "slug": "joy", "balance": 150000},
+		{"id": "w-study", "profile_id": "` + balanceKid + `", "slug": "study", "balance": 210000}
+	]`,
+	"wallet_transactions.json": `[
+		{"profile_id": "` + balanceKid + `", "wallet_id": "w-joy", "type": "deposit", "amount": 99999, "created_at": "2025-09-30T08:00:00"},
+		{"profile_id": "` + balanceKid + `", "wallet_id": "w-joy", "type": "deposit", "amount": 20000, "created_at": "2025-10-07T08:00:00"},
+		{"profile_id": "` + balanceKid + `", "wallet_id": "w-joy", "type": "deposit", "amount": 30000, "created_at": "2025-10-13T00:00:00"},
+		{"profile_id": "` + balanceKid + `", "wallet_id": "w-joy", "type": "withdraw", "amount": 10000, "created_at": "2025-10-15T17:00:00"},
+		{"profile_id": "` + balanceKid + `", "wallet_id": "w-study", "type": "interest", "amount": 5000, "created_at": "2025-10-20T00:00:00"},
+		{"profile_id": "` + balanceKid + `", "wallet_id": "w-deleted", "type": "deposit", "amount": 7000, "created_at": "2025-10-14T08:00:00"}
+	]`,
+}
+
+func newBalanceTestLayer(t *testing.T, mode string) *SilverLayer {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range balanceFixtures {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set, err := fixtures.Load(dir)
+	if err != nil {
+		t.Fatalf("fixtures.Load: %v", err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	s := NewSilverLayer(nil, logger, config.SilverConfig{Balances: config.BalancesConfig{Mode: mode}})
+	s.SetFixtures(set)
+	return s
+}
+
+func balanceTestWeek() *weekmanager.WeekRange {
+	start := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)
+	return &weekmanager.WeekRange{WeekNumber: 1, Label: "Tuần 1 - Tháng 10/2025", StartDate: start, EndDate: start.AddDate(0, 0, 7)}
+}
+
+func TestBalancesReconstructedFromLaterTransactions(t *testing.T) {
+	s := newBalanceTestLayer(t, "")
+	metrics, err := s.fixtureWeekMetrics(context.Background(), balanceKid, balanceTestWeek())
+	if err != nil {
+		t.Fatalf("fixtureWeekMetrics: %v", err)
+	}
+	// joy: 150000 now, minus 30000 deposited and plus 10000 withdrawn after the week; study: the
+	// 5000 interest credited after the week is undone; the deleted wallet's deposit is ignored
+	if metrics.JoyWallet != 130000 || metrics.StudyWallet != 205000 || metrics.TotalBalance != 335000 {
+		t.Errorf("balances joy %v, study %v, total %v; want 130000, 205000, 335000",
+			metrics.JoyWallet, metrics.StudyWallet, metrics.TotalBalance)
+	}
+	if metrics.MoneyReceived != 20000 || metrics.MoneyReceivedCount != 1 {
+		t.Errorf("received %v in %d deposits, want the week's 20000 in 1", metrics.MoneyReceived, metrics.MoneyReceivedCount)
+	}
+}
+
+func TestBalancesCurrentMode(t *testing.T) {
+	s := newBalanceTestLayer(t, BalanceModeCurrent)
+	metrics, err := s.fixtureWeekMetrics(context.Background(), balanceKid, balanceTestWeek())
+	if err != nil {
+		t.Fatalf("fixtureWeekMetrics: %v", err)
+	}
+	if metrics.JoyWallet != 150000 || metrics.StudyWallet != 210000 {
+		t.Errorf("balances joy %v, study %v; want the current 150000, 210000", metrics.JoyWallet, metrics.StudyWallet)
+	}
+}
+
+func TestNewBalancePolicy(t *testing.T) {
+	tests := []struct {
+		cfg         config.BalancesConfig
+		reconstruct bool
+		wantErr     bool
+	}{
+		{config.BalancesConfig{}, true, false},
+		{config.BalancesConfig{Mode: BalanceModeCurrent}, false, false},
+		{config.BalancesConfig{Mode: BalanceModeSnapshots}, false, false},
+		{config.BalancesConfig{Mode: BalanceModeSnapshots, SnapshotTable: "Snapshots; DROP"}, true, true},
+		{config.BalancesConfig{Mode: "latest"}, true, true},
+	}
+	for _, tt := range tests {
+		policy, err := NewBalancePolicy(tt.cfg)
+		if (err != nil) != tt.wantErr || policy.reconstructs() != tt.reconstruct {
+			t.Errorf("NewBalancePolicy(%+v) reconstructs %v, err %v", tt.cfg, policy.reconstructs(), err)
+		}
+	}
+}
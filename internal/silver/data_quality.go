@@ -0,0 +1,41 @@
+package silver
+
+import "database/sql"
+
+// Data quality flags recorded on kids whose profile data is incomplete
+const (
+	DataQualityUnknownAge     = "unknown_age"     // date_of_birth is NULL
+	DataQualityImplausibleAge = "implausible_age" // date_of_birth gives an age outside the app's range
+	DataQualityMissingName    = "missing_name"    // No usable name on the profile
+	DataQualityDeletedProfile = "deleted_profile" // Profile was soft-deleted (silver.deleted_profiles.mode: flag)
+	DataQualityMissingWallets = "missing_wallets" // Transactions reference wallets that no longer exist
+)
+
+// Ages outside this range are treated as data entry errors
+const (
+	minPlausibleAge = 3
+	maxPlausibleAge = 18
+)
+
+// applyProfileFields sets age and nickname from nullable columns, recording data quality flags
+// instead of coercing missing values to 0 or a placeholder name
+func (p *KidProfile) applyProfileFields(age sql.NullInt64, nickname sql.NullString) {
+	p.DataQuality = nil
+
+	switch {
+	case !age.Valid:
+		p.DataQuality = append(p.DataQuality, DataQualityUnknownAge)
+	case age.Int64 < minPlausibleAge || age.Int64 > maxPlausibleAge:
+		p.DataQuality = append(p.DataQuality, DataQualityImplausibleAge)
+	default:
+		a := int(age.Int64)
+		p.Age = &a
+	}
+
+	if nickname.Valid {
+		p.Nickname = nickname.String
+	} else {
+		p.Nickname = ""
+		p.DataQuality = append(p.DataQuality, DataQualityMissingName)
+	}
+}
@@ -0,0 +1,82 @@
+package silver
+
+import (
+	"database/sql"
+	"fmt"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/progress"
+)
+
+// Modes for silver.deleted_profiles
+const (
+	DeletedProfilesInclude = "include" // Deleted kids are analyzed like any other
+	DeletedProfilesExclude = "exclude" // Deleted kids are left out of the week
+	DeletedProfilesFlag    = "flag"    // Deleted kids are analyzed with the deleted_profile data quality flag
+)
+
+// DeletedProfilePolicy decides what happens to kids whose profile was soft-deleted
+type DeletedProfilePolicy struct {
+	column string // profiles column holding the deletion time ("" = off)
+	mode   string
+}
+
+// NewDeletedProfilePolicy builds the policy from config; without a column no profile counts as deleted
+func NewDeletedProfilePolicy(cfg config.DeletedProfilesConfig) (DeletedProfilePolicy, error) {
+	policy := DeletedProfilePolicy{mode: cfg.Mode}
+	switch cfg.Mode {
+	case "":
+		policy.mode = DeletedProfilesInclude
+	case DeletedProfilesInclude, DeletedProfilesExclude, DeletedProfilesFlag:
+	default:
+		return DeletedProfilePolicy{mode: DeletedProfilesInclude},
+			fmt.Errorf("silver.deleted_profiles.mode must be include, exclude or flag, got %q", cfg.Mode)
+	}
+
+	if cfg.Column == "" {
+		return policy, nil
+	}
+	if !columnNamePattern.MatchString(cfg.Column) {
+		return DeletedProfilePolicy{mode: DeletedProfilesInclude},
+			fmt.Errorf("invalid silver.deleted_profiles.column %q", cfg.Column)
+	}
+	policy.column = cfg.Column
+	return policy, nil
+}
+
+// deletedExpr returns the SQL expression selecting the profile's deletion time (NULL when off)
+func (p DeletedProfilePolicy) deletedExpr() string {
+	if p.column == "" {
+		return "NULL::timestamptz"
+	}
+	return p.column + "::timestamptz"
+}
+
+// excludes reports whether a kid is left out of the week entirely
+func (p DeletedProfilePolicy) excludes(profile KidProfile) bool {
+	return profile.Deleted && p.mode == DeletedProfilesExclude
+}
+
+// excludeDeletedProfiles drops deleted kids from the week when the policy excludes them
+func (s *SilverLayer) excludeDeletedProfiles(week string, profiles []KidProfile) []KidProfile {
+	kept := profiles[:0]
+	for _, profile := range profiles {
+		if s.deleted.excludes(profile) {
+			s.progress.RecordKid(week, profile.ProfileID.String(), profile.Nickname, progress.DispositionDeletedProfile, "")
+			continue
+		}
+		kept = append(kept, profile)
+	}
+	if excluded := len(profiles) - len(kept); excluded > 0 {
+		s.logger.Infof("🗑️  %d deleted kid profiles excluded (silver.deleted_profiles.mode: exclude)", excluded)
+	}
+	return kept
+}
+
+// applyDeletedAt marks the profile as deleted, flagging it when the policy asks for it
+func (p DeletedProfilePolicy) applyDeletedAt(profile *KidProfile, deletedAt sql.NullTime) {
+	profile.Deleted = deletedAt.Valid
+	if profile.Deleted && p.mode == DeletedProfilesFlag {
+		profile.DataQuality = append(profile.DataQuality, DataQualityDeletedProfile)
+	}
+}
@@ -0,0 +1,225 @@
+package silver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"ai-production-pipeline/internal/fileio"
+)
+
+// FieldDiff describes a single field that differs between two Silver outputs
+type FieldDiff struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// KidDiff lists the field differences for one kid
+type KidDiff struct {
+	ProfileID string      `json:"profile_id"`
+	Nickname  string      `json:"nickname"`
+	Fields    []FieldDiff `json:"fields"`
+}
+
+// DiffReport is the result of comparing two Silver outputs
+type DiffReport struct {
+	OnlyInOld    []string    `json:"only_in_old"` // Profile IDs missing from the new output
+	OnlyInNew    []string    `json:"only_in_new"` // Profile IDs missing from the old output
+	Changed      []KidDiff   `json:"changed"`
+	TopLevel     []FieldDiff `json:"top_level"` // Differences outside the kids array
+	KidsCompared int         `json:"kids_compared"`
+}
+
+// HasDifferences reports whether the outputs differ beyond tolerance
+func (r *DiffReport) HasDifferences() bool {
+	return len(r.OnlyInOld) > 0 || len(r.OnlyInNew) > 0 || len(r.Changed) > 0 || len(r.TopLevel) > 0
+}
+
+// DiffOptions controls how Silver outputs are compared
+type DiffOptions struct {
+	Tolerance    float64         // Absolute tolerance for numbers
+	IgnoreFields map[string]bool // Field names skipped at any depth (e.g. generated_at)
+}
+
+// DiffOutputs compares two Silver output files kid-by-kid and field-by-field
+func DiffOutputs(oldPath, newPath string, opts DiffOptions) (*DiffReport, error) {
+	oldData, err := loadOutputMap(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := loadOutputMap(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DiffReport{}
+
+	// Top-level fields (week, total_kids, ...)
+	oldTop := withoutKey(oldData, "kids")
+	newTop := withoutKey(newData, "kids")
+	diffValues("", oldTop, newTop, opts, &report.TopLevel)
+
+	oldKids := indexKids(oldData)
+	newKids := indexKids(newData)
+
+	for id := range oldKids {
+		if _, ok := newKids[id]; !ok {
+			report.OnlyInOld = append(report.OnlyInOld, id)
+		}
+	}
+	for id := range newKids {
+		if _, ok := oldKids[id]; !ok {
+			report.OnlyInNew = append(report.OnlyInNew, id)
+		}
+	}
+	sort.Strings(report.OnlyInOld)
+	sort.Strings(report.OnlyInNew)
+
+	ids := make([]string, 0, len(oldKids))
+	for id := range oldKids {
+		if _, ok := newKids[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		report.KidsCompared++
+		var fields []FieldDiff
+		diffValues("", oldKids[id], newKids[id], opts, &fields)
+		if len(fields) > 0 {
+			nickname, _ := oldKids[id]["nickname"].(string)
+			report.Changed = append(report.Changed, KidDiff{ProfileID: id, Nickname: nickname, Fields: fields})
+		}
+	}
+
+	return report, nil
+}
+
+// Format renders the diff report as human-readable text
+func (r *DiffReport) Format() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Kids compared: %d | changed: %d | only in old: %d | only in new: %d\n",
+		r.KidsCompared, len(r.Changed), len(r.OnlyInOld), len(r.OnlyInNew))
+
+	for _, f := range r.TopLevel {
+		fmt.Fprintf(&b, "  [output] %s: %v → %v\n", f.Path, f.Old, f.New)
+	}
+	for _, id := range r.OnlyInOld {
+		fmt.Fprintf(&b, "  - %s (missing in new)\n", id)
+	}
+	for _, id := range r.OnlyInNew {
+		fmt.Fprintf(&b, "  + %s (missing in old)\n", id)
+	}
+	for _, kid := range r.Changed {
+		fmt.Fprintf(&b, "\n  %s (%s): %d field(s)\n", kid.Nickname, kid.ProfileID, len(kid.Fields))
+		for _, f := range kid.Fields {
+			fmt.Fprintf(&b, "     %s: %v → %v\n", f.Path, f.Old, f.New)
+		}
+	}
+
+	return b.String()
+}
+
+// diffValues recursively compares decoded JSON values
+func diffValues(path string, oldVal, newVal interface{}, opts DiffOptions, out *[]FieldDiff) {
+	switch o := oldVal.(type) {
+	case map[string]interface{}:
+		n, ok := newVal.(map[string]interface{})
+		if !ok {
+			*out = append(*out, FieldDiff{Path: path, Old: oldVal, New: newVal})
+			return
+		}
+		keys := make(map[string]bool)
+		for k := range o {
+			keys[k] = true
+		}
+		for k := range n {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			if !opts.IgnoreFields[k] {
+				sorted = append(sorted, k)
+			}
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			diffValues(joinPath(path, k), o[k], n[k], opts, out)
+		}
+
+	case []interface{}:
+		n, ok := newVal.([]interface{})
+		if !ok || len(o) != len(n) {
+			*out = append(*out, FieldDiff{Path: path, Old: oldVal, New: newVal})
+			return
+		}
+		for i := range o {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), o[i], n[i], opts, out)
+		}
+
+	case float64:
+		n, ok := newVal.(float64)
+		if !ok || math.Abs(o-n) > opts.Tolerance {
+			*out = append(*out, FieldDiff{Path: path, Old: oldVal, New: newVal})
+		}
+
+	default:
+		if oldVal != newVal {
+			*out = append(*out, FieldDiff{Path: path, Old: oldVal, New: newVal})
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// loadOutputMap reads a Silver output file as generic JSON
+func loadOutputMap(path string) (map[string]interface{}, error) {
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// indexKids maps profile_id to kid entry
+func indexKids(data map[string]interface{}) map[string]map[string]interface{} {
+	kids := make(map[string]map[string]interface{})
+	list, _ := data["kids"].([]interface{})
+	for i, k := range list {
+		kid, ok := k.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := kid["profile_id"].(string)
+		if id == "" {
+			id = fmt.Sprintf("#%d", i)
+		}
+		kids[id] = kid
+	}
+	return kids
+}
+
+func withoutKey(m map[string]interface{}, key string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if k != key {
+			out[k] = v
+		}
+	}
+	return out
+}
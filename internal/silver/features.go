@@ -0,0 +1,191 @@
+package silver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/weekmanager"
+)
+
+// FeatureInput is what a calculation function sees for one kid-week
+type FeatureInput struct {
+	Metrics  *WeekMetrics // The week's built-in metrics
+	Value    float64      // Result of the feature's SQL (0 without SQL)
+	HasValue bool         // The feature has SQL and it returned a non-null value
+}
+
+// FeatureFunc derives a feature value; ok is false when it is undefined for the week (e.g. nothing spent)
+type FeatureFunc func(in FeatureInput) (value float64, ok bool)
+
+// featureFuncs are the calculation functions features can name in derive. To add a metric, add a
+// function here and a silver.features entry in config.yaml.
+var featureFuncs = map[string]FeatureFunc{
+	// share_of_spent: the SQL amount as a percentage of the week's total spending
+	"share_of_spent": func(in FeatureInput) (float64, bool) {
+		if !in.HasValue || in.Metrics.TotalSpent <= 0 {
+			return 0, false
+		}
+		return in.Value / in.Metrics.TotalSpent * 100, true
+	},
+	// per_active_day: the SQL value divided by the days with a transaction
+	"per_active_day": func(in FeatureInput) (float64, bool) {
+		if !in.HasValue || in.Metrics.ActiveDays == 0 {
+			return 0, false
+		}
+		return in.Value / float64(in.Metrics.ActiveDays), true
+	},
+	// savings_rate: share of the money received that was not spent
+	"savings_rate": func(in FeatureInput) (float64, bool) {
+		if in.Metrics.MoneyReceived <= 0 {
+			return 0, false
+		}
+		return (in.Metrics.MoneyReceived - in.Metrics.TotalSpent) / in.Metrics.MoneyReceived * 100, true
+	},
+	// charity_share_of_spent: charity spending as a percentage of all spending
+	"charity_share_of_spent": func(in FeatureInput) (float64, bool) {
+		if in.Metrics.TotalSpent <= 0 {
+			return 0, false
+		}
+		return in.Metrics.CharitySpent / in.Metrics.TotalSpent * 100, true
+	},
+}
+
+// feature is a validated feature definition
+type feature struct {
+	config.FeatureConfig
+	derive FeatureFunc
+	params int // Placeholders the SQL uses ($1..$3), so only those are bound
+}
+
+// FeatureRegistry computes the configured derived metrics for each kid-week
+type FeatureRegistry struct {
+	features []feature
+}
+
+// placeholderPattern finds $N parameters in feature SQL
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// NewFeatureRegistry validates feature definitions (nil when there are none)
+func NewFeatureRegistry(defs []config.FeatureConfig) (*FeatureRegistry, error) {
+	if len(defs) == 0 {
+		return nil, nil
+	}
+	registry := &FeatureRegistry{}
+	seen := make(map[string]bool)
+	for i, def := range defs {
+		if def.Name == "" {
+			return nil, fmt.Errorf("silver.features[%d]: name is required", i)
+		}
+		if def.OutputField == "" {
+			def.OutputField = def.Name
+		}
+		if !columnNamePattern.MatchString(def.OutputField) {
+			return nil, fmt.Errorf("silver.features %s: output_field %q must be lowercase letters, digits and underscores", def.Name, def.OutputField)
+		}
+		if seen[def.OutputField] {
+			return nil, fmt.Errorf("silver.features %s: output_field %q is used twice", def.Name, def.OutputField)
+		}
+		seen[def.OutputField] = true
+
+		f := feature{FeatureConfig: def}
+		if def.SQL == "" && def.Derive == "" {
+			return nil, fmt.Errorf("silver.features %s: needs sql, derive or both", def.Name)
+		}
+		if def.Derive != "" {
+			f.derive = featureFuncs[def.Derive]
+			if f.derive == nil {
+				return nil, fmt.Errorf("silver.features %s: unknown derive function %q", def.Name, def.Derive)
+			}
+		}
+		if def.SQL != "" {
+			words := strings.Fields(def.SQL)
+			if len(words) == 0 {
+				return nil, fmt.Errorf("silver.features %s: sql is blank", def.Name)
+			}
+			first := strings.ToUpper(words[0])
+			if first != "SELECT" && first != "WITH" {
+				return nil, fmt.Errorf("silver.features %s: sql must be a SELECT query", def.Name)
+			}
+			for _, match := range placeholderPattern.FindAllStringSubmatch(def.SQL, -1) {
+				n, _ := strconv.Atoi(match[1])
+				if n < 1 || n > 3 {
+					return nil, fmt.Errorf("silver.features %s: sql may only use $1 (profile ID), $2 (week start) and $3 (week end), found $%d", def.Name, n)
+				}
+				if n > f.params {
+					f.params = n
+				}
+			}
+		}
+		registry.features = append(registry.features, f)
+	}
+	return registry, nil
+}
+
+// computeFeatures fills metrics.Features. A feature whose query fails is left out with a warning, so one
+// broken metric never costs a kid their report.
+func (s *SilverLayer) computeFeatures(ctx context.Context, profileID string, week *weekmanager.WeekRange, metrics *WeekMetrics) {
+	if s.features == nil {
+		return
+	}
+	startDate, endDate := week.FormatDateRange()
+	args := []interface{}{profileID, startDate, endDate}
+
+	for _, f := range s.features.features {
+		in := FeatureInput{Metrics: metrics}
+		if f.SQL != "" && s.fixtures != nil {
+			continue // SQL features need the database
+		}
+		if f.SQL != "" {
+			value, ok, err := s.queryFeature(ctx, f, args[:f.params])
+			if err != nil {
+				s.logger.Warnf("      ⚠️  Feature %s failed for %s: %v", f.Name, week.Label, err)
+				continue
+			}
+			in.Value, in.HasValue = value, ok
+		}
+
+		value, ok := in.Value, in.HasValue
+		if f.derive != nil {
+			value, ok = f.derive(in)
+		}
+		if !ok || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		if metrics.Features == nil {
+			metrics.Features = make(map[string]float64)
+		}
+		metrics.Features[f.OutputField] = math.Round(value*100) / 100
+	}
+}
+
+// queryFeature runs a feature's SQL; ok is false when it returned NULL or no row
+func (s *SilverLayer) queryFeature(ctx context.Context, f feature, args []interface{}) (float64, bool, error) {
+	row := s.db.QueryRowContext(ctx, f.SQL, args...)
+	if f.Amount {
+		var raw interface{}
+		if err := row.Scan(&raw); err == sql.ErrNoRows || (err == nil && raw == nil) {
+			return 0, false, nil
+		} else if err != nil {
+			return 0, false, err
+		}
+		money, err := s.amounts.parse(raw)
+		if err != nil {
+			return 0, false, err
+		}
+		return s.amounts.Float(money), true, nil
+	}
+
+	var value sql.NullFloat64
+	if err := row.Scan(&value); err == sql.ErrNoRows {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	return value.Float64, value.Valid, nil
+}
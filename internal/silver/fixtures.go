@@ -0,0 +1,182 @@
+package silver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/fixtures"
+	"ai-production-pipeline/internal/uuid"
+	"ai-production-pipeline/internal/weekmanager"
+)
+
+// SetFixtures reads the source rows from a fixture set instead of the database (run --fixtures).
+// Metrics are computed in memory the way the queries compute them; SQL features, spending
+// categories, section preferences and operator notes need the database and are not read.
+func (s *SilverLayer) SetFixtures(set *fixtures.Set) {
+	s.fixtures = set
+}
+
+// fixtureProfiles returns the fixture kids in file order, like getAllKidProfiles
+func (s *SilverLayer) fixtureProfiles() ([]KidProfile, []InvalidProfile) {
+	var profiles []KidProfile
+	var invalid []InvalidProfile
+	for _, row := range s.fixtures.Rows("profiles") {
+		if kind := row.String("profile_type"); kind != "" && kind != "kid" {
+			continue
+		}
+		id, err := uuid.Parse(row.String("id"))
+		if err != nil {
+			invalid = append(invalid, InvalidProfile{ProfileID: row.String("id"), Error: err.Error()})
+			continue
+		}
+		profiles = append(profiles, s.fixtureProfile(id, row))
+	}
+	return profiles, invalid
+}
+
+// fixtureKidProfile returns one fixture kid, like getKidProfile
+func (s *SilverLayer) fixtureKidProfile(profileID uuid.UUID) (*KidProfile, error) {
+	for _, row := range s.fixtures.Rows("profiles") {
+		if kind := row.String("profile_type"); kind != "" && kind != "kid" {
+			continue
+		}
+		if id, err := uuid.Parse(row.String("id")); err == nil && id == profileID {
+			profile := s.fixtureProfile(id, row)
+			return &profile, nil
+		}
+	}
+	return nil, fmt.Errorf("kid profile not found")
+}
+
+// fixtureProfile builds a profile from its row, with the same data quality flags as the query
+func (s *SilverLayer) fixtureProfile(id uuid.UUID, row fixtures.Row) KidProfile {
+	p := KidProfile{ProfileID: id, FullName: row.String("full_name"), DateOfBirth: row.String("date_of_birth")}
+	if p.FullName == "" {
+		p.FullName = "Unknown"
+	}
+	if s.languageColumn != "" {
+		p.Language = row.String(s.languageColumn)
+	}
+
+	var age sql.NullInt64
+	if dob, ok := row.Time("date_of_birth"); ok {
+		now := time.Now()
+		years := now.Year() - dob.Year()
+		if now.Month() < dob.Month() || (now.Month() == dob.Month() && now.Day() < dob.Day()) {
+			years-- // No birthday yet this year, like AGE(CURRENT_DATE, date_of_birth)
+		}
+		age = sql.NullInt64{Int64: int64(years), Valid: true}
+	}
+	var nickname sql.NullString
+	if name := strings.TrimSpace(row.String("full_name")); name != "" {
+		nickname = sql.NullString{String: row.String("full_name"), Valid: true}
+	}
+	p.applyProfileFields(age, nickname)
+
+	var deletedAt sql.NullTime
+	if s.deleted.column != "" {
+		deletedAt.Time, deletedAt.Valid = row.Time(s.deleted.column)
+	}
+	s.deleted.applyDeletedAt(&p, deletedAt)
+	return p
+}
+
+// fixtureParentID returns the kid's parent profile from the fixtures, like getParentID
+func (s *SilverLayer) fixtureParentID(profileID string) string {
+	for _, row := range s.fixtures.Rows("profiles") {
+		if row.String("id") == profileID {
+			return row.String(s.parentColumn)
+		}
+	}
+	return ""
+}
+
+// fixtureWeekMetrics groups a kid's fixture rows like the per-kid queries and builds the week from them
+func (s *SilverLayer) fixtureWeekMetrics(ctx context.Context, profileID string, week *weekmanager.WeekRange) (*WeekMetrics, error) {
+	start, end := fixtureDate(week.StartDate), fixtureDate(week.EndDate)
+	source := &kidSourceRows{}
+
+	// Balances are the current ones; the transactions after the week are undone from them (the
+	// snapshots mode has no table here, so it reconstructs too)
+	slugs := make(map[string]string)
+	for _, row := range s.fixtures.Rows("wallets") {
+		slugs[row.String("id")] = row.String("slug")
+		if row.String("profile_id") != profileID {
+			continue
+		}
+		balance, err := s.amounts.parse(row.Amount("balance"))
+		if err != nil {
+			return nil, fmt.Errorf("wallet %s: %w", row.String("id"), err)
+		}
+		source.Wallets = append(source.Wallets, walletRow{Slug: row.String("slug"), Balance: balance})
+	}
+
+	transactions := make(map[txRow]*txRow)
+	later := make(map[txRow]*txRow)
+	days := make(map[dayRow]bool)
+	for _, row := range s.fixtures.Rows("wallet_transactions") {
+		if row.String("profile_id") != profileID {
+			continue
+		}
+		createdAt, _ := row.Time("created_at")
+		amount, err := s.amounts.parse(row.Amount("amount"))
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", row.String("id"), err)
+		}
+		var txSource string
+		if s.interest.sourceColumn != "" {
+			txSource = row.String(s.interest.sourceColumn)
+		}
+		key := txRow{Slug: slugs[row.String("wallet_id")], Type: row.String("type"), Source: txSource}
+
+		groups := transactions
+		switch {
+		case createdAt.Before(start):
+			continue
+		case !createdAt.Before(end):
+			if s.balances.mode == BalanceModeCurrent {
+				continue
+			}
+			groups = later
+		default:
+			days[dayRow{Day: createdAt.Format("2006-01-02"), Type: key.Type, Source: txSource}] = true
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &txRow{Slug: key.Slug, Type: key.Type, Source: key.Source}
+			groups[key] = group
+		}
+		group.Amount += amount
+		group.Count++
+	}
+	for _, group := range transactions {
+		source.Transactions = append(source.Transactions, *group)
+	}
+	for _, group := range later {
+		source.Later = append(source.Later, *group)
+	}
+	for day := range days {
+		source.Days = append(source.Days, day)
+	}
+
+	statuses := make(map[string]int)
+	for _, row := range s.fixtures.Rows("missions") {
+		createdAt, _ := row.Time("created_at")
+		if row.String("profile_id") == profileID && !createdAt.Before(start) && createdAt.Before(end) {
+			statuses[row.String("status")]++
+		}
+	}
+	for status, count := range statuses {
+		source.Missions = append(source.Missions, missionRow{Status: status, Count: count})
+	}
+
+	return s.buildWeekMetrics(ctx, profileID, week, source)
+}
+
+// fixtureDate is the day of t at midnight, the way a week boundary compares as a ::date
+func fixtureDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
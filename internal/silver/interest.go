@@ -0,0 +1,69 @@
+package silver
+
+import (
+	"fmt"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// InterestRule recognizes the weekly interest the app credits to the study wallet, so it is reported
+// apart from deposits the family made
+type InterestRule struct {
+	types        map[string]bool
+	sourceColumn string // wallet_transactions column flagging interest ("" = types only)
+	sourceValues map[string]bool
+}
+
+// NewInterestRule builds the rule from config, defaulting to transactions of type 'interest'
+func NewInterestRule(cfg config.InterestConfig) (InterestRule, error) {
+	rule := InterestRule{types: make(map[string]bool), sourceValues: make(map[string]bool)}
+	types := cfg.Types
+	if len(types) == 0 && cfg.SourceColumn == "" {
+		types = []string{"interest"}
+	}
+	for _, t := range types {
+		rule.types[normalizeStatus(t)] = true
+	}
+
+	if cfg.SourceColumn == "" {
+		return rule, nil
+	}
+	if !columnNamePattern.MatchString(cfg.SourceColumn) {
+		return rule, fmt.Errorf("invalid silver.interest.source_column %q", cfg.SourceColumn)
+	}
+	values := cfg.SourceValues
+	if len(values) == 0 {
+		values = []string{"interest"}
+	}
+	rule.sourceColumn = cfg.SourceColumn
+	for _, v := range values {
+		rule.sourceValues[normalizeStatus(v)] = true
+	}
+	return rule, nil
+}
+
+// sourceExpr returns the SQL expression selecting the transaction's source flag (alias is the table alias, may be "")
+func (r InterestRule) sourceExpr(alias string) string {
+	if r.sourceColumn == "" {
+		return "''"
+	}
+	if alias != "" {
+		return fmt.Sprintf("COALESCE(%s.%s::text, '')", alias, r.sourceColumn)
+	}
+	return fmt.Sprintf("COALESCE(%s::text, '')", r.sourceColumn)
+}
+
+// Matches reports whether a transaction with this type and source flag is an interest credit
+func (r InterestRule) Matches(txType, source string) bool {
+	return r.types[normalizeStatus(txType)] || (source != "" && r.sourceValues[normalizeStatus(source)])
+}
+
+// studyGrowthRate is the week's interest as a percentage of the study wallet before the week's flows.
+// The balance is the one at the end of the week, so the opening balance is derived by undoing its study transactions.
+func studyGrowthRate(interest, studyNet, studyBalance Money) (float64, bool) {
+	opening := studyBalance - studyNet
+	if interest <= 0 || opening <= 0 {
+		return 0, false
+	}
+	return float64(interest) / float64(opening) * 100, true
+}
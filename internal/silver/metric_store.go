@@ -0,0 +1,328 @@
+package silver
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// metricStoreVersion is bumped when WeekMetrics changes meaning; rows from other versions are recomputed
+const metricStoreVersion = 2 // 2: wallet balances as of the week end (silver.balances)
+
+// HistoryPoint is a compact summary of one stored week, used for long lookback trends
+type HistoryPoint struct {
+	WeekLabel      string  `json:"week_label"`
+	StartDate      string  `json:"start_date"`
+	MoneyReceived  float64 `json:"money_received"`
+	TotalSpent     float64 `json:"total_spent"`
+	TotalBalance   float64 `json:"total_balance"`
+	CompletionRate float64 `json:"completion_rate"`
+	ActiveDays     int     `json:"active_days"`
+}
+
+// MetricStore keeps each kid's WeekMetrics in a time-series table keyed by (profile, week),
+// so earlier weeks are read back instead of recomputed from raw transactions on every run.
+// A row is only read back while the Silver settings it was computed with (configHash) and the
+// week's source rows (its watermark) are unchanged.
+type MetricStore struct {
+	db         *sql.DB
+	logger     *logrus.Logger
+	table      string
+	readOnly   bool   // Put and PutMany do nothing (OpenMetricStore)
+	configHash string // Hash of the settings that shape WeekMetrics
+
+	mu         sync.Mutex
+	watermarks map[string]string // Week key -> source-data watermark, read once per store
+}
+
+// metricsConfigHash hashes the Silver settings that change what WeekMetrics holds, so rows computed
+// under other settings are recomputed
+func metricsConfigHash(cfg config.SilverConfig) string {
+	shaping := struct {
+		MissionStatuses config.MissionStatusConfig
+		Amounts         config.AmountConfig
+		Features        []config.FeatureConfig
+		Interest        config.InterestConfig
+		DeletedProfiles config.DeletedProfilesConfig
+		Balances        config.BalancesConfig
+	}{cfg.MissionStatuses, cfg.Amounts, cfg.Features, cfg.Interest, cfg.DeletedProfiles, cfg.Balances}
+	raw, _ := json.Marshal(shaping)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8])
+}
+
+// metricStoreTable returns the store's table name, checked before it is put into SQL
+func metricStoreTable(table string) (string, error) {
+	if table == "" {
+		table = "kid_week_metrics"
+	}
+	if !columnNamePattern.MatchString(table) {
+		return "", fmt.Errorf("invalid metric store table %q", table)
+	}
+	return table, nil
+}
+
+// OpenMetricStore opens an existing store for reading only, for the serve API whose GET requests
+// must not write to the database: Put and PutMany do nothing, and a missing table is an error
+func OpenMetricStore(db *sql.DB, logger *logrus.Logger, cfg config.SilverConfig) (*MetricStore, error) {
+	table, err := metricStoreTable(cfg.MetricStore.Table)
+	if err != nil {
+		return nil, err
+	}
+	var exists bool
+	if err := db.QueryRow(`SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", table, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("table %s does not exist (pipeline runs create it)", table)
+	}
+	return &MetricStore{db: db, logger: logger, table: table, readOnly: true, configHash: metricsConfigHash(cfg),
+		watermarks: make(map[string]string)}, nil
+}
+
+// NewMetricStore creates the store and its table if missing
+func NewMetricStore(db *sql.DB, logger *logrus.Logger, cfg config.SilverConfig) (*MetricStore, error) {
+	table, err := metricStoreTable(cfg.MetricStore.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			profile_id  UUID NOT NULL,
+			week_start  DATE NOT NULL,
+			week_end    DATE NOT NULL,
+			week_label  TEXT NOT NULL,
+			version     INT NOT NULL,
+			config_hash TEXT NOT NULL DEFAULT '',
+			watermark   TEXT NOT NULL DEFAULT '',
+			metrics     JSONB NOT NULL,
+			computed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (profile_id, week_start, week_end)
+		);
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS config_hash TEXT NOT NULL DEFAULT '';
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS watermark TEXT NOT NULL DEFAULT ''
+	`, table)
+	if _, err := db.Exec(query); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", table, err)
+	}
+
+	return &MetricStore{db: db, logger: logger, table: table, configHash: metricsConfigHash(cfg),
+		watermarks: make(map[string]string)}, nil
+}
+
+// watermark returns the week's source-data watermark: the count and newest row version (xmin) of
+// its transactions and missions. Inserting, updating or deleting any of them (e.g. a late or
+// corrected transaction) changes it. It is read once per week and store.
+func (m *MetricStore) watermark(ctx context.Context, week *weekmanager.WeekRange) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if watermark, ok := m.watermarks[week.Key()]; ok {
+		return watermark, nil
+	}
+
+	startDate, endDate := week.FormatDateRange()
+	var watermark string
+	err := m.db.QueryRowContext(ctx, `
+		SELECT (SELECT count(*) || ':' || COALESCE(max(xmin::text::bigint), 0)
+		        FROM wallet_transactions WHERE created_at >= $1::date AND created_at < $2::date)
+		    || '/' ||
+		       (SELECT count(*) || ':' || COALESCE(max(xmin::text::bigint), 0)
+		        FROM missions WHERE created_at >= $1::date AND created_at < $2::date)
+	`, startDate, endDate).Scan(&watermark)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the source watermark of %s: %w", week.Label, err)
+	}
+	m.watermarks[week.Key()] = watermark
+	return watermark, nil
+}
+
+// Get returns the stored metrics for a kid and week (ok=false when missing, from another version or
+// settings, or when the week's source rows changed since)
+func (m *MetricStore) Get(ctx context.Context, profileID string, week *weekmanager.WeekRange) (*WeekMetrics, bool, error) {
+	watermark, err := m.watermark(ctx, week)
+	if err != nil {
+		return nil, false, err
+	}
+	startDate, endDate := week.FormatDateRange()
+	query := fmt.Sprintf(`
+		SELECT metrics
+		FROM %s
+		WHERE profile_id = $1::uuid AND week_start = $2::date AND week_end = $3::date AND version = $4
+		  AND config_hash = $5 AND watermark = $6
+	`, m.table)
+
+	var raw []byte
+	err = m.db.QueryRowContext(ctx, query, profileID, startDate, endDate, metricStoreVersion, m.configHash, watermark).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read stored metrics: %w", err)
+	}
+
+	var metrics WeekMetrics
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return nil, false, fmt.Errorf("failed to parse stored metrics: %w", err)
+	}
+	metrics.WeekLabel = week.Label // Labels depend on the calendar config, not on the data
+	return &metrics, true, nil
+}
+
+// GetMany returns the stored metrics of a week for the given kids by profile ID, in one query; kids
+// without a current row (see Get) are left out
+func (m *MetricStore) GetMany(ctx context.Context, profileIDs []string, week *weekmanager.WeekRange) (map[string]*WeekMetrics, error) {
+	watermark, err := m.watermark(ctx, week)
+	if err != nil {
+		return nil, err
+	}
+	startDate, endDate := week.FormatDateRange()
+	query := fmt.Sprintf(`
+		SELECT profile_id::text, metrics
+		FROM %s
+		WHERE profile_id = ANY($1::uuid[]) AND week_start = $2::date AND week_end = $3::date AND version = $4
+		  AND config_hash = $5 AND watermark = $6
+	`, m.table)
+	rows, err := m.db.QueryContext(ctx, query, pq.Array(profileIDs), startDate, endDate, metricStoreVersion, m.configHash, watermark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stored metrics: %w", err)
+	}
+	defer rows.Close()
+
+	stored := make(map[string]*WeekMetrics)
+	for rows.Next() {
+		var profileID string
+		var raw []byte
+		if err := rows.Scan(&profileID, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan stored metrics: %w", err)
+		}
+		var metrics WeekMetrics
+		if err := json.Unmarshal(raw, &metrics); err != nil {
+			return nil, fmt.Errorf("failed to parse stored metrics for %s: %w", profileID, err)
+		}
+		metrics.WeekLabel = week.Label
+		stored[profileID] = &metrics
+	}
+	return stored, rows.Err()
+}
+
+// Put stores (or replaces) a kid's metrics for a completed week
+func (m *MetricStore) Put(ctx context.Context, profileID string, week *weekmanager.WeekRange, metrics *WeekMetrics) error {
+	if week.IsPartial || m.readOnly {
+		return nil // Week-to-date numbers are never history
+	}
+	raw, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+	watermark, err := m.watermark(ctx, week)
+	if err != nil {
+		return err
+	}
+
+	startDate, endDate := week.FormatDateRange()
+	query := fmt.Sprintf(`
+		INSERT INTO %s (profile_id, week_start, week_end, week_label, version, config_hash, watermark, metrics)
+		VALUES ($1::uuid, $2::date, $3::date, $4, $5, $6, $7, $8)
+		ON CONFLICT (profile_id, week_start, week_end)
+		DO UPDATE SET week_label = EXCLUDED.week_label, version = EXCLUDED.version, config_hash = EXCLUDED.config_hash,
+		              watermark = EXCLUDED.watermark, metrics = EXCLUDED.metrics, computed_at = now()
+	`, m.table)
+	if _, err := m.db.ExecContext(ctx, query, profileID, startDate, endDate, week.Label, metricStoreVersion, m.configHash, watermark, raw); err != nil {
+		return fmt.Errorf("failed to store metrics: %w", err)
+	}
+	return nil
+}
+
+// PutMany stores (or replaces) the metrics of a completed week for many kids in one statement
+func (m *MetricStore) PutMany(ctx context.Context, week *weekmanager.WeekRange, metrics map[string]*WeekMetrics) error {
+	if week.IsPartial || m.readOnly || len(metrics) == 0 {
+		return nil
+	}
+	profileIDs := make([]string, 0, len(metrics))
+	payloads := make([]string, 0, len(metrics))
+	for profileID, kidMetrics := range metrics {
+		raw, err := json.Marshal(kidMetrics)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metrics for %s: %w", profileID, err)
+		}
+		profileIDs = append(profileIDs, profileID)
+		payloads = append(payloads, string(raw))
+	}
+	watermark, err := m.watermark(ctx, week)
+	if err != nil {
+		return err
+	}
+
+	startDate, endDate := week.FormatDateRange()
+	query := fmt.Sprintf(`
+		INSERT INTO %s (profile_id, week_start, week_end, week_label, version, config_hash, watermark, metrics)
+		SELECT kid.profile_id, $3::date, $4::date, $5, $6, $7, $8, kid.metrics
+		FROM unnest($1::uuid[], $2::jsonb[]) AS kid(profile_id, metrics)
+		ON CONFLICT (profile_id, week_start, week_end)
+		DO UPDATE SET week_label = EXCLUDED.week_label, version = EXCLUDED.version, config_hash = EXCLUDED.config_hash,
+		              watermark = EXCLUDED.watermark, metrics = EXCLUDED.metrics, computed_at = now()
+	`, m.table)
+	if _, err := m.db.ExecContext(ctx, query, pq.Array(profileIDs), pq.Array(payloads), startDate, endDate, week.Label, metricStoreVersion, m.configHash, watermark); err != nil {
+		return fmt.Errorf("failed to store metrics: %w", err)
+	}
+	return nil
+}
+
+// History returns up to limit stored weeks for a kid that ended on or before week's start, oldest
+// first. Only rows computed with the current settings count; their watermarks are not checked, so a
+// week whose source rows changed shows its stored numbers until a run recomputes it.
+func (m *MetricStore) History(ctx context.Context, profileID string, week *weekmanager.WeekRange, limit int) ([]HistoryPoint, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	startDate, _ := week.FormatDateRange()
+	query := fmt.Sprintf(`
+		SELECT metrics
+		FROM (
+			SELECT metrics, week_start
+			FROM %s
+			WHERE profile_id = $1::uuid AND week_end <= $2::date AND version = $3 AND config_hash = $4
+			ORDER BY week_start DESC
+			LIMIT $5
+		) recent
+		ORDER BY week_start
+	`, m.table)
+	rows, err := m.db.QueryContext(ctx, query, profileID, startDate, metricStoreVersion, m.configHash, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metric history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []HistoryPoint
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("failed to scan metric history: %w", err)
+		}
+		var metrics WeekMetrics
+		if err := json.Unmarshal(raw, &metrics); err != nil {
+			return nil, fmt.Errorf("failed to parse metric history: %w", err)
+		}
+		history = append(history, HistoryPoint{
+			WeekLabel:      metrics.WeekLabel,
+			StartDate:      metrics.StartDate,
+			MoneyReceived:  metrics.MoneyReceived,
+			TotalSpent:     metrics.TotalSpent,
+			TotalBalance:   metrics.TotalBalance,
+			CompletionRate: metrics.CompletionRate,
+			ActiveDays:     metrics.ActiveDays,
+		})
+	}
+	return history, rows.Err()
+}
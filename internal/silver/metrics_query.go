@@ -0,0 +1,336 @@
+package silver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/lib/pq"
+)
+
+// Metrics query modes (silver.metrics_query)
+const (
+	MetricsQueryAggregate = "aggregate" // One statement per week for all kids
+	MetricsQueryPerKid    = "per_kid"   // Wallet, transaction, mission and active-day queries per kid
+)
+
+// ValidateMetricsQuery checks silver.metrics_query
+func ValidateMetricsQuery(cfg config.SilverConfig) error {
+	switch cfg.MetricsQuery {
+	case "", MetricsQueryAggregate, MetricsQueryPerKid:
+		return nil
+	}
+	return fmt.Errorf("silver.metrics_query must be %s or %s, got %q", MetricsQueryAggregate, MetricsQueryPerKid, cfg.MetricsQuery)
+}
+
+// kidSourceRows are the grouped source rows a kid's week is computed from
+type kidSourceRows struct {
+	Wallets      []walletRow
+	Transactions []txRow
+	Missions     []missionRow
+	Days         []dayRow
+	Later        []txRow // Transactions after the week (silver.balances.mode: transactions)
+}
+
+// walletRow is one wallet's balance, before the later flows are undone
+type walletRow struct {
+	Slug    string
+	Balance Money
+}
+
+// txRow is the week's transactions of one wallet, type and source flag
+type txRow struct {
+	Slug   string // "" when the wallet was deleted
+	Type   string
+	Source string
+	Amount Money
+	Count  int
+}
+
+// missionRow is the week's missions with one status
+type missionRow struct {
+	Status string
+	Count  int
+}
+
+// dayRow is a day with transactions of one type and source flag
+type dayRow struct {
+	Day    string
+	Type   string
+	Source string
+}
+
+// aggregateRow is how the aggregate query returns a kid's grouped rows; amounts come as numeric text
+type aggregateRow struct {
+	Wallets []struct {
+		Slug    string `json:"slug"`
+		Balance string `json:"balance"`
+	} `json:"wallets"`
+	Transactions []struct {
+		Slug   string `json:"slug"`
+		Type   string `json:"type"`
+		Source string `json:"source"`
+		Amount string `json:"amount"`
+		Count  int    `json:"count"`
+	} `json:"transactions"`
+	Missions []struct {
+		Status string `json:"status"`
+		Count  int    `json:"count"`
+	} `json:"missions"`
+	Days []struct {
+		Day    string `json:"day"`
+		Type   string `json:"type"`
+		Source string `json:"source"`
+	} `json:"days"`
+	Later []struct {
+		Slug   string `json:"slug"`
+		Type   string `json:"type"`
+		Source string `json:"source"`
+		Amount string `json:"amount"`
+		Count  int    `json:"count"`
+	} `json:"later"`
+}
+
+// aggregateQuery builds the statement returning one row per kid of $1 with the week's wallet,
+// transaction, mission and active-day rows ($2 start, $3 end), and the transactions after the week
+// when balances are reconstructed, grouped exactly like the per-kid queries
+func (s *SilverLayer) aggregateQuery() string {
+	return fmt.Sprintf(`
+		WITH kids AS (
+			SELECT DISTINCT unnest($1::uuid[]) AS profile_id
+		),
+		wallet_rows AS (
+			SELECT b.profile_id, b.slug, b.balance
+			FROM (%[2]s) b
+			JOIN kids k ON k.profile_id = b.profile_id
+		),
+		tx_rows AS (
+			SELECT
+				wt.profile_id,
+				COALESCE(w.slug, '') AS slug,
+				wt.type,
+				%[1]s AS source,
+				SUM(wt.amount) AS total,
+				COUNT(*) AS count
+			FROM wallet_transactions wt
+			JOIN kids k ON k.profile_id = wt.profile_id
+			LEFT JOIN wallets w ON wt.wallet_id = w.id
+			WHERE wt.created_at >= $2::date
+			  AND wt.created_at < $3::date
+			GROUP BY 1, 2, 3, 4
+		),
+		mission_rows AS (
+			SELECT m.profile_id, COALESCE(m.status, '') AS status, COUNT(*) AS count
+			FROM missions m
+			JOIN kids k ON k.profile_id = m.profile_id
+			WHERE m.created_at >= $2::date
+			  AND m.created_at < $3::date
+			GROUP BY 1, m.status
+		),
+		day_rows AS (
+			SELECT wt.profile_id, DATE(wt.created_at)::text AS day, wt.type, %[1]s AS source
+			FROM wallet_transactions wt
+			JOIN kids k ON k.profile_id = wt.profile_id
+			WHERE wt.created_at >= $2::date
+			  AND wt.created_at < $3::date
+			GROUP BY 1, 2, 3, 4
+		),
+		later_rows AS (
+			SELECT
+				wt.profile_id,
+				COALESCE(w.slug, '') AS slug,
+				wt.type,
+				%[1]s AS source,
+				SUM(wt.amount) AS total,
+				COUNT(*) AS count
+			FROM wallet_transactions wt
+			JOIN kids k ON k.profile_id = wt.profile_id
+			LEFT JOIN wallets w ON wt.wallet_id = w.id
+			WHERE wt.created_at >= $3::date
+			  AND %[3]t
+			GROUP BY 1, 2, 3, 4
+		)
+		SELECT k.profile_id::text, json_build_object(
+			'wallets', COALESCE((SELECT json_agg(json_build_object('slug', r.slug, 'balance', r.balance::text))
+				FROM wallet_rows r WHERE r.profile_id = k.profile_id), '[]'::json),
+			'transactions', COALESCE((SELECT json_agg(json_build_object('slug', r.slug, 'type', r.type, 'source', r.source, 'amount', r.total::text, 'count', r.count))
+				FROM tx_rows r WHERE r.profile_id = k.profile_id), '[]'::json),
+			'missions', COALESCE((SELECT json_agg(json_build_object('status', r.status, 'count', r.count))
+				FROM mission_rows r WHERE r.profile_id = k.profile_id), '[]'::json),
+			'days', COALESCE((SELECT json_agg(json_build_object('day', r.day, 'type', r.type, 'source', r.source))
+				FROM day_rows r WHERE r.profile_id = k.profile_id), '[]'::json),
+			'later', COALESCE((SELECT json_agg(json_build_object('slug', r.slug, 'type', r.type, 'source', r.source, 'amount', r.total::text, 'count', r.count))
+				FROM later_rows r WHERE r.profile_id = k.profile_id), '[]'::json)
+		)::text
+		FROM kids k
+	`, s.interest.sourceExpr("wt"), s.balances.walletSelect("$3"), s.balances.reconstructs())
+}
+
+// weekPrefetch holds metrics for all the kids of a run, by week and profile ID: computed with the
+// aggregate query or read from the metric store in one query per week
+type weekPrefetch map[string]map[string]*WeekMetrics
+
+// weekKey identifies a week in a weekPrefetch (partial and complete weeks differ in their end date)
+func weekKey(week *weekmanager.WeekRange) string {
+	startDate, endDate := week.FormatDateRange()
+	return startDate + "/" + endDate
+}
+
+// get returns a kid's prefetched metrics for week (nil = query them for this kid)
+func (p weekPrefetch) get(week *weekmanager.WeekRange, profileID string) *WeekMetrics {
+	if p == nil || week == nil {
+		return nil
+	}
+	return p[weekKey(week)][profileID]
+}
+
+// prefetchWeeks computes the current week and the two weeks before it for all the kids at once, or
+// returns nil in per_kid mode and for fixtures (read in memory). Earlier weeks are read from the
+// metric store first, and only the kids it lacks are computed (and stored). When a query fails, the
+// kids it covered are queried one by one as in per_kid mode.
+func (s *SilverLayer) prefetchWeeks(ctx context.Context, profiles []KidProfile, weekData *weekmanager.WeekData) weekPrefetch {
+	if s.metricsQuery != MetricsQueryAggregate || s.fixtures != nil || len(profiles) == 0 {
+		return nil
+	}
+	prefetched := make(weekPrefetch)
+	for _, week := range []*weekmanager.WeekRange{&weekData.CurrentWeek, weekData.PreviousWeek, weekData.TwoWeeksAgo} {
+		if week == nil || ctx.Err() != nil {
+			continue
+		}
+		prefetched[weekKey(week)] = s.prefetchWeek(ctx, profiles, week, week != &weekData.CurrentWeek)
+	}
+	return prefetched
+}
+
+// prefetchWeek returns a week's metrics by profile ID; stored are earlier weeks, read from the metric
+// store before anything is computed. Computed metrics are written back in one statement.
+func (s *SilverLayer) prefetchWeek(ctx context.Context, profiles []KidProfile, week *weekmanager.WeekRange, stored bool) map[string]*WeekMetrics {
+	metrics := make(map[string]*WeekMetrics, len(profiles))
+	missing := profiles
+	if stored && s.metricStore != nil {
+		ids := make([]string, len(profiles))
+		for i, profile := range profiles {
+			ids[i] = profile.ProfileID.String()
+		}
+		found, err := s.metricStore.GetMany(ctx, ids, week)
+		if err != nil {
+			s.logger.Warnf("⚠️  Metric store read failed for %s, recomputing: %v", week.Label, err)
+		}
+		missing = nil
+		for _, profile := range profiles {
+			if m, ok := found[profile.ProfileID.String()]; ok {
+				metrics[profile.ProfileID.String()] = m
+			} else {
+				missing = append(missing, profile)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return metrics
+	}
+
+	computed, err := s.getWeekMetricsAggregate(ctx, missing, week)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warnf("⚠️  Aggregate metrics query failed for %s, querying kids one by one (silver.metrics_query): %v", week.Label, err)
+		}
+		return metrics
+	}
+	if s.metricStore != nil {
+		if err := s.metricStore.PutMany(ctx, week, computed); err != nil {
+			s.logger.Warnf("⚠️  Could not store metrics for %s: %v", week.Label, err)
+		}
+	}
+	for profileID, m := range computed {
+		metrics[profileID] = m
+	}
+	return metrics
+}
+
+// getWeekMetricsAggregate computes the week for the kids with one statement instead of five
+// queries per kid. Spending categories and derived features are still queried per kid.
+func (s *SilverLayer) getWeekMetricsAggregate(ctx context.Context, profiles []KidProfile, week *weekmanager.WeekRange) (map[string]*WeekMetrics, error) {
+	startDate, endDate := week.FormatDateRange()
+	ids := make([]string, len(profiles))
+	for i, profile := range profiles {
+		ids[i] = profile.ProfileID.String()
+	}
+
+	rows, err := s.query(ctx, "week_aggregate", s.aggregateQuery(), pq.Array(ids), startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := make(map[string]*kidSourceRows, len(profiles))
+	for rows.Next() {
+		var profileID, encoded string
+		if err := rows.Scan(&profileID, &encoded); err != nil {
+			return nil, err
+		}
+		source, err := s.decodeAggregateRow(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("kid %s: %w", profileID, err)
+		}
+		sources[profileID] = source
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[string]*WeekMetrics, len(sources))
+	for profileID, source := range sources {
+		if metrics[profileID], err = s.buildWeekMetrics(ctx, profileID, week, source); err != nil {
+			return nil, fmt.Errorf("kid %s: %w", profileID, err)
+		}
+	}
+	return metrics, nil
+}
+
+// decodeAggregateRow turns a kid's JSON column into source rows, parsing amounts like the per-kid scans
+func (s *SilverLayer) decodeAggregateRow(encoded string) (*kidSourceRows, error) {
+	var row aggregateRow
+	if err := json.Unmarshal([]byte(encoded), &row); err != nil {
+		return nil, fmt.Errorf("failed to parse aggregate row: %w", err)
+	}
+	source := &kidSourceRows{}
+	for _, w := range row.Wallets {
+		balance, err := s.amounts.parse(nullableAmount(w.Balance))
+		if err != nil {
+			return nil, err
+		}
+		source.Wallets = append(source.Wallets, walletRow{Slug: w.Slug, Balance: balance})
+	}
+	for _, t := range row.Transactions {
+		amount, err := s.amounts.parse(nullableAmount(t.Amount))
+		if err != nil {
+			return nil, err
+		}
+		source.Transactions = append(source.Transactions, txRow{Slug: t.Slug, Type: t.Type, Source: t.Source, Amount: amount, Count: t.Count})
+	}
+	for _, m := range row.Missions {
+		source.Missions = append(source.Missions, missionRow{Status: m.Status, Count: m.Count})
+	}
+	for _, d := range row.Days {
+		source.Days = append(source.Days, dayRow{Day: d.Day, Type: d.Type, Source: d.Source})
+	}
+	for _, t := range row.Later {
+		amount, err := s.amounts.parse(nullableAmount(t.Amount))
+		if err != nil {
+			return nil, err
+		}
+		source.Later = append(source.Later, txRow{Slug: t.Slug, Type: t.Type, Source: t.Source, Amount: amount, Count: t.Count})
+	}
+	return source, nil
+}
+
+// nullableAmount maps a NULL amount (empty in the JSON) to nil, which parses as zero like a scanned NULL
+func nullableAmount(text string) interface{} {
+	if text == "" {
+		return nil
+	}
+	return text
+}
@@ -0,0 +1,53 @@
+package silver
+
+import (
+	"strings"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// MissionOutcome is the normalized outcome of a mission status
+type MissionOutcome int
+
+const (
+	MissionPending MissionOutcome = iota
+	MissionCompleted
+	MissionFailed
+)
+
+// MissionStatusTaxonomy maps raw missions.status values to outcomes
+type MissionStatusTaxonomy struct {
+	outcomes map[string]MissionOutcome
+}
+
+// NewMissionStatusTaxonomy builds the taxonomy from config, defaulting to the legacy 'complete' status
+func NewMissionStatusTaxonomy(cfg config.MissionStatusConfig) MissionStatusTaxonomy {
+	completed := cfg.Completed
+	if len(completed) == 0 {
+		completed = []string{"complete"}
+	}
+
+	t := MissionStatusTaxonomy{outcomes: make(map[string]MissionOutcome)}
+	for _, status := range cfg.Pending {
+		t.outcomes[normalizeStatus(status)] = MissionPending
+	}
+	for _, status := range cfg.Failed {
+		t.outcomes[normalizeStatus(status)] = MissionFailed
+	}
+	for _, status := range completed {
+		t.outcomes[normalizeStatus(status)] = MissionCompleted
+	}
+	return t
+}
+
+// Classify returns the outcome for a status; unknown statuses count as pending
+func (t MissionStatusTaxonomy) Classify(status string) MissionOutcome {
+	if outcome, ok := t.outcomes[normalizeStatus(status)]; ok {
+		return outcome
+	}
+	return MissionPending
+}
+
+func normalizeStatus(status string) string {
+	return strings.ToLower(strings.TrimSpace(status))
+}
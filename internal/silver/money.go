@@ -0,0 +1,111 @@
+package silver
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// Amount storage formats (silver.amounts.storage)
+const (
+	AmountStorageNumeric = "numeric" // Decimal major units (e.g. 12500.00 đồng)
+	AmountStorageInteger = "integer" // Whole minor units (e.g. 12500 đồng)
+)
+
+// Money is an amount in integer minor units. Sums are exact; floats only appear in the output.
+type Money int64
+
+// AmountFormat converts database amounts to Money according to how the deployment stores them
+type AmountFormat struct {
+	integer bool
+	scale   int64 // Minor units per major unit (10^decimals)
+}
+
+// NewAmountFormat builds the format from config, defaulting to numeric đồng with no minor digits
+func NewAmountFormat(cfg config.AmountConfig) (AmountFormat, error) {
+	if cfg.Decimals < 0 || cfg.Decimals > 6 {
+		return AmountFormat{scale: 1}, fmt.Errorf("silver.amounts.decimals must be between 0 and 6, got %d", cfg.Decimals)
+	}
+	format := AmountFormat{scale: int64(math.Pow10(cfg.Decimals))}
+	switch cfg.Storage {
+	case "", AmountStorageNumeric:
+	case AmountStorageInteger:
+		format.integer = true
+	default:
+		return format, fmt.Errorf("silver.amounts.storage must be %q or %q, got %q", AmountStorageNumeric, AmountStorageInteger, cfg.Storage)
+	}
+	return format, nil
+}
+
+// Scan returns a sql.Scanner that stores a column value into dst
+func (f AmountFormat) Scan(dst *Money) sql.Scanner {
+	return &amountScanner{format: f, dst: dst}
+}
+
+// Float returns m in major units for the JSON output
+func (f AmountFormat) Float(m Money) float64 {
+	if f.scale <= 1 {
+		return float64(m)
+	}
+	return float64(m) / float64(f.scale)
+}
+
+// parse converts a database value (integer, float or numeric text) to minor units, rounding half away from zero
+func (f AmountFormat) parse(src interface{}) (Money, error) {
+	value := new(big.Rat)
+	switch v := src.(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		value.SetInt64(v)
+	case float64:
+		value.SetString(strconv.FormatFloat(v, 'f', -1, 64))
+	case []byte:
+		if _, ok := value.SetString(string(v)); !ok {
+			return 0, fmt.Errorf("invalid amount %q", v)
+		}
+	case string:
+		if _, ok := value.SetString(v); !ok {
+			return 0, fmt.Errorf("invalid amount %q", v)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported amount type %T", src)
+	}
+
+	if !f.integer {
+		value.Mul(value, new(big.Rat).SetInt64(f.scale))
+	}
+
+	num, den := value.Num(), value.Denom()
+	rounded := new(big.Int).Mul(num, big.NewInt(2))
+	if num.Sign() < 0 {
+		rounded.Sub(rounded, den)
+	} else {
+		rounded.Add(rounded, den)
+	}
+	rounded.Quo(rounded, new(big.Int).Mul(den, big.NewInt(2)))
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("amount %s overflows int64 minor units", value.FloatString(2))
+	}
+	return Money(rounded.Int64()), nil
+}
+
+// amountScanner scans a column into Money
+type amountScanner struct {
+	format AmountFormat
+	dst    *Money
+}
+
+// Scan implements sql.Scanner
+func (s *amountScanner) Scan(src interface{}) error {
+	m, err := s.format.parse(src)
+	if err != nil {
+		return err
+	}
+	*s.dst = m
+	return nil
+}
@@ -0,0 +1,87 @@
+package silver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/weekmanager"
+)
+
+// markupTags matches HTML-like tags, which notes must not carry into emails or push payloads
+var markupTags = regexp.MustCompile(`<[^<>]*>`)
+
+// extraBlankLines collapses runs of blank lines to one
+var extraBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// SetOperatorNotes reads human-written per-kid, per-week notes from cfg.Table ("" = off)
+func (s *SilverLayer) SetOperatorNotes(cfg config.OperatorNotesConfig) {
+	if cfg.Table != "" && !columnNamePattern.MatchString(cfg.Table) {
+		s.logger.Warnf("⚠️  Ignoring invalid operator notes table %q", cfg.Table)
+		return
+	}
+	if cfg.MaxChars <= 0 {
+		cfg.MaxChars = 500
+	}
+	s.notes = cfg
+}
+
+// getOperatorNote returns the latest (approved, when required) note for a kid's week ("" = none)
+func (s *SilverLayer) getOperatorNote(ctx context.Context, profileID string, week *weekmanager.WeekRange) (string, error) {
+	approved := ""
+	if s.notes.RequireApproval {
+		approved = "AND COALESCE(TRIM(approved_by), '') <> ''"
+	}
+	query := fmt.Sprintf(`
+		SELECT note
+		FROM %s
+		WHERE profile_id = $1::uuid AND week_start = $2::date %s
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, s.notes.Table, approved)
+
+	var note string
+	err := s.db.QueryRowContext(ctx, query, profileID, week.StartDate.Format("2006-01-02")).Scan(&note)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query operator notes: %w", err)
+	}
+	return SanitizeOperatorNote(note, s.notes.MaxChars)
+}
+
+// SanitizeOperatorNote cleans a note for verbatim delivery: markup tags, control and invisible
+// formatting characters are removed and whitespace is tidied. Notes longer than maxChars runes are
+// rejected rather than cut, since the message must reach the family exactly as written.
+func SanitizeOperatorNote(note string, maxChars int) (string, error) {
+	note = strings.ReplaceAll(note, "\r\n", "\n")
+	note = markupTags.ReplaceAllString(note, "")
+	note = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n':
+			return r
+		case r == '\t' || r == '\r':
+			return ' '
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r): // Includes zero-width and bidi overrides
+			return -1
+		}
+		return r
+	}, note)
+
+	lines := strings.Split(note, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	note = strings.TrimSpace(extraBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+
+	if length := utf8.RuneCountInString(note); length > maxChars {
+		return "", fmt.Errorf("operator note is %d characters, limit is %d", length, maxChars)
+	}
+	return note, nil
+}
@@ -0,0 +1,17 @@
+package silver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// getParentID returns the parent profile linked to a kid ("" when unset)
+func (s *SilverLayer) getParentID(ctx context.Context, profileID string) (string, error) {
+	query := fmt.Sprintf(`SELECT COALESCE(%s::text, '') FROM profiles WHERE id = $1::uuid`, s.parentColumn)
+	var parentID string
+	if err := s.db.QueryRowContext(ctx, query, profileID).Scan(&parentID); err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to query parent: %w", err)
+	}
+	return parentID, nil
+}
@@ -0,0 +1,56 @@
+package silver
+
+import (
+	"context"
+	"sync"
+
+	"ai-production-pipeline/internal/weekmanager"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// analyzeAll analyzes the kids on up to silver.max_concurrent workers. done is called for each kid
+// as it finishes, one call at a time, with its index in profiles; a done error stops handing out kids
+// and is returned once the running ones finish. A cancelled ctx stops the week the same way.
+func (s *SilverLayer) analyzeAll(ctx context.Context, profiles []KidProfile, weekData *weekmanager.WeekData, prefetched weekPrefetch,
+	done func(index int, kid *EnhancedKidData, err error) error) error {
+	g, stop := errgroup.WithContext(ctx)
+	g.SetLimit(s.maxConcurrent)
+
+	var (
+		mu     sync.Mutex
+		failed bool // A done call returned an error; later kids are not reported
+	)
+	for index := range profiles {
+		if stop.Err() != nil {
+			break
+		}
+		index := index
+		g.Go(func() error {
+			// Started after a done error while this kid waited for a worker
+			if stop.Err() != nil {
+				return nil
+			}
+			profile := profiles[index]
+			s.logger.Debugf("   Analyzing: %s (ID: %s)", profile.Nickname, profile.ProfileID)
+			// Running kids finish with ctx, so a done error does not cut them short
+			kid, err := s.analyzeKidEnhanced(ctx, profile, weekData, prefetched)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if failed {
+				return nil
+			}
+			if err := done(index, kid, err); err != nil {
+				failed = true
+				return err
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,55 @@
+package silver
+
+import (
+	"time"
+
+	"ai-production-pipeline/internal/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// progressLog logs Silver's progress through a week every N kids or every T seconds, whichever
+// comes first, instead of a line per kid (those are debug level)
+type progressLog struct {
+	logger    *logrus.Logger
+	total     int
+	everyKids int
+	every     time.Duration
+	started   time.Time
+	lastAt    time.Time
+	lastCount int
+}
+
+// newProgressLog applies the silver.progress_log defaults: every 500 kids or 30 seconds
+func newProgressLog(logger *logrus.Logger, cfg config.ProgressLogConfig, total int) *progressLog {
+	p := &progressLog{
+		logger:    logger,
+		total:     total,
+		everyKids: cfg.EveryKids,
+		every:     time.Duration(cfg.EverySeconds) * time.Second,
+		started:   time.Now(),
+	}
+	if p.everyKids <= 0 {
+		p.everyKids = 500
+	}
+	if p.every <= 0 {
+		p.every = 30 * time.Second
+	}
+	p.lastAt = p.started
+	return p
+}
+
+// record notes that done kids have finished, failed of them with an error, and logs when due.
+// The last kid is left to the week summary.
+func (p *progressLog) record(done, active, failed int) {
+	if done >= p.total {
+		return
+	}
+	now := time.Now()
+	if done-p.lastCount < p.everyKids && now.Sub(p.lastAt) < p.every {
+		return
+	}
+	p.lastAt, p.lastCount = now, done
+	p.logger.Infof("   ⏳ Silver progress: %d/%d kids (%.0f%%), %d active, %d failed, %s elapsed",
+		done, p.total, float64(done)/float64(p.total)*100, active, failed, now.Sub(p.started).Round(time.Second))
+}
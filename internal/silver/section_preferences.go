@@ -0,0 +1,46 @@
+package silver
+
+import (
+	"context"
+	"fmt"
+)
+
+// SectionRequest is an optional report section a parent switched on for their kid
+type SectionRequest struct {
+	Key    string `json:"key"`
+	Detail string `json:"detail,omitempty"` // Free text from the parent, e.g. "a new bike"
+}
+
+// SetSectionPreferences reads parent-requested optional report sections from table ("" = off)
+func (s *SilverLayer) SetSectionPreferences(table string) {
+	if table != "" && !columnNamePattern.MatchString(table) {
+		s.logger.Warnf("⚠️  Ignoring invalid section preferences table %q", table)
+		return
+	}
+	s.preferencesTable = table
+}
+
+// getSectionRequests returns the optional sections enabled for a kid
+func (s *SilverLayer) getSectionRequests(ctx context.Context, profileID string) ([]SectionRequest, error) {
+	query := fmt.Sprintf(`
+		SELECT section_key, COALESCE(TRIM(detail), '')
+		FROM %s
+		WHERE profile_id = $1::uuid AND enabled
+		ORDER BY section_key
+	`, s.preferencesTable)
+	rows, err := s.db.QueryContext(ctx, query, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query section preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []SectionRequest
+	for rows.Next() {
+		var request SectionRequest
+		if err := rows.Scan(&request.Key, &request.Detail); err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
@@ -0,0 +1,43 @@
+package silver
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// KidSelection limits which kids are processed in a run (used for cheap smoke tests)
+type KidSelection struct {
+	Limit         int     // Process only the first N kids (0 = no limit)
+	SamplePercent float64 // Process a random X% of kids (0 = all kids)
+	Seed          int64   // Seed for reproducible sampling
+}
+
+// IsActive reports whether any selection rule is configured
+func (ks KidSelection) IsActive() bool {
+	return ks.Limit > 0 || ks.SamplePercent > 0
+}
+
+// Apply filters profiles by sample first, then by limit.
+// Sampling hashes the seed with each profile ID, so the same kids are picked every week.
+func (ks KidSelection) Apply(profiles []KidProfile) []KidProfile {
+	selected := profiles
+
+	if ks.SamplePercent > 0 && ks.SamplePercent < 100 {
+		threshold := uint64(ks.SamplePercent * 100)
+		sampled := make([]KidProfile, 0, len(profiles))
+		for _, p := range profiles {
+			h := fnv.New64a()
+			fmt.Fprintf(h, "%d:%s", ks.Seed, p.ProfileID)
+			if h.Sum64()%10000 < threshold {
+				sampled = append(sampled, p)
+			}
+		}
+		selected = sampled
+	}
+
+	if ks.Limit > 0 && len(selected) > ks.Limit {
+		selected = selected[:ks.Limit]
+	}
+
+	return selected
+}
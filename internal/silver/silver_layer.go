@@ -1,13 +1,23 @@
 package silver
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"math"
-	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/fixtures"
+	"ai-production-pipeline/internal/progress"
+	"ai-production-pipeline/internal/telemetry"
+	"ai-production-pipeline/internal/uuid"
 	"ai-production-pipeline/internal/weekmanager"
 
 	_ "github.com/lib/pq"
@@ -16,22 +26,52 @@ import (
 
 // SilverLayer handles enhanced transformation with historical comparison
 type SilverLayer struct {
-	db     *sql.DB
-	logger *logrus.Logger
+	db              *sql.DB
+	logger          *logrus.Logger
+	selection       KidSelection
+	missionStatuses MissionStatusTaxonomy
+	amounts         AmountFormat
+	interest        InterestRule
+	compression     string // Output compression format ("" = none)
+	metadata        *buildinfo.Metadata
+	languageColumn  string // profiles column holding the app language ("" = not read)
+	parentColumn    string // profiles column linking a kid to its parent profile ("" = not read)
+	progress        *progress.Tracker
+	categoryTable   string // Transaction categories table for the spending breakdown ("" = off)
+	metricStore     *MetricStore
+	lookbackWeeks   int // Stored weeks added as history per kid
+
+	features         *FeatureRegistry           // Derived metrics from silver.features (nil = none)
+	preferencesTable string                     // Parent section preferences table ("" = off)
+	notes            config.OperatorNotesConfig // Customer-success notes table ("" = off)
+	deleted          DeletedProfilePolicy       // Soft-deleted kids: include, exclude or flag
+	metricsQuery     string                     // How the week's metrics are queried (silver.metrics_query)
+	maxConcurrent    int                        // Kids analyzed at once (silver.max_concurrent)
+	progressLog      config.ProgressLogConfig   // How often progress through a week is logged
+	balances         BalancePolicy              // How balances at the end of a week are read (silver.balances)
+	fixtures         *fixtures.Set              // Source rows read from JSON files instead of db (run --fixtures)
 }
 
 // EnhancedKidData represents complete kid analysis with historical context
 type EnhancedKidData struct {
-	ProfileID   string `json:"profile_id"`
-	Nickname    string `json:"nickname"`
-	Age         int    `json:"age"`
-	DateOfBirth string `json:"date_of_birth"`
+	ProfileID   string   `json:"profile_id"`
+	Nickname    string   `json:"nickname"`
+	Age         *int     `json:"age"` // null when unknown (see data_quality)
+	DateOfBirth string   `json:"date_of_birth"`
+	Language    string   `json:"language,omitempty"`     // App language preference from the profile
+	ParentID    string   `json:"parent_id,omitempty"`    // Parent profile (silver.parent_column), groups siblings
+	DataQuality []string `json:"data_quality,omitempty"` // Profile data issues (unknown_age, missing_name, ...)
+
+	RequestedSections []SectionRequest `json:"requested_sections,omitempty"` // Optional report sections enabled by the parent
+	OperatorNote      string           `json:"operator_note,omitempty"`      // Customer-success note, delivered verbatim (never sent to the AI)
 
 	// Multi-week data
 	CurrentWeek  WeekMetrics  `json:"current_week"`
 	PreviousWeek *WeekMetrics `json:"previous_week,omitempty"`
 	TwoWeeksAgo  *WeekMetrics `json:"two_weeks_ago,omitempty"`
 
+	History []HistoryPoint `json:"history,omitempty"` // Earlier weeks from the metric store, oldest first
+
 	// Analysis (only if historical data available)
 	Trends     *TrendData      `json:"trends,omitempty"`
 	Statistics *StatisticsData `json:"statistics,omitempty"`
@@ -48,6 +88,10 @@ type WeekMetrics struct {
 	StartDate string `json:"start_date"`
 	EndDate   string `json:"end_date"`
 
+	// Partial period (week-to-date)
+	IsPartial       bool    `json:"is_partial,omitempty"`
+	ElapsedFraction float64 `json:"elapsed_fraction,omitempty"` // Share of the week covered, used to prorate trends
+
 	// Wallet balances
 	JoyWallet      float64 `json:"joy_wallet"`
 	SpendingWallet float64 `json:"spending_wallet"`
@@ -58,19 +102,30 @@ type WeekMetrics struct {
 	// Transaction summary
 	MoneyReceived      float64 `json:"money_received"`
 	MoneyReceivedCount int     `json:"money_received_count"`
+	InterestEarned     float64 `json:"interest_earned,omitempty"` // Interest paid on the study wallet (not in money_received)
+	InterestCount      int     `json:"interest_count,omitempty"`
+	StudyGrowthRate    float64 `json:"study_growth_rate,omitempty"` // Interest as % of the study wallet at the start of the week
 	TotalSpent         float64 `json:"total_spent"`
 	JoySpent           float64 `json:"joy_spent"`
 	SpendingSpent      float64 `json:"spending_spent"`
 	CharitySpent       float64 `json:"charity_spent"`
 	StudySpent         float64 `json:"study_spent"`
 	SpentCount         int     `json:"spent_count"`
+	OrphanTransactions int     `json:"orphan_transactions,omitempty"` // Transactions whose wallet was deleted (counted in the totals, not per wallet)
+
+	SpendingByCategory map[string]float64 `json:"spending_by_category,omitempty"` // Spent per transaction category
+
+	Features map[string]float64 `json:"features,omitempty"` // Derived metrics from silver.features, by output field
 
 	// Mission data
 	MissionsTotal     int     `json:"missions_total"`
 	MissionsCompleted int     `json:"missions_completed"`
 	MissionsPending   int     `json:"missions_pending"`
+	MissionsFailed    int     `json:"missions_failed"`
 	CompletionRate    float64 `json:"completion_rate"`
 
+	MissionStatusCounts map[string]int `json:"mission_status_counts,omitempty"` // Raw count per DB status
+
 	// Activity
 	TransactionCount   int     `json:"transaction_count"`
 	AvgTransactionSize float64 `json:"avg_transaction_size"`
@@ -119,25 +174,183 @@ type StatisticsData struct {
 
 // EnhancedOutput represents the final JSON output
 type EnhancedOutput struct {
-	GeneratedAt string            `json:"generated_at"`
-	Week        string            `json:"week"`
-	TotalKids   int               `json:"total_kids"`
-	Kids        []EnhancedKidData `json:"kids"`
+	GeneratedAt string              `json:"generated_at"`
+	Week        string              `json:"week"`
+	IsPartial   bool                `json:"is_partial,omitempty"`
+	AsOf        string              `json:"as_of,omitempty"`
+	TotalKids   int                 `json:"total_kids"`
+	Kids        []EnhancedKidData   `json:"kids"`
+	Metadata    *buildinfo.Metadata `json:"metadata,omitempty"` // What produced this file
+
+	InvalidProfiles []InvalidProfile `json:"invalid_profiles,omitempty"` // Skipped: profile ID is not a valid UUID
+}
+
+// InvalidProfile is a kid profile skipped because its ID is not a valid UUID
+type InvalidProfile struct {
+	ProfileID string `json:"profile_id"`
+	Error     string `json:"error"`
 }
 
-func NewSilverLayer(db *sql.DB, logger *logrus.Logger) *SilverLayer {
+// partialSuffix marks week-to-date outputs so they are never mistaken for final versions
+const partialSuffix = ".partial"
+
+// PartialOutputPath returns the output path used for week-to-date results
+func PartialOutputPath(path string) string {
+	ext := filepath.Ext(path)
+	if strings.HasSuffix(strings.TrimSuffix(path, ext), partialSuffix) {
+		return path
+	}
+	return strings.TrimSuffix(path, ext) + partialSuffix + ext
+}
+
+// IsPartialOutputPath reports whether path is a week-to-date output path
+func IsPartialOutputPath(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, filepath.Ext(path)), partialSuffix)
+}
+
+func NewSilverLayer(db *sql.DB, logger *logrus.Logger, cfg config.SilverConfig) *SilverLayer {
+	languageColumn := cfg.LanguageColumn
+	if languageColumn != "" && !columnNamePattern.MatchString(languageColumn) {
+		logger.Warnf("⚠️  Ignoring invalid silver.language_column %q", languageColumn)
+		languageColumn = ""
+	}
+	parentColumn := cfg.ParentColumn
+	if parentColumn != "" && !columnNamePattern.MatchString(parentColumn) {
+		logger.Warnf("⚠️  Ignoring invalid silver.parent_column %q", parentColumn)
+		parentColumn = ""
+	}
+
+	amounts, err := NewAmountFormat(cfg.Amounts)
+	if err != nil {
+		logger.Warnf("⚠️  %v; using numeric đồng", err)
+	}
+	features, err := NewFeatureRegistry(cfg.Features)
+	if err != nil {
+		logger.Warnf("⚠️  %v; derived features disabled", err)
+	}
+	interest, err := NewInterestRule(cfg.Interest)
+	if err != nil {
+		logger.Warnf("⚠️  %v; interest is detected by transaction type only", err)
+	}
+	deleted, err := NewDeletedProfilePolicy(cfg.DeletedProfiles)
+	if err != nil {
+		logger.Warnf("⚠️  %v; deleted profiles are analyzed as usual", err)
+	}
+	metricsQuery := cfg.MetricsQuery
+	if err := ValidateMetricsQuery(cfg); err != nil || metricsQuery == "" {
+		if err != nil {
+			logger.Warnf("⚠️  %v; using %s", err, MetricsQueryAggregate)
+		}
+		metricsQuery = MetricsQueryAggregate
+	}
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	balances, err := NewBalancePolicy(cfg.Balances)
+	if err != nil {
+		logger.Warnf("⚠️  %v; reconstructing balances from transactions", err)
+	}
+
 	return &SilverLayer{
-		db:     db,
-		logger: logger,
+		db:              db,
+		logger:          logger,
+		missionStatuses: NewMissionStatusTaxonomy(cfg.MissionStatuses),
+		amounts:         amounts,
+		interest:        interest,
+		features:        features,
+		languageColumn:  languageColumn,
+		parentColumn:    parentColumn,
+		deleted:         deleted,
+		metricsQuery:    metricsQuery,
+		balances:        balances,
+		maxConcurrent:   maxConcurrent,
+		progressLog:     cfg.ProgressLog,
+	}
+}
+
+// query runs a source query and records its latency under name
+func (s *SilverLayer) query(ctx context.Context, name, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	telemetry.DBQueryDuration.Since(start, name)
+	return rows, err
+}
+
+// columnNamePattern restricts configured column names to plain identifiers (they are put into SQL)
+var columnNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// languageExpr returns the SQL expression selecting the profile language (alias is the table alias, may be "")
+func (s *SilverLayer) languageExpr(alias string) string {
+	if s.languageColumn == "" {
+		return "''"
 	}
+	if alias != "" {
+		return fmt.Sprintf("COALESCE(%s.%s::text, '')", alias, s.languageColumn)
+	}
+	return fmt.Sprintf("COALESCE(%s::text, '')", s.languageColumn)
+}
+
+// SetCompression sets the output compression format (gzip, zstd or "" for none)
+func (s *SilverLayer) SetCompression(format string) {
+	s.compression = format
+}
+
+// SetMetadata stamps build and config metadata into Silver outputs
+func (s *SilverLayer) SetMetadata(metadata buildinfo.Metadata) {
+	s.metadata = &metadata
+}
+
+// SetProgressTracker reports the number of kids selected for each week
+func (s *SilverLayer) SetProgressTracker(tracker *progress.Tracker) {
+	s.progress = tracker
+}
+
+// SetCategoryTable enables the per-category spending breakdown from the categorization stage
+func (s *SilverLayer) SetCategoryTable(table string) {
+	if table != "" && !columnNamePattern.MatchString(table) {
+		s.logger.Warnf("⚠️  Ignoring invalid categories table %q", table)
+		return
+	}
+	s.categoryTable = table
+}
+
+// SetMetricStore reads earlier weeks from store instead of recomputing them and records each computed week.
+// lookbackWeeks stored weeks are added to every kid's history.
+func (s *SilverLayer) SetMetricStore(store *MetricStore, lookbackWeeks int) {
+	s.metricStore = store
+	s.lookbackWeeks = lookbackWeeks
+}
+
+// SetKidSelection restricts processing to a subset of kids (limit and/or sample)
+func (s *SilverLayer) SetKidSelection(selection KidSelection) {
+	s.selection = selection
 }
 
 // Transform performs enhanced transformation for a specific week
-func (s *SilverLayer) Transform(weekData *weekmanager.WeekData, outputPath string) error {
+func (s *SilverLayer) Transform(ctx context.Context, weekData *weekmanager.WeekData, outputPath string) error {
+	return s.TransformStream(ctx, weekData, outputPath, nil)
+}
+
+// TransformStream is Transform that also hands each kid to emit as soon as it is analyzed,
+// so Gold can start on it while the rest of the week is still being queried.
+// The complete output file is still written at the end. A cancelled ctx aborts the running
+// query and the week; no output is written.
+func (s *SilverLayer) TransformStream(ctx context.Context, weekData *weekmanager.WeekData, outputPath string, emit func(EnhancedKidData)) error {
 	s.logger.Info("=" + repeatString("=", 80))
 	s.logger.Infof("🔄 Silver Layer V3: Processing %s", weekData.CurrentWeek.Label)
 	s.logger.Info("=" + repeatString("=", 80))
 
+	// Partial weeks must never overwrite final outputs
+	if weekData.CurrentWeek.IsPartial {
+		if !IsPartialOutputPath(outputPath) {
+			return fmt.Errorf("refusing to store partial week %s as final output %s (use %s)",
+				weekData.CurrentWeek.Label, outputPath, PartialOutputPath(outputPath))
+		}
+		s.logger.Warnf("⏳ Partial week: metrics computed up to %s (%.0f%% of week elapsed)",
+			weekData.CurrentWeek.AsOf.Format("2006-01-02 15:04"), weekData.CurrentWeek.ElapsedFraction()*100)
+	}
+
 	if weekData.HasHistoricalData() {
 		s.logger.Infof("📊 Historical data available: %d previous weeks",
 			func() int {
@@ -152,41 +365,104 @@ func (s *SilverLayer) Transform(weekData *weekmanager.WeekData, outputPath strin
 	}
 
 	// Get ALL kid profiles (not filtered by activity)
-	profiles, err := s.getAllKidProfiles()
-	if err != nil {
+	var profiles []KidProfile
+	var invalid []InvalidProfile
+	var err error
+	if s.fixtures != nil {
+		profiles, invalid = s.fixtureProfiles()
+	} else if profiles, invalid, err = s.getAllKidProfiles(ctx); err != nil {
 		return fmt.Errorf("failed to get kid profiles: %w", err)
 	}
+	s.reportInvalidProfiles(invalid)
+	week := weekData.CurrentWeek.Label
+	for _, profile := range invalid {
+		s.progress.RecordKid(week, profile.ProfileID, "", progress.DispositionInvalidID, profile.Error)
+	}
+	profiles = s.excludeDeletedProfiles(week, profiles)
+
+	if s.selection.IsActive() {
+		totalProfiles := len(profiles)
+		selected := s.selection.Apply(profiles)
+		kept := make(map[uuid.UUID]bool, len(selected))
+		for _, profile := range selected {
+			kept[profile.ProfileID] = true
+		}
+		for _, profile := range profiles {
+			if !kept[profile.ProfileID] {
+				s.progress.RecordKid(week, profile.ProfileID.String(), profile.Nickname, progress.DispositionNotSelected, "")
+			}
+		}
+		profiles = selected
+		s.logger.Warnf("⚠️  Kid selection active (limit=%d, sample=%.1f%%, seed=%d): %d/%d kids",
+			s.selection.Limit, s.selection.SamplePercent, s.selection.Seed, len(profiles), totalProfiles)
+	}
 
 	s.logger.Infof("👥 Processing %d kids (including inactive)", len(profiles))
+	s.progress.SetWeekKids(len(profiles))
 
-	// Analyze each kid
-	var kidsData []EnhancedKidData
+	// Analyze the kids concurrently; the output keeps the profile order
+	prefetched := s.prefetchWeeks(ctx, profiles, weekData)
+	results := make([]*EnhancedKidData, len(profiles))
+	analyzed := 0
 	activeCount := 0
 	inactiveCount := 0
-
-	for _, profile := range profiles {
-		s.logger.Infof("   Analyzing: %s (ID: %s)", profile.Nickname, profile.ProfileID)
-
-		kidData, err := s.analyzeKidEnhanced(profile, weekData)
+	failedCount := 0
+	incompleteCount := 0
+	progressLog := newProgressLog(s.logger, s.progressLog, len(profiles))
+
+	err = s.analyzeAll(ctx, profiles, weekData, prefetched, func(index int, kidData *EnhancedKidData, err error) error {
+		profile := profiles[index]
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if err != nil {
 			s.logger.Errorf("   ❌ Error analyzing %s: %v", profile.Nickname, err)
-			continue
+			s.progress.RecordKid(week, profile.ProfileID.String(), profile.Nickname, progress.DispositionSilverFailed, err.Error())
+			telemetry.LayerKids.Inc(telemetry.LayerSilver, telemetry.ResultFailure)
+			failedCount++
+			progressLog.record(analyzed+failedCount, activeCount, failedCount)
+			return nil
+		}
+		telemetry.LayerKids.Inc(telemetry.LayerSilver, telemetry.ResultSuccess)
+
+		if len(kidData.DataQuality) > 0 {
+			incompleteCount++
+			s.logger.Debugf("   ⚠️  Incomplete profile data for %s: %v", profile.Nickname, kidData.DataQuality)
 		}
 
 		// Include ALL kids regardless of activity
-		kidsData = append(kidsData, *kidData)
+		results[index] = kidData
+		analyzed++
+		if emit != nil {
+			emit(*kidData)
+		}
 
 		if kidData.CurrentWeek.TransactionCount > 0 || kidData.CurrentWeek.MissionsCompleted > 0 {
 			activeCount++
-			s.logger.Infof("   ✅ Active: Activity Score %.2f, Trends: %v",
-				kidData.ActivityScore, kidData.Trends != nil)
+			s.logger.Debugf("   ✅ %s active: Activity Score %.2f, Trends: %v",
+				profile.Nickname, kidData.ActivityScore, kidData.Trends != nil)
 		} else {
 			inactiveCount++
-			s.logger.Infof("   ⚪ Inactive: No activity this week (Trends: %v)",
-				kidData.Trends != nil)
+			s.logger.Debugf("   ⚪ %s inactive: No activity this week (Trends: %v)",
+				profile.Nickname, kidData.Trends != nil)
 		}
+		progressLog.record(analyzed+failedCount, activeCount, failedCount)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("interrupted after %d/%d kids: %w", analyzed, len(profiles), err)
 	}
 
+	var kidsData []EnhancedKidData
+	for _, kidData := range results {
+		if kidData != nil {
+			kidsData = append(kidsData, *kidData)
+		}
+	}
+
+	if incompleteCount > 0 {
+		s.logger.Warnf("⚠️  %d kids have incomplete profile data (see data_quality)", incompleteCount)
+	}
 	s.logger.Infof("📊 Summary: %d active, %d inactive, %d total",
 		activeCount, inactiveCount, len(kidsData)) // Create output
 	output := EnhancedOutput{
@@ -194,48 +470,124 @@ func (s *SilverLayer) Transform(weekData *weekmanager.WeekData, outputPath strin
 		Week:        weekData.CurrentWeek.Label,
 		TotalKids:   len(kidsData),
 		Kids:        kidsData,
+		Metadata:    s.metadata,
+
+		InvalidProfiles: invalid,
+	}
+	if weekData.CurrentWeek.IsPartial {
+		output.IsPartial = true
+		output.AsOf = weekData.CurrentWeek.AsOf.Format(time.RFC3339)
 	}
 
 	// Save to JSON
-	if err := s.saveJSON(output, outputPath); err != nil {
+	writtenPath, err := s.saveJSON(output, outputPath)
+	if err != nil {
 		return fmt.Errorf("failed to save JSON: %w", err)
 	}
 
-	s.logger.Infof("✅ Silver Layer V3 Complete: %s", outputPath)
+	s.logger.Infof("✅ Silver Layer V3 Complete: %s", writtenPath)
 	return nil
 }
 
-// analyzeKidEnhanced performs complete analysis with historical comparison
-func (s *SilverLayer) analyzeKidEnhanced(profile KidProfile, weekData *weekmanager.WeekData) (*EnhancedKidData, error) {
+// AnalyzeProfile analyzes a single kid for the given week without writing any output file
+func (s *SilverLayer) AnalyzeProfile(ctx context.Context, profileID string, weekData *weekmanager.WeekData) (*EnhancedKidData, error) {
+	id, err := uuid.Parse(profileID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile ID: %w", err)
+	}
+	var profile *KidProfile
+	if s.fixtures != nil {
+		profile, err = s.fixtureKidProfile(id)
+	} else {
+		profile, err = s.getKidProfile(ctx, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kid profile %s: %w", profileID, err)
+	}
+	if s.deleted.excludes(*profile) {
+		return nil, fmt.Errorf("kid profile %s is deleted (silver.deleted_profiles.mode: exclude)", profileID)
+	}
+
+	return s.analyzeKidEnhanced(ctx, *profile, weekData, nil)
+}
+
+// analyzeKidEnhanced performs complete analysis with historical comparison. Weeks in prefetched were
+// computed (and stored) for all kids at once; the others are queried for this kid.
+func (s *SilverLayer) analyzeKidEnhanced(ctx context.Context, profile KidProfile, weekData *weekmanager.WeekData, prefetched weekPrefetch) (*EnhancedKidData, error) {
+	profileID := profile.ProfileID.String()
 	data := &EnhancedKidData{
-		ProfileID:   profile.ProfileID,
+		ProfileID:   profileID,
 		Nickname:    profile.Nickname,
 		Age:         profile.Age,
 		DateOfBirth: profile.DateOfBirth,
+		Language:    profile.Language,
+		DataQuality: append([]string(nil), profile.DataQuality...),
+	}
+
+	if s.parentColumn != "" && s.fixtures != nil {
+		data.ParentID = s.fixtureParentID(profileID)
+	} else if s.parentColumn != "" {
+		parentID, err := s.getParentID(ctx, profileID)
+		if err != nil {
+			s.logger.Warnf("      ⚠️  Could not read parent for %s: %v", profile.Nickname, err)
+		}
+		data.ParentID = parentID
+	}
+
+	if s.preferencesTable != "" {
+		requests, err := s.getSectionRequests(ctx, profileID)
+		if err != nil {
+			s.logger.Warnf("      ⚠️  Could not read section preferences for %s: %v", profile.Nickname, err)
+		}
+		data.RequestedSections = requests
+	}
+
+	if s.notes.Table != "" {
+		note, err := s.getOperatorNote(ctx, profileID, &weekData.CurrentWeek)
+		if err != nil {
+			s.logger.Warnf("      ⚠️  Operator note for %s not attached: %v", profile.Nickname, err)
+		}
+		data.OperatorNote = note
 	}
 
 	// Get current week metrics
-	currentMetrics, err := s.getWeekMetrics(profile.ProfileID, &weekData.CurrentWeek)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current week metrics: %w", err)
+	currentMetrics := prefetched.get(&weekData.CurrentWeek, profileID)
+	if currentMetrics == nil {
+		var err error
+		if currentMetrics, err = s.getWeekMetrics(ctx, profileID, &weekData.CurrentWeek); err != nil {
+			return nil, fmt.Errorf("failed to get current week metrics: %w", err)
+		}
+		s.storeMetrics(ctx, profileID, &weekData.CurrentWeek, currentMetrics)
 	}
 	data.CurrentWeek = *currentMetrics
+	if currentMetrics.OrphanTransactions > 0 {
+		s.logger.Warnf("      ⚠️  %s has %d transactions on deleted wallets", profile.Nickname, currentMetrics.OrphanTransactions)
+		data.DataQuality = append(data.DataQuality, DataQualityMissingWallets)
+	}
 
 	// Get historical metrics if available
 	if weekData.HasHistoricalData() {
-		prevMetrics, err := s.getWeekMetrics(profile.ProfileID, weekData.PreviousWeek)
+		prevMetrics, err := s.getHistoricalMetrics(ctx, profileID, weekData.PreviousWeek, prefetched)
 		if err == nil {
 			data.PreviousWeek = prevMetrics
 		}
 
 		if weekData.HasTwoWeeksHistory() {
-			twoWeeksMetrics, err := s.getWeekMetrics(profile.ProfileID, weekData.TwoWeeksAgo)
+			twoWeeksMetrics, err := s.getHistoricalMetrics(ctx, profileID, weekData.TwoWeeksAgo, prefetched)
 			if err == nil {
 				data.TwoWeeksAgo = twoWeeksMetrics
 			}
 		}
 	}
 
+	if s.metricStore != nil && s.lookbackWeeks > 0 {
+		history, err := s.metricStore.History(ctx, profileID, &weekData.CurrentWeek, s.lookbackWeeks)
+		if err != nil {
+			s.logger.Warnf("      ⚠️  Could not read metric history for %s: %v", profile.Nickname, err)
+		}
+		data.History = history
+	}
+
 	// Calculate activity score
 	data.ActivityScore = s.calculateActivityScore(currentMetrics)
 
@@ -255,147 +607,335 @@ func (s *SilverLayer) analyzeKidEnhanced(profile KidProfile, weekData *weekmanag
 	return data, nil
 }
 
-// getWeekMetrics gets all metrics for a kid in a specific week
-func (s *SilverLayer) getWeekMetrics(profileID string, week *weekmanager.WeekRange) (*WeekMetrics, error) {
-	startDate, endDate := week.FormatDateRange()
+// getHistoricalMetrics reads an earlier week from the prefetched weeks or the metric store, computing
+// and storing it on a miss
+func (s *SilverLayer) getHistoricalMetrics(ctx context.Context, profileID string, week *weekmanager.WeekRange, prefetched weekPrefetch) (*WeekMetrics, error) {
+	if metrics := prefetched.get(week, profileID); metrics != nil {
+		return metrics, nil
+	}
+	if s.metricStore != nil {
+		metrics, ok, err := s.metricStore.Get(ctx, profileID, week)
+		if err != nil {
+			s.logger.Warnf("      ⚠️  Metric store read failed, recomputing %s: %v", week.Label, err)
+		} else if ok {
+			return metrics, nil
+		}
+	}
 
-	metrics := &WeekMetrics{
-		WeekLabel: week.Label,
-		StartDate: startDate,
-		EndDate:   endDate,
+	metrics, err := s.getWeekMetrics(ctx, profileID, week)
+	if err != nil {
+		return nil, err
+	}
+	s.storeMetrics(ctx, profileID, week, metrics)
+	return metrics, nil
+}
+
+// storeMetrics records computed metrics in the metric store (no-op without a store)
+func (s *SilverLayer) storeMetrics(ctx context.Context, profileID string, week *weekmanager.WeekRange, metrics *WeekMetrics) {
+	if s.metricStore == nil {
+		return
+	}
+	if err := s.metricStore.Put(ctx, profileID, week, metrics); err != nil {
+		s.logger.Warnf("      ⚠️  Could not store metrics for %s: %v", week.Label, err)
+	}
+}
+
+// getWeekMetrics gets all metrics for a kid in a specific week
+func (s *SilverLayer) getWeekMetrics(ctx context.Context, profileID string, week *weekmanager.WeekRange) (*WeekMetrics, error) {
+	if s.fixtures != nil {
+		return s.fixtureWeekMetrics(ctx, profileID, week)
 	}
+	startDate, endDate := week.FormatDateRange()
+	var source kidSourceRows
 
-	// Get wallet balances (current state, not time-ranged)
-	walletQuery := `
+	// Get wallet balances as of the end of the week (silver.balances)
+	walletQuery := fmt.Sprintf(`
 		SELECT slug, balance
-		FROM wallets
-		WHERE profile_id = $1::uuid
-	`
-	rows, err := s.db.Query(walletQuery, profileID)
+		FROM (%s WHERE w.profile_id = $1::uuid) balances
+	`, s.balances.walletSelect("$2"))
+	walletArgs := []interface{}{profileID}
+	if s.balances.mode == BalanceModeSnapshots {
+		walletArgs = append(walletArgs, endDate) // Postgres rejects parameters the statement does not use
+	}
+	rows, err := s.query(ctx, "wallets", walletQuery, walletArgs...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	totalBalance := 0.0
 	for rows.Next() {
-		var walletType string
-		var balance float64
-		if err := rows.Scan(&walletType, &balance); err != nil {
+		var row walletRow
+		if err := rows.Scan(&row.Slug, s.amounts.Scan(&row.Balance)); err != nil {
 			return nil, err
 		}
-
-		totalBalance += balance
-		switch walletType {
-		case "joy":
-			metrics.JoyWallet = balance
-		case "spending":
-			metrics.SpendingWallet = balance
-		case "charity":
-			metrics.CharityWallet = balance
-		case "study":
-			metrics.StudyWallet = balance
-		}
+		source.Wallets = append(source.Wallets, row)
 	}
-	metrics.TotalBalance = totalBalance
 
 	// Get transaction data for this week
-	txQuery := `
+	txQuery := fmt.Sprintf(`
 		SELECT 
-			w.slug,
+			COALESCE(w.slug, ''),
 			wt.type,
+			%s as source,
 			SUM(wt.amount) as total,
 			COUNT(*) as count
 		FROM wallet_transactions wt
-		JOIN wallets w ON wt.wallet_id = w.id
+		LEFT JOIN wallets w ON wt.wallet_id = w.id
 		WHERE wt.profile_id = $1::uuid
 		  AND wt.created_at >= $2::date
 		  AND wt.created_at < $3::date
-		GROUP BY w.slug, wt.type
-	`
-	txRows, err := s.db.Query(txQuery, profileID, startDate, endDate)
+		GROUP BY 1, 2, 3
+	`, s.interest.sourceExpr("wt"))
+	txRows, err := s.query(ctx, "transactions", txQuery, profileID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 	defer txRows.Close()
 
 	for txRows.Next() {
-		var walletType, txType string
-		var amount float64
-		var count int
-		if err := txRows.Scan(&walletType, &txType, &amount, &count); err != nil {
+		var row txRow
+		if err := txRows.Scan(&row.Slug, &row.Type, &row.Source, s.amounts.Scan(&row.Amount), &row.Count); err != nil {
 			return nil, err
 		}
+		source.Transactions = append(source.Transactions, row)
+	}
 
-		if txType == "deposit" {
-			metrics.MoneyReceived += amount
-			metrics.MoneyReceivedCount += count
-		} else if txType == "withdraw" {
-			metrics.TotalSpent += amount
-			metrics.SpentCount += count
+	// Get the transactions after the week, undone from the current balances
+	if s.balances.reconstructs() {
+		laterQuery := fmt.Sprintf(`
+			SELECT COALESCE(w.slug, ''), wt.type, %s, SUM(wt.amount), COUNT(*)
+			FROM wallet_transactions wt
+			LEFT JOIN wallets w ON wt.wallet_id = w.id
+			WHERE wt.profile_id = $1::uuid
+			  AND wt.created_at >= $2::date
+			GROUP BY 1, 2, 3
+		`, s.interest.sourceExpr("wt"))
+		laterRows, err := s.query(ctx, "later_transactions", laterQuery, profileID, endDate)
+		if err != nil {
+			return nil, err
+		}
+		defer laterRows.Close()
 
-			switch walletType {
-			case "joy":
-				metrics.JoySpent += amount
-			case "spending":
-				metrics.SpendingSpent += amount
-			case "charity":
-				metrics.CharitySpent += amount
-			case "study":
-				metrics.StudySpent += amount
+		for laterRows.Next() {
+			var row txRow
+			if err := laterRows.Scan(&row.Slug, &row.Type, &row.Source, s.amounts.Scan(&row.Amount), &row.Count); err != nil {
+				return nil, err
 			}
+			source.Later = append(source.Later, row)
+		}
+		if err := laterRows.Err(); err != nil {
+			return nil, err
 		}
-	}
-
-	metrics.TransactionCount = metrics.MoneyReceivedCount + metrics.SpentCount
-	if metrics.TransactionCount > 0 {
-		metrics.AvgTransactionSize = (metrics.MoneyReceived + metrics.TotalSpent) / float64(metrics.TransactionCount)
 	}
 
 	// Get mission data
 	missionQuery := `
-		SELECT 
-			COALESCE(COUNT(*), 0) as total,
-			COALESCE(SUM(CASE WHEN status = 'complete' THEN 1 ELSE 0 END), 0) as completed
+		SELECT COALESCE(status, ''), COUNT(*)
 		FROM missions
 		WHERE profile_id = $1::uuid
 		  AND created_at >= $2::date
 		  AND created_at < $3::date
+		GROUP BY status
 	`
-	var completed sql.NullInt64
-	err = s.db.QueryRow(missionQuery, profileID, startDate, endDate).Scan(
-		&metrics.MissionsTotal,
-		&completed,
-	)
-	if completed.Valid {
-		metrics.MissionsCompleted = int(completed.Int64)
-	}
-	if err != nil && err != sql.ErrNoRows {
+	missionRows, err := s.query(ctx, "missions", missionQuery, profileID, startDate, endDate)
+	if err != nil {
 		return nil, err
 	}
+	defer missionRows.Close()
 
-	metrics.MissionsPending = metrics.MissionsTotal - metrics.MissionsCompleted
-	if metrics.MissionsTotal > 0 {
-		metrics.CompletionRate = float64(metrics.MissionsCompleted) / float64(metrics.MissionsTotal) * 100
+	for missionRows.Next() {
+		var row missionRow
+		if err := missionRows.Scan(&row.Status, &row.Count); err != nil {
+			return nil, err
+		}
+		source.Missions = append(source.Missions, row)
+	}
+	if err := missionRows.Err(); err != nil {
+		return nil, err
 	}
 
-	// Get active days
-	activeDaysQuery := `
-		SELECT COUNT(DISTINCT DATE(created_at))
+	// Get active days (interest credits are not activity by the kid)
+	activeDaysQuery := fmt.Sprintf(`
+		SELECT DATE(created_at)::text, type, %s
 		FROM wallet_transactions
 		WHERE profile_id = $1::uuid
 		  AND created_at >= $2::date
 		  AND created_at < $3::date
-	`
-	if err := s.db.QueryRow(activeDaysQuery, profileID, startDate, endDate).Scan(&metrics.ActiveDays); err != nil {
-		if err != sql.ErrNoRows {
+		GROUP BY 1, 2, 3
+	`, s.interest.sourceExpr(""))
+	dayRows, err := s.query(ctx, "active_days", activeDaysQuery, profileID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer dayRows.Close()
+
+	for dayRows.Next() {
+		var row dayRow
+		if err := dayRows.Scan(&row.Day, &row.Type, &row.Source); err != nil {
 			return nil, err
 		}
+		source.Days = append(source.Days, row)
+	}
+	if err := dayRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.buildWeekMetrics(ctx, profileID, week, &source)
+}
+
+// buildWeekMetrics computes a kid's week from its source rows, then adds the spending categories
+// and derived features (still queried per kid)
+func (s *SilverLayer) buildWeekMetrics(ctx context.Context, profileID string, week *weekmanager.WeekRange, source *kidSourceRows) (*WeekMetrics, error) {
+	startDate, endDate := week.FormatDateRange()
+
+	metrics := &WeekMetrics{
+		WeekLabel: week.Label,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	if week.IsPartial {
+		metrics.IsPartial = true
+		metrics.ElapsedFraction = week.ElapsedFraction()
 	}
 
+	var totalBalance, studyBalance Money
+	later := s.laterFlows(source.Later)
+	for _, row := range source.Wallets {
+		balance := row.Balance - later[row.Slug]
+		totalBalance += balance
+		switch row.Slug {
+		case "joy":
+			metrics.JoyWallet = s.amounts.Float(balance)
+		case "spending":
+			metrics.SpendingWallet = s.amounts.Float(balance)
+		case "charity":
+			metrics.CharityWallet = s.amounts.Float(balance)
+		case "study":
+			metrics.StudyWallet = s.amounts.Float(balance)
+			studyBalance = balance
+		}
+	}
+	metrics.TotalBalance = s.amounts.Float(totalBalance)
+
+	// Summed in minor units; converted once so totals never drift (e.g. 99999.99999999999)
+	var received, spent, interest, studyNet Money
+	spentByWallet := make(map[string]Money)
+	for _, row := range source.Transactions {
+		walletType, amount, count := row.Slug, row.Amount, row.Count
+		if walletType == "" {
+			metrics.OrphanTransactions += count
+		}
+
+		if s.interest.Matches(row.Type, row.Source) {
+			interest += amount
+			metrics.InterestCount += count
+			if walletType == "study" {
+				studyNet += amount
+			}
+		} else if row.Type == "deposit" {
+			received += amount
+			metrics.MoneyReceivedCount += count
+			if walletType == "study" {
+				studyNet += amount
+			}
+		} else if row.Type == "withdraw" {
+			spent += amount
+			spentByWallet[walletType] += amount
+			metrics.SpentCount += count
+			if walletType == "study" {
+				studyNet -= amount
+			}
+		}
+	}
+	metrics.MoneyReceived = s.amounts.Float(received)
+	metrics.InterestEarned = s.amounts.Float(interest)
+	if rate, ok := studyGrowthRate(interest, studyNet, studyBalance); ok {
+		metrics.StudyGrowthRate = rate
+	}
+	metrics.TotalSpent = s.amounts.Float(spent)
+	metrics.JoySpent = s.amounts.Float(spentByWallet["joy"])
+	metrics.SpendingSpent = s.amounts.Float(spentByWallet["spending"])
+	metrics.CharitySpent = s.amounts.Float(spentByWallet["charity"])
+	metrics.StudySpent = s.amounts.Float(spentByWallet["study"])
+
+	metrics.TransactionCount = metrics.MoneyReceivedCount + metrics.SpentCount
+	if metrics.TransactionCount > 0 {
+		metrics.AvgTransactionSize = s.amounts.Float(received+spent) / float64(metrics.TransactionCount)
+	}
+
+	if s.categoryTable != "" {
+		var err error
+		if metrics.SpendingByCategory, err = s.getSpendingByCategory(ctx, profileID, startDate, endDate); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range source.Missions {
+		if metrics.MissionStatusCounts == nil {
+			metrics.MissionStatusCounts = make(map[string]int)
+		}
+		metrics.MissionStatusCounts[row.Status] += row.Count
+		metrics.MissionsTotal += row.Count
+
+		switch s.missionStatuses.Classify(row.Status) {
+		case MissionCompleted:
+			metrics.MissionsCompleted += row.Count
+		case MissionFailed:
+			metrics.MissionsFailed += row.Count
+		default:
+			metrics.MissionsPending += row.Count
+		}
+	}
+
+	if metrics.MissionsTotal > 0 {
+		metrics.CompletionRate = float64(metrics.MissionsCompleted) / float64(metrics.MissionsTotal) * 100
+	}
+
+	activeDays := make(map[string]bool)
+	for _, row := range source.Days {
+		if !s.interest.Matches(row.Type, row.Source) {
+			activeDays[row.Day] = true
+		}
+	}
+	metrics.ActiveDays = len(activeDays)
+
+	s.computeFeatures(ctx, profileID, week, metrics)
 	return metrics, nil
 }
 
+// getSpendingByCategory sums a kid's spending per category; transactions not categorized yet count as "uncategorized"
+func (s *SilverLayer) getSpendingByCategory(ctx context.Context, profileID, startDate, endDate string) (map[string]float64, error) {
+	query := fmt.Sprintf(`
+		SELECT COALESCE(tc.category, 'uncategorized'), SUM(wt.amount)
+		FROM wallet_transactions wt
+		LEFT JOIN %s tc ON tc.transaction_id = wt.id::text
+		WHERE wt.profile_id = $1::uuid
+		  AND wt.type = 'withdraw'
+		  AND wt.created_at >= $2::date
+		  AND wt.created_at < $3::date
+		GROUP BY 1
+	`, s.categoryTable)
+	rows, err := s.db.QueryContext(ctx, query, profileID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spending by category: %w", err)
+	}
+	defer rows.Close()
+
+	var spending map[string]float64
+	for rows.Next() {
+		var category string
+		var amount Money
+		if err := rows.Scan(&category, s.amounts.Scan(&amount)); err != nil {
+			return nil, err
+		}
+		if spending == nil {
+			spending = make(map[string]float64)
+		}
+		spending[category] = s.amounts.Float(amount)
+	}
+	return spending, rows.Err()
+}
+
 // calculateTrends calculates trends by comparing weeks
 func (s *SilverLayer) calculateTrends(data *EnhancedKidData) *TrendData {
 	trends := &TrendData{}
@@ -407,6 +947,14 @@ func (s *SilverLayer) calculateTrends(data *EnhancedKidData) *TrendData {
 		return trends
 	}
 
+	// Prorate flow metrics of the previous week when the current week is only partially elapsed
+	prorate := 1.0
+	if current.IsPartial && current.ElapsedFraction > 0 {
+		prorate = current.ElapsedFraction
+	}
+	previousSpent := previous.TotalSpent * prorate
+	previousTxCount := int(math.Round(float64(previous.TransactionCount) * prorate))
+
 	// Balance trend
 	if previous.TotalBalance > 0 {
 		balanceChange := current.TotalBalance - previous.TotalBalance
@@ -426,9 +974,9 @@ func (s *SilverLayer) calculateTrends(data *EnhancedKidData) *TrendData {
 	}
 
 	// Spending trend
-	if previous.TotalSpent > 0 {
-		spendingChange := current.TotalSpent - previous.TotalSpent
-		trends.SpendingChangePercent = (spendingChange / previous.TotalSpent) * 100
+	if previousSpent > 0 {
+		spendingChange := current.TotalSpent - previousSpent
+		trends.SpendingChangePercent = (spendingChange / previousSpent) * 100
 
 		if math.Abs(trends.SpendingChangePercent) < 10 {
 			trends.SpendingTrend = "stable"
@@ -452,7 +1000,7 @@ func (s *SilverLayer) calculateTrends(data *EnhancedKidData) *TrendData {
 	}
 
 	// Activity trend
-	activityChange := current.TransactionCount - previous.TransactionCount
+	activityChange := current.TransactionCount - previousTxCount
 	trends.ActivityChange = activityChange
 
 	if activityChange > 2 {
@@ -665,49 +1213,110 @@ func (s *SilverLayer) calculateImprovementRate(data *EnhancedKidData) float64 {
 
 // Helper: getKidProfiles gets all kid profiles
 // getAllKidProfiles returns ALL kids in the system (used for comprehensive weekly analysis)
-func (s *SilverLayer) getAllKidProfiles() ([]KidProfile, error) {
+// Rows whose ID is not a valid UUID are returned separately instead of failing queries mid-week.
+func (s *SilverLayer) getAllKidProfiles(ctx context.Context) ([]KidProfile, []InvalidProfile, error) {
 	query := `
 		SELECT 
 			id::text,
 			COALESCE(full_name, 'Unknown'),
-			COALESCE(full_name, 'Kid'),
-			COALESCE(EXTRACT(YEAR FROM AGE(CURRENT_DATE, date_of_birth)), 0)::int,
-			COALESCE(date_of_birth::text, '')
+			NULLIF(TRIM(full_name), ''),
+			EXTRACT(YEAR FROM AGE(CURRENT_DATE, date_of_birth))::int,
+			COALESCE(date_of_birth::text, ''),
+			` + s.languageExpr("") + `,
+			` + s.deleted.deletedExpr() + `
 		FROM profiles
 		WHERE profile_type = 'kid'
 		ORDER BY created_at
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.query(ctx, "profiles", query)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	var profiles []KidProfile
+	var invalid []InvalidProfile
 	for rows.Next() {
 		var p KidProfile
-		if err := rows.Scan(&p.ProfileID, &p.FullName, &p.Nickname, &p.Age, &p.DateOfBirth); err != nil {
-			return nil, err
+		var rawID string
+		var age sql.NullInt64
+		var nickname sql.NullString
+		var deletedAt sql.NullTime
+		if err := rows.Scan(&rawID, &p.FullName, &nickname, &age, &p.DateOfBirth, &p.Language, &deletedAt); err != nil {
+			return nil, nil, err
+		}
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			invalid = append(invalid, InvalidProfile{ProfileID: rawID, Error: err.Error()})
+			continue
 		}
+		p.ProfileID = id
+		p.applyProfileFields(age, nickname)
+		s.deleted.applyDeletedAt(&p, deletedAt)
 		profiles = append(profiles, p)
 	}
 
-	return profiles, rows.Err()
+	return profiles, invalid, rows.Err()
+}
+
+// getKidProfile returns a single kid profile by ID
+func (s *SilverLayer) getKidProfile(ctx context.Context, profileID uuid.UUID) (*KidProfile, error) {
+	query := `
+		SELECT 
+			id::text,
+			COALESCE(full_name, 'Unknown'),
+			NULLIF(TRIM(full_name), ''),
+			EXTRACT(YEAR FROM AGE(CURRENT_DATE, date_of_birth))::int,
+			COALESCE(date_of_birth::text, ''),
+			` + s.languageExpr("") + `,
+			` + s.deleted.deletedExpr() + `
+		FROM profiles
+		WHERE profile_type = 'kid'
+		  AND id = $1::uuid
+	`
+
+	var p KidProfile
+	var age sql.NullInt64
+	var nickname sql.NullString
+	var deletedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, query, profileID).Scan(&p.ProfileID, &p.FullName, &nickname, &age, &p.DateOfBirth, &p.Language, &deletedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("kid profile not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.applyProfileFields(age, nickname)
+	s.deleted.applyDeletedAt(&p, deletedAt)
+
+	return &p, nil
+}
+
+// reportInvalidProfiles logs the profiles skipped for a malformed ID
+func (s *SilverLayer) reportInvalidProfiles(invalid []InvalidProfile) {
+	for _, profile := range invalid {
+		s.logger.Warnf("   ⚠️  Skipping profile with invalid ID: %s", profile.Error)
+	}
+	if len(invalid) > 0 {
+		s.logger.Warnf("⚠️  %d kid profiles skipped for invalid IDs (listed under invalid_profiles in the output)", len(invalid))
+	}
 }
 
 // getActiveKidProfiles returns kids who had transactions or missions in the given week
 // NOTE: Currently not used - kept for potential future filtering needs
-func (s *SilverLayer) getActiveKidProfiles(week *weekmanager.WeekRange) ([]KidProfile, error) {
+func (s *SilverLayer) getActiveKidProfiles(ctx context.Context, week *weekmanager.WeekRange) ([]KidProfile, error) {
 	startDate, endDate := week.FormatDateRange()
 
 	query := `
 		SELECT DISTINCT
 			p.id::text,
 			COALESCE(p.full_name, 'Unknown'),
-			COALESCE(p.full_name, 'Kid'),
-			COALESCE(EXTRACT(YEAR FROM AGE(CURRENT_DATE, p.date_of_birth)), 0)::int,
+			NULLIF(TRIM(p.full_name), ''),
+			EXTRACT(YEAR FROM AGE(CURRENT_DATE, p.date_of_birth))::int,
 			COALESCE(p.date_of_birth::text, ''),
+			` + s.languageExpr("p") + `,
+			` + s.deleted.deletedExpr() + `,
 			p.created_at
 		FROM profiles p
 		WHERE p.profile_type = 'kid'
@@ -731,7 +1340,7 @@ func (s *SilverLayer) getActiveKidProfiles(week *weekmanager.WeekRange) ([]KidPr
 		ORDER BY p.created_at
 	`
 
-	rows, err := s.db.Query(query, startDate, endDate)
+	rows, err := s.db.QueryContext(ctx, query, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -740,28 +1349,34 @@ func (s *SilverLayer) getActiveKidProfiles(week *weekmanager.WeekRange) ([]KidPr
 	var profiles []KidProfile
 	for rows.Next() {
 		var p KidProfile
+		var age sql.NullInt64
+		var nickname sql.NullString
+		var deletedAt sql.NullTime
 		var createdAt interface{} // Ignore this field, only used for ORDER BY
-		if err := rows.Scan(&p.ProfileID, &p.FullName, &p.Nickname, &p.Age, &p.DateOfBirth, &createdAt); err != nil {
+		if err := rows.Scan(&p.ProfileID, &p.FullName, &nickname, &age, &p.DateOfBirth, &p.Language, &deletedAt, &createdAt); err != nil {
 			return nil, err
 		}
+		p.applyProfileFields(age, nickname)
+		s.deleted.applyDeletedAt(&p, deletedAt)
 		profiles = append(profiles, p)
 	}
 
 	return profiles, rows.Err()
 }
 
-// saveJSON saves data to JSON file
-func (s *SilverLayer) saveJSON(data interface{}, filepath string) error {
+// saveJSON saves data to JSON file (compressed if configured) and returns the written path
+func (s *SilverLayer) saveJSON(data interface{}, path string) (string, error) {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	if err := os.WriteFile(filepath, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	writtenPath, err := fileio.WriteFile(path, jsonData, s.compression)
+	if err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
-	return nil
+	return writtenPath, nil
 }
 
 // Helper functions
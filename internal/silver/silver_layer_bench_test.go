@@ -0,0 +1,134 @@
+package silver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/sirupsen/logrus"
+)
+
+// benchKid builds a kid with three weeks of realistic metrics
+func benchKid(i int) EnhancedKidData {
+	week := func(scale float64) *WeekMetrics {
+		return &WeekMetrics{
+			WeekLabel:          "Tuần 3 - Tháng 10/2025",
+			JoyWallet:          26754.74 * scale,
+			SpendingWallet:     120000 * scale,
+			CharityWallet:      15000 * scale,
+			StudyWallet:        43000 * scale,
+			TotalBalance:       204754.74 * scale,
+			MoneyReceived:      50000 * scale,
+			MoneyReceivedCount: 3,
+			TotalSpent:         31230.5 * scale,
+			JoySpent:           15615.07 * scale,
+			SpendingSpent:      10000 * scale,
+			CharitySpent:       5615.43 * scale,
+			MissionsTotal:      6,
+			MissionsCompleted:  4,
+			CompletionRate:     66.67,
+			TransactionCount:   9,
+			ActiveDays:         5,
+		}
+	}
+	return EnhancedKidData{
+		ProfileID:    fmt.Sprintf("00000000-0000-0000-0000-%012d", i),
+		Nickname:     fmt.Sprintf("Bé %d", i),
+		Age:          intPtr(9),
+		CurrentWeek:  *week(1),
+		PreviousWeek: week(0.8),
+		TwoWeeksAgo:  week(1.1),
+	}
+}
+
+func BenchmarkCalculateTrends(b *testing.B) {
+	s := &SilverLayer{}
+	kid := benchKid(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.calculateTrends(&kid)
+	}
+}
+
+func BenchmarkCalculateStatisticsAndScores(b *testing.B) {
+	s := &SilverLayer{}
+	kid := benchKid(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.calculateStatistics(&kid)
+		s.calculateActivityScore(&kid.CurrentWeek)
+		s.calculateConsistencyScore(&kid)
+		s.calculateImprovementRate(&kid)
+	}
+}
+
+func BenchmarkMarshalLargeOutput(b *testing.B) {
+	for _, kids := range []int{100, 1000, 5000} {
+		kids := kids
+		output := EnhancedOutput{Week: "Tuần 3 - Tháng 10/2025", TotalKids: kids}
+		for i := 0; i < kids; i++ {
+			output.Kids = append(output.Kids, benchKid(i))
+		}
+		b.Run(fmt.Sprintf("kids=%d", kids), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				data, err := json.MarshalIndent(output, "", "  ")
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.SetBytes(int64(len(data)))
+			}
+		})
+	}
+}
+
+// BenchmarkWeekMetricsQueries compares the aggregate statement with the per-kid queries for the last
+// seven days of a real database. Set BENCH_DATABASE_URL (e.g. a staging copy) to run it.
+func BenchmarkWeekMetricsQueries(b *testing.B) {
+	dsn := os.Getenv("BENCH_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("BENCH_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	s := NewSilverLayer(db, logger, config.SilverConfig{})
+	ctx := context.Background()
+	profiles, _, err := s.getAllKidProfiles(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	end := time.Now().Truncate(24 * time.Hour)
+	week := &weekmanager.WeekRange{Label: "bench", StartDate: end.AddDate(0, 0, -7), EndDate: end}
+
+	b.Run(fmt.Sprintf("aggregate/kids=%d", len(profiles)), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.getWeekMetricsAggregate(ctx, profiles, week); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run(fmt.Sprintf("per_kid/kids=%d", len(profiles)), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, profile := range profiles {
+				if _, err := s.getWeekMetrics(ctx, profile.ProfileID.String(), week); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func intPtr(v int) *int { return &v }
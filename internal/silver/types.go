@@ -1,11 +1,16 @@
 package silver
 
+import "ai-production-pipeline/internal/uuid"
+
 // KidProfile represents basic kid profile information
 type KidProfile struct {
-	ProfileID    string // UUID
+	ProfileID    uuid.UUID
 	FullName     string
 	Nickname     string
-	Age          int
+	Age          *int // nil when date_of_birth is missing or implausible
 	DateOfBirth  string
-	TotalBalance float64 // Optional, used by transformer_v2
+	TotalBalance float64  // Optional, used by transformer_v2
+	Language     string   // App language preference ("" when unknown)
+	DataQuality  []string // Data quality flags (unknown_age, missing_name, ...)
+	Deleted      bool     // Soft-deleted per silver.deleted_profiles.column
 }
@@ -0,0 +1,33 @@
+package telemetry
+
+// Layer and result label values
+const (
+	LayerSilver = "silver"
+	LayerGold   = "gold"
+
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultPartial = "partial" // Gold stopped early (run deadline or provider outage)
+)
+
+// Pipeline metrics, recorded by the processor, the layers and the run loop
+var (
+	AIRequests = NewCounterVec("pipeline_ai_requests_total",
+		"AI provider calls by model and outcome (ok, cached or the failure class)", "model", "outcome")
+	AIRetries = NewCounterVec("pipeline_ai_retries_total",
+		"AI provider calls that retried an earlier failed attempt", "model")
+	AITokens = NewCounterVec("pipeline_ai_tokens_total",
+		"Tokens billed by the AI provider", "model", "type")
+	AIRequestDuration = NewHistogramVec("pipeline_ai_request_duration_seconds",
+		"Time waiting on the AI provider per call", []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120}, "model")
+
+	WeekDuration = NewHistogramVec("pipeline_week_duration_seconds",
+		"Time to process one week through Silver and Gold", []float64{60, 300, 600, 1200, 1800, 3600, 7200, 14400})
+	DBQueryDuration = NewHistogramVec("pipeline_db_query_duration_seconds",
+		"Source database query latency by query", []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 5}, "query")
+
+	LayerWeeks = NewCounterVec("pipeline_layer_weeks_total",
+		"Weeks finished per layer and result", "layer", "result")
+	LayerKids = NewCounterVec("pipeline_layer_kids_total",
+		"Kids processed per layer and result", "layer", "result")
+)
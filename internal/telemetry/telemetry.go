@@ -0,0 +1,195 @@
+// Package telemetry keeps the pipeline's Prometheus counters and histograms in memory and renders them
+// in the text exposition format. Metrics are always recorded; they are only served when
+// status.prometheus is enabled.
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// labelEscaper escapes label values for the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// collector is a registered metric family
+type collector interface {
+	write(b *bytes.Buffer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []collector // Rendered in registration order
+)
+
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, c)
+}
+
+// labelKey joins label values into a map key; missing values are empty
+func labelKey(labels []string, values []string) string {
+	padded := make([]string, len(labels))
+	copy(padded, values)
+	return strings.Join(padded, "\xff")
+}
+
+// labelPairs renders a series' labels, e.g. {model="gpt-4o",outcome="ok"} ("" without labels)
+func labelPairs(labels []string, key string, extra ...string) string {
+	var pairs []string
+	if len(labels) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], labelEscaper.Replace(value)))
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], extra[i+1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sortedKeys returns map keys in a stable order for the output
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a counter per label set. Safe for concurrent use.
+type CounterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec registers a counter with the given label names
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+	register(c)
+	return c
+}
+
+// Add adds v (ignored when negative) to the series for labelValues
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.values[labelKey(c.labels, labelValues)] += v
+	c.mu.Unlock()
+}
+
+// Inc adds one to the series for labelValues
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) write(b *bytes.Buffer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(b, "%s%s %g\n", c.name, labelPairs(c.labels, key), c.values[key])
+	}
+}
+
+// HistogramVec is a histogram per label set with fixed upper bounds. Safe for concurrent use.
+type HistogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64 // Ascending upper bounds; +Inf is implied
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec registers a histogram with the given bucket upper bounds and label names
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	h := &HistogramVec{name: name, help: help, labels: labels, buckets: sorted, series: make(map[string]*histogram)}
+	register(h)
+	return h
+}
+
+// Observe records v in the series for labelValues
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := labelKey(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+// Since records the seconds elapsed since start
+func (h *HistogramVec) Since(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *HistogramVec) write(b *bytes.Buffer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, labelPairs(h.labels, key, "le", fmt.Sprintf("%g", bound)), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, labelPairs(h.labels, key, "le", "+Inf"), s.count)
+		fmt.Fprintf(b, "%s_sum%s %g\n", h.name, labelPairs(h.labels, key), s.sum)
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, labelPairs(h.labels, key), s.count)
+	}
+}
+
+// Write renders every registered metric in the Prometheus text format
+func Write(w io.Writer) error {
+	registryMu.Lock()
+	collectors := append([]collector{}, registry...)
+	registryMu.Unlock()
+
+	var b bytes.Buffer
+	for _, c := range collectors {
+		c.write(&b)
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// ServeHTTP serves every registered metric (GET /metrics)
+func ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	Write(w)
+}
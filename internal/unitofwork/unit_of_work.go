@@ -0,0 +1,177 @@
+package unitofwork
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ai-production-pipeline/internal/fileio"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrFinished is returned when staging into a unit of work that was already committed or rolled back
+var ErrFinished = errors.New("unit of work already finished")
+
+// stagedFile is an output written to a temp file, moved into place on commit
+type stagedFile struct {
+	tempPath  string
+	finalPath string
+	backup    string // Previous version of finalPath, restored if the commit fails
+	stalePath string // Uncompressed copy superseded by a compressed output
+}
+
+// UnitOfWork groups the pipeline-owned writes of one week (report files, DB rows) so they
+// become visible together or not at all. Files are staged to temp files and renamed on
+// Commit; DB sinks write through Tx and commit in the same step.
+type UnitOfWork struct {
+	logger   *logrus.Logger
+	tx       *sql.Tx
+	files    []stagedFile
+	finished bool
+}
+
+// Begin starts a unit of work. db may be nil when there are no DB sinks.
+func Begin(ctx context.Context, db *sql.DB, logger *logrus.Logger) (*UnitOfWork, error) {
+	uow := &UnitOfWork{logger: logger}
+	if db != nil {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		uow.tx = tx
+	}
+	return uow, nil
+}
+
+// Tx returns the transaction for DB sinks (nil when the unit has no database)
+func (u *UnitOfWork) Tx() *sql.Tx {
+	return u.tx
+}
+
+// StageFile writes data (compressed with format) to a temp file next to path.
+// The returned path is where the file will appear after Commit.
+func (u *UnitOfWork) StageFile(path string, data []byte, format string) (string, error) {
+	if u.finished {
+		return "", ErrFinished
+	}
+
+	encoded, err := fileio.Encode(data, format)
+	if err != nil {
+		return "", err
+	}
+	finalPath := path + fileio.Extension(format)
+
+	temp, err := os.CreateTemp(filepath.Dir(finalPath), "."+filepath.Base(finalPath)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to stage %s: %w", finalPath, err)
+	}
+	if _, err := temp.Write(encoded); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return "", fmt.Errorf("failed to stage %s: %w", finalPath, err)
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return "", fmt.Errorf("failed to stage %s: %w", finalPath, err)
+	}
+	os.Chmod(temp.Name(), 0644)
+
+	staged := stagedFile{tempPath: temp.Name(), finalPath: finalPath}
+	if finalPath != path {
+		staged.stalePath = path
+	}
+	u.files = append(u.files, staged)
+	return finalPath, nil
+}
+
+// Commit moves staged files into place and commits the transaction.
+// If any step fails, files already moved are restored to their previous versions.
+func (u *UnitOfWork) Commit() error {
+	if u.finished {
+		return ErrFinished
+	}
+	u.finished = true
+
+	for i := range u.files {
+		if err := u.files[i].install(); err != nil {
+			u.restore(i)
+			u.discard(i)
+			if u.tx != nil {
+				u.tx.Rollback()
+			}
+			return fmt.Errorf("failed to commit %s: %w", u.files[i].finalPath, err)
+		}
+	}
+
+	if u.tx != nil {
+		if err := u.tx.Commit(); err != nil {
+			u.restore(len(u.files))
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+
+	// Committed: drop backups and uncompressed copies superseded by compressed outputs
+	for _, f := range u.files {
+		if f.backup != "" {
+			os.Remove(f.backup)
+		}
+		if f.stalePath != "" {
+			os.Remove(f.stalePath)
+		}
+	}
+	u.logger.Debugf("✅ Unit of work committed (%d files)", len(u.files))
+	return nil
+}
+
+// Rollback discards staged files and rolls back the transaction. It is a no-op after Commit.
+func (u *UnitOfWork) Rollback() error {
+	if u.finished {
+		return nil
+	}
+	u.finished = true
+
+	u.discard(0)
+	if u.tx != nil {
+		if err := u.tx.Rollback(); err != nil {
+			return fmt.Errorf("failed to roll back transaction: %w", err)
+		}
+	}
+	u.logger.Warnf("↩️  Unit of work rolled back (%d staged files discarded)", len(u.files))
+	return nil
+}
+
+// install moves the staged file into place, keeping the previous version as a backup
+func (f *stagedFile) install() error {
+	if _, err := os.Stat(f.finalPath); err == nil {
+		f.backup = f.tempPath + ".bak"
+		if err := os.Rename(f.finalPath, f.backup); err != nil {
+			f.backup = ""
+			return err
+		}
+	}
+	return os.Rename(f.tempPath, f.finalPath)
+}
+
+// restore puts back the previous versions of the first n installed files
+func (u *UnitOfWork) restore(n int) {
+	for _, f := range u.files[:n] {
+		if f.backup != "" {
+			if err := os.Rename(f.backup, f.finalPath); err != nil {
+				u.logger.Errorf("❌ Failed to restore %s: %v", f.finalPath, err)
+			}
+		} else {
+			os.Remove(f.finalPath)
+		}
+	}
+}
+
+// discard removes staged temp files from index i onwards
+func (u *UnitOfWork) discard(i int) {
+	for _, f := range u.files[i:] {
+		os.Remove(f.tempPath)
+	}
+}
@@ -0,0 +1,115 @@
+package uuid
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalid is wrapped by every parse error, so callers can report bad IDs as input errors
+var ErrInvalid = errors.New("invalid UUID")
+
+// UUID is a 16-byte RFC 4122 identifier. It scans from and writes to Postgres uuid and text columns.
+type UUID [16]byte
+
+// Nil is the all-zero UUID
+var Nil UUID
+
+// Parse normalizes and validates s. Surrounding whitespace, upper case, braces and a "urn:uuid:"
+// prefix are accepted; the hyphens may be omitted.
+func Parse(s string) (UUID, error) {
+	var u UUID
+	trimmed := strings.TrimSpace(s)
+	trimmed = strings.TrimPrefix(strings.ToLower(trimmed), "urn:uuid:")
+	trimmed = strings.TrimSuffix(strings.TrimPrefix(trimmed, "{"), "}")
+
+	digits := trimmed
+	if len(trimmed) == 36 {
+		for _, i := range []int{8, 13, 18, 23} {
+			if trimmed[i] != '-' {
+				return u, fmt.Errorf("%w %q: expected '-' at position %d", ErrInvalid, s, i+1)
+			}
+		}
+		digits = strings.ReplaceAll(trimmed, "-", "")
+	}
+	if len(digits) != 32 {
+		return u, fmt.Errorf("%w %q: want 32 hex digits like 123e4567-e89b-12d3-a456-426614174000, got %d characters",
+			ErrInvalid, s, len(trimmed))
+	}
+	if _, err := hex.Decode(u[:], []byte(digits)); err != nil {
+		return Nil, fmt.Errorf("%w %q: %v", ErrInvalid, s, err)
+	}
+	return u, nil
+}
+
+// MustParse is Parse that panics on error (constants and tests)
+func MustParse(s string) UUID {
+	u, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// String returns the canonical lower-case form
+func (u UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+// IsNil reports whether u is the all-zero UUID
+func (u UUID) IsNil() bool {
+	return u == Nil
+}
+
+// Scan implements sql.Scanner for uuid and text columns
+func (u *UUID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*u = parsed
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		return u.Scan(string(v))
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalid, src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer
+func (u UUID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// MarshalText implements encoding.TextMarshaler (JSON strings)
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
@@ -0,0 +1,97 @@
+package weekmanager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+)
+
+// Week detection defaults, matching the original wallet_transactions-only query
+const (
+	defaultAnchorDate  = "2025-10-01"
+	defaultSourceTable = "wallet_transactions"
+)
+
+// sourceTables are the tables week detection can read; each has profile activity in created_at
+var sourceTables = map[string]bool{
+	"wallet_transactions": true,
+	"missions":            true,
+}
+
+// detection holds the parsed calendar.anchor_date, calendar.source_tables and calendar.week_start
+type detection struct {
+	anchor   time.Time
+	tables   []string
+	firstDay time.Weekday
+}
+
+// parseDetection validates the week detection settings, filling in defaults
+func parseDetection(cal config.CalendarConfig) (detection, error) {
+	d := detection{firstDay: time.Monday, tables: cal.SourceTables}
+
+	anchor := cal.AnchorDate
+	if anchor == "" {
+		anchor = defaultAnchorDate
+	}
+	var err error
+	if d.anchor, err = time.Parse("2006-01-02", anchor); err != nil {
+		return d, fmt.Errorf("invalid calendar.anchor_date %q: %w", cal.AnchorDate, err)
+	}
+
+	if len(d.tables) == 0 {
+		d.tables = []string{defaultSourceTable}
+	}
+	seen := make(map[string]bool)
+	for _, table := range d.tables {
+		if !sourceTables[table] {
+			return d, fmt.Errorf("calendar.source_tables: unknown table %q (want wallet_transactions or missions)", table)
+		}
+		if seen[table] {
+			return d, fmt.Errorf("calendar.source_tables: %q listed twice", table)
+		}
+		seen[table] = true
+	}
+
+	switch strings.ToLower(cal.WeekStart) {
+	case "", "monday":
+	case "sunday":
+		d.firstDay = time.Sunday
+	default:
+		return d, fmt.Errorf("calendar.week_start must be monday or sunday, got %q", cal.WeekStart)
+	}
+	return d, nil
+}
+
+// CheckDetection validates the week detection settings (for validate-config)
+func CheckDetection(cal config.CalendarConfig) error {
+	_, err := parseDetection(cal)
+	return err
+}
+
+// query returns the distinct week starts with activity in any source table since the anchor ($1)
+func (d detection) query() string {
+	// DATE_TRUNC('week') starts weeks on Monday; shift by a day for Sunday weeks
+	weekStart := "DATE_TRUNC('week', created_at)::date"
+	if d.firstDay == time.Sunday {
+		weekStart = "(DATE_TRUNC('week', created_at + INTERVAL '1 day') - INTERVAL '1 day')::date"
+	}
+
+	selects := make([]string, len(d.tables))
+	for i, table := range d.tables {
+		selects[i] = fmt.Sprintf("SELECT %s AS week_start FROM %s WHERE created_at >= $1::date", weekStart, table)
+	}
+	return fmt.Sprintf(`
+		SELECT DISTINCT week_start
+		FROM (%s) activity
+		ORDER BY week_start ASC
+	`, strings.Join(selects, " UNION "))
+}
+
+// weekStartOf returns the first day (00:00) of the week containing t
+func (d detection) weekStartOf(t time.Time) time.Time {
+	offset := (int(t.Weekday()) - int(d.firstDay) + 7) % 7
+	day := t.AddDate(0, 0, -offset)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+}
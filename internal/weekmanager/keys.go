@@ -0,0 +1,101 @@
+package weekmanager
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key returns the week's unique identifier, its start date (YYYY-MM-DD)
+func (wr WeekRange) Key() string {
+	return wr.StartDate.Format("2006-01-02")
+}
+
+// OutputFile returns the name of the week's output file with prefix, keyed by start date
+// (kids_reports → kids_reports_week_2025-10-06.json)
+func (wr WeekRange) OutputFile(prefix string) string {
+	return OutputFile(prefix, wr.Key())
+}
+
+// OutputFile returns the name of the output file with prefix for the week whose key is key
+func OutputFile(prefix, key string) string {
+	return OutputName(prefix, key, ".json")
+}
+
+// ParseKey checks that key is a week key (a start date, YYYY-MM-DD, as listed by pipeline weeks)
+func ParseKey(key string) (time.Time, error) {
+	start, err := time.Parse("2006-01-02", key)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("week %q is not a start date (YYYY-MM-DD, see pipeline weeks)", key)
+	}
+	return start, nil
+}
+
+// legacyOutputName matches output files and directories named by week number, as they were before
+// they were keyed by start date (kids_reports_week_3.json.gz, kids_analysis_week_3.partial.json,
+// kids_reports_week_3/)
+var legacyOutputName = regexp.MustCompile(`^(.+)_week_([0-9]+)((?:\.partial)?\.json(?:\.gz|\.zst)?)?$`)
+
+// ParseLegacyOutputFile splits a legacy output name into its prefix, week number and suffix
+// (kids_reports_week_3.json.gz → kids_reports, 3, .json.gz); ok is false for any other name
+func ParseLegacyOutputFile(name string) (prefix string, number int, suffix string, ok bool) {
+	match := legacyOutputName.FindStringSubmatch(name)
+	if match == nil {
+		return "", 0, "", false
+	}
+	number, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, "", false
+	}
+	return match[1], number, match[3], true
+}
+
+// OutputName returns the name of the output file or directory with prefix and suffix for the week
+// whose key is key (kids_reports, 2025-10-06, .json.gz → kids_reports_week_2025-10-06.json.gz)
+func OutputName(prefix, key, suffix string) string {
+	return fmt.Sprintf("%s_week_%s%s", prefix, key, suffix)
+}
+
+// disambiguateLabels appends the start date to labels shared by several weeks (e.g. two holiday
+// weeks in one month, or weeks before the semester), so labels stay unique
+func disambiguateLabels(weeks []WeekRange) {
+	count := make(map[string]int, len(weeks))
+	for _, week := range weeks {
+		count[week.Label]++
+	}
+	for i := range weeks {
+		if count[weeks[i].Label] > 1 {
+			weeks[i].Label = fmt.Sprintf("%s (%s)", weeks[i].Label, weeks[i].StartDate.Format("02/01"))
+		}
+	}
+}
+
+// CheckCollisions fails when two weeks resolve to the same start date, label or week number.
+// Token usage, checkpoints, run state and stored rows are keyed by label and output files by start
+// date, so a collision would merge two weeks' costs or overwrite one week's files with another's.
+func CheckCollisions(weeks []WeekRange) error {
+	keys := make(map[string]string, len(weeks))
+	labels := make(map[string]string, len(weeks))
+	numbers := make(map[int]string, len(weeks))
+	var collisions []string
+	for _, week := range weeks {
+		if other, ok := keys[week.Key()]; ok {
+			collisions = append(collisions, fmt.Sprintf("start date %s (%q and %q)", week.Key(), other, week.Label))
+		}
+		if other, ok := labels[week.Label]; ok {
+			collisions = append(collisions, fmt.Sprintf("label %q (weeks starting %s and %s)", week.Label, other, week.Key()))
+		}
+		if other, ok := numbers[week.WeekNumber]; ok {
+			collisions = append(collisions, fmt.Sprintf("week number %d (weeks starting %s and %s)", week.WeekNumber, other, week.Key()))
+		}
+		keys[week.Key()] = week.Label
+		labels[week.Label] = week.Key()
+		numbers[week.WeekNumber] = week.Key()
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("weeks collide on %s", strings.Join(collisions, "; "))
+	}
+	return nil
+}
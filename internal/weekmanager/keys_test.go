@@ -0,0 +1,65 @@
+package weekmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLegacyOutputFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		number int
+		suffix string
+		ok     bool
+	}{
+		{"kids_reports_week_3.json", "kids_reports", 3, ".json", true},
+		{"kids_reports_week_3.json.gz", "kids_reports", 3, ".json.gz", true},
+		{"kids_analysis_week_12.partial.json.zst", "kids_analysis", 12, ".partial.json.zst", true},
+		{"kids_reports_week_3", "kids_reports", 3, "", true}, // Render directory
+		{"kids_reports_week_2025-10-06.json", "", 0, "", false},
+		{"kids_reports_week_3.csv", "", 0, "", false},
+		{"kids_reports.json", "", 0, "", false},
+	}
+	for _, tt := range tests {
+		prefix, number, suffix, ok := ParseLegacyOutputFile(tt.name)
+		if prefix != tt.prefix || number != tt.number || suffix != tt.suffix || ok != tt.ok {
+			t.Errorf("ParseLegacyOutputFile(%q) = %q, %d, %q, %v; want %q, %d, %q, %v",
+				tt.name, prefix, number, suffix, ok, tt.prefix, tt.number, tt.suffix, tt.ok)
+		}
+	}
+}
+
+func TestOutputNameRoundTrip(t *testing.T) {
+	week := WeekRange{StartDate: time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)}
+	if got := week.OutputFile("kids_reports"); got != "kids_reports_week_2025-10-06.json" {
+		t.Errorf("OutputFile = %q", got)
+	}
+
+	// A legacy name keeps its prefix and suffix when renamed to the week's key
+	prefix, _, suffix, ok := ParseLegacyOutputFile("kids_analysis_week_3.partial.json.gz")
+	if !ok {
+		t.Fatal("legacy name not recognized")
+	}
+	if got := OutputName(prefix, week.Key(), suffix); got != "kids_analysis_week_2025-10-06.partial.json.gz" {
+		t.Errorf("OutputName = %q", got)
+	}
+	if _, _, _, ok := ParseLegacyOutputFile(OutputName(prefix, week.Key(), suffix)); ok {
+		t.Error("a start-date name must not parse as legacy")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	start, err := ParseKey("2025-10-06")
+	if err != nil {
+		t.Fatalf("ParseKey: %v", err)
+	}
+	if got := (WeekRange{StartDate: start}).Key(); got != "2025-10-06" {
+		t.Errorf("Key = %q after ParseKey", got)
+	}
+	for _, key := range []string{"3", "06/10/2025", "2025-13-01", ""} {
+		if _, err := ParseKey(key); err == nil {
+			t.Errorf("ParseKey(%q) succeeded, want an error", key)
+		}
+	}
+}
@@ -3,8 +3,11 @@ package weekmanager
 import (
 	"database/sql"
 	"fmt"
+	"sort"
 	"time"
 
+	"ai-production-pipeline/internal/config"
+
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
@@ -15,74 +18,241 @@ type WeekRange struct {
 	Label      string
 	StartDate  time.Time
 	EndDate    time.Time
+	SchoolWeek int       // Week number within the semester (0 when no calendar is configured)
+	IsHoliday  bool      // True for weeks listed as holidays in the school calendar
+	IsPartial  bool      // True when the week has not ended yet (week-to-date)
+	AsOf       time.Time // Cut-off time for partial weeks
 }
 
 // WeekManager handles automatic week calculation from database
 type WeekManager struct {
-	db     *sql.DB
-	logger *logrus.Logger
+	db           *sql.DB
+	logger       *logrus.Logger
+	calendar     config.CalendarConfig
+	detection    detection
+	detectionErr error // Reported by GetAvailableWeeks
 }
 
-func NewWeekManager(db *sql.DB, logger *logrus.Logger) *WeekManager {
+func NewWeekManager(db *sql.DB, logger *logrus.Logger, calendar config.CalendarConfig) *WeekManager {
+	d, err := parseDetection(calendar)
 	return &WeekManager{
-		db:     db,
-		logger: logger,
+		db:           db,
+		logger:       logger,
+		calendar:     calendar,
+		detection:    d,
+		detectionErr: err,
 	}
 }
 
 // GetAvailableWeeks gets all distinct weeks from database data
 func (wm *WeekManager) GetAvailableWeeks() ([]WeekRange, error) {
-	query := `
-		SELECT DISTINCT 
-			DATE_TRUNC('week', created_at)::date as week_start
-		FROM wallet_transactions
-		WHERE created_at >= '2025-10-01'
-		ORDER BY week_start ASC
-	`
-
-	rows, err := wm.db.Query(query)
+	if wm.detectionErr != nil {
+		return nil, wm.detectionErr
+	}
+
+	rows, err := wm.db.Query(wm.detection.query(), wm.detection.anchor.Format("2006-01-02"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query weeks: %w", err)
 	}
 	defer rows.Close()
 
-	var weeks []WeekRange
-	weekNum := 1
-
+	var weekStarts []time.Time
 	for rows.Next() {
 		var weekStart time.Time
 		if err := rows.Scan(&weekStart); err != nil {
 			return nil, fmt.Errorf("failed to scan week: %w", err)
 		}
+		weekStarts = append(weekStarts, weekStart)
+	}
 
-		// Calculate week end (7 days later)
-		weekEnd := weekStart.AddDate(0, 0, 7)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating weeks: %w", err)
+	}
 
-		// Format label
-		label := fmt.Sprintf("Tuần %d - Tháng %02d/2025", weekNum, weekStart.Month())
+	weeks, err := wm.buildWeeks(weekStarts)
+	if err != nil {
+		return nil, err
+	}
+	wm.logger.Infof("📅 Found %d weeks in database", len(weeks))
+	wm.logWeeks(weeks)
+	return weeks, nil
+}
 
-		weeks = append(weeks, WeekRange{
-			WeekNumber: weekNum,
-			Label:      label,
-			StartDate:  weekStart,
-			EndDate:    weekEnd,
-		})
+// WeeksFromActivity builds the weeks like GetAvailableWeeks from activity times instead of the
+// database: activity returns the created_at times of a detection source table (calendar.source_tables)
+func (wm *WeekManager) WeeksFromActivity(activity func(table string) []time.Time) ([]WeekRange, error) {
+	if wm.detectionErr != nil {
+		return nil, wm.detectionErr
+	}
 
-		weekNum++
+	seen := make(map[time.Time]bool)
+	var weekStarts []time.Time
+	for _, table := range wm.detection.tables {
+		for _, t := range activity(table) {
+			if t.Before(wm.detection.anchor) {
+				continue
+			}
+			weekStart := wm.detection.weekStartOf(t)
+			if !seen[weekStart] {
+				seen[weekStart] = true
+				weekStarts = append(weekStarts, weekStart)
+			}
+		}
 	}
+	sort.Slice(weekStarts, func(i, j int) bool { return weekStarts[i].Before(weekStarts[j]) })
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating weeks: %w", err)
+	weeks, err := wm.buildWeeks(weekStarts)
+	if err != nil {
+		return nil, err
 	}
+	wm.logger.Infof("📅 Found %d weeks in fixtures", len(weeks))
+	wm.logWeeks(weeks)
+	return weeks, nil
+}
 
-	wm.logger.Infof("📅 Found %d weeks in database", len(weeks))
+// buildWeeks numbers and labels the weeks starting on weekStarts (ascending) and marks the one in progress
+func (wm *WeekManager) buildWeeks(weekStarts []time.Time) ([]WeekRange, error) {
+	var weeks []WeekRange
+	if wm.calendar.SemesterStart != "" {
+		var err error
+		weeks, err = wm.buildSchoolWeeks(weekStarts)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for i, weekStart := range weekStarts {
+			weekNum := i + 1
+			weeks = append(weeks, WeekRange{
+				WeekNumber: weekNum,
+				Label:      fmt.Sprintf("Tuần %d - Tháng %02d/%d", weekNum, weekStart.Month(), weekStart.Year()),
+				StartDate:  weekStart,
+				EndDate:    weekStart.AddDate(0, 0, 7), // Calculate week end (7 days later)
+			})
+		}
+	}
+
+	// Weeks key tracking, checkpoints and stored rows by label, so no two may share one
+	disambiguateLabels(weeks)
+
+	// Mark the in-progress week as partial
+	now := time.Now()
+	for i := range weeks {
+		if weeks[i].EndDate.After(now) {
+			weeks[i].IsPartial = true
+			weeks[i].AsOf = now
+		}
+	}
+
+	return weeks, nil
+}
+
+// logWeeks lists the detected weeks
+func (wm *WeekManager) logWeeks(weeks []WeekRange) {
 	for _, w := range weeks {
+		if w.IsPartial {
+			wm.logger.Infof("   %s: %s to %s (partial, as of %s)", w.Label, w.StartDate.Format("2006-01-02"),
+				w.EndDate.Format("2006-01-02"), w.AsOf.Format("2006-01-02 15:04"))
+			continue
+		}
 		wm.logger.Infof("   %s: %s to %s", w.Label, w.StartDate.Format("2006-01-02"), w.EndDate.Format("2006-01-02"))
 	}
+}
+
+// buildSchoolWeeks numbers weeks from the semester start, skipping holiday weeks in the count
+func (wm *WeekManager) buildSchoolWeeks(weekStarts []time.Time) ([]WeekRange, error) {
+	semesterStart, err := time.Parse("2006-01-02", wm.calendar.SemesterStart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar.semester_start %q: %w", wm.calendar.SemesterStart, err)
+	}
+	semesterWeek := wm.detection.weekStartOf(semesterStart)
+
+	holidays := make(map[string]bool)
+	for _, h := range wm.calendar.HolidayWeeks {
+		holidayDate, err := time.Parse("2006-01-02", h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar.holiday_weeks entry %q: %w", h, err)
+		}
+		holidays[wm.detection.weekStartOf(holidayDate).Format("2006-01-02")] = true
+	}
+
+	var weeks []WeekRange
+	weekNum := 1
+	for _, weekStart := range weekStarts {
+		firstDay := wm.detection.weekStartOf(weekStart)
+		week := WeekRange{
+			StartDate: weekStart,
+			EndDate:   weekStart.AddDate(0, 0, 7),
+			IsHoliday: holidays[firstDay.Format("2006-01-02")],
+		}
+
+		if week.IsHoliday && wm.calendar.ExcludeHolidays {
+			wm.logger.Infof("   ⏭️  Skipping holiday week starting %s", weekStart.Format("2006-01-02"))
+			continue
+		}
+
+		// Count school weeks from the semester start, holidays don't advance the count
+		if !firstDay.Before(semesterWeek) {
+			schoolWeek := 1
+			for d := semesterWeek; d.Before(firstDay); d = d.AddDate(0, 0, 7) {
+				if !holidays[d.Format("2006-01-02")] {
+					schoolWeek++
+				}
+			}
+			if !week.IsHoliday {
+				week.SchoolWeek = schoolWeek
+			}
+		}
+
+		week.WeekNumber = weekNum
+		switch {
+		case week.IsHoliday:
+			week.Label = fmt.Sprintf("Tuần nghỉ lễ - Tháng %02d/%d", weekStart.Month(), weekStart.Year())
+		case week.SchoolWeek > 0:
+			week.Label = fmt.Sprintf("Tuần %d - Tháng %02d/%d", week.SchoolWeek, weekStart.Month(), weekStart.Year())
+		default:
+			week.Label = fmt.Sprintf("Trước học kỳ - Tháng %02d/%d", weekStart.Month(), weekStart.Year())
+		}
+
+		weeks = append(weeks, week)
+		weekNum++
+	}
 
 	return weeks, nil
 }
 
+// GetWeekToDate returns the current (partial) week up to now, with the two previous full weeks as history
+func (wm *WeekManager) GetWeekToDate(now time.Time) *WeekData {
+	weekStart := wm.detection.weekStartOf(now)
+	// Queries use an exclusive end date, so end tomorrow to include today's activity
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	previous := WeekRange{
+		WeekNumber: 2,
+		Label:      fmt.Sprintf("Tuần trước - Tháng %02d/%d", weekStart.AddDate(0, 0, -7).Month(), weekStart.AddDate(0, 0, -7).Year()),
+		StartDate:  weekStart.AddDate(0, 0, -7),
+		EndDate:    weekStart,
+	}
+	twoWeeksAgo := WeekRange{
+		WeekNumber: 1,
+		Label:      fmt.Sprintf("Hai tuần trước - Tháng %02d/%d", weekStart.AddDate(0, 0, -14).Month(), weekStart.AddDate(0, 0, -14).Year()),
+		StartDate:  weekStart.AddDate(0, 0, -14),
+		EndDate:    weekStart.AddDate(0, 0, -7),
+	}
+
+	return &WeekData{
+		CurrentWeek: WeekRange{
+			WeekNumber: 3,
+			Label:      fmt.Sprintf("Tuần hiện tại (đến %s)", now.Format("02/01/2006")),
+			StartDate:  weekStart,
+			EndDate:    today.AddDate(0, 0, 1),
+			IsPartial:  true,
+			AsOf:       now,
+		},
+		PreviousWeek: &previous,
+		TwoWeeksAgo:  &twoWeeksAgo,
+	}
+}
+
 // GetWeekData returns data for specific week with historical context
 func (wm *WeekManager) GetWeekData(currentWeek WeekRange, allWeeks []WeekRange) *WeekData {
 	data := &WeekData{
@@ -126,6 +296,21 @@ func (wd *WeekData) HasTwoWeeksHistory() bool {
 	return wd.PreviousWeek != nil && wd.TwoWeeksAgo != nil
 }
 
+// ElapsedFraction returns the share of the week covered so far (1 for complete weeks)
+func (wr *WeekRange) ElapsedFraction() float64 {
+	if !wr.IsPartial {
+		return 1
+	}
+	fraction := wr.AsOf.Sub(wr.StartDate).Hours() / (7 * 24)
+	if fraction <= 0 {
+		return 1.0 / 7
+	}
+	if fraction > 1 {
+		return 1
+	}
+	return fraction
+}
+
 // FormatDateRange formats date range for SQL queries
 func (wr *WeekRange) FormatDateRange() (string, string) {
 	return wr.StartDate.Format("2006-01-02"), wr.EndDate.Format("2006-01-02")
@@ -3,17 +3,42 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"ai-production-pipeline/internal/apispec"
+	"ai-production-pipeline/internal/bronze"
+	"ai-production-pipeline/internal/buildinfo"
+	"ai-production-pipeline/internal/categorize"
+	"ai-production-pipeline/internal/checkpoint"
 	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/delivery"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/fixtures"
 	"ai-production-pipeline/internal/gold"
+	pipelinelogger "ai-production-pipeline/internal/logger"
+	"ai-production-pipeline/internal/monthly"
+	"ai-production-pipeline/internal/outputstore"
+	"ai-production-pipeline/internal/pipeline"
 	"ai-production-pipeline/internal/processor"
+	"ai-production-pipeline/internal/progress"
+	"ai-production-pipeline/internal/redact"
+	"ai-production-pipeline/internal/runlock"
+	"ai-production-pipeline/internal/schedule"
+	"ai-production-pipeline/internal/schema"
 	"ai-production-pipeline/internal/silver"
+	"ai-production-pipeline/internal/telemetry"
+	"ai-production-pipeline/internal/unitofwork"
 	"ai-production-pipeline/internal/weekmanager"
 
 	"github.com/joho/godotenv"
@@ -21,64 +46,188 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func main() {
-	// Setup signal handling for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// runOptions holds command-line options for a pipeline run
+type runOptions struct {
+	Limit  int
+	Sample float64
+	Seed   int64
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	CampaignFile string // Overrides prompts.extra_context_file for this run
+	Order        string // Week processing order: oldest-first or newest-first
+	Redeliver    bool   // Deliver reports again even if the ledger says they were sent
+	Fresh        bool   // Regenerate every kid even if the week's output already has its report
+	Resume       bool   // Skip weeks and kid reports an interrupted run already checkpointed
+	NoCache      bool   // Bypass openai.response_cache for this run
+	Granularity  string // Report period: week (default) or month
 
-	go func() {
-		<-sigChan
-		fmt.Println("\n🛑 Received interrupt signal, shutting down gracefully...")
-		cancel()
-	}()
+	// Week selection (pipeline report / backfill); numbering and history still use all weeks
+	WeekStart string    // Only the week starting on this date (YYYY-MM-DD)
+	LastWeek  bool      // Only the latest week
+	Completed bool      // Leave out the in-progress week, as if silver.partial_week_mode were skip (serve --schedule)
+	From, To  time.Time // Only weeks overlapping [From, To]
 
-	// Run the application
-	if err := runAutomatedPipeline(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
-		os.Exit(1)
+	FromBronze bool   // Run Silver on each week's latest bronze snapshot instead of the live tables
+	Fixtures   string // Read source rows from this fixtures directory and use the mock AI; no database
+
+	ProfileID string // Only this kid, merged into the week's existing output (report --profile-id)
+	Stream    bool   // Print the kid's report to stderr as it is generated (report --profile-id --stream)
+
+	logger *logrus.Logger // Log here instead of opening a new log file per run (serve --schedule)
+}
+
+// selects reports whether week is part of the run's week selection (every week when none is set)
+func (o runOptions) selects(week weekmanager.WeekRange, isLast bool) bool {
+	switch {
+	case o.WeekStart != "" && week.Key() != o.WeekStart:
+		return false
+	case o.LastWeek && !isLast:
+		return false
+	case !o.From.IsZero() && !week.EndDate.After(o.From):
+		return false
+	case !o.To.IsZero() && week.StartDate.After(o.To):
+		return false
 	}
+	return true
+}
+
+// measuresSLO reports whether the run's deliveries count toward the report availability SLO: backfills,
+// bronze replays, single-kid reports and --fresh regenerations redo weeks already delivered
+func (o runOptions) measuresSLO() bool {
+	return o.From.IsZero() && o.To.IsZero() && !o.FromBronze && o.ProfileID == "" && !o.Fresh
+}
+
+// Week processing orders (--order)
+const (
+	orderOldestFirst = "oldest-first"
+	orderNewestFirst = "newest-first"
+)
+
+// Report periods (--granularity)
+const (
+	granularityWeek  = "week"
+	granularityMonth = "month"
+)
+
+func main() {
+	os.Exit(runCommand(os.Args[1:]))
+}
+
+// runResult is what a run, backfill or report prints with --output json
+type runResult struct {
+	Granularity string                  `json:"granularity"`
+	Weeks       []string                `json:"weeks,omitempty"`   // Selected weeks, in processing order
+	Summary     *progress.StatusSummary `json:"summary,omitempty"` // Final run status (weekly runs that got to processing)
+	Report      *gold.AIReport          `json:"report,omitempty"`  // The kid's new report (report --profile-id)
 }
 
-func runAutomatedPipeline(ctx context.Context) error {
+// errRunDeferred is returned when weeks had prompts queued while the AI provider was down, so the
+// run exits non-zero until flush-deferred sends them
+var errRunDeferred = errors.New("prompts queued while the AI provider was down")
+
+// runAutomatedPipeline runs the selected weeks; result is filled in as far as the run got
+func runAutomatedPipeline(ctx context.Context, opts runOptions, result *runResult) error {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
-		fmt.Println("⚠️  No .env file found, using system environment variables")
+		fmt.Fprintln(os.Stderr, "⚠️  No .env file found, using system environment variables")
+	}
+	result.Granularity = granularityWeek
+	if opts.Granularity != "" {
+		result.Granularity = opts.Granularity
 	}
 
 	// Load configuration
-	cfg, err := config.LoadConfig("config/config.yaml")
+	configPath := "config/config.yaml"
+	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if opts.CampaignFile != "" {
+		cfg.Prompts.ExtraContextFile = opts.CampaignFile
+	}
+	if opts.Fresh && opts.Resume {
+		return fmt.Errorf("--fresh and --resume cannot be combined")
+	}
+	if opts.Fresh {
+		cfg.Gold.ReuseExisting = false
+	}
+	if opts.Granularity != "" && opts.Granularity != granularityWeek && opts.Granularity != granularityMonth {
+		return fmt.Errorf("--granularity must be week or month, got %q", opts.Granularity)
+	}
+	if opts.ProfileID != "" && opts.Granularity == granularityMonth {
+		return fmt.Errorf("--profile-id generates a weekly report and cannot be combined with --granularity=month")
+	}
+	if opts.FromBronze && (opts.Granularity == granularityMonth || opts.ProfileID != "") {
+		return fmt.Errorf("--from-bronze replays weekly runs and cannot be combined with --granularity=month or --profile-id")
+	}
+	if opts.Fixtures != "" && (opts.FromBronze || opts.Granularity == granularityMonth) {
+		return fmt.Errorf("--fixtures cannot be combined with --from-bronze or --granularity=month")
+	}
+	if opts.NoCache {
+		cfg.OpenAI.ResponseCache.Enabled = false
+	}
+	if opts.Stream {
+		if opts.ProfileID == "" {
+			return fmt.Errorf("--stream needs --profile-id")
+		}
+		cfg.OpenAI.Stream = true
+	}
 
 	// Setup logger
-	logger := setupLogger(cfg)
+	logger := opts.logger
+	if logger == nil {
+		logger = setupLogger(cfg)
+	}
 	logger.Info("=" + repeatString("=", 100))
 	logger.Info("🚀 AUTOMATED AI PRODUCTION PIPELINE - MULTI-WEEK ANALYSIS")
 	logger.Info("=" + repeatString("=", 100))
 
-	// Get OpenAI API key
-	apiKey := os.Getenv("OPENAI_API_KEY")
+	// Fingerprint of binary, config and prompts, stamped into every artifact
+	metadata := buildinfo.Collect(cfg, configPath)
+	metadata.LogBanner(logger)
+
+	// Fixture runs replace the database with JSON files and the AI provider with the mock
+	var fixtureSet *fixtures.Set
+	if opts.Fixtures != "" {
+		if fixtureSet, err = setupFixtures(ctx, cfg, opts.Fixtures, logger); err != nil {
+			return fmt.Errorf("failed to load fixtures: %w", err)
+		}
+	}
+
+	// Get the AI provider's API key
+	keyEnv := processor.APIKeyEnv(cfg.OpenAI.Provider)
+	apiKey := os.Getenv(keyEnv)
 	if apiKey == "" {
-		return fmt.Errorf("OPENAI_API_KEY environment variable is required")
+		return fmt.Errorf("%s environment variable is required", keyEnv)
 	}
 
 	// Connect to database
-	db, err := connectDatabase(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+	var db *sql.DB
+	if fixtureSet == nil {
+		if db, err = connectDatabase(cfg); err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+	}
+
+	// Fail early if source tables drifted from what the queries expect
+	if cfg.Database.CheckSchema {
+		if err := schema.Check(db, logger, requiredColumns(cfg)); err != nil {
+			return err
+		}
 	}
-	defer db.Close()
 
 	// Initialize Week Manager
-	weekMgr := weekmanager.NewWeekManager(db, logger)
+	weekMgr := weekmanager.NewWeekManager(db, logger, cfg.Calendar)
 
 	// Get all available weeks from database
-	logger.Info("📅 Detecting available weeks from database...")
-	weeks, err := weekMgr.GetAvailableWeeks()
+	var weeks []weekmanager.WeekRange
+	if fixtureSet != nil {
+		logger.Info("📅 Detecting available weeks from fixtures...")
+		weeks, err = weekMgr.WeeksFromActivity(fixtureSet.CreatedAt)
+	} else {
+		logger.Info("📅 Detecting available weeks from database...")
+		weeks, err = weekMgr.GetAvailableWeeks()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get available weeks: %w", err)
 	}
@@ -88,6 +237,10 @@ func runAutomatedPipeline(ctx context.Context) error {
 	}
 
 	logger.Infof("✅ Found %d weeks of data", len(weeks))
+	if err := weekmanager.CheckCollisions(weeks); err != nil {
+		return err
+	}
+	warnLegacyWeekFiles(logger, cfg.Data.OutputDir)
 
 	// Check if we should only process the last week (for testing)
 	testMode := os.Getenv("TEST_LAST_WEEK_ONLY")
@@ -97,23 +250,336 @@ func runAutomatedPipeline(ctx context.Context) error {
 		weeks = []weekmanager.WeekRange{lastWeek}
 	}
 
+	// Handle the in-progress (partial) week
+	if (cfg.Silver.PartialWeekMode == "skip" || opts.Completed) && len(weeks) > 0 && weeks[len(weeks)-1].IsPartial {
+		logger.Warnf("⏭️  Skipping partial week %s (silver.partial_week_mode=skip or a scheduled run)", weeks[len(weeks)-1].Label)
+		weeks = weeks[:len(weeks)-1]
+		if len(weeks) == 0 {
+			return fmt.Errorf("no complete weeks to process")
+		}
+	}
+
+	// Weeks to process, in processing order (report --week and backfill select a subset)
+	var order []int
+	for _, i := range weekOrder(len(weeks), opts.Order) {
+		if opts.selects(weeks[i], i == len(weeks)-1) {
+			order = append(order, i)
+		}
+	}
+	if len(order) == 0 {
+		return fmt.Errorf("no weeks match the selection (%d weeks available)", len(weeks))
+	}
+	if len(order) < len(weeks) {
+		logger.Infof("📌 Processing %d of %d weeks", len(order), len(weeks))
+	}
+	for _, i := range order {
+		result.Weeks = append(result.Weeks, weeks[i].Label)
+	}
+	first, last := order[0], order[len(order)-1]
+	if first > last {
+		first, last = last, first
+	}
+
+	// Identifies the run in the cost ledger and names its bronze replay schema
+	runID := newRunID()
+
+	// Bronze snapshots of the source rows; with --from-bronze, Silver reads a restored snapshot instead
+	var bronzeLayer *bronze.Layer
+	sourceDB := db
+	if cfg.Bronze.Enabled || opts.FromBronze {
+		if bronzeLayer, err = bronze.NewLayer(db, logger, cfg.Bronze, cfg.Data.CompressionCodec()); err != nil {
+			return err
+		}
+		bronzeLayer.SetMetadata(metadata)
+		bronzeLayer.AddTables(bronze.SourceTables(cfg)...)
+		if opts.FromBronze {
+			if err := bronzeLayer.SetRunID(runID); err != nil {
+				return err
+			}
+			defer func() {
+				if err := bronzeLayer.DropReplaySchema(context.Background()); err != nil {
+					logger.Warnf("⚠️  %v", err)
+				}
+			}()
+			replayDB, err := connectReplayDatabase(cfg, bronzeLayer.ReplaySchema())
+			if err != nil {
+				return fmt.Errorf("failed to connect to the bronze replay schema: %w", err)
+			}
+			defer replayDB.Close()
+			sourceDB = replayDB
+			logger.Warnf("🥉 --from-bronze: Silver reads each week's snapshot from schema %s, not the live tables", bronzeLayer.ReplaySchema())
+		}
+	}
+
 	// Initialize Silver Layer
-	silverLayer := silver.NewSilverLayer(db, logger)
+	silverLayer := silver.NewSilverLayer(sourceDB, logger, cfg.Silver)
+	silverLayer.SetCompression(cfg.Data.CompressionCodec())
+	silverLayer.SetMetadata(metadata)
+	if fixtureSet != nil {
+		silverLayer.SetFixtures(fixtureSet)
+	}
+	// A replay must neither read live-computed weeks nor overwrite them with the snapshot's
+	if cfg.Silver.MetricStore.Enabled && opts.FromBronze {
+		logger.Infof("🥉 --from-bronze: the metric store is off for this run")
+	} else if cfg.Silver.MetricStore.Enabled {
+		store, err := silver.NewMetricStore(db, logger, cfg.Silver)
+		if err != nil {
+			logger.Warnf("⚠️  Metric store unavailable, recomputing history from transactions: %v", err)
+		} else {
+			silverLayer.SetMetricStore(store, cfg.Silver.MetricStore.LookbackWeeks)
+		}
+	}
+	if cfg.Gold.OptionalSections.Enabled {
+		silverLayer.SetSectionPreferences(cfg.Gold.OptionalSections.Table)
+	}
+	if cfg.Gold.OperatorNotes.Enabled {
+		silverLayer.SetOperatorNotes(cfg.Gold.OperatorNotes)
+	}
+	silverLayer.SetKidSelection(silver.KidSelection{
+		Limit:         opts.Limit,
+		SamplePercent: opts.Sample,
+		Seed:          opts.Seed,
+	})
 
 	// Initialize Gold Layer (for AI reports)
-	goldLayer, err := gold.NewGoldLayer(cfg, logger)
+	goldLayer, err := newGoldLayer(cfg, logger, db)
+	if err != nil {
+		return err
+	}
+	metadata.TemplateHash = goldLayer.TemplateHash() // Covers every loaded template, not just the file
+
+	// Silver and Gold rows are written in each week's transaction, next to the files
+	var outputs *outputstore.Store
+	var uowDB *sql.DB
+	if cfg.Data.DatabaseOutput.Enabled {
+		outputs, err = outputstore.NewStore(db, logger, cfg.Data.DatabaseOutput.SilverTable, cfg.Data.DatabaseOutput.GoldTable)
+		if err != nil {
+			return fmt.Errorf("failed to initialize database output: %w", err)
+		}
+		if err := outputs.SetWriteBatching(writeBatching(cfg)); err != nil {
+			return err
+		}
+		goldLayer.SetOutputStore(outputs)
+		uowDB = db
+	}
+
+	// The run's AI usage goes to the cost ledger however the run ends
+	costLedger, err := openCostLedger(cfg, logger, db)
+	if err != nil {
+		return err
+	}
+	defer recordCosts(costLedger, logger, runID, goldLayer, weeks)
+
+	// Fail fast on a bad key, model name or missing JSON mode instead of after Silver
+	if cfg.OpenAI.Preflight {
+		if err := goldLayer.Preflight(ctx); err != nil {
+			return err
+		}
+		if cfg.Categorization.Enabled {
+			if err := goldLayer.GetAIProcessor().Preflight(ctx, cfg.Categorization.Model); err != nil {
+				return fmt.Errorf("categorization model: %w", err)
+			}
+		}
+		if cfg.Gold.ParentDigest.Enabled {
+			if err := goldLayer.GetAIProcessor().Preflight(ctx, cfg.Gold.ParentDigest.Model); err != nil {
+				return fmt.Errorf("parent digest model: %w", err)
+			}
+		}
+		if cfg.Gold.KidVersion.Enabled && cfg.Gold.KidVersion.Mode == gold.KidVersionAI {
+			if err := goldLayer.GetAIProcessor().Preflight(ctx, cfg.Gold.KidVersion.Model); err != nil {
+				return fmt.Errorf("kid version model: %w", err)
+			}
+		}
+		if cfg.Gold.FamilyReport.Enabled && cfg.Gold.FamilyReport.Model != "" {
+			if err := goldLayer.GetAIProcessor().Preflight(ctx, cfg.Gold.FamilyReport.Model); err != nil {
+				return fmt.Errorf("family report model: %w", err)
+			}
+		}
+	}
+
+	// Optional stage before Silver: categorize spending descriptions with the cheap model
+	if cfg.Categorization.Enabled {
+		categorizer, err := categorize.NewCategorizer(db, goldLayer.GetAIProcessor(), logger, cfg.Categorization)
+		if err != nil {
+			return fmt.Errorf("failed to initialize categorization: %w", err)
+		}
+		// Include the two history weeks Silver compares against
+		from := weeks[first].StartDate.AddDate(0, 0, -14)
+		to := weeks[last].EndDate
+		if _, err := categorizer.Run(ctx, from, to); err != nil {
+			logger.Warnf("⚠️  Transaction categorization failed, continuing without spending categories: %v", err)
+		} else {
+			silverLayer.SetCategoryTable(categorizer.Table())
+		}
+	}
+
+	// Monthly rollups and reports replace the weekly run
+	if opts.Granularity == granularityMonth {
+		err := runMonthly(ctx, cfg, logger, metadata, weekMgr, weeks, order, silverLayer, goldLayer, outputs)
+		printTokenReports(goldLayer)
+		return err
+	}
+
+	// One kid's report, merged into the week's existing output
+	if opts.ProfileID != "" {
+		goldLayer.SetMetadata(metadata)
+		if opts.Stream {
+			ctx = processor.WithStreamHandler(ctx, func(delta string) { fmt.Fprint(os.Stderr, delta) })
+		}
+		result.Report, err = runKidReport(ctx, cfg, logger, db, weekMgr, weeks, order[0], silverLayer, goldLayer, opts.ProfileID)
+		printTokenReports(goldLayer)
+		return err
+	}
+
+	// Delivery of finished weeks (at most once per report version, tracked in the database)
+	var deliverer *delivery.Deliverer
+	if cfg.Delivery.Enabled {
+		var ledger *delivery.Ledger
+		if db != nil {
+			if ledger, err = delivery.NewLedger(db, logger, cfg.Delivery.LedgerTable); err != nil {
+				return fmt.Errorf("failed to initialize delivery ledger: %w", err)
+			}
+		}
+		sender, err := delivery.NewOutboxSender(cfg.Delivery.OutboxDir)
+		if err != nil {
+			return fmt.Errorf("failed to initialize delivery: %w", err)
+		}
+		deliverer = delivery.NewDeliverer(ledger, sender, logger)
+		pendingTimeout := time.Hour
+		if cfg.Delivery.PendingTimeout != "" {
+			if pendingTimeout, err = time.ParseDuration(cfg.Delivery.PendingTimeout); err != nil {
+				return fmt.Errorf("invalid delivery.pending_timeout %q: %w", cfg.Delivery.PendingTimeout, err)
+			}
+		}
+		if err := deliverer.Reconcile(ctx, pendingTimeout); err != nil {
+			logger.Warnf("⚠️  Could not reconcile unconfirmed deliveries: %v", err)
+		}
+		if opts.Redeliver {
+			logger.Warn("⚠️  --redeliver: reports are delivered again even if sent before")
+		}
+	}
+
+	// Parent-readable HTML/PDF copies of finished weeks
+	var renderer *gold.ReportRenderer
+	if cfg.Gold.Render.Enabled {
+		if renderer, err = gold.NewReportRenderer(cfg.Gold.Render); err != nil {
+			return fmt.Errorf("failed to initialize report rendering: %w", err)
+		}
+	}
+
+	// Soft-stop deadline: stop starting new kids when reached, but let in-flight calls finish
+	softCtx := ctx
+	if cfg.Run.MaxDuration != "" {
+		maxDuration, err := time.ParseDuration(cfg.Run.MaxDuration)
+		if err != nil {
+			return fmt.Errorf("invalid run.max_duration %q: %w", cfg.Run.MaxDuration, err)
+		}
+		var softCancel context.CancelFunc
+		softCtx, softCancel = context.WithTimeout(ctx, maxDuration)
+		defer softCancel()
+		goldLayer.SetSoftStop(softCtx)
+		logger.Infof("⏰ Run deadline: %s (at %s)", maxDuration, time.Now().Add(maxDuration).Format("15:04:05"))
+	}
+	var deferredWeeks []string
+	var queuedWeeks []string // Weeks with prompts queued while the AI provider was down
+
+	// One run per week at a time across instances sharing the database
+	locker, err := runlock.NewLocker(db, logger, cfg.Run.Lock)
+	if err != nil {
+		return err
+	}
+	var weekLock *runlock.Lock
+	defer func() { weekLock.Release() }()
+
+	// Track run progress (persisted for restarts, optionally served over HTTP)
+	tracker := progress.NewTracker(cfg.Status.StateFile, time.Duration(cfg.Status.PersistIntervalSeconds)*time.Second, logger)
+	tracker.SetSummaryPath(cfg.Status.SummaryFile)
+	tracker.SetMetricsPath(cfg.Status.MetricsFile)
+	if cfg.Status.SLO.Enabled {
+		target, objective, err := cfg.Status.SLO.Settings()
+		if err != nil {
+			return err
+		}
+		tracker.SetSLO(target, objective, cfg.Status.SLO.HistoryFile)
+	}
+	silverLayer.SetProgressTracker(tracker)
+	goldLayer.SetProgressTracker(tracker)
+	goldLayer.SetMetadata(metadata)
+
+	// Completed weeks and generated reports are checkpointed; only --resume reads them back. Without it,
+	// each week's checkpoints are cleared once the week's lock is held, so other weeks' stay intact.
+	checkpoints, err := checkpoint.NewStore(cfg.Run.CheckpointDir, logger)
 	if err != nil {
-		return fmt.Errorf("failed to initialize Gold layer: %w", err)
+		return err
+	}
+	if opts.Resume {
+		logger.Infof("🔁 Resuming from checkpoints in %s", cfg.Run.CheckpointDir)
+	}
+	goldLayer.SetCheckpoint(checkpoints, opts.Resume)
+
+	tracker.Start(runID, len(order))
+	tracker.SetMetadata(metadata)
+	defer func() {
+		summary := tracker.Snapshot().Summary()
+		result.Summary = &summary
+	}()
+	go tracker.Run(ctx)
+	if cfg.Status.ListenAddr != "" {
+		startStatusServer(ctx, cfg.Status.ListenAddr, tracker, cfg.Status.Prometheus.Enabled, logger)
+	}
+	if cfg.Status.Prometheus.Enabled && cfg.Status.Prometheus.ListenAddr != "" {
+		startMetricsServer(ctx, cfg.Status.Prometheus.ListenAddr, tracker, logger)
 	}
 
-	// Process each week
-	for i, week := range weeks {
+	// Process each week (week numbers keep chronological order either way)
+	if opts.Order == orderNewestFirst {
+		logger.Info("⏪ Processing newest week first (--order newest-first)")
+	}
+	for _, i := range order {
+		week := weeks[i]
 		weekNum := i + 1
+		weekLock.Release()
+		weekLock = nil
+
+		// Deadline reached: don't start new weeks
+		if softCtx.Err() != nil && ctx.Err() == nil {
+			deferredWeeks = append(deferredWeeks, week.Label)
+			tracker.DeferWeek(week.Label)
+			continue
+		}
+
+		// Resuming: weeks an earlier run completed are not processed again
+		if opts.Resume && checkpoints.WeekDone(week.Label) {
+			logger.Infof("⏭️  Week %d (%s) already completed, skipping (--resume)", weekNum, week.Label)
+			tracker.StartWeek(week.Label)
+			tracker.FinishWeek()
+			continue
+		}
+
 		logger.Info("")
 		logger.Info("=" + repeatString("=", 100))
 		logger.Infof("📊 PROCESSING WEEK %d/%d: %s", weekNum, len(weeks), week.Label)
 		logger.Info("=" + repeatString("=", 100))
 
+		weekLock, err = locker.Acquire(ctx, week.StartDate, week.Label)
+		if errors.Is(err, runlock.ErrLocked) && locker.OnConflict() == runlock.OnConflictSkip {
+			logger.Warnf("⏭️  Skipping week %d: %v", weekNum, err)
+			continue
+		}
+		if err != nil {
+			tracker.Finish(progress.StatusFailed)
+			return fmt.Errorf("week %d: %w", weekNum, err)
+		}
+		if !opts.Resume {
+			if err := checkpoints.ClearWeek(week.Label); err != nil {
+				tracker.Finish(progress.StatusFailed)
+				return fmt.Errorf("week %d: %w", weekNum, err)
+			}
+		}
+
+		tracker.StartWeek(week.Label)
+		weekStart := time.Now()
+
 		// Get week data with historical context
 		weekData := weekMgr.GetWeekData(week, weeks)
 
@@ -130,21 +596,104 @@ func runAutomatedPipeline(ctx context.Context) error {
 			logger.Warn("⚠️  First week - no historical comparison")
 		}
 
+		if bronzeLayer != nil {
+			if err := prepareBronze(ctx, bronzeLayer, week, opts.FromBronze, logger); err != nil {
+				tracker.Finish(progress.StatusFailed)
+				return fmt.Errorf("week %d: %w", weekNum, err)
+			}
+		}
+
 		// Run Silver Layer V3: Enhanced transformation with trends
 		logger.Info("")
 		logger.Info("📂 Running Silver Layer V3: Enhanced Transformation")
-		silverOutputPath := filepath.Join(cfg.Data.OutputDir, fmt.Sprintf("kids_analysis_week_%d.json", weekNum))
-		if err := silverLayer.Transform(weekData, silverOutputPath); err != nil {
-			return fmt.Errorf("silver layer failed for week %d: %w", weekNum, err)
+		silverOutputPath := weekOutputPath(cfg, "kids_analysis", week)
+		reportOutputPath := weekOutputPath(cfg, "kids_reports", week)
+		if week.IsPartial {
+			logger.Warnf("⏳ %s is still in progress - outputs are stored as partial", week.Label)
+			silverOutputPath = silver.PartialOutputPath(silverOutputPath)
+			reportOutputPath = silver.PartialOutputPath(reportOutputPath)
 		}
 
-		// Run Gold Layer V2: AI Report Generation
-		logger.Info("")
-		logger.Info("📂 Running Gold Layer V2: AI Report Generation")
+		// Earlier weeks' parent suggestions, so reports don't repeat them
+		if cfg.Gold.SuggestionDedup.Enabled {
+			if err := goldLayer.LoadSuggestionHistory(reportHistoryPaths(cfg, weeks, i, cfg.Gold.SuggestionDedup.HistoryWeeks)...); err != nil {
+				logger.Warnf("⚠️  Could not load suggestion history: %v", err)
+			}
+		}
+
+		// Earlier weeks' names per profile ID, so a renamed kid is still reported as the same child
+		if cfg.Gold.Identity.Enabled {
+			if err := goldLayer.LoadIdentityHistory(identityHistoryPaths(cfg, weeks, i)...); err != nil {
+				logger.Warnf("⚠️  Could not load kid identity history: %v", err)
+			}
+		}
+
+		// The week's Gold writes (and database rows) commit together or not at all
+		uow, err := unitofwork.Begin(ctx, uowDB, logger)
+		if err != nil {
+			tracker.Finish(progress.StatusFailed)
+			return fmt.Errorf("failed to begin unit of work for week %d: %w", weekNum, err)
+		}
+		goldLayer.SetUnitOfWork(uow)
 
-		// Generate reports for this week
-		reportOutputPath := filepath.Join(cfg.Data.OutputDir, fmt.Sprintf("kids_reports_week_%d.json", weekNum))
-		successCount, err := goldLayer.GenerateReportsFromFile(ctx, silverOutputPath, reportOutputPath, week.Label)
+		var successCount int
+		var silverErr error
+		if cfg.Run.StreamWeeks {
+			logger.Info("📂 Gold Layer V2 runs alongside Silver (streaming)")
+			successCount, err = pipeline.StreamWeek(ctx, logger, silverLayer, goldLayer, weekData,
+				silverOutputPath, reportOutputPath, cfg.Run.StreamQueueSize)
+			var failed *pipeline.SilverError
+			if errors.As(err, &failed) {
+				silverErr = failed.Err
+			}
+		} else if silverErr = silverLayer.Transform(ctx, weekData, silverOutputPath); silverErr == nil {
+			// Run Gold Layer V2: AI Report Generation
+			logger.Info("")
+			logger.Info("📂 Running Gold Layer V2: AI Report Generation")
+			successCount, err = goldLayer.GenerateReportsFromFile(ctx, silverOutputPath, reportOutputPath, week.Label)
+		}
+		goldLayer.SetUnitOfWork(nil)
+		partial := errors.Is(err, gold.ErrSoftStopped) || errors.Is(err, gold.ErrProviderUnavailable)
+		if outputs != nil && silverErr == nil && (err == nil || partial) {
+			if storeErr := outputs.SaveSilverFile(uow.Tx(), week.Label, silverOutputPath); storeErr != nil {
+				err = storeErr
+			}
+		}
+		telemetry.WeekDuration.Since(weekStart)
+		// Interrupted (SIGINT/SIGTERM): nothing of the week is committed, and the run state stays
+		// "running" so the next run reports it as interrupted
+		if ctx.Err() != nil {
+			uow.Rollback()
+			logger.Warnf("🛑 Week %d interrupted, nothing committed", weekNum)
+			return fmt.Errorf("week %d interrupted: %w", weekNum, ctx.Err())
+		}
+		recordLayerResults(silverErr, err, partial)
+		if silverErr != nil {
+			uow.Rollback()
+			tracker.Finish(progress.StatusFailed)
+			return fmt.Errorf("silver layer failed for week %d: %w", weekNum, silverErr)
+		}
+		if err != nil && !partial {
+			uow.Rollback()
+		} else if commitErr := uow.Commit(); commitErr != nil {
+			err = commitErr
+		} else {
+			tracker.FinishWeek()
+			if tracker.SLOEnabled() && !week.IsPartial && opts.measuresSLO() {
+				recordWeekSLO(logger, tracker, week, reportOutputPath)
+			}
+		}
+		if errors.Is(err, gold.ErrProviderUnavailable) {
+			logger.Warnf("⏸️  Week %d: AI provider unavailable, %d reports generated, rest queued", weekNum, successCount)
+			logger.Infof("   📄 Gold output (partial): %s", reportOutputPath)
+			queuedWeeks = append(queuedWeeks, week.Label)
+			continue
+		}
+		if errors.Is(err, gold.ErrSoftStopped) {
+			logger.Warnf("⏰ Week %d stopped at run deadline: %d reports generated, rest deferred", weekNum, successCount)
+			logger.Infof("   📄 Gold output (partial): %s", reportOutputPath)
+			continue
+		}
 		if err != nil {
 			logger.Errorf("❌ Gold layer failed for week %d: %v", weekNum, err)
 			// Continue to next week instead of failing completely
@@ -154,69 +703,1255 @@ func runAutomatedPipeline(ctx context.Context) error {
 		logger.Infof("✅ Week %d completed: %d reports generated", weekNum, successCount)
 		logger.Infof("   📄 Silver output: %s", silverOutputPath)
 		logger.Infof("   📄 Gold output: %s", reportOutputPath)
+
+		// Short per-parent summary across all their kids, for push notifications (complete weeks only)
+		if cfg.Gold.ParentDigest.Enabled && !week.IsPartial {
+			digestPath := weekOutputPath(cfg, "kids_digests", week)
+			if _, err := goldLayer.GenerateParentDigests(ctx, reportOutputPath, digestPath, week.Label); err != nil {
+				logger.Errorf("❌ Parent digests failed for week %d: %v", weekNum, err)
+			}
+		}
+
+		// Short kid-facing version of every report, next to the parent reports
+		if cfg.Gold.KidVersion.Enabled && !week.IsPartial {
+			kidPath := weekOutputPath(cfg, "kids_kid_versions", week)
+			if _, err := goldLayer.GenerateKidVersions(ctx, reportOutputPath, kidPath, week.Label); err != nil {
+				logger.Errorf("❌ Kid versions failed for week %d: %v", weekNum, err)
+			}
+		}
+
+		// Household report comparing siblings, from the week's Silver metrics and Gold reports
+		if cfg.Gold.FamilyReport.Enabled && !week.IsPartial {
+			familyPath := weekOutputPath(cfg, "family_reports", week)
+			if _, err := goldLayer.GenerateFamilyReports(ctx, silverOutputPath, reportOutputPath, familyPath, week.Label); err != nil {
+				logger.Errorf("❌ Family reports failed for week %d: %v", weekNum, err)
+			}
+		}
+
+		if renderer != nil && !week.IsPartial {
+			if rendered, err := renderer.RenderFile(ctx, reportOutputPath); err != nil {
+				logger.Errorf("❌ Rendering failed for week %d (%d rendered): %v", weekNum, rendered, err)
+			} else {
+				logger.Infof("   🖨️  Rendered reports: %s (%d)", gold.RenderDir(reportOutputPath), rendered)
+			}
+		}
+
+		// Week-to-date reports are interim and never delivered
+		if deliverer != nil && !week.IsPartial {
+			if _, err := deliverer.DeliverFile(ctx, reportOutputPath, opts.Redeliver); err != nil {
+				logger.Errorf("❌ Delivery failed for week %d: %v", weekNum, err)
+			}
+		}
+
+		// Week-to-date outputs change as the week goes on, so only complete weeks are checkpointed
+		if !week.IsPartial {
+			if err := checkpoints.MarkWeekDone(week.Label); err != nil {
+				logger.Warnf("⚠️  %v", err)
+			}
+		}
+	}
+
+	// Provider down: the queued prompts are sent by flush-deferred
+	if len(queuedWeeks) > 0 {
+		tracker.Finish(progress.StatusDeferred)
+		logger.Info("")
+		logger.Info("=" + repeatString("=", 100))
+		logger.Warn("⏸️  PIPELINE DEFERRED: AI PROVIDER UNAVAILABLE")
+		for _, label := range queuedWeeks {
+			logger.Warnf("   📥 Queued prompts for: %s", label)
+		}
+		logger.Warn("   Run 'pipeline flush-deferred' once the provider recovers")
+		printDispositions(logger, tracker.Snapshot())
+		logger.Info("=" + repeatString("=", 100))
+		printTokenReports(goldLayer)
+		return fmt.Errorf("%w for %d weeks; run pipeline flush-deferred once it recovers", errRunDeferred, len(queuedWeeks))
+	}
+
+	// Deadline reached: exit cleanly with a partial status
+	if softCtx.Err() != nil && ctx.Err() == nil {
+		tracker.Finish(progress.StatusPartial)
+		logger.Info("")
+		logger.Info("=" + repeatString("=", 100))
+		logger.Warn("⏰ PIPELINE STOPPED AT RUN DEADLINE (PARTIAL)")
+		for _, label := range deferredWeeks {
+			logger.Warnf("   ⏭️  Deferred week: %s", label)
+		}
+		printDispositions(logger, tracker.Snapshot())
+		printSLO(logger, tracker.Snapshot())
+		logger.Info("=" + repeatString("=", 100))
+		printTokenReports(goldLayer)
+		return nil
 	}
 
+	tracker.Finish(progress.StatusCompleted)
+
 	// Final summary
 	logger.Info("")
 	logger.Info("=" + repeatString("=", 100))
 	logger.Info("🎉 AUTOMATED PIPELINE COMPLETED SUCCESSFULLY")
-	logger.Infof("📊 Processed %d weeks", len(weeks))
+	logger.Infof("📊 Processed %d weeks", len(order))
+	printDispositions(logger, tracker.Snapshot())
+	printSLO(logger, tracker.Snapshot())
 	logger.Info("=" + repeatString("=", 100))
 
 	// Print token usage and cost report
 	logger.Info("")
-	goldLayer.GetAIProcessor().PrintTokenReport()
+	printTokenReports(goldLayer)
 
 	return nil
 }
 
-// connectDatabase establishes database connection
-func connectDatabase(cfg *config.Config) (*sql.DB, error) {
-	connStr := cfg.Database.ConnectionString()
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+// weekOrder returns the indexes of weeks in processing order
+func weekOrder(count int, order string) []int {
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = i
+		if order == orderNewestFirst {
+			indexes[i] = count - 1 - i
+		}
 	}
+	return indexes
+}
 
-	if err := db.Ping(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+// printDispositions logs the kid-week counts per disposition and the reason for every kid without a report
+func printDispositions(logger *logrus.Logger, state progress.RunState) {
+	if len(state.Dispositions) == 0 {
+		return
+	}
+	names := make([]string, 0, len(state.Dispositions))
+	for name := range state.Dispositions {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	for _, name := range names {
+		logger.Infof("   %-18s %d", name, state.Dispositions[name])
+	}
+	for _, kid := range state.KidDispositions {
+		logger.Warnf("   ⚠️  %s: %s (%s) %s: %s", kid.Week, kid.Nickname, kid.ProfileID, kid.Disposition, kid.Reason)
+	}
+}
 
-	// Configure connection pool
-	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
-	db.SetConnMaxLifetime(time.Duration(cfg.Database.MaxLifetimeMin) * time.Minute)
+// runKidReport regenerates one kid's report for weeks[index] under the week's run lock, without
+// touching the week's Silver output or the other kids' reports
+func runKidReport(ctx context.Context, cfg *config.Config, logger *logrus.Logger, db *sql.DB, weekMgr *weekmanager.WeekManager,
+	weeks []weekmanager.WeekRange, index int, silverLayer *silver.SilverLayer, goldLayer *gold.GoldLayer, profileID string) (*gold.AIReport, error) {
+	week := weeks[index]
+	weekNum := index + 1
+	logger.Infof("👤 Regenerating the report of %s for week %d (%s)", profileID, weekNum, week.Label)
 
-	return db, nil
-}
+	locker, err := runlock.NewLocker(db, logger, cfg.Run.Lock)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := locker.Acquire(ctx, week.StartDate, week.Label)
+	if err != nil {
+		return nil, fmt.Errorf("week %d: %w", weekNum, err)
+	}
+	defer lock.Release()
 
-// createAIProcessor creates configured AI processor
-func createAIProcessor(cfg *config.Config, apiKey string, logger *logrus.Logger) *processor.AIProcessor {
-	processorConfig := processor.Config{
-		APIKey:             apiKey,
-		Model:              cfg.OpenAI.Model,
-		MaxTokens:          cfg.OpenAI.MaxTokens,
-		Temperature:        cfg.OpenAI.Temperature,
-		Timeout:            time.Duration(cfg.OpenAI.TimeoutSeconds) * time.Second,
-		BatchSize:          cfg.Batch.Size,
-		MaxConcurrent:      cfg.Batch.MaxConcurrent,
-		RateLimitPerMin:    cfg.RateLimit.RequestsPerMinute,
-		MaxRetries:         cfg.Retry.MaxAttempts,
-		InitialRetryDelay:  time.Duration(cfg.Retry.InitialDelaySeconds) * time.Second,
-		MaxRetryDelay:      time.Duration(cfg.Retry.MaxDelaySeconds) * time.Second,
-		ExponentialBackoff: cfg.Retry.ExponentialBackoff,
-		TrackTokenUsage:    cfg.Monitoring.TrackTokenUsage,
-		TrackTiming:        cfg.Monitoring.TrackTiming,
-		ShowProgress:       cfg.Monitoring.ShowProgress,
+	reportOutputPath := weekOutputPath(cfg, "kids_reports", week)
+	if week.IsPartial {
+		reportOutputPath = silver.PartialOutputPath(reportOutputPath)
+	}
+	if cfg.Gold.SuggestionDedup.Enabled {
+		if err := goldLayer.LoadSuggestionHistory(reportHistoryPaths(cfg, weeks, index, cfg.Gold.SuggestionDedup.HistoryWeeks)...); err != nil {
+			logger.Warnf("⚠️  Could not load suggestion history: %v", err)
+		}
+	}
+	if cfg.Gold.Identity.Enabled {
+		if err := goldLayer.LoadIdentityHistory(identityHistoryPaths(cfg, weeks, index)...); err != nil {
+			logger.Warnf("⚠️  Could not load kid identity history: %v", err)
+		}
 	}
 
-	return processor.NewAIProcessor(processorConfig, logger)
+	return pipeline.GenerateKidReport(ctx, silverLayer, goldLayer, weekMgr.GetWeekData(week, weeks), profileID, reportOutputPath)
 }
 
-// setupLogger configures and returns a logger instance
+// identityHistoryPaths lists the report files of the gold.identity.history_weeks weeks before weeks[index], newest first
+func identityHistoryPaths(cfg *config.Config, weeks []weekmanager.WeekRange, index int) []string {
+	historyWeeks := cfg.Gold.Identity.HistoryWeeks
+	if historyWeeks <= 0 {
+		historyWeeks = 4
+	}
+	return reportHistoryPaths(cfg, weeks, index, historyWeeks)
+}
+
+// reportHistoryPaths lists the report files of up to n weeks before weeks[index], newest first
+func reportHistoryPaths(cfg *config.Config, weeks []weekmanager.WeekRange, index, n int) []string {
+	var paths []string
+	for back := 1; back <= n && index-back >= 0; back++ {
+		paths = append(paths, weekOutputPath(cfg, "kids_reports", weeks[index-back]))
+	}
+	return paths
+}
+
+// weekOutputPath returns the path of the week's output file with prefix (e.g. kids_reports) in data.output_dir
+func weekOutputPath(cfg *config.Config, prefix string, week weekmanager.WeekRange) string {
+	return filepath.Join(cfg.Data.OutputDir, week.OutputFile(prefix))
+}
+
+// runMonthly rolls the weeks up by month (only months with a selected week) and writes a monthly
+// report per kid. Weekly Silver outputs from earlier runs are reused; missing or partial weeks are
+// transformed first. The month's weekly reports, when earlier runs wrote them, are summarized into
+// the monthly prompt. outputs is nil without database output.
+func runMonthly(ctx context.Context, cfg *config.Config, logger *logrus.Logger, metadata buildinfo.Metadata, weekMgr *weekmanager.WeekManager,
+	weeks []weekmanager.WeekRange, order []int, silverLayer *silver.SilverLayer, goldLayer *gold.GoldLayer, outputs *outputstore.Store) error {
+	reporter, err := monthly.NewReporter(goldLayer.GetAIProcessor(), logger, cfg.Monthly, goldLayer.GetDefaultLanguage(), cfg.Currency.Code)
+	if err != nil {
+		return fmt.Errorf("failed to initialize monthly reports: %w", err)
+	}
+	reporter.SetMetadata(metadata)
+	if outputs != nil {
+		reporter.SetOutputStore(outputs)
+	}
+
+	selected := make(map[string]bool)
+	for _, i := range order {
+		selected[monthly.MonthOf(weeks[i])] = true
+	}
+	compression := cfg.Data.CompressionCodec()
+	for _, month := range monthly.GroupWeeks(weeks, time.Now()) {
+		if !selected[month.Key] {
+			continue
+		}
+		if !month.Complete && !cfg.Monthly.IncludeIncomplete {
+			logger.Warnf("⏭️  Skipping %s: the month has not ended yet (monthly.include_incomplete)", month.Key)
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		logger.Info("")
+		logger.Info("=" + repeatString("=", 100))
+		logger.Infof("🗓️  PROCESSING MONTH %s (%d weeks)", month.Key, len(month.Weeks))
+		logger.Info("=" + repeatString("=", 100))
+
+		var weekOutputs []*silver.EnhancedOutput
+		var reportPaths []string
+		for _, week := range month.Weeks {
+			silverOutputPath := weekOutputPath(cfg, "kids_analysis", week)
+			reportOutputPath := weekOutputPath(cfg, "kids_reports", week)
+			if week.IsPartial {
+				silverOutputPath = silver.PartialOutputPath(silverOutputPath)
+				reportOutputPath = silver.PartialOutputPath(reportOutputPath)
+			}
+			reportPaths = append(reportPaths, reportOutputPath)
+			if _, err := fileio.ResolvePath(silverOutputPath); err != nil || week.IsPartial {
+				logger.Infof("📂 Running Silver Layer V3 for %s", week.Label)
+				if err := silverLayer.Transform(ctx, weekMgr.GetWeekData(week, weeks), silverOutputPath); err != nil {
+					return fmt.Errorf("silver layer failed for %s: %w", week.Label, err)
+				}
+			}
+			output, err := monthly.LoadWeek(silverOutputPath)
+			if err != nil {
+				return err
+			}
+			weekOutputs = append(weekOutputs, output)
+		}
+
+		// Month-over-month trends come from the previous month's rollup, when an earlier run wrote it
+		var previous *monthly.Output
+		previousPath := filepath.Join(cfg.Data.OutputDir, fmt.Sprintf("kids_monthly_%s.json", monthly.PreviousKey(month.Key)))
+		if _, err := fileio.ResolvePath(previousPath); err == nil {
+			if previous, err = monthly.LoadOutput(previousPath); err != nil {
+				logger.Warnf("⚠️  No month-over-month trends for %s: %v", month.Key, err)
+			}
+		}
+
+		rollup := monthly.Aggregate(month, weekOutputs, previous)
+		rollup.Metadata = &metadata
+		rollupPath, err := monthly.Save(rollup, filepath.Join(cfg.Data.OutputDir, fmt.Sprintf("kids_monthly_%s.json", month.Key)), compression)
+		if err != nil {
+			return err
+		}
+		logger.Infof("✅ Monthly rollup saved to: %s (%d kids)", rollupPath, rollup.TotalKids)
+
+		weekly, err := monthly.LoadWeeklySummaries(reportPaths)
+		if err != nil {
+			logger.Warnf("⚠️  Monthly reports for %s are written without the weekly reports: %v", month.Key, err)
+		}
+		reportPath := filepath.Join(cfg.Data.OutputDir, fmt.Sprintf("kids_monthly_reports_%s.json", month.Key))
+		if _, err := reporter.Generate(ctx, rollup, weekly, reportPath, compression); err != nil {
+			logger.Errorf("❌ Monthly reports failed for %s: %v", month.Key, err)
+		}
+	}
+	return nil
+}
+
+// recordWeekSLO measures a just-committed week against the report availability SLO
+func recordWeekSLO(logger *logrus.Logger, tracker *progress.Tracker, week weekmanager.WeekRange, reportOutputPath string) {
+	available, err := gold.ReportAvailability(reportOutputPath, tracker.Snapshot().StartedAt, time.Now())
+	if err != nil {
+		logger.Warnf("⚠️  Could not measure report SLO for %s: %v", week.Label, err)
+		return
+	}
+	result, ok := tracker.RecordWeekSLO(week.Label, week.EndDate, available)
+	if !ok {
+		logger.Debugf("⏱️  Report SLO for %s was measured on its first delivery", week.Label)
+		return
+	}
+	if !result.Met {
+		logger.Warnf("⚠️  Report SLO missed for %s: %.2f%% of %d kids within %gh (objective %g%%)",
+			week.Label, result.AttainmentPercent, result.Kids, result.TargetHours, result.ObjectivePercent)
+	}
+}
+
+// printSLO logs the report availability SLO for every week measured in the run
+func printSLO(logger *logrus.Logger, state progress.RunState) {
+	attainment, ok := state.SLOAttainment()
+	if !ok {
+		return
+	}
+	logger.Infof("⏱️  Report SLO: %.2f%% of reports available within target", attainment)
+	for _, week := range state.SLO {
+		mark := "✅"
+		if !week.Met {
+			mark = "❌"
+		}
+		logger.Infof("   %s %s: %d/%d within %gh (%.2f%%, objective %g%%), p50 %s, p99 %s",
+			mark, week.Week, week.WithinTarget, week.Kids, week.TargetHours, week.AttainmentPercent, week.ObjectivePercent,
+			time.Duration(week.P50Seconds)*time.Second, time.Duration(week.P99Seconds)*time.Second)
+	}
+}
+
+// printTokenReports prints token usage for the primary model and, if enabled, the consensus model
+func printTokenReports(goldLayer *gold.GoldLayer) {
+	goldLayer.GetAIProcessor().PrintTokenReport()
+	if secondary := goldLayer.GetConsensusProcessor(); secondary != nil {
+		secondary.PrintTokenReport()
+	}
+}
+
+// runRegenerate refreshes stored reports generated with templates older than a given hash:
+// pipeline regenerate [--older-than HASH] [--batch-size N] [--yes]
+func runRegenerate(args []string) int {
+	fs := flag.NewFlagSet("regenerate", flag.ExitOnError)
+	olderThan := fs.String("older-than", "", "Template hash; reports from older templates are regenerated (default: the current template)")
+	batchSize := fs.Int("batch-size", 0, "Reports per batch (default: gold.regeneration.batch_size)")
+	maxCost := fs.Float64("max-cost", -1, "Refuse plans estimated above this many USD (default: gold.regeneration.max_cost_usd)")
+	yes := fs.Bool("yes", false, "Regenerate; without it only the plan is printed")
+	out := addOutputFlag(fs, "regenerate")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: pipeline regenerate [--older-than HASH] [--batch-size N] [--max-cost USD] [--yes] [--output json]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	godotenv.Load()
+	configPath := "config/config.yaml"
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to load config: %w", err))
+	}
+	if *batchSize <= 0 {
+		*batchSize = cfg.Gold.Regeneration.BatchSize
+	}
+	if *maxCost < 0 {
+		*maxCost = cfg.Gold.Regeneration.MaxCostUSD
+	}
+
+	logger := setupLogger(cfg)
+
+	// Reports are compared with the prompts runs use, prompts.db_table overrides included
+	var db *sql.DB
+	if cfg.Prompts.DBTable != "" || cfg.CostLedger.Enabled {
+		if db, err = connectDatabase(cfg); err != nil {
+			return out.fail(1, fmt.Errorf("failed to connect to database: %w", err))
+		}
+		defer db.Close()
+	}
+
+	// The plan only reads the report store, so it works without an OpenAI key
+	plan, err := gold.PlanRegeneration(cfg, db, logger, cfg.Data.OutputDir, *olderThan)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	out.printf("%s", plan.Format())
+	result := regenerateResult{Plan: plan, DryRun: !*yes}
+
+	if len(plan.Items) == 0 {
+		out.printf("✅ Nothing to regenerate\n")
+		return out.done(result)
+	}
+	if *maxCost > 0 && plan.EstimatedCostUSD > *maxCost {
+		return out.exit(1, result, fmt.Errorf("estimated cost $%.4f exceeds the limit of $%.2f (--max-cost)", plan.EstimatedCostUSD, *maxCost))
+	}
+	if !*yes {
+		out.printf("\nDry run: re-run with --yes to regenerate these reports\n")
+		return out.done(result)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	metadata := buildinfo.Collect(cfg, configPath)
+	metadata.LogBanner(logger)
+
+	goldLayer, err := newGoldLayer(cfg, logger, db)
+	if err != nil {
+		return out.exit(1, result, err)
+	}
+	goldLayer.SetMetadata(metadata)
+
+	costLedger, err := openCostLedger(cfg, logger, db)
+	if err != nil {
+		return out.exit(1, result, err)
+	}
+	defer recordCosts(costLedger, logger, newRunID(), goldLayer, plan.Weeks())
+
+	result.Regenerated, err = goldLayer.Regenerate(ctx, plan, *batchSize)
+	logger.Infof("🔄 Regenerated %d/%d reports", result.Regenerated, len(plan.Items))
+	printTokenReports(goldLayer)
+	if err != nil {
+		return out.exit(1, result, err)
+	}
+	return out.done(result)
+}
+
+// newGoldLayer creates the Gold layer with the prompt overrides of prompts.db_table read from db, so
+// run, regenerate and flush-deferred render and hash the same prompts
+func newGoldLayer(cfg *config.Config, logger *logrus.Logger, db *sql.DB) (*gold.GoldLayer, error) {
+	goldLayer, err := gold.NewGoldLayer(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Gold layer: %w", err)
+	}
+	if cfg.Prompts.DBTable != "" {
+		if err := goldLayer.LoadPromptOverrides(db, cfg.Prompts.DBTable); err != nil {
+			return nil, fmt.Errorf("failed to load prompt overrides: %w", err)
+		}
+	}
+	return goldLayer, nil
+}
+
+// regenerateResult is regenerate's result with --output json
+type regenerateResult struct {
+	Plan        *gold.RegenerationPlan `json:"plan"`
+	DryRun      bool                   `json:"dry_run"`
+	Regenerated int                    `json:"regenerated"`
+}
+
+// runFlushDeferred sends the prompts queued while the AI provider was down and merges the reports into
+// their weeks' outputs: pipeline flush-deferred
+func runFlushDeferred(args []string) int {
+	fs := flag.NewFlagSet("flush-deferred", flag.ExitOnError)
+	out := addOutputFlag(fs, "flush-deferred")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: pipeline flush-deferred [--output json]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	godotenv.Load()
+	configPath := "config/config.yaml"
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to load config: %w", err))
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	logger := setupLogger(cfg)
+	metadata := buildinfo.Collect(cfg, configPath)
+	metadata.LogBanner(logger)
+
+	var db *sql.DB
+	var weeks []weekmanager.WeekRange
+	if cfg.Data.DatabaseOutput.Enabled || cfg.Prompts.DBTable != "" || cfg.Run.Lock.Enabled || cfg.CostLedger.Enabled {
+		if db, err = connectDatabase(cfg); err != nil {
+			return out.fail(1, fmt.Errorf("failed to connect to database: %w", err))
+		}
+		defer db.Close()
+		if weeks, err = weekmanager.NewWeekManager(db, logger, cfg.Calendar).GetAvailableWeeks(); err != nil {
+			return out.fail(1, fmt.Errorf("failed to get available weeks: %w", err))
+		}
+	}
+	lockWeek, err := flushWeekLocker(cfg, logger, db, weeks)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	goldLayer, err := newGoldLayer(cfg, logger, db)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	goldLayer.SetMetadata(metadata)
+	if cfg.Data.DatabaseOutput.Enabled {
+		outputs, err := outputstore.NewStore(db, logger, cfg.Data.DatabaseOutput.SilverTable, cfg.Data.DatabaseOutput.GoldTable)
+		if err != nil {
+			return out.fail(1, fmt.Errorf("failed to initialize database output: %w", err))
+		}
+		if err := outputs.SetWriteBatching(writeBatching(cfg)); err != nil {
+			return out.fail(1, err)
+		}
+		goldLayer.SetOutputStore(outputs)
+	}
+
+	// Still down: leave the queue as it is
+	if cfg.OpenAI.Preflight {
+		if err := goldLayer.Preflight(ctx); err != nil {
+			return out.fail(1, fmt.Errorf("AI provider still unavailable, nothing flushed: %w", err))
+		}
+	}
+
+	costLedger, err := openCostLedger(cfg, logger, db)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	defer recordCosts(costLedger, logger, newRunID(), goldLayer, weeks)
+
+	result, err := goldLayer.FlushDeferred(ctx, lockWeek)
+	logger.Infof("📤 Flushed %d/%d queued prompts (%d dropped, %d still queued)", result.Flushed, result.Queued, result.Failed, result.Remaining)
+	printTokenReports(goldLayer)
+	if err != nil {
+		return out.exit(1, result, err)
+	}
+	if result.Remaining > 0 {
+		return out.exit(1, result, fmt.Errorf("%d prompts are still queued; run flush-deferred again later", result.Remaining))
+	}
+	return out.done(result)
+}
+
+// flushWeekLocker returns flush-deferred's week locker: the run lock of the week with the queue's
+// label, so a flush never merges into a week a run is writing. Nil when run.lock is disabled.
+func flushWeekLocker(cfg *config.Config, logger *logrus.Logger, db *sql.DB, weeks []weekmanager.WeekRange) (gold.WeekLocker, error) {
+	locker, err := runlock.NewLocker(db, logger, cfg.Run.Lock)
+	if err != nil || locker == nil {
+		return nil, err
+	}
+	byLabel := make(map[string]weekmanager.WeekRange, len(weeks))
+	for _, week := range weeks {
+		byLabel[week.Label] = week
+	}
+	return func(ctx context.Context, label string) (func(), error) {
+		week, ok := byLabel[label]
+		if !ok {
+			return nil, fmt.Errorf("week %q is not in the database", label)
+		}
+		lock, err := locker.Acquire(ctx, week.StartDate, week.Label)
+		if err != nil {
+			return nil, err
+		}
+		return lock.Release, nil
+	}, nil
+}
+
+// runPromptShow renders the prompt for one kid and week without calling the API:
+// pipeline prompt show --profile <id> --week YYYY-MM-DD
+func runPromptShow(args []string) int {
+	fs := flag.NewFlagSet("prompt show", flag.ExitOnError)
+	profileID := fs.String("profile", "", "Kid profile ID")
+	week := fs.String("week", "", "Week start date, YYYY-MM-DD (as listed by pipeline weeks)")
+	out := addOutputFlag(fs, "prompt show")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: pipeline prompt show --profile <id> --week YYYY-MM-DD [--output json]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	if *profileID == "" || *week == "" {
+		fs.Usage()
+		return out.fail(2, fmt.Errorf("--profile and --week are required"))
+	}
+	if _, err := weekmanager.ParseKey(*week); err != nil {
+		return out.fail(2, err)
+	}
+
+	godotenv.Load()
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to load config: %w", err))
+	}
+
+	// Keep stdout for the prompt itself
+	logger := logrus.New()
+	logger.AddHook(redact.NewHook())
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(logrus.WarnLevel)
+
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to connect to database: %w", err))
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	preview, err := pipeline.PreviewPrompt(ctx, cfg, db, logger, *profileID, *week)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	if out.isJSON() {
+		return out.done(preview)
+	}
+
+	fmt.Println("=== SYSTEM MESSAGE ===")
+	fmt.Println(preview.SystemMessage)
+	fmt.Println()
+	fmt.Println("=== PROMPT ===")
+	fmt.Println(preview.Prompt)
+	fmt.Println()
+	fmt.Println("=== ESTIMATE (no API call made) ===")
+	fmt.Printf("Model:             %s\n", preview.Model)
+	fmt.Printf("Language:          %s\n", preview.Language)
+	fmt.Printf("Week type:         %s\n", preview.WeekType)
+	fmt.Printf("System tokens:     ~%d\n", preview.SystemTokens)
+	fmt.Printf("Prompt tokens:     ~%d\n", preview.PromptTokens)
+	fmt.Printf("Max output tokens: %d\n", preview.MaxCompletionTokens)
+	fmt.Printf("Max cost:          ~$%.5f\n", preview.EstimatedCostUSD)
+	return 0
+}
+
+// runWeeks lists the weeks found in the database, as the pipeline would number them:
+// pipeline weeks [--output json]
+func runWeeks(args []string) int {
+	fs := flag.NewFlagSet("weeks", flag.ExitOnError)
+	out := addOutputFlag(fs, "weeks")
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	godotenv.Load()
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to load config: %w", err))
+	}
+	logger := setupLogger(cfg)
+
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to connect to database: %w", err))
+	}
+	defer db.Close()
+
+	weeks, err := weekmanager.NewWeekManager(db, logger, cfg.Calendar).GetAvailableWeeks()
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to get available weeks: %w", err))
+	}
+
+	infos := make([]weekInfo, 0, len(weeks))
+	for _, week := range weeks {
+		info := weekInfo{
+			Number:     week.WeekNumber,
+			Label:      week.Label,
+			StartDate:  week.StartDate.Format("2006-01-02"),
+			EndDate:    week.EndDate.Format("2006-01-02"),
+			SchoolWeek: week.SchoolWeek,
+			Holiday:    week.IsHoliday,
+			Partial:    week.IsPartial,
+		}
+		infos = append(infos, info)
+		note := ""
+		if week.IsHoliday {
+			note = " (holiday)"
+		} else if week.IsPartial {
+			note = " (partial)"
+		}
+		out.printf("%3d  %s  %s..%s%s\n", week.WeekNumber, week.Label, info.StartDate, info.EndDate, note)
+	}
+	return out.done(infos)
+}
+
+// weekInfo is one week in the weeks command's result
+type weekInfo struct {
+	Number     int    `json:"number"`
+	Label      string `json:"label"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	SchoolWeek int    `json:"school_week,omitempty"`
+	Holiday    bool   `json:"holiday,omitempty"`
+	Partial    bool   `json:"partial,omitempty"`
+}
+
+// runServe serves Silver analytics over HTTP for other teams (no AI, no output files), and with
+// --schedule also runs the latest completed week on a cron schedule:
+// pipeline serve [--addr :8090] [--schedule "0 6 * * MON"]
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8090", "Listen address (\"\" = no HTTP API, only --schedule); beyond loopback it needs serve.token_env")
+	scheduleSpec := fs.String("schedule", "", "Cron expression (local time), e.g. \"0 6 * * MON\": run the latest completed week on this schedule")
+	fs.Parse(args)
+
+	var sched *schedule.Schedule
+	if *scheduleSpec != "" {
+		var err error
+		if sched, err = schedule.Parse(*scheduleSpec); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			return 2
+		}
+	} else if *addr == "" {
+		fmt.Fprintln(os.Stderr, "❌ Error: --addr \"\" needs --schedule")
+		return 2
+	}
+
+	godotenv.Load()
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: failed to load config: %v\n", err)
+		return 1
+	}
+	logger := setupLogger(cfg)
+
+	// The API returns kids' personal data: anything reachable from other hosts needs a token
+	token := os.Getenv(serveTokenEnv(cfg.Serve))
+	if *addr != "" && token == "" && !isLoopbackAddr(*addr) {
+		fmt.Fprintf(os.Stderr, "❌ Error: --addr %s accepts connections from other hosts: set %s (serve.token_env) or listen on 127.0.0.1\n", *addr, serveTokenEnv(cfg.Serve))
+		return 2
+	}
+
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	// Same Silver setup as pipeline runs, so the numbers match the reports. GET requests only read:
+	// stored metrics are used, but what is computed on a miss is not written back.
+	silverLayer := silver.NewSilverLayer(db, logger, cfg.Silver)
+	if cfg.Silver.MetricStore.Enabled {
+		store, err := silver.OpenMetricStore(db, logger, cfg.Silver)
+		if err != nil {
+			logger.Warnf("⚠️  Metric store unavailable, recomputing history from transactions: %v", err)
+		} else {
+			silverLayer.SetMetricStore(store, cfg.Silver.MetricStore.LookbackWeeks)
+		}
+	}
+	if cfg.Gold.OptionalSections.Enabled {
+		silverLayer.SetSectionPreferences(cfg.Gold.OptionalSections.Table)
+	}
+	if cfg.Categorization.Enabled {
+		silverLayer.SetCategoryTable(cfg.Categorization.Table)
+	}
+	analyzer := silver.NewAnalyzer(silverLayer, weekmanager.NewWeekManager(db, logger, cfg.Calendar))
+	mux := http.NewServeMux()
+	mux.Handle("/", analyzer)
+	mux.Handle(apispec.Path, apispec.Handler())
+	if cfg.Data.DatabaseOutput.Enabled {
+		outputs, err := outputstore.NewStore(db, logger, cfg.Data.DatabaseOutput.SilverTable, cfg.Data.DatabaseOutput.GoldTable)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: failed to initialize database output: %v\n", err)
+			return 1
+		}
+		cold, err := openColdStore(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			return 1
+		}
+		mux.Handle("/reports/kid", reportHandler(analyzer, outputs, cold))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Scheduled runs stay in this process; an interrupt lets the current run stop gracefully first
+	if sched != nil {
+		scheduled := make(chan struct{})
+		go func() {
+			defer close(scheduled)
+			runSchedule(ctx, sched, cfg.Run.ScheduleHistoryFile, logger)
+		}()
+		defer func() { stop(); <-scheduled }()
+		logger.Infof("⏰ Running the latest completed week on schedule %q", sched)
+	}
+	if *addr == "" {
+		<-ctx.Done()
+		return 0
+	}
+
+	server := &http.Server{Addr: *addr, Handler: requireToken(mux, token)}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	logger.Infof("🌐 Silver analysis API listening on %s (/weeks, /analysis/kid, /analysis/week, /reports/kid with database_output, spec at %s)", *addr, apispec.Path)
+	if token != "" {
+		logger.Infof("🔒 Requests need the bearer token from %s", serveTokenEnv(cfg.Serve))
+	}
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// reportHandler serves a kid's stored Gold report, rehydrating it from cold storage when archived:
+// GET /reports/kid?profile_id=ID&week=N
+func reportHandler(analyzer *silver.Analyzer, outputs *outputstore.Store, cold outputstore.ColdStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		profileID := r.URL.Query().Get("profile_id")
+		if profileID == "" {
+			http.Error(w, "profile_id is required", http.StatusBadRequest)
+			return
+		}
+		number, err := strconv.Atoi(r.URL.Query().Get("week"))
+		if err != nil {
+			http.Error(w, "week must be a week number", http.StatusBadRequest)
+			return
+		}
+		week, err := analyzer.Week(number)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		report, err := outputs.GoldReport(cold, week.Label, profileID)
+		if errors.Is(err, outputstore.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(report)
+	})
+}
+
+// openColdStore opens database_output.archive's cold storage, or returns nil when archiving is off
+func openColdStore(cfg *config.Config) (outputstore.ColdStore, error) {
+	if !cfg.Data.DatabaseOutput.Archive.Enabled {
+		return nil, nil
+	}
+	return outputstore.NewDirColdStore(cfg.Data.DatabaseOutput.Archive.Dir)
+}
+
+// writeBatching returns database_output's batching of week writes
+func writeBatching(cfg *config.Config) outputstore.WriteBatching {
+	return outputstore.WriteBatching{
+		BatchSize:   cfg.Data.DatabaseOutput.WriteBatchSize,
+		MaxInFlight: cfg.Data.DatabaseOutput.MaxInFlightBatches,
+	}
+}
+
+// runArchive moves Gold report rows older than database_output.archive.after_months to cold
+// storage, leaving stub rows: pipeline archive [--output json]
+func runArchive(args []string) int {
+	fs := flag.NewFlagSet("archive", flag.ExitOnError)
+	out := addOutputFlag(fs, "archive")
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	godotenv.Load()
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to load config: %w", err))
+	}
+	archive := cfg.Data.DatabaseOutput.Archive
+	if !cfg.Data.DatabaseOutput.Enabled || !archive.Enabled {
+		return out.fail(2, fmt.Errorf("archiving needs data.database_output.enabled and data.database_output.archive.enabled"))
+	}
+	logger := setupLogger(cfg)
+
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to connect to database: %w", err))
+	}
+	defer db.Close()
+	outputs, err := outputstore.NewStore(db, logger, cfg.Data.DatabaseOutput.SilverTable, cfg.Data.DatabaseOutput.GoldTable)
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to initialize database output: %w", err))
+	}
+	cold, err := openColdStore(cfg)
+	if err != nil {
+		return out.fail(1, err)
+	}
+
+	cutoff := archive.Cutoff(time.Now())
+	result := archiveResult{Cutoff: cutoff.Format("2006-01-02")}
+	result.Archived, err = outputs.ArchiveGold(cold, cutoff, archive.BatchSize)
+	out.printf("🧊 Archived %d reports written before %s\n", result.Archived, result.Cutoff)
+	if err != nil {
+		return out.exit(1, result, err)
+	}
+	return out.done(result)
+}
+
+// archiveResult is archive's result with --output json
+type archiveResult struct {
+	Cutoff   string `json:"cutoff"`
+	Archived int    `json:"archived"`
+}
+
+// runCompare evaluates the same week's Gold output from two environments side by side:
+// pipeline compare --week YYYY-MM-DD <dir-or-file A> <dir-or-file B>
+func runCompare(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	week := fs.String("week", "", "Week start date, YYYY-MM-DD (required when A/B are output directories)")
+	out := addOutputFlag(fs, "compare")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: pipeline compare [--week YYYY-MM-DD] [--output json] <staging-dir|file> <prod-dir|file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return out.fail(2, fmt.Errorf("compare needs two outputs"))
+	}
+
+	paths := make([]string, 2)
+	for i, arg := range fs.Args() {
+		paths[i] = arg
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			if *week == "" {
+				return out.fail(2, fmt.Errorf("--week is required when comparing directories"))
+			}
+			if _, err := weekmanager.ParseKey(*week); err != nil {
+				return out.fail(2, err)
+			}
+			paths[i] = filepath.Join(arg, weekmanager.OutputFile("kids_reports", *week))
+		}
+	}
+
+	comparison, err := gold.CompareWeekOutputs(paths[0], paths[1])
+	if err != nil {
+		return out.fail(1, err)
+	}
+
+	out.printf("%s", comparison.Format())
+	return out.done(comparison)
+}
+
+// runRender renders an existing week's reports to HTML (and PDF when configured), e.g. after
+// regenerating one kid with report --profile-id: pipeline render --week YYYY-MM-DD
+func runRender(args []string) int {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	week := fs.String("week", "", "Start date of the week to render, YYYY-MM-DD")
+	out := addOutputFlag(fs, "render")
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+	if *week == "" {
+		return out.fail(2, fmt.Errorf("--week is required"))
+	}
+	if _, err := weekmanager.ParseKey(*week); err != nil {
+		return out.fail(2, err)
+	}
+
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to load config: %w", err))
+	}
+	renderer, err := gold.NewReportRenderer(cfg.Gold.Render)
+	if err != nil {
+		return out.fail(1, err)
+	}
+
+	reportPath := filepath.Join(cfg.Data.OutputDir, weekmanager.OutputFile("kids_reports", *week))
+	rendered, err := renderer.RenderFile(context.Background(), reportPath)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	result := renderResult{Dir: gold.RenderDir(reportPath), Rendered: rendered}
+	out.printf("🖨️  %d reports rendered to %s\n", rendered, result.Dir)
+	return out.done(result)
+}
+
+// renderResult is the render command's result
+type renderResult struct {
+	Dir      string `json:"dir"`
+	Rendered int    `json:"rendered"`
+}
+
+// runOpenAPI writes the serve API's OpenAPI document (also served at /openapi.json) to stdout or --out
+func runOpenAPI(args []string) int {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	outPath := fs.String("out", "", "Write the document to this file instead of stdout")
+	fs.Parse(args)
+
+	data, err := apispec.JSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		return 1
+	}
+	if *outPath == "" {
+		fmt.Println(string(data))
+		return 0
+	}
+	if err := os.WriteFile(*outPath, append(data, '\n'), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "📄 OpenAPI document written to %s\n", *outPath)
+	return 0
+}
+
+// runSilverDiff compares two Silver outputs: pipeline silver diff [--tolerance X] old.json new.json
+func runSilverDiff(args []string) int {
+	fs := flag.NewFlagSet("silver diff", flag.ExitOnError)
+	tolerance := fs.Float64("tolerance", 1e-6, "Absolute tolerance for numeric fields")
+	ignore := fs.String("ignore", "generated_at,metadata", "Comma-separated field names to ignore")
+	out := addOutputFlag(fs, "silver diff")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: pipeline silver diff [--tolerance X] [--ignore a,b] [--output json] old.json new.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return out.fail(2, fmt.Errorf("silver diff needs two outputs"))
+	}
+
+	ignoreFields := make(map[string]bool)
+	for _, f := range strings.Split(*ignore, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			ignoreFields[f] = true
+		}
+	}
+
+	report, err := silver.DiffOutputs(fs.Arg(0), fs.Arg(1), silver.DiffOptions{
+		Tolerance:    *tolerance,
+		IgnoreFields: ignoreFields,
+	})
+	if err != nil {
+		return out.fail(2, err)
+	}
+
+	out.printf("%s", report.Format())
+	if report.HasDifferences() {
+		out.printf("\n❌ Outputs differ\n")
+		return out.exit(1, report, nil)
+	}
+	out.printf("✅ Outputs match within tolerance\n")
+	return out.done(report)
+}
+
+// recordLayerResults counts the week's Silver and Gold outcome (Gold is not counted when Silver failed)
+func recordLayerResults(silverErr, goldErr error, partial bool) {
+	if silverErr != nil {
+		telemetry.LayerWeeks.Inc(telemetry.LayerSilver, telemetry.ResultFailure)
+		return
+	}
+	telemetry.LayerWeeks.Inc(telemetry.LayerSilver, telemetry.ResultSuccess)
+	switch {
+	case partial:
+		telemetry.LayerWeeks.Inc(telemetry.LayerGold, telemetry.ResultPartial)
+	case goldErr != nil:
+		telemetry.LayerWeeks.Inc(telemetry.LayerGold, telemetry.ResultFailure)
+	default:
+		telemetry.LayerWeeks.Inc(telemetry.LayerGold, telemetry.ResultSuccess)
+	}
+}
+
+// metricsHandler serves the run and SLO gauges, followed by the pipeline counters and histograms
+// when status.prometheus is enabled
+func metricsHandler(tracker *progress.Tracker, pipelineMetrics bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tracker.ServeMetrics(w, r)
+		if pipelineMetrics {
+			telemetry.Write(w)
+		}
+	}
+}
+
+// startMetricsServer serves GET /metrics on its own address (status.prometheus.listen_addr) until ctx is cancelled
+func startMetricsServer(ctx context.Context, addr string, tracker *progress.Tracker, logger *logrus.Logger) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", metricsHandler(tracker, true))
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		logger.Infof("📈 Prometheus metrics listening on %s/metrics", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Warnf("Metrics server stopped: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+}
+
+// startStatusServer serves the current run state on GET /status until ctx is cancelled
+func startStatusServer(ctx context.Context, addr string, tracker *progress.Tracker, pipelineMetrics bool, logger *logrus.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/status", tracker)
+	mux.HandleFunc("/metrics", metricsHandler(tracker, pipelineMetrics))
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		logger.Infof("🌐 Status endpoint listening on %s/status (Prometheus metrics on /metrics)", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Warnf("Status server stopped: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+}
+
+// requiredColumns returns the source columns to verify, including configured optional ones
+func requiredColumns(cfg *config.Config) []schema.Column {
+	columns := append([]schema.Column{}, schema.RequiredColumns...)
+	if cfg.Silver.Amounts.Storage == silver.AmountStorageInteger {
+		for i, column := range columns {
+			if column.Family == schema.FamilyNumeric {
+				columns[i].Expected = "bigint"
+			}
+		}
+	}
+	if cfg.Silver.LanguageColumn != "" {
+		columns = append(columns, schema.Column{
+			Table: "profiles", Column: cfg.Silver.LanguageColumn, Family: schema.FamilyText, Expected: "character varying",
+		})
+	}
+	if cfg.Silver.ParentColumn != "" {
+		columns = append(columns, schema.Column{
+			Table: "profiles", Column: cfg.Silver.ParentColumn, Family: schema.FamilyUUID, Expected: "uuid",
+		})
+	}
+	if cfg.Silver.DeletedProfiles.Column != "" {
+		columns = append(columns, schema.Column{
+			Table: "profiles", Column: cfg.Silver.DeletedProfiles.Column, Family: schema.FamilyTime, Expected: "timestamp with time zone",
+		})
+	}
+	if cfg.Categorization.Enabled {
+		columns = append(columns,
+			schema.Column{Table: "wallet_transactions", Column: "id", Family: schema.FamilyAny, Expected: "uuid"},
+			schema.Column{Table: "wallet_transactions", Column: cfg.Categorization.DescriptionColumn, Family: schema.FamilyText, Expected: "text"},
+		)
+	}
+	return columns
+}
+
+// prepareBronze snapshots the week's source rows before Silver, or restores the week's latest snapshot
+// when replaying. A failed snapshot only costs the replay, so Silver still runs on the live tables.
+func prepareBronze(ctx context.Context, bronzeLayer *bronze.Layer, week weekmanager.WeekRange, replay bool, logger *logrus.Logger) error {
+	if !replay {
+		if _, _, err := bronzeLayer.Extract(ctx, week); err != nil {
+			logger.Warnf("⚠️  Bronze snapshot failed for %s, the week cannot be replayed: %v", week.Label, err)
+		}
+		return nil
+	}
+	path, version, err := bronzeLayer.Latest(week)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		return fmt.Errorf("no bronze snapshot of %s to replay (--from-bronze)", week.Label)
+	}
+	snapshot, err := bronze.Load(path)
+	if err != nil {
+		return err
+	}
+	return bronzeLayer.Restore(ctx, snapshot)
+}
+
+// connectReplayDatabase opens a second pool whose search_path starts with the bronze replay schema, so
+// Silver's table names resolve to the restored snapshot (tables it does not hold still come from public)
+func connectReplayDatabase(cfg *config.Config, schema string) (*sql.DB, error) {
+	return openDatabase(cfg, cfg.Database.ConnectionString()+" search_path="+schema+",public")
+}
+
+// connectDatabase establishes database connection
+func connectDatabase(cfg *config.Config) (*sql.DB, error) {
+	return openDatabase(cfg, cfg.Database.ConnectionString())
+}
+
+// openDatabase opens and pings a pool for connStr with the configured pool settings
+func openDatabase(cfg *config.Config, connStr string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", redact.Error(err))
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", redact.Error(err))
+	}
+
+	// Configure connection pool
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.Database.MaxLifetimeMin) * time.Minute)
+
+	return db, nil
+}
+
+// createAIProcessor creates configured AI processor
+func createAIProcessor(cfg *config.Config, apiKey string, logger *logrus.Logger) (*processor.AIProcessor, error) {
+	cacheDir, cacheTTL, err := cfg.OpenAI.ResponseCache.Settings()
+	if err != nil {
+		return nil, err
+	}
+	faults, err := gold.FaultConfig(cfg.OpenAI.FaultInjection)
+	if err != nil {
+		return nil, err
+	}
+	autoTune, err := gold.AutoTuneConfig(cfg.Batch)
+	if err != nil {
+		return nil, err
+	}
+	processorConfig := processor.Config{
+		APIKey:             apiKey,
+		Model:              cfg.OpenAI.Model,
+		MaxTokens:          cfg.OpenAI.MaxTokens,
+		Temperature:        cfg.OpenAI.Temperature,
+		Timeout:            time.Duration(cfg.OpenAI.TimeoutSeconds) * time.Second,
+		ItemBudget:         time.Duration(cfg.OpenAI.ItemBudgetSecs) * time.Second,
+		BatchSize:          cfg.Batch.Size,
+		MaxConcurrent:      cfg.Batch.MaxConcurrent,
+		AutoTune:           autoTune,
+		RateLimitPerMin:    cfg.RateLimit.RequestsPerMinute,
+		MaxRetries:         cfg.Retry.MaxAttempts,
+		InitialRetryDelay:  time.Duration(cfg.Retry.InitialDelaySeconds) * time.Second,
+		MaxRetryDelay:      time.Duration(cfg.Retry.MaxDelaySeconds) * time.Second,
+		ExponentialBackoff: cfg.Retry.ExponentialBackoff,
+		TrackTokenUsage:    cfg.Monitoring.TrackTokenUsage,
+		TrackTiming:        cfg.Monitoring.TrackTiming,
+		ShowProgress:       cfg.Monitoring.ShowProgress,
+		Provider:           cfg.OpenAI.Provider,
+		BaseURL:            cfg.OpenAI.BaseURL,
+		AuthStyle:          cfg.OpenAI.AuthStyle,
+		APIVersion:         cfg.OpenAI.APIVersion,
+		ExtraHeaders:       cfg.OpenAI.ExtraHeaders,
+		ProxyURL:           cfg.HTTP.Proxy,
+		CABundleFile:       cfg.HTTP.CABundleFile,
+		MaxRequestBytes:    cfg.HTTP.MaxRequestBytes,
+		MaxResponseBytes:   cfg.HTTP.MaxResponseBytes,
+		StoreResponses:     cfg.OpenAI.StoreResponses,
+		Stream:             cfg.OpenAI.Stream,
+		StreamIdleTimeout:  time.Duration(cfg.OpenAI.StreamIdleSecs) * time.Second,
+		CacheDir:           cacheDir,
+		CacheTTL:           cacheTTL,
+		Faults:             faults,
+	}
+
+	return processor.NewAIProcessor(processorConfig, logger)
+}
+
+// setupLogger configures and returns a logger instance
 func setupLogger(cfg *config.Config) *logrus.Logger {
 	logger := logrus.New()
+	logger.AddHook(redact.NewHook())
 
 	// Set log level
 	level, err := logrus.ParseLevel(cfg.Logging.Level)
@@ -237,17 +1972,19 @@ func setupLogger(cfg *config.Config) *logrus.Logger {
 
 	// Setup file logging if enabled
 	if cfg.Logging.LogToFile {
-		if err := os.MkdirAll(cfg.Logging.LogDir, 0755); err != nil {
-			logger.Warnf("Failed to create log directory: %v", err)
+		writer, err := pipelinelogger.NewRotatingWriter(pipelinelogger.RotationConfig{
+			Dir:        cfg.Logging.LogDir,
+			Prefix:     "pipeline",
+			MaxSizeMB:  cfg.Logging.MaxSizeMB,
+			MaxAge:     time.Duration(cfg.Logging.MaxAgeHours) * time.Hour,
+			MaxBackups: cfg.Logging.MaxBackups,
+			Compress:   cfg.Logging.Compress,
+		})
+		if err != nil {
+			logger.Warnf("Failed to open log file: %v", err)
 		} else {
-			logFile := filepath.Join(cfg.Logging.LogDir, fmt.Sprintf("pipeline_%s.log", time.Now().Format("20060102_150405")))
-			file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-			if err != nil {
-				logger.Warnf("Failed to open log file: %v", err)
-			} else {
-				logger.SetOutput(file)
-				logger.Infof("Logging to file: %s", logFile)
-			}
+			logger.SetOutput(writer)
+			logger.Infof("Logging to file: %s", writer.Path())
 		}
 	}
 
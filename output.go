@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Result formats (--output)
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// cliOutput prints a command's result. With --output json, stdout carries exactly one JSON document
+// (cliResult) and everything else, including human-readable lines and errors, goes to stderr.
+type cliOutput struct {
+	command string
+	format  string
+}
+
+// cliResult is the JSON document printed with --output json
+type cliResult struct {
+	Command  string      `json:"command"`
+	OK       bool        `json:"ok"`
+	ExitCode int         `json:"exit_code"`
+	Error    string      `json:"error,omitempty"`
+	Result   interface{} `json:"result,omitempty"`
+}
+
+// addOutputFlag registers --output for command on fs
+func addOutputFlag(fs *flag.FlagSet, command string) *cliOutput {
+	out := &cliOutput{command: command}
+	fs.StringVar(&out.format, "output", outputText, "Result format: text, or json for one machine-readable document on stdout (logs stay on stderr)")
+	return out
+}
+
+// check rejects unknown formats; call it right after parsing flags
+func (o *cliOutput) check() error {
+	if o.format != outputText && o.format != outputJSON {
+		o.format = outputText
+		return fmt.Errorf("--output must be %s or %s", outputText, outputJSON)
+	}
+	return nil
+}
+
+// isJSON reports whether the result is printed as JSON
+func (o *cliOutput) isJSON() bool {
+	return o.format == outputJSON
+}
+
+// text returns where human-readable output goes: stdout, or stderr in JSON mode
+func (o *cliOutput) text() io.Writer {
+	if o.isJSON() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printf prints a human-readable line (to stderr in JSON mode)
+func (o *cliOutput) printf(format string, args ...interface{}) {
+	fmt.Fprintf(o.text(), format, args...)
+}
+
+// done prints result in JSON mode and returns exit code 0
+func (o *cliOutput) done(result interface{}) int {
+	return o.exit(0, result, nil)
+}
+
+// fail prints err to stderr (and the JSON document in JSON mode) and returns code
+func (o *cliOutput) fail(code int, err error) int {
+	return o.exit(code, nil, err)
+}
+
+// exit finishes the command with code; result may accompany a failure (e.g. a run that stopped midway)
+func (o *cliOutput) exit(code int, result interface{}, err error) int {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+	}
+	if !o.isJSON() {
+		return code
+	}
+	doc := cliResult{Command: o.command, OK: code == 0, ExitCode: code, Result: result}
+	if err != nil {
+		doc.Error = err.Error()
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if encodeErr := encoder.Encode(doc); encodeErr != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: failed to write JSON output: %v\n", encodeErr)
+		return 1
+	}
+	return code
+}
@@ -0,0 +1,26 @@
+// Package prompts embeds the default Vietnamese report template, system message and HTML report
+// layout, so the binary still starts when the prompts directory is not deployed next to it.
+package prompts
+
+import _ "embed"
+
+// Embedded source names, as logged and recorded in report metadata
+const (
+	DefaultTemplateName      = "vietnamese_financial_report.txt"
+	DefaultSystemMessageName = "system_message.txt"
+)
+
+// DefaultTemplate is the built-in Vietnamese report template
+//
+//go:embed vietnamese_financial_report.txt
+var DefaultTemplate string
+
+// DefaultSystemMessage is the built-in Vietnamese system message
+//
+//go:embed system_message.txt
+var DefaultSystemMessage string
+
+// DefaultReportHTML is the built-in HTML template for rendered reports (gold.render)
+//
+//go:embed report.html
+var DefaultReportHTML string
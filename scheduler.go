@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ai-production-pipeline/internal/progress"
+	"ai-production-pipeline/internal/schedule"
+
+	"github.com/sirupsen/logrus"
+)
+
+// scheduledRun is one serve --schedule run, appended to run.schedule_history_file
+type scheduledRun struct {
+	Schedule        string                  `json:"schedule"`
+	ScheduledAt     time.Time               `json:"scheduled_at"`
+	StartedAt       time.Time               `json:"started_at"`
+	FinishedAt      time.Time               `json:"finished_at"`
+	DurationSeconds float64                 `json:"duration_seconds"`
+	Weeks           []string                `json:"weeks,omitempty"`
+	Status          string                  `json:"status"` // The run's final status; "failed" when it returned an error, "deferred" when it queued prompts
+	Error           string                  `json:"error,omitempty"`
+	Summary         *progress.StatusSummary `json:"summary,omitempty"`
+}
+
+// runSchedule runs the latest completed week each time sched fires, until ctx is done. Runs never
+// overlap: a firing missed while a run is still going is skipped, and the next one is computed from
+// when the run ended.
+func runSchedule(ctx context.Context, sched *schedule.Schedule, historyPath string, logger *logrus.Logger) {
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			logger.Errorf("❌ Schedule %q never fires; no runs will start", sched)
+			return
+		}
+		logger.Infof("⏰ Next scheduled run at %s (%s)", next.Format(time.RFC3339), sched)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		run := runScheduled(ctx, sched, next, logger)
+		if historyPath != "" {
+			if err := appendScheduledRun(historyPath, run); err != nil {
+				logger.Warnf("⚠️  Failed to record scheduled run in %s: %v", historyPath, err)
+			}
+		}
+	}
+}
+
+// runScheduled runs the pipeline for the latest completed week. The run gets its own context, so its
+// status servers stop when it ends and an interrupt still stops it gracefully. It logs to serve's
+// logger, so the resident process keeps one log file however many runs it starts.
+func runScheduled(ctx context.Context, sched *schedule.Schedule, scheduledAt time.Time, logger *logrus.Logger) scheduledRun {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	run := scheduledRun{Schedule: sched.String(), ScheduledAt: scheduledAt, StartedAt: time.Now()}
+	logger.Infof("▶️  Scheduled run started (%s)", sched)
+
+	var result runResult
+	err := runAutomatedPipeline(runCtx, runOptions{LastWeek: true, Completed: true, Order: orderOldestFirst, logger: logger}, &result)
+	run.FinishedAt = time.Now()
+	run.DurationSeconds = run.FinishedAt.Sub(run.StartedAt).Seconds()
+	run.Weeks = result.Weeks
+	run.Summary = result.Summary
+
+	switch {
+	case errors.Is(err, errRunDeferred):
+		run.Status = "deferred"
+		run.Error = err.Error()
+		logger.Warnf("⏸️  Scheduled run deferred after %s: %v", run.FinishedAt.Sub(run.StartedAt).Round(time.Second), err)
+	case err != nil:
+		run.Status = "failed"
+		run.Error = err.Error()
+		logger.Errorf("❌ Scheduled run failed after %s: %v", run.FinishedAt.Sub(run.StartedAt).Round(time.Second), err)
+	default:
+		run.Status = "completed"
+		if result.Summary != nil && result.Summary.Status != "" {
+			run.Status = result.Summary.Status
+		}
+		logger.Infof("✅ Scheduled run %s in %s (weeks: %v)", run.Status, run.FinishedAt.Sub(run.StartedAt).Round(time.Second), run.Weeks)
+	}
+	return run
+}
+
+// appendScheduledRun appends run to the JSONL history at path
+func appendScheduledRun(path string, run scheduledRun) error {
+	line, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
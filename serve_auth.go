@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"ai-production-pipeline/internal/apispec"
+	"ai-production-pipeline/internal/config"
+)
+
+// defaultServeTokenEnv holds the serve API token when serve.token_env is empty
+const defaultServeTokenEnv = "SERVE_API_TOKEN"
+
+// serveTokenEnv returns the environment variable that holds the serve API token
+func serveTokenEnv(cfg config.ServeConfig) string {
+	if cfg.TokenEnv != "" {
+		return cfg.TokenEnv
+	}
+	return defaultServeTokenEnv
+}
+
+// isLoopbackAddr reports whether a listen address only accepts connections from this host
+// (":8090" and "0.0.0.0:8090" listen on every interface)
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>", except for the OpenAPI
+// document. An empty token lets every request through (loopback only, see runServe).
+func requireToken(next http.Handler, token string) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != apispec.Path {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-production-pipeline/internal/apispec"
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/silver"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newTestServeHandler wires the serve API like runServe. Its week manager cannot list weeks (an
+// invalid anchor date), so no request reaches the database.
+func newTestServeHandler(token string) http.Handler {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	weeks := weekmanager.NewWeekManager(nil, logger, config.CalendarConfig{AnchorDate: "not-a-date"})
+	analyzer := silver.NewAnalyzer(silver.NewSilverLayer(nil, logger, config.SilverConfig{}), weeks)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", analyzer)
+	mux.Handle(apispec.Path, apispec.Handler())
+	mux.Handle("/reports/kid", reportHandler(analyzer, nil, nil))
+	return requireToken(mux, token)
+}
+
+func TestServeHandlers(t *testing.T) {
+	handler := newTestServeHandler("")
+	tests := []struct {
+		method string
+		target string
+		status int
+	}{
+		{http.MethodGet, apispec.Path, http.StatusOK},
+		{http.MethodPost, "/weeks", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/weeks", http.StatusInternalServerError},
+		{http.MethodGet, "/unknown", http.StatusNotFound},
+		{http.MethodGet, "/analysis/kid?week=abc&profile_id=x", http.StatusBadRequest},
+		{http.MethodGet, "/analysis/week", http.StatusBadRequest},
+		{http.MethodGet, "/analysis/kid?week=1&profile_id=x", http.StatusNotFound},
+		{http.MethodPost, "/reports/kid?week=1&profile_id=x", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/reports/kid?week=1", http.StatusBadRequest},
+		{http.MethodGet, "/reports/kid?week=last&profile_id=x", http.StatusBadRequest},
+		{http.MethodGet, "/reports/kid?week=1&profile_id=x", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+		if recorder.Code != tt.status {
+			t.Errorf("%s %s = %d, want %d (%s)", tt.method, tt.target, recorder.Code, tt.status, recorder.Body)
+		}
+	}
+}
+
+func TestServeRequiresToken(t *testing.T) {
+	handler := newTestServeHandler("s3cret")
+	tests := []struct {
+		target        string
+		authorization string
+		status        int
+	}{
+		{"/weeks", "", http.StatusUnauthorized},
+		{"/weeks", "Bearer wrong", http.StatusUnauthorized},
+		{"/weeks", "s3cret", http.StatusUnauthorized}, // Not a bearer token
+		{"/unknown", "Bearer s3cret", http.StatusNotFound},
+		{apispec.Path, "", http.StatusOK}, // The spec is public
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.authorization != "" {
+			request.Header.Set("Authorization", tt.authorization)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != tt.status {
+			t.Errorf("GET %s with %q = %d, want %d", tt.target, tt.authorization, recorder.Code, tt.status)
+		}
+		if tt.status == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") != "Bearer" {
+			t.Errorf("GET %s with %q: missing WWW-Authenticate", tt.target, tt.authorization)
+		}
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:8090": true,
+		"localhost:8090": true,
+		"[::1]:8090":     true,
+		":8090":          false,
+		"0.0.0.0:8090":   false,
+		"10.0.0.5:8090":  false,
+		"127.0.0.1":      false, // No port
+	}
+	for addr, want := range tests {
+		if got := isLoopbackAddr(addr); got != want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
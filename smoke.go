@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/delivery"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/gold"
+	"ai-production-pipeline/internal/processor"
+	"ai-production-pipeline/internal/schema"
+	"ai-production-pipeline/internal/silver"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/sirupsen/logrus"
+)
+
+// smokeWeek labels the synthetic week
+const smokeWeek = "Smoke test"
+
+// smokeReport is the mock API's report: valid JSON in the report shape, no figures
+const smokeReport = `{
+  "child_name": "Bé Smoke",
+  "financial_tendencies": [{"type": "Tiết kiệm", "description": "Bé giữ tiền đều đặn.", "suggestion": "Tiếp tục duy trì."}],
+  "performance_sections": [{"title": "Tự quản lý tài chính", "level": "Tốt", "score": 4, "summary": "Bé quản lý tiền tốt."}],
+  "next_week_goals": ["Tiếp tục tiết kiệm"],
+  "parent_suggestions": ["Khen ngợi bé khi bé tiết kiệm"],
+  "strengths": ["Tiết kiệm đều đặn", "Hoàn thành nhiệm vụ"],
+  "top_risk": "Đôi khi tiêu hết ví tiêu vặt quá sớm.",
+  "badge": {"key": "steady_saver", "reason": "Bé tiết kiệm đều mỗi tuần."}
+}`
+
+// smokeStep is one wiring check
+type smokeStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// runSmoke runs one synthetic kid through config, database, Silver output, Gold and delivery, with
+// every file written to a temporary directory: pipeline smoke [--real-api] [--skip-db] [--timeout 30s]
+func runSmoke(args []string) int {
+	fs := flag.NewFlagSet("smoke", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Config file to check")
+	realAPI := fs.Bool("real-api", false, "Call the configured AI provider (one report) instead of a local mock")
+	skipDB := fs.Bool("skip-db", false, "Skip the database checks")
+	timeout := fs.Duration("timeout", 30*time.Second, "Fail when the whole check takes longer")
+	verbose := fs.Bool("verbose", false, "Show pipeline logs")
+	out := addOutputFlag(fs, "smoke")
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	if *verbose {
+		logger.SetLevel(logrus.InfoLevel)
+	}
+
+	dir, err := os.MkdirTemp("", "pipeline-smoke-")
+	if err != nil {
+		return out.fail(1, err)
+	}
+
+	var cfg *config.Config
+	var goldLayer *gold.GoldLayer
+	silverPath := filepath.Join(dir, "kids_analysis_week_0.json")
+	reportPath := filepath.Join(dir, "kids_reports_week_0.json")
+
+	steps := []smokeStep{
+		{"config", func(ctx context.Context) error {
+			loaded, problems, err := checkConfig(*configPath)
+			if err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%d problems, first: %s (run validate-config for all)", len(problems), problems[0])
+			}
+			cfg = loaded
+			return nil
+		}},
+		{"database", func(ctx context.Context) error {
+			if *skipDB {
+				return errSkipped
+			}
+			return smokeDatabase(ctx, cfg, logger)
+		}},
+		{"ai api", func(ctx context.Context) error {
+			if !*realAPI {
+				url, err := startMockAI(ctx, smokeReport)
+				if err != nil {
+					return err
+				}
+				useMockAI(cfg, url)
+			}
+			cfg.Gold.ReuseExisting = false
+			layer, err := gold.NewGoldLayer(cfg, logger)
+			if err != nil {
+				return err
+			}
+			goldLayer = layer
+			return goldLayer.Preflight(ctx)
+		}},
+		{"silver output", func(ctx context.Context) error {
+			return writeSmokeSilver(cfg, silverPath)
+		}},
+		{"gold", func(ctx context.Context) error {
+			generated, err := goldLayer.GenerateReportsFromFile(ctx, silverPath, reportPath, smokeWeek)
+			if err != nil {
+				return err
+			}
+			if generated != 1 {
+				return fmt.Errorf("generated %d reports, want 1", generated)
+			}
+			return nil
+		}},
+		{"parent digest", func(ctx context.Context) error {
+			if !cfg.Gold.ParentDigest.Enabled {
+				return errSkipped
+			}
+			digests, err := goldLayer.GenerateParentDigests(ctx, reportPath, filepath.Join(dir, "kids_digests_week_0.json"), smokeWeek)
+			if err != nil {
+				return err
+			}
+			if digests != 1 {
+				return fmt.Errorf("generated %d digests, want 1", digests)
+			}
+			return nil
+		}},
+		{"kid version", func(ctx context.Context) error {
+			if !cfg.Gold.KidVersion.Enabled {
+				return errSkipped
+			}
+			versions, err := goldLayer.GenerateKidVersions(ctx, reportPath, filepath.Join(dir, "kids_kid_versions_week_0.json"), smokeWeek)
+			if err != nil {
+				return err
+			}
+			if versions != 1 {
+				return fmt.Errorf("wrote %d kid versions, want 1", versions)
+			}
+			return nil
+		}},
+		{"delivery", func(ctx context.Context) error {
+			if !cfg.Delivery.Enabled {
+				return errSkipped
+			}
+			return smokeDelivery(ctx, reportPath, filepath.Join(dir, "outbox"))
+		}},
+	}
+
+	// Steps such as database queries do not all honor ctx, so the time box is enforced here
+	finished := make(chan []smokeResult, 1)
+	go func() { finished <- runSmokeSteps(ctx, steps, out) }()
+	select {
+	case results := <-finished:
+		if smokePassed(results) {
+			os.RemoveAll(dir)
+			out.printf("✅ Smoke test passed\n")
+			return out.done(results)
+		}
+		fmt.Fprintf(os.Stderr, "   Files kept in %s\n", dir)
+		return out.exit(1, results, fmt.Errorf("smoke test failed"))
+	case <-ctx.Done():
+		fmt.Fprintf(os.Stderr, "   Files kept in %s\n", dir)
+		return out.fail(1, fmt.Errorf("smoke test timed out after %v", *timeout))
+	}
+}
+
+// smokeResult is one step's outcome, listed by --output json
+type smokeResult struct {
+	Step       string `json:"step"`
+	Status     string `json:"status"` // ok, skipped or failed
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// smokePassed reports whether no step failed
+func smokePassed(results []smokeResult) bool {
+	for _, result := range results {
+		if result.Status == "failed" {
+			return false
+		}
+	}
+	return true
+}
+
+// errSkipped marks a step that does not apply to this config
+var errSkipped = errors.New("skipped")
+
+// runSmokeSteps runs steps in order, printing each result, and stops at the first failure
+func runSmokeSteps(ctx context.Context, steps []smokeStep, out *cliOutput) []smokeResult {
+	var results []smokeResult
+	for _, step := range steps {
+		start := time.Now()
+		err := step.run(ctx)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		result := smokeResult{Step: step.name, DurationMs: elapsed.Milliseconds()}
+		switch {
+		case errors.Is(err, errSkipped):
+			result.Status = "skipped"
+			out.printf("⏭️  %-14s skipped\n", step.name)
+		case err != nil:
+			result.Status = "failed"
+			result.Error = err.Error()
+			fmt.Fprintf(os.Stderr, "❌ %-14s %v (%v)\n", step.name, err, elapsed)
+			return append(results, result)
+		default:
+			result.Status = "ok"
+			out.printf("✅ %-14s ok (%v)\n", step.name, elapsed)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// smokeDatabase connects, checks the schema (when enabled) and runs the week discovery query
+func smokeDatabase(ctx context.Context, cfg *config.Config, logger *logrus.Logger) error {
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	if cfg.Database.CheckSchema {
+		if err := schema.Check(db, logger, requiredColumns(cfg)); err != nil {
+			return err
+		}
+	}
+	if _, err := weekmanager.NewWeekManager(db, logger, cfg.Calendar).GetAvailableWeeks(); err != nil {
+		return fmt.Errorf("failed to list weeks: %w", err)
+	}
+	return nil
+}
+
+// startMockAI serves canned chat completions on a local port until ctx is done: report for JSON
+// requests and one sentence for text requests (parent digests)
+func startMockAI(ctx context.Context, report string) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to start mock AI API: %w", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req processor.OpenAIRequest
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &req)
+
+		content := report
+		if req.ResponseFormat.Type == "text" {
+			content = "Bé Smoke đã có một tuần tiết kiệm tốt."
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(processor.OpenAIResponse{
+			ID:      "chatcmpl-smoke",
+			Model:   req.Model,
+			Choices: []processor.Choice{{Message: processor.Message{Role: "assistant", Content: content}, FinishReason: "stop"}},
+			Usage:   processor.Usage{PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150},
+		})
+	})}
+	go server.Serve(listener)
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	return "http://" + listener.Addr().String(), nil
+}
+
+// useMockAI points every AI call at the mock API, with placeholder keys where none are set
+func useMockAI(cfg *config.Config, url string) {
+	cfg.OpenAI.Provider = processor.ProviderOpenAI
+	cfg.OpenAI.BaseURL = url
+	cfg.OpenAI.StoreResponses = false
+	cfg.Gold.Consensus.SecondaryBaseURL = url
+	for _, env := range []string{processor.APIKeyEnv(processor.ProviderOpenAI), cfg.Gold.Consensus.SecondaryAPIKeyEnv} {
+		if env != "" && os.Getenv(env) == "" {
+			os.Setenv(env, "smoke-test")
+		}
+	}
+}
+
+// writeSmokeSilver writes a Silver output with one synthetic kid, in the configured compression
+func writeSmokeSilver(cfg *config.Config, path string) error {
+	age := 9
+	kid := silver.EnhancedKidData{
+		ProfileID:   "00000000-0000-4000-8000-000000000001",
+		Nickname:    "Smoke",
+		Age:         &age,
+		DateOfBirth: "2016-01-01",
+		ParentID:    "00000000-0000-4000-8000-000000000002",
+		CurrentWeek: silver.WeekMetrics{
+			WeekLabel:      smokeWeek,
+			StartDate:      "2025-01-06",
+			EndDate:        "2025-01-13",
+			JoyWallet:      20000,
+			SpendingWallet: 50000,
+			TotalBalance:   70000,
+			MoneyReceived:  50000,
+			TotalSpent:     10000,
+			JoySpent:       10000,
+		},
+		ActivityScore: 0.5,
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"week":       smokeWeek,
+		"total_kids": 1,
+		"kids":       []silver.EnhancedKidData{kid},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fileio.WriteFile(path, data, cfg.Data.CompressionCodec())
+	return err
+}
+
+// smokeDelivery sends the reports to a temporary outbox. The ledger is not used, so nothing is
+// recorded as delivered.
+func smokeDelivery(ctx context.Context, reportPath, outbox string) error {
+	data, err := fileio.ReadFile(reportPath)
+	if err != nil {
+		return fmt.Errorf("failed to read reports: %w", err)
+	}
+	var output struct {
+		Reports []gold.AIReport `json:"reports"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return fmt.Errorf("failed to parse reports: %w", err)
+	}
+	sender, err := delivery.NewOutboxSender(outbox)
+	if err != nil {
+		return err
+	}
+	for _, report := range output.Reports {
+		if err := sender.Send(ctx, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
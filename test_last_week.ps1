@@ -11,7 +11,7 @@ Write-Host ""
 # Check if pipeline exists
 if (-not (Test-Path ".\pipeline.exe")) {
     Write-Host "Building pipeline..." -ForegroundColor Yellow
-    go build -o pipeline.exe main.go
+    go build -o pipeline.exe .
     if ($LASTEXITCODE -ne 0) {
         Write-Host ""
         Write-Host "❌ Build failed" -ForegroundColor Red
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ai-production-pipeline/internal/config"
+	"ai-production-pipeline/internal/fileio"
+	"ai-production-pipeline/internal/weekmanager"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+// weekFileRename is one legacy output file or directory and the start-date name it gets
+type weekFileRename struct {
+	From   string `json:"from"`
+	To     string `json:"to,omitempty"`
+	Reason string `json:"reason,omitempty"` // Why it was skipped
+}
+
+// renameWeekFilesResult is rename-week-files' result
+type renameWeekFilesResult struct {
+	Renames []weekFileRename `json:"renames"`
+	Skipped []weekFileRename `json:"skipped,omitempty"`
+	DryRun  bool             `json:"dry_run"`
+}
+
+// runRenameWeekFiles renames the output files earlier versions named by week number to the
+// start-date names runs read now: pipeline rename-week-files [--yes]
+func runRenameWeekFiles(args []string) int {
+	fs := flag.NewFlagSet("rename-week-files", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "Rename the files (without it, only print the plan)")
+	out := addOutputFlag(fs, "rename-week-files")
+	fs.Parse(args)
+	if err := out.check(); err != nil {
+		return out.fail(2, err)
+	}
+
+	godotenv.Load()
+	cfg, err := config.LoadConfig("config/config.yaml")
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to load config: %w", err))
+	}
+	logger := setupLogger(cfg)
+
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to connect to database: %w", err))
+	}
+	defer db.Close()
+
+	weeks, err := weekmanager.NewWeekManager(db, logger, cfg.Calendar).GetAvailableWeeks()
+	if err != nil {
+		return out.fail(1, fmt.Errorf("failed to get available weeks: %w", err))
+	}
+
+	result, err := planWeekFileRenames(cfg.Data.OutputDir, weeks)
+	if err != nil {
+		return out.fail(1, err)
+	}
+	result.DryRun = !*yes
+	for _, rename := range result.Renames {
+		out.printf("%s → %s\n", rename.From, rename.To)
+	}
+	for _, skipped := range result.Skipped {
+		out.printf("⏭️  %s: %s\n", skipped.From, skipped.Reason)
+	}
+	if len(result.Renames) == 0 {
+		out.printf("✅ Nothing to rename\n")
+		return out.done(result)
+	}
+	if !*yes {
+		out.printf("\nDry run: re-run with --yes to rename these files\n")
+		return out.done(result)
+	}
+
+	for _, rename := range result.Renames {
+		if err := os.Rename(filepath.Join(cfg.Data.OutputDir, rename.From), filepath.Join(cfg.Data.OutputDir, rename.To)); err != nil {
+			return out.exit(1, result, fmt.Errorf("failed to rename %s: %w", rename.From, err))
+		}
+	}
+	logger.Infof("📁 Renamed %d week files in %s", len(result.Renames), cfg.Data.OutputDir)
+	return out.done(result)
+}
+
+// planWeekFileRenames maps every legacy output in outputDir to its week. A file whose recorded week
+// label belongs to another week (the numbering shifted since it was written) follows the label;
+// one whose label matches no week is skipped. Render directories follow their report file.
+func planWeekFileRenames(outputDir string, weeks []weekmanager.WeekRange) (*renameWeekFilesResult, error) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", outputDir, err)
+	}
+	byNumber := make(map[int]weekmanager.WeekRange, len(weeks))
+	byLabel := make(map[string]weekmanager.WeekRange, len(weeks))
+	for _, week := range weeks {
+		byNumber[week.WeekNumber] = week
+		byLabel[week.Label] = week
+	}
+
+	result := &renameWeekFilesResult{}
+	resolved := make(map[string]weekmanager.WeekRange) // "<prefix>_week_<N>" → week, from its file
+	var dirs []os.DirEntry
+	for _, entry := range entries {
+		prefix, number, suffix, ok := weekmanager.ParseLegacyOutputFile(entry.Name())
+		if !ok {
+			continue
+		}
+		if entry.IsDir() {
+			dirs = append(dirs, entry)
+			continue
+		}
+		week, reason := legacyFileWeek(filepath.Join(outputDir, entry.Name()), number, byNumber, byLabel)
+		if reason != "" {
+			result.Skipped = append(result.Skipped, weekFileRename{From: entry.Name(), Reason: reason})
+			continue
+		}
+		resolved[fmt.Sprintf("%s_week_%d", prefix, number)] = week
+		result.add(outputDir, entry.Name(), weekmanager.OutputName(prefix, week.Key(), suffix))
+	}
+	for _, entry := range dirs {
+		prefix, number, suffix, _ := weekmanager.ParseLegacyOutputFile(entry.Name())
+		week, ok := resolved[fmt.Sprintf("%s_week_%d", prefix, number)]
+		if !ok {
+			result.Skipped = append(result.Skipped, weekFileRename{From: entry.Name(), Reason: "no report file to take its week from"})
+			continue
+		}
+		result.add(outputDir, entry.Name(), weekmanager.OutputName(prefix, week.Key(), suffix))
+	}
+	sort.Slice(result.Renames, func(i, j int) bool { return result.Renames[i].From < result.Renames[j].From })
+	return result, nil
+}
+
+// add plans renaming from to to, unless to already exists
+func (r *renameWeekFilesResult) add(outputDir, from, to string) {
+	if _, err := os.Stat(filepath.Join(outputDir, to)); err == nil {
+		r.Skipped = append(r.Skipped, weekFileRename{From: from, To: to, Reason: "target already exists"})
+		return
+	}
+	r.Renames = append(r.Renames, weekFileRename{From: from, To: to})
+}
+
+// legacyFileWeek returns the week of a legacy output file: the week with its recorded label, else
+// week number. reason is set when it cannot be told.
+func legacyFileWeek(path string, number int, byNumber map[int]weekmanager.WeekRange, byLabel map[string]weekmanager.WeekRange) (weekmanager.WeekRange, string) {
+	var recorded struct {
+		Week string `json:"week"`
+	}
+	data, err := fileio.ReadFile(path)
+	if err != nil {
+		return weekmanager.WeekRange{}, err.Error()
+	}
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return weekmanager.WeekRange{}, fmt.Sprintf("not a week output: %v", err)
+	}
+	if recorded.Week != "" {
+		if week, ok := byLabel[recorded.Week]; ok {
+			return week, ""
+		}
+		return weekmanager.WeekRange{}, fmt.Sprintf("week %q is not in the database", recorded.Week)
+	}
+	if week, ok := byNumber[number]; ok {
+		return week, ""
+	}
+	return weekmanager.WeekRange{}, fmt.Sprintf("week %d is not in the database", number)
+}
+
+// warnLegacyWeekFiles warns when outputDir still holds outputs named by week number, which runs no
+// longer read (reuse_existing, history, monthly rollups and regenerate would miss them)
+func warnLegacyWeekFiles(logger *logrus.Logger, outputDir string) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return
+	}
+	count := 0
+	for _, entry := range entries {
+		if _, _, _, ok := weekmanager.ParseLegacyOutputFile(entry.Name()); ok {
+			count++
+		}
+	}
+	if count > 0 {
+		logger.Warnf("⚠️  %d files in %s are still named by week number and are ignored; run pipeline rename-week-files to rename them", count, outputDir)
+	}
+}
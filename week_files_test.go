@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"ai-production-pipeline/internal/weekmanager"
+)
+
+func TestPlanWeekFileRenames(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mkdir := func(name string) {
+		t.Helper()
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	week := func(number int, label string, start string) weekmanager.WeekRange {
+		date, _ := time.Parse("2006-01-02", start)
+		return weekmanager.WeekRange{WeekNumber: number, Label: label, StartDate: date, EndDate: date.AddDate(0, 0, 7)}
+	}
+	weeks := []weekmanager.WeekRange{
+		week(1, "Tuần 1 - Tháng 10/2025", "2025-10-06"),
+		week(2, "Tuần 2 - Tháng 10/2025", "2025-10-13"),
+		week(3, "Tuần 3 - Tháng 10/2025", "2025-10-20"),
+		week(4, "Tuần 4 - Tháng 10/2025", "2025-10-27"),
+	}
+
+	// Numbering shifted since it was written: the recorded label wins, and the render directory follows
+	write("kids_reports_week_1.json", `{"week":"Tuần 2 - Tháng 10/2025","reports":[]}`)
+	mkdir("kids_reports_week_1")
+	// No recorded label: the week number is used
+	write("kids_analysis_week_3.json", `{"kids":[]}`)
+	write("notes.txt", `not an output`)
+	// Label no longer in the database
+	write("kids_reports_week_5.json", `{"week":"Tuần 1 - Tháng 9/2025"}`)
+	// Render directory without a report file
+	mkdir("kids_reports_week_7")
+	// Target already written by a newer run
+	write("kids_analysis_week_4.json", `{"week":"Tuần 4 - Tháng 10/2025"}`)
+	write("kids_analysis_week_2025-10-27.json", `{}`)
+	// Already keyed by start date
+	write("kids_reports_week_2025-10-06.json", `{}`)
+
+	result, err := planWeekFileRenames(dir, weeks)
+	if err != nil {
+		t.Fatalf("planWeekFileRenames: %v", err)
+	}
+
+	wantRenames := []weekFileRename{
+		{From: "kids_analysis_week_3.json", To: "kids_analysis_week_2025-10-20.json"},
+		{From: "kids_reports_week_1", To: "kids_reports_week_2025-10-13"},
+		{From: "kids_reports_week_1.json", To: "kids_reports_week_2025-10-13.json"},
+	}
+	if !reflect.DeepEqual(result.Renames, wantRenames) {
+		t.Errorf("Renames = %+v\nwant %+v", result.Renames, wantRenames)
+	}
+
+	skipped := make(map[string]string)
+	for _, skip := range result.Skipped {
+		skipped[skip.From] = skip.Reason
+	}
+	for _, name := range []string{"kids_reports_week_5.json", "kids_reports_week_7", "kids_analysis_week_4.json"} {
+		if skipped[name] == "" {
+			t.Errorf("%s not skipped (skipped: %v)", name, skipped)
+		}
+	}
+	if len(result.Skipped) != 3 {
+		t.Errorf("Skipped = %+v, want 3 entries", result.Skipped)
+	}
+}
+
+func TestPlanWeekFileRenamesMissingDir(t *testing.T) {
+	if _, err := planWeekFileRenames(filepath.Join(t.TempDir(), "missing"), nil); err == nil {
+		t.Error("want an error for a missing output directory")
+	}
+}